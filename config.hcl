@@ -187,6 +187,14 @@ products {
   }
 }
 
+// search configures the search provider.
+search {
+  // provider is the search provider to use for document, draft, internal, and
+  // link indexes. Valid values are "algolia" (default) and "postgres". The
+  // "postgres" provider uses full-text search in the app database.
+  provider = "algolia"
+}
+
 // server contains the configuration for the server.
 server {
   // addr is the address to bind to for listening.
@@ -194,3 +202,17 @@ server {
   // uncomment this if usign docker compose or deploying
   // addr = "0.0.0.0:8000"
 }
+
+// storage configures the document storage provider.
+storage {
+  // provider is the storage provider to use for documents. Valid values are
+  // "google" (default) and "local". The "local" provider keeps documents as
+  // Markdown files with JSON metadata under local_root. With it, the
+  // google_workspace folder IDs are used as-is and template IDs refer to
+  // files in local_root.
+  provider = "google"
+
+  // local_root is the directory used to store documents by the "local"
+  // provider.
+  // local_root = "./data/documents"
+}
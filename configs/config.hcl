@@ -11,18 +11,42 @@ algolia {
   internal_index_name       = "internal"
   links_index_name          = "links"
   missing_fields_index_name = "missing_fields"
+  sections_index_name       = "docs_sections"
   search_api_key            = ""
   write_api_key             = ""
 }
 
-// document_types configures document types. Currently this block should not be
-// modified, but Hermes will support custom document types in the near future.
-// *** DO NOT MODIFY document_types ***
+// digests configures daily and weekly digest emails of subscribed document
+// events, for users who chose to receive them instead of an email per event.
+digests {
+  // enabled enables sending digest emails in the server. Email must also be
+  // enabled.
+  enabled = false
+
+  // hour is the hour of the day (0-23, UTC) to send digests.
+  hour = 9
+
+  // interval is the time between checks for digests to send.
+  interval = "1h"
+
+  // weekly_day is the day of the week to send weekly digests.
+  weekly_day = "monday"
+}
+
+// document_types seeds document types, which are created in the database at
+// startup if they don't exist (and weren't deleted). Once created, document
+// types are managed with the "/api/v1/document-types" API, and changes to this
+// block don't affect them.
 document_types {
   document_type "RFC" {
     description = "Create a Request for Comments document to present a proposal to colleagues for their review and feedback."
     template    = "1Oz_7FhaWxdFUDEzKCC5Cy58t57C4znmC_Qr80BORy1U"
 
+    // index_content enables indexing the content of published documents of
+    // this type for full-text search. Content is split into sections at
+    // headings, which are indexed as separate search records.
+    index_content = true
+
     more_info_link {
       text = "More info on the RFC template"
       url  = "https://works.hashicorp.com/articles/rfc-template"
@@ -44,6 +68,20 @@ document_types {
       name = "Target Version"
       type = "string"
     }
+
+    // approval_policy requires approvals before documents of this type are
+    // approved. Stages are satisfied in order; if approvers isn't set, a stage
+    // is approved by the document's reviewers, and if min_approvals isn't set,
+    // all approvers must approve. Without a policy, document owners mark
+    // documents as approved.
+    // approval_policy {
+    //   stage "Tech lead" {
+    //     approvers = ["techlead@yourorganization.com"]
+    //   }
+    //   stage "Reviewers" {
+    //     min_approvals = 2
+    //   }
+    // }
   }
 
   document_type "PRD" {
@@ -73,6 +111,24 @@ email {
 
   // from_address is the email address to send email notifications from.
   from_address = "hermes@yourorganization.com"
+
+  // provider is the provider used to send emails: "gmail" (default) sends
+  // emails using the Gmail API of the Google Workspace service, and "smtp"
+  // sends emails using the SMTP server configured below (e.g., a local mail
+  // server for development).
+  provider = "gmail"
+
+  // smtp configures the SMTP server used to send emails if provider is "smtp".
+  smtp {
+    host = "localhost"
+    port = 1025
+
+    // username and password authenticate with the SMTP server. Authentication
+    // is disabled if username isn't set. The password can also be set using
+    // the SMTP_PASSWORD environment variable.
+    username = ""
+    password = ""
+  }
 }
 
 // google_workspace configures Hermes to work with Google Workspace.
@@ -112,10 +168,39 @@ google_workspace {
 
 // indexer contains the configuration for the indexer.
 indexer {
+  // leader_election enables leader election between indexer replicas, so that
+  // only one replica indexes documents at a time.
+  leader_election = false
+
+  // lease_duration is the duration of the leader lease, which is the maximum
+  // time before another replica takes over if the leader stops.
+  lease_duration = "30s"
+
   // max_parallel_docs is the maximum number of documents that will be
   // simultaneously indexed.
   max_parallel_docs = 5
 
+  // metrics_addr is the address that the indexer serves Prometheus metrics (at
+  // /metrics), health (at /health), and Drive push notifications (at
+  // /drive/notifications) on.
+  metrics_addr = ":9091"
+
+  // poll_interval is the time between indexer runs.
+  poll_interval = "1m"
+
+  // use_drive_changes enables finding updated documents using the Google Drive
+  // Changes API.
+  use_drive_changes = false
+
+  // drive_notification_url is the public HTTPS URL of the indexer's
+  // /drive/notifications endpoint. If set, Drive push notifications trigger
+  // indexer runs as soon as documents change (requires use_drive_changes).
+  // drive_notification_url = "https://hermes-indexer.example.com/drive/notifications"
+
+  // drive_notification_token is a secret token that push notifications must
+  // include.
+  // drive_notification_token = ""
+
   // update_doc_headers enables the indexer to automatically update document
   // headers for changed documents based on Hermes metadata.
   update_doc_headers = true
@@ -141,6 +226,44 @@ okta {
   disabled = true
 }
 
+// oidc configures Hermes to authenticate users with a generic OpenID Connect
+// provider (e.g., Keycloak) using the authorization code flow and session
+// cookies. If enabled, it takes precedence over Okta and Google authentication.
+oidc {
+  // issuer_url is the URL of the OpenID provider.
+  issuer_url = "https://keycloak.yourorganization.com/realms/hermes"
+
+  // client_id is the OIDC client ID.
+  client_id = ""
+
+  // client_secret is the OIDC client secret.
+  client_secret = ""
+
+  // redirect_url is the URL of the Hermes OIDC callback endpoint, which must be
+  // allowed by the OIDC client.
+  redirect_url = "http://localhost:8000/auth/oidc/callback"
+
+  // email_claim is the ID token claim containing the user's email address.
+  email_claim = "email"
+
+  // groups_claim is the ID token claim containing the user's groups.
+  groups_claim = "groups"
+
+  // admin_groups are the groups whose members are granted the admin role when
+  // they sign in.
+  admin_groups = []
+
+  // session_duration is the duration of user sessions.
+  session_duration = "12h"
+
+  // session_secret is the secret used to sign session cookies. It must be at
+  // least 32 characters.
+  session_secret = ""
+
+  // disabled disables OIDC authentication.
+  disabled = true
+}
+
 // postgres configures PostgreSQL as the app database.
 postgres {
   dbname   = "db"
@@ -164,8 +287,81 @@ products {
   }
 }
 
+// reminders configures reminders sent to reviewers who haven't approved a
+// document as its due date approaches and again once it is overdue.
+reminders {
+  // enabled enables the reminder scheduler in the server.
+  enabled = false
+
+  // days_before is the number of days before the due date to start reminding
+  // reviewers.
+  days_before = 2
+
+  // interval is the time between checks for reminders to send.
+  interval = "1h"
+
+  // slack enables sending reminders as Slack direct messages. Email reminders
+  // are sent if email is enabled.
+  slack = false
+}
+
+// saved_searches configures notifications of new documents matching users'
+// saved searches.
+saved_searches {
+  // enabled enables checking saved searches for new matches in the server.
+  enabled = false
+
+  // interval is the time between checks of saved searches.
+  interval = "1h"
+
+  // slack enables sending notifications as Slack direct messages. Email
+  // notifications are sent if email is enabled.
+  slack = false
+}
+
 // server contains the configuration for the server.
 server {
   // addr is the address to bind to for listening.
   addr = "127.0.0.1:8000"
+
+  // disable_algolia_proxy disables the /1/indexes/ endpoint that proxies
+  // Algolia queries with the search API key. Use /api/v1/search instead.
+  disable_algolia_proxy = false
+
+  // metrics_addr is the address that the server serves Prometheus metrics (at
+  // /metrics) on. It should not be reachable by users, so it only listens on
+  // the loopback interface by default. Use an address like ":9090" to let a
+  // Prometheus server on another host scrape it.
+  metrics_addr = "127.0.0.1:9090"
+}
+
+// slack configures the Slack app used to send Slack messages. The app's bot
+// token is set using the SLACK_BOT_ACCESS_TOKEN environment variable.
+slack {
+  // bot_token is the bot token of the Slack app, used to send Slack direct
+  // messages. Slack notifications are disabled if it isn't set. It can also
+  // be set using the SLACK_BOT_ACCESS_TOKEN environment variable.
+  bot_token = ""
+
+  // signing_secret is the signing secret of the Slack app, used to verify
+  // requests from Slack. Set it (or the SLACK_SIGNING_SECRET environment
+  // variable) to add Approve and Request changes buttons to review request
+  // messages. The app's interactivity request URL must be set to
+  // "<base_url>/api/v1/slack/interactions". If reminders are enabled with
+  // slack = true, messages also have a Snooze button, and snoozed review
+  // requests are sent again by the reminders scheduler. It also
+  // enables the "/docvault" slash command, whose request URL must be set to
+  // "<base_url>/api/v1/slack/commands".
+  signing_secret = ""
+}
+
+// webhooks configures delivery of document events to outbound webhooks, which
+// are managed by admins using the /api/v1/webhooks API.
+webhooks {
+  // max_attempts is the number of attempts to deliver an event to a webhook
+  // before giving up. Failed attempts are retried with exponential backoff.
+  max_attempts = 10
+
+  // poll_interval is the time between checks for pending deliveries.
+  poll_interval = "10s"
 }
@@ -1,24 +1,32 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
+	"github.com/hashicorp-forge/hermes/internal/notify"
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
 	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"github.com/hashicorp/go-hclog"
 	"gorm.io/gorm"
 )
 
+// ApprovalHandler handles requests to approve a document at
+// "/api/v1/approvals/{id}" (POST), and to request changes of it (DELETE).
 func ApprovalHandler(
 	cfg *config.Config,
 	l hclog.Logger,
-	ar *algolia.Client,
-	aw *algolia.Client,
+	sp search.Provider,
+	st storage.Provider,
 	s *gw.Service,
-	db *gorm.DB) http.Handler {
+	db *gorm.DB,
+	n notify.Channel,
+) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -35,169 +43,10 @@ func ApprovalHandler(
 				return
 			}
 
-			// Check if document is locked.
-			locked, err := hcd.IsLocked(docID, db, s, l)
-			if err != nil {
-				l.Error("error checking document locked status",
-					"error", err,
-					"path", r.URL.Path,
-					"method", r.Method,
-					"doc_id", docID,
-				)
-				http.Error(w, "Error getting document status", http.StatusNotFound)
-				return
-			}
-			// Don't continue if document is locked.
-			if locked {
-				http.Error(w, "Document is locked", http.StatusLocked)
-				return
-			}
-
-			// Get base document object from Algolia so we can determine the doc type.
-			baseDocObj := &hcd.BaseDoc{}
-			err = ar.Docs.GetObject(docID, &baseDocObj)
-			if err != nil {
-				l.Error("error requesting base document object from Algolia",
-					"error", err,
-					"path", r.URL.Path,
-					"method", r.Method,
-					"doc_id", docID,
-				)
-				http.Error(w, "Error requesting changes of document",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Create new document object of the proper doc type.
-			docObj, err := hcd.NewEmptyDoc(baseDocObj.DocType)
-			if err != nil {
-				l.Error("error creating new empty doc",
-					"error", err,
-					"path", r.URL.Path,
-					"method", r.Method,
-					"doc_id", docID,
-				)
-				http.Error(w, "Error requesting changes of document",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Get document object from Algolia.
-			err = ar.Docs.GetObject(docID, &docObj)
-			if err != nil {
-				l.Error("error getting document from Algolia",
-					"error", err,
-					"doc_id", docID,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-				http.Error(w, "Error accessing document",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Authorize request.
 			userEmail := r.Context().Value("userEmail").(string)
-			if docObj.GetStatus() != "In-Review" {
-				http.Error(w,
-					"Can only request changes of documents in the \"In-Review\" status",
-					http.StatusBadRequest)
-				return
-			}
-			if !contains(docObj.GetReviewers(), userEmail) {
-				http.Error(w, "Not authorized as a document reviewer",
-					http.StatusUnauthorized)
-				return
-			}
-			if contains(docObj.GetChangesRequestedBy(), userEmail) {
-				http.Error(w, "Document already has changes requested by user",
-					http.StatusBadRequest)
-				return
-			}
-
-			// Add email to slice of users who have requested changes of the document.
-			docObj.SetChangesRequestedBy(
-				append(docObj.GetChangesRequestedBy(), userEmail))
-
-			// If user had previously reviewed, delete email from slice of users who
-			// have reviewed the document.
-			var newReviewedBy []string
-			for _, a := range docObj.GetReviewedBy() {
-				if a != userEmail {
-					newReviewedBy = append(newReviewedBy, a)
-				}
-			}
-			docObj.SetReviewedBy(newReviewedBy)
-
-			// Get latest Google Drive file revision.
-			latestRev, err := s.GetLatestRevision(docID)
-			if err != nil {
-				l.Error("error getting latest revision",
-					"error", err,
-					"method", r.Method,
-					"path", r.URL.Path,
-					"doc_id", docID)
-				http.Error(w, "Error requesting changes of document",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Mark latest revision to be kept forever.
-			_, err = s.KeepRevisionForever(docID, latestRev.Id)
-			if err != nil {
-				l.Error("error marking revision to keep forever",
-					"error", err,
-					"method", r.Method,
-					"path", r.URL.Path,
-					"doc_id", docID,
-					"rev_id", latestRev.Id)
-				http.Error(w, "Error requesting changes",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Record file revision in the Algolia document object.
-			revisionName := fmt.Sprintf("Changes requested by %s", userEmail)
-			docObj.SetFileRevision(latestRev.Id, revisionName)
-
-			// Save modified doc object in Algolia.
-			res, err := aw.Docs.SaveObject(docObj)
-			if err != nil {
-				l.Error("error saving requested changes doc object in Algolia",
-					"error", err,
-					"doc_id", docID,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-				http.Error(w, "Error requesting changes of document",
-					http.StatusInternalServerError)
-				return
-			}
-			err = res.Wait()
-			if err != nil {
-				l.Error("error saving requested changes doc object in Algolia",
-					"error", err,
-					"doc_id", docID,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-				http.Error(w, "Error requesting changes of document",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Replace the doc header.
-			err = docObj.ReplaceHeader(
-				docID, cfg.BaseURL, true, s)
-			if err != nil {
-				l.Error("error replacing doc header",
-					"error", err,
-					"doc_id", docID,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-				http.Error(w, "Error requesting changes of document",
-					http.StatusInternalServerError)
+			if err := requestDocumentChanges(
+				cfg, l, sp, st, db, r, docID, userEmail); err != nil {
+				respondApprovalError(w, err)
 				return
 			}
 
@@ -224,170 +73,10 @@ func ApprovalHandler(
 				return
 			}
 
-			// Check if document is locked.
-			locked, err := hcd.IsLocked(docID, db, s, l)
-			if err != nil {
-				l.Error("error checking document locked status",
-					"error", err,
-					"path", r.URL.Path,
-					"method", r.Method,
-					"doc_id", docID,
-				)
-				http.Error(w, "Error getting document status", http.StatusNotFound)
-				return
-			}
-			// Don't continue if document is locked.
-			if locked {
-				http.Error(w, "Document is locked", http.StatusLocked)
-				return
-			}
-
-			// Get base document object from Algolia so we can determine the doc type.
-			baseDocObj := &hcd.BaseDoc{}
-			err = ar.Docs.GetObject(docID, &baseDocObj)
-			if err != nil {
-				l.Error("error requesting base document object from Algolia",
-					"error", err,
-					"path", r.URL.Path,
-					"method", r.Method,
-					"doc_id", docID,
-				)
-				http.Error(w, "Error creating review",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Create new document object of the proper doc type.
-			docObj, err := hcd.NewEmptyDoc(baseDocObj.DocType)
-			if err != nil {
-				l.Error("error creating new empty doc",
-					"error", err,
-					"path", r.URL.Path,
-					"method", r.Method,
-					"doc_id", docID,
-				)
-				http.Error(w, "Error creating review",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Get document object from Algolia.
-			err = ar.Docs.GetObject(docID, &docObj)
-			if err != nil {
-				l.Error("error getting document from Algolia",
-					"error", err,
-					"doc_id", docID,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-				http.Error(w, "Error accessing document",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Authorize request.
 			userEmail := r.Context().Value("userEmail").(string)
-			if docObj.GetStatus() != "In-Review" && docObj.GetStatus() != "In Review" {
-				http.Error(w,
-					"Only documents in the \"In-Review\" status can be reviewed",
-					http.StatusBadRequest)
-				return
-			}
-			if !contains(docObj.GetReviewers(), userEmail) {
-				http.Error(w,
-					"Not authorized as a document reviewer",
-					http.StatusUnauthorized)
-				return
-			}
-			if contains(docObj.GetReviewedBy(), userEmail) {
-				http.Error(w,
-					"Document already reviewed by user",
-					http.StatusBadRequest)
-				return
-			}
-
-			// Add email to slice of users who have reviewed the document.
-			docObj.SetReviewedBy(append(docObj.GetReviewedBy(), userEmail))
-
-			// If the user had previously requested changes, delete email from slice
-			// of users who have requested changes of the document.
-			var newChangesRequestedBy []string
-			for _, a := range docObj.GetChangesRequestedBy() {
-				if a != userEmail {
-					newChangesRequestedBy = append(newChangesRequestedBy, a)
-				}
-			}
-			docObj.SetChangesRequestedBy(newChangesRequestedBy)
-
-			// Get latest Google Drive file revision.
-			latestRev, err := s.GetLatestRevision(docID)
-			if err != nil {
-				l.Error("error getting latest revision",
-					"error", err,
-					"method", r.Method,
-					"path", r.URL.Path,
-					"doc_id", docID)
-				http.Error(w, "Error creating review",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Mark latest revision to be kept forever.
-			_, err = s.KeepRevisionForever(docID, latestRev.Id)
-			if err != nil {
-				l.Error("error marking revision to keep forever",
-					"error", err,
-					"method", r.Method,
-					"path", r.URL.Path,
-					"doc_id", docID,
-					"rev_id", latestRev.Id)
-				http.Error(w, "Error creating review",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Record file revision in the Algolia document object.
-			revisionName := fmt.Sprintf("Reviewed by %s", userEmail)
-			docObj.SetFileRevision(latestRev.Id, revisionName)
-
-			// Save modified doc object in Algolia.
-			res, err := aw.Docs.SaveObject(docObj)
-			if err != nil {
-				l.Error("error saving reviewed doc object in Algolia",
-					"error", err,
-					"doc_id", docID,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-				http.Error(w, "Error reviewing document",
-					http.StatusInternalServerError)
-				return
-			}
-			err = res.Wait()
-			if err != nil {
-				l.Error("error saving reviewed doc object in Algolia",
-					"error", err,
-					"doc_id", docID,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-				http.Error(w, "Error reviewing document",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// Replace the doc header.
-			err = docObj.ReplaceHeader(
-				docID, cfg.BaseURL, true, s)
-			if err != nil {
-				l.Error("error replacing doc header",
-					"error", err,
-					"doc_id", docID,
-					"method", r.Method,
-					"path", r.URL.Path,
-				)
-				http.Error(w, "Error reviewing document",
-					http.StatusInternalServerError)
+			if err := approveDocument(
+				cfg, l, sp, st, db, n, r, docID, userEmail); err != nil {
+				respondApprovalError(w, err)
 				return
 			}
 
@@ -407,3 +96,505 @@ func ApprovalHandler(
 		}
 	})
 }
+
+// approvalError is an error approving or requesting changes of a document,
+// with the HTTP status code and message to respond with. Errors are logged
+// where they occur.
+type approvalError struct {
+	code int
+	msg  string
+}
+
+func (e *approvalError) Error() string {
+	return e.msg
+}
+
+// respondApprovalError responds to an HTTP request with the status code and
+// message of approval error err.
+func respondApprovalError(w http.ResponseWriter, err error) {
+	var ae *approvalError
+	if errors.As(err, &ae) {
+		http.Error(w, ae.msg, ae.code)
+		return
+	}
+	http.Error(w, "Error reviewing document", http.StatusInternalServerError)
+}
+
+// requestDocumentChanges records that the reviewer with email address
+// userEmail requested changes of the in-review document with ID docID. Request
+// r is used for logging, and the audit log and webhook events; its context
+// must contain the reviewer's email address. It returns an *approvalError if
+// the changes can't be requested.
+func requestDocumentChanges(
+	cfg *config.Config,
+	l hclog.Logger,
+	sp search.Provider,
+	st storage.Provider,
+	db *gorm.DB,
+	r *http.Request,
+	docID string,
+	userEmail string,
+) error {
+	// Check if document is locked.
+	locked, err := hcd.IsLocked(docID, db, st, l)
+	if err != nil {
+		l.Error("error checking document locked status",
+			"error", err,
+			"path", r.URL.Path,
+			"method", r.Method,
+			"doc_id", docID,
+		)
+		return &approvalError{http.StatusNotFound,
+			"Error getting document status"}
+	}
+	// Don't continue if document is locked.
+	if locked {
+		return &approvalError{http.StatusLocked, "Document is locked"}
+	}
+
+	// Get base document object from search index so we can determine the doc type.
+	baseDocObj := &hcd.BaseDoc{}
+	err = sp.Docs().GetObject(docID, &baseDocObj)
+	if err != nil {
+		l.Error("error requesting base document object from search index",
+			"error", err,
+			"path", r.URL.Path,
+			"method", r.Method,
+			"doc_id", docID,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error requesting changes of document"}
+	}
+
+	// Create new document object of the proper doc type.
+	docObj, err := hcd.NewEmptyDoc(baseDocObj.DocType)
+	if err != nil {
+		l.Error("error creating new empty doc",
+			"error", err,
+			"path", r.URL.Path,
+			"method", r.Method,
+			"doc_id", docID,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error requesting changes of document"}
+	}
+
+	// Get document object from search index.
+	err = sp.Docs().GetObject(docID, &docObj)
+	if err != nil {
+		l.Error("error getting document from search index",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error accessing document"}
+	}
+
+	// Authorize request.
+	if docObj.GetStatus() != "In-Review" {
+		return &approvalError{http.StatusBadRequest,
+			"Can only request changes of documents in the \"In-Review\" status"}
+	}
+	if !contains(docObj.GetReviewers(), userEmail) {
+		return &approvalError{http.StatusUnauthorized,
+			"Not authorized as a document reviewer"}
+	}
+	if contains(docObj.GetChangesRequestedBy(), userEmail) {
+		return &approvalError{http.StatusBadRequest,
+			"Document already has changes requested by user"}
+	}
+
+	// Save the document state before the review for the audit log.
+	before, err := auditSnapshot(docObj)
+	if err != nil {
+		l.Error("error encoding document object for audit log",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"doc_id", docID)
+		return &approvalError{http.StatusInternalServerError,
+			"Error requesting changes of document"}
+	}
+
+	// Add email to slice of users who have requested changes of the document.
+	docObj.SetChangesRequestedBy(
+		append(docObj.GetChangesRequestedBy(), userEmail))
+
+	// If user had previously reviewed, delete email from slice of users who
+	// have reviewed the document.
+	var newReviewedBy []string
+	for _, a := range docObj.GetReviewedBy() {
+		if a != userEmail {
+			newReviewedBy = append(newReviewedBy, a)
+		}
+	}
+	docObj.SetReviewedBy(newReviewedBy)
+
+	// Get latest Google Drive file revision.
+	latestRev, err := st.GetLatestRevision(docID)
+	if err != nil {
+		l.Error("error getting latest revision",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"doc_id", docID)
+		return &approvalError{http.StatusInternalServerError,
+			"Error requesting changes of document"}
+	}
+
+	// Mark latest revision to be kept forever.
+	_, err = st.KeepRevisionForever(docID, latestRev.Id)
+	if err != nil {
+		l.Error("error marking revision to keep forever",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"doc_id", docID,
+			"rev_id", latestRev.Id)
+		return &approvalError{http.StatusInternalServerError,
+			"Error requesting changes"}
+	}
+
+	// Record file revision in the search document object.
+	revisionName := fmt.Sprintf("Changes requested by %s", userEmail)
+	docObj.SetFileRevision(latestRev.Id, revisionName)
+
+	// Record the review in the audit log.
+	ae, err := newAuditEvent(
+		r, models.ChangesRequestedAuditAction, docID, "", before, docObj)
+	if err == nil {
+		err = withAuditEvent(db, ae, func(tx *gorm.DB) error {
+			return enqueueWebhookEvent(tx, r, cfg.BaseURL,
+				models.DocumentChangesRequestedWebhookEvent, docObj)
+		})
+	}
+	if err != nil {
+		l.Error("error recording requested changes in database",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error requesting changes of document"}
+	}
+
+	// Save modified doc object in search index. This is done after the database
+	// transaction is committed, as the search index can't be rolled back.
+	if err := sp.Docs().SaveObject(docObj); err != nil {
+		l.Error("error saving requested changes doc object in search index "+
+			"after recording the review in the database; the search index is "+
+			"out of sync",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error requesting changes of document"}
+	}
+
+	// Replace the doc header.
+	err = docObj.ReplaceHeader(
+		docID, cfg.BaseURL, true, st)
+	if err != nil {
+		l.Error("error replacing doc header",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error requesting changes of document"}
+	}
+
+	return nil
+}
+
+// approveDocument records that the reviewer with email address userEmail
+// approved the in-review document with ID docID, and marks the document as
+// reviewed once its document type's approval policy is satisfied. Request r is
+// used for logging, and the audit log and webhook events; its context must
+// contain the reviewer's email address. It returns an *approvalError if the
+// document can't be approved.
+func approveDocument(
+	cfg *config.Config,
+	l hclog.Logger,
+	sp search.Provider,
+	st storage.Provider,
+	db *gorm.DB,
+	n notify.Channel,
+	r *http.Request,
+	docID string,
+	userEmail string,
+) error {
+	// Check if document is locked.
+	locked, err := hcd.IsLocked(docID, db, st, l)
+	if err != nil {
+		l.Error("error checking document locked status",
+			"error", err,
+			"path", r.URL.Path,
+			"method", r.Method,
+			"doc_id", docID,
+		)
+		return &approvalError{http.StatusNotFound,
+			"Error getting document status"}
+	}
+	// Don't continue if document is locked.
+	if locked {
+		return &approvalError{http.StatusLocked, "Document is locked"}
+	}
+
+	// Get base document object from search index so we can determine the doc type.
+	baseDocObj := &hcd.BaseDoc{}
+	err = sp.Docs().GetObject(docID, &baseDocObj)
+	if err != nil {
+		l.Error("error requesting base document object from search index",
+			"error", err,
+			"path", r.URL.Path,
+			"method", r.Method,
+			"doc_id", docID,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error creating review"}
+	}
+
+	// Create new document object of the proper doc type.
+	docObj, err := hcd.NewEmptyDoc(baseDocObj.DocType)
+	if err != nil {
+		l.Error("error creating new empty doc",
+			"error", err,
+			"path", r.URL.Path,
+			"method", r.Method,
+			"doc_id", docID,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error creating review"}
+	}
+
+	// Get document object from search index.
+	err = sp.Docs().GetObject(docID, &docObj)
+	if err != nil {
+		l.Error("error getting document from search index",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error accessing document"}
+	}
+
+	// Authorize request.
+	if docObj.GetStatus() != "In-Review" && docObj.GetStatus() != "In Review" {
+		return &approvalError{http.StatusBadRequest,
+			"Only documents in the \"In-Review\" status can be reviewed"}
+	}
+	policy, err := getApprovalPolicy(db, docObj.GetDocType())
+	if err != nil {
+		l.Error("error getting approval policy",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"doc_id", docID)
+		return &approvalError{http.StatusInternalServerError,
+			"Error creating review"}
+	}
+	// Explicit approvers of approval policy stages can approve documents even
+	// if they weren't added as reviewers.
+	if !contains(docObj.GetReviewers(), userEmail) &&
+		!policy.IsApprover(userEmail) {
+		return &approvalError{http.StatusUnauthorized,
+			"Not authorized as a document reviewer"}
+	}
+	if contains(docObj.GetReviewedBy(), userEmail) {
+		return &approvalError{http.StatusBadRequest,
+			"Document already reviewed by user"}
+	}
+
+	// Check that the approval policy of the document type allows the user to
+	// approve the document at this stage.
+	if err := policy.CanApprove(
+		docObj.GetReviewers(), docObj.GetReviewedBy(), userEmail); err != nil {
+		return &approvalError{http.StatusConflict,
+			fmt.Sprintf("Document can't be reviewed by user yet: %s", err)}
+	}
+
+	// Save the document state before the review for the audit log.
+	before, err := auditSnapshot(docObj)
+	if err != nil {
+		l.Error("error encoding document object for audit log",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"doc_id", docID)
+		return &approvalError{http.StatusInternalServerError,
+			"Error creating review"}
+	}
+
+	// Add email to slice of users who have reviewed the document.
+	docObj.SetReviewedBy(append(docObj.GetReviewedBy(), userEmail))
+
+	// If the user had previously requested changes, delete email from slice
+	// of users who have requested changes of the document.
+	var newChangesRequestedBy []string
+	for _, a := range docObj.GetChangesRequestedBy() {
+		if a != userEmail {
+			newChangesRequestedBy = append(newChangesRequestedBy, a)
+		}
+	}
+	docObj.SetChangesRequestedBy(newChangesRequestedBy)
+
+	// Get latest Google Drive file revision.
+	latestRev, err := st.GetLatestRevision(docID)
+	if err != nil {
+		l.Error("error getting latest revision",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"doc_id", docID)
+		return &approvalError{http.StatusInternalServerError,
+			"Error creating review"}
+	}
+
+	// Mark latest revision to be kept forever.
+	_, err = st.KeepRevisionForever(docID, latestRev.Id)
+	if err != nil {
+		l.Error("error marking revision to keep forever",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"doc_id", docID,
+			"rev_id", latestRev.Id)
+		return &approvalError{http.StatusInternalServerError,
+			"Error creating review"}
+	}
+
+	// Record file revision in the search document object.
+	revisionName := fmt.Sprintf("Reviewed by %s", userEmail)
+	docObj.SetFileRevision(latestRev.Id, revisionName)
+
+	// Approve the document once the approval policy of the document type is
+	// satisfied.
+	approved := !policy.IsEmpty() && policy.Evaluate(
+		docObj.GetReviewers(), docObj.GetReviewedBy()).Satisfied
+	if approved {
+		docObj.SetStatus("Reviewed")
+	}
+
+	// Record the review in the database and the audit log.
+	ae, err := newAuditEvent(
+		r, models.DocumentApprovedAuditAction, docID, "", before, docObj)
+	if err == nil {
+		err = withAuditEvent(db, ae, func(tx *gorm.DB) error {
+			if err := enqueueWebhookEvent(tx, r, cfg.BaseURL,
+				models.DocumentApprovedWebhookEvent, docObj); err != nil {
+				return err
+			}
+			if approved {
+				d := models.Document{
+					GoogleFileID: docID,
+				}
+				if err := d.Get(tx); err != nil {
+					return fmt.Errorf("error getting document: %w", err)
+				}
+				d.Status = models.ReviewedDocumentStatus
+				if err := d.Upsert(tx); err != nil {
+					return fmt.Errorf("error upserting document: %w", err)
+				}
+				return enqueueWebhookEvent(tx, r, cfg.BaseURL,
+					models.DocumentStatusChangedWebhookEvent, docObj)
+			}
+			return nil
+		})
+	}
+	if err != nil {
+		l.Error("error recording review in database",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error reviewing document"}
+	}
+
+	// Save modified doc object in search index. This is done after the database
+	// transaction is committed, as the search index can't be rolled back.
+	if err := sp.Docs().SaveObject(docObj); err != nil {
+		l.Error("error saving reviewed doc object in search index after "+
+			"recording the review in the database; the search index is out of "+
+			"sync",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error reviewing document"}
+	}
+
+	// Notify subscribers if the document is now approved.
+	if approved {
+		notifySubscribers(cfg, l, db, n, r, docObj,
+			models.ApprovedSubscriptionEvent)
+	}
+
+	// Post the approval to the document's Slack channel threads.
+	notifySlackChannelsApproved(cfg, l, db, r, docObj, userEmail, approved)
+
+	// Notify the document owners of the approval. Failures are logged instead
+	// of failing the request because the approval has already been saved.
+	docURL, err := getDocumentURL(cfg.BaseURL, docID)
+	if err == nil {
+		var msg notify.Message
+		msg, err = newApprovalReceivedMessage(
+			cfg, docObj, docURL, userEmail, approved)
+		if err == nil {
+			err = n.Send(msg)
+		}
+	}
+	if err != nil {
+		l.Error("error notifying document owners of approval",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+	}
+
+	// Replace the doc header.
+	err = docObj.ReplaceHeader(
+		docID, cfg.BaseURL, true, st)
+	if err != nil {
+		l.Error("error replacing doc header",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return &approvalError{http.StatusInternalServerError,
+			"Error reviewing document"}
+	}
+
+	return nil
+}
+
+// getApprovalPolicy returns the approval policy of document type docType. An
+// empty policy is returned if the document type isn't in the database.
+func getApprovalPolicy(
+	db *gorm.DB, docType string) (models.ApprovalPolicy, error) {
+	dt := models.DocumentType{
+		Name: docType,
+	}
+	if err := dt.Get(db); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ApprovalPolicy{}, nil
+		}
+		return models.ApprovalPolicy{}, err
+	}
+
+	return dt.ApprovalPolicy.Data, nil
+}
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maxAuditEventsLimit is the maximum number of audit events returned in a
+// single response.
+const maxAuditEventsLimit = 1000
+
+// AuditEventResponse is an audit event returned by the audit API endpoints.
+type AuditEventResponse struct {
+	ID         uint                          `json:"id"`
+	Timestamp  time.Time                     `json:"timestamp"`
+	Actor      string                        `json:"actor"`
+	Action     models.AuditAction            `json:"action"`
+	DocumentID string                        `json:"documentID,omitempty"`
+	Target     string                        `json:"target,omitempty"`
+	Before     json.RawMessage               `json:"before,omitempty"`
+	After      json.RawMessage               `json:"after,omitempty"`
+	Diff       map[string]models.AuditChange `json:"diff"`
+}
+
+// AuditHandler returns audit events for all documents, templates, and users.
+// Only admins can access the audit log.
+func AuditHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			// Authorize request.
+			if !authorizeAdmin(w, r, l, db) {
+				return
+			}
+
+			f, err := parseAuditEventFilter(r)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			writeAuditEvents(w, r, l, db, f)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+	})
+}
+
+// serveDocumentHistory responds with the audit events of document docID. The
+// history of published documents is visible to all users, while the history of
+// drafts is only visible to their owners, contributors, and admins.
+func serveDocumentHistory(
+	w http.ResponseWriter,
+	r *http.Request,
+	l hclog.Logger,
+	sp search.Provider,
+	db *gorm.DB,
+	docID string,
+) {
+	switch r.Method {
+	case "GET":
+		// Authorize request.
+		userEmail := r.Context().Value("userEmail").(string)
+		baseDocObj := &hcd.BaseDoc{}
+		err := sp.Docs().GetObject(docID, &baseDocObj)
+		if errors.Is(err, search.ErrNotFound) {
+			err = sp.Drafts().GetObject(docID, &baseDocObj)
+			if err == nil &&
+				!contains(baseDocObj.Owners, userEmail) &&
+				!contains(baseDocObj.Contributors, userEmail) {
+				u := models.User{EmailAddress: userEmail}
+				isAdmin, adminErr := u.IsUserAdmin(db)
+				if adminErr != nil {
+					l.Error("error checking if user is an admin",
+						"error", adminErr,
+						"method", r.Method,
+						"path", r.URL.Path,
+						"doc_id", docID,
+					)
+					http.Error(w, "Error accessing document history",
+						http.StatusInternalServerError)
+					return
+				}
+				if !isAdmin {
+					http.Error(w, "Not authorized to access document history",
+						http.StatusForbidden)
+					return
+				}
+			}
+		}
+		if errors.Is(err, search.ErrNotFound) {
+			http.Error(w, "Document not found", http.StatusNotFound)
+			return
+		} else if err != nil {
+			l.Error("error requesting base document object from search index",
+				"error", err,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"doc_id", docID,
+			)
+			http.Error(w, "Error accessing document history",
+				http.StatusInternalServerError)
+			return
+		}
+
+		f, err := parseAuditEventFilter(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Bad request: %q", err),
+				http.StatusBadRequest)
+			return
+		}
+		f.DocumentID = docID
+
+		writeAuditEvents(w, r, l, db, f)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+// writeAuditEvents finds audit events matching filter f and writes them to
+// the response.
+func writeAuditEvents(
+	w http.ResponseWriter,
+	r *http.Request,
+	l hclog.Logger,
+	db *gorm.DB,
+	f models.AuditEventFilter,
+) {
+	var events models.AuditEvents
+	if err := events.Find(db, f); err != nil {
+		l.Error("error finding audit events",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		http.Error(w, "Error accessing audit log",
+			http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]AuditEventResponse, 0, len(events))
+	for _, e := range events {
+		diff, err := e.Diff()
+		if err != nil {
+			l.Error("error computing audit event diff",
+				"error", err,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"audit_event_id", e.ID,
+			)
+			http.Error(w, "Error accessing audit log",
+				http.StatusInternalServerError)
+			return
+		}
+		resp = append(resp, AuditEventResponse{
+			ID:         e.ID,
+			Timestamp:  e.CreatedAt,
+			Actor:      e.Actor,
+			Action:     e.Action,
+			DocumentID: e.DocumentID,
+			Target:     e.Target,
+			Before:     json.RawMessage(e.Before),
+			After:      json.RawMessage(e.After),
+			Diff:       diff,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(resp); err != nil {
+		l.Error("error encoding audit events response",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return
+	}
+}
+
+// parseAuditEventFilter parses an audit event filter from the "actor",
+// "action", "document", "since", "until", "limit", and "offset" URL query
+// parameters. Times can be RFC 3339 timestamps or dates.
+func parseAuditEventFilter(r *http.Request) (models.AuditEventFilter, error) {
+	q := r.URL.Query()
+	f := models.AuditEventFilter{
+		Actor:      q.Get("actor"),
+		Action:     models.AuditAction(q.Get("action")),
+		DocumentID: q.Get("document"),
+		Limit:      100,
+	}
+
+	var err error
+	if s := q.Get("since"); s != "" {
+		if f.Since, err = parseAuditTime(s); err != nil {
+			return f, fmt.Errorf("invalid since: %w", err)
+		}
+	}
+	if s := q.Get("until"); s != "" {
+		if f.Until, err = parseAuditTime(s); err != nil {
+			return f, fmt.Errorf("invalid until: %w", err)
+		}
+	}
+	if s := q.Get("limit"); s != "" {
+		if f.Limit, err = strconv.Atoi(s); err != nil || f.Limit < 1 {
+			return f, fmt.Errorf("invalid limit: %q", s)
+		}
+		if f.Limit > maxAuditEventsLimit {
+			f.Limit = maxAuditEventsLimit
+		}
+	}
+	if s := q.Get("offset"); s != "" {
+		if f.Offset, err = strconv.Atoi(s); err != nil || f.Offset < 0 {
+			return f, fmt.Errorf("invalid offset: %q", s)
+		}
+	}
+
+	return f, nil
+}
+
+// parseAuditTime parses an RFC 3339 timestamp or a "YYYY-MM-DD" date.
+func parseAuditTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(hcd.DateLayout, s)
+}
+
+// parseDocumentHistoryURLPath parses a URL path with the format
+// "{prefix}/{document_id}/history" and returns the document ID. ok is false if
+// the path isn't a document history path.
+func parseDocumentHistoryURLPath(path, prefix string) (docID string, ok bool) {
+	path = strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "history" {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// newAuditEvent returns an audit event for an action performed by the user of
+// request r. before and after are the states of the changed object (nil values
+// are omitted).
+func newAuditEvent(
+	r *http.Request,
+	action models.AuditAction,
+	docID, target string,
+	before, after interface{},
+) (models.AuditEvent, error) {
+	e := models.AuditEvent{
+		Actor:      r.Context().Value("userEmail").(string),
+		Action:     action,
+		DocumentID: docID,
+		Target:     target,
+	}
+
+	var err error
+	if e.Before, err = auditSnapshot(before); err != nil {
+		return e, fmt.Errorf("error encoding before state: %w", err)
+	}
+	if e.After, err = auditSnapshot(after); err != nil {
+		return e, fmt.Errorf("error encoding after state: %w", err)
+	}
+
+	return e, nil
+}
+
+// auditSnapshot encodes v as JSON for an audit event. Document content is
+// removed because it can be large and isn't useful in the audit log.
+func auditSnapshot(v interface{}) (datatypes.JSON, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		// Not a JSON object so store as is.
+		return datatypes.JSON(b), nil
+	}
+	delete(fields, "content")
+	b, err = json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return datatypes.JSON(b), nil
+}
+
+// withAuditEvent runs fn and creates audit event e in a single database
+// transaction, so the event is only recorded if the change succeeds (and vice
+// versa). fn should only change the database, as other changes (e.g., to the
+// search index) aren't rolled back if the transaction fails; make them after
+// withAuditEvent returns instead.
+func withAuditEvent(
+	db *gorm.DB, e models.AuditEvent, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := e.Create(tx); err != nil {
+			return fmt.Errorf("error creating audit event: %w", err)
+		}
+		return nil
+	})
+}
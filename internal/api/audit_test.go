@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDocumentHistoryURLPath(t *testing.T) {
+	cases := map[string]struct {
+		path string
+
+		wantDocID string
+		wantOK    bool
+	}{
+		"history": {
+			path:      "/api/v1/documents/myID/history",
+			wantDocID: "myID",
+			wantOK:    true,
+		},
+		"history with trailing slash": {
+			path:      "/api/v1/documents/myID/history/",
+			wantDocID: "myID",
+			wantOK:    true,
+		},
+		"document": {
+			path: "/api/v1/documents/myID",
+		},
+		"other subresource": {
+			path: "/api/v1/documents/myID/something",
+		},
+		"no document ID": {
+			path: "/api/v1/documents//history",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			docID, ok := parseDocumentHistoryURLPath(c.path, "/api/v1/documents")
+			assert.Equal(t, c.wantOK, ok)
+			assert.Equal(t, c.wantDocID, docID)
+		})
+	}
+}
+
+func TestParseAuditEventFilter(t *testing.T) {
+	cases := map[string]struct {
+		query string
+
+		want      models.AuditEventFilter
+		shouldErr bool
+	}{
+		"no parameters": {
+			want: models.AuditEventFilter{Limit: 100},
+		},
+		"all parameters": {
+			query: "actor=a@example.com&action=draft.created&document=myID" +
+				"&since=2023-05-01&until=2023-05-02T12:00:00Z&limit=10&offset=20",
+			want: models.AuditEventFilter{
+				Actor:      "a@example.com",
+				Action:     models.DraftCreatedAuditAction,
+				DocumentID: "myID",
+				Since:      time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+				Until:      time.Date(2023, 5, 2, 12, 0, 0, 0, time.UTC),
+				Limit:      10,
+				Offset:     20,
+			},
+		},
+		"limit above maximum": {
+			query: "limit=5000",
+			want:  models.AuditEventFilter{Limit: maxAuditEventsLimit},
+		},
+		"invalid since": {
+			query:     "since=yesterday",
+			shouldErr: true,
+		},
+		"invalid limit": {
+			query:     "limit=0",
+			shouldErr: true,
+		},
+		"invalid offset": {
+			query:     "offset=-1",
+			shouldErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/v1/audit?"+c.query, nil)
+			got, err := parseAuditEventFilter(r)
+			if c.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestAuditSnapshot(t *testing.T) {
+	got, err := auditSnapshot(map[string]interface{}{
+		"title":   "My Doc",
+		"content": "Lots of content",
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"title":"My Doc"}`, string(got))
+
+	got, err = auditSnapshot(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
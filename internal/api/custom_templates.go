@@ -1,20 +1,16 @@
 package api
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 
-	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
-	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
-	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
 	"github.com/hashicorp/go-hclog"
 	"gorm.io/gorm"
 )
 
+// TemplateRequest is a request to create a document type from the web app's
+// template form.
 type TemplateRequest struct {
 	Description  string `json:"description,omitempty"`
 	TemplateName string `json:"templateName"`
@@ -32,42 +28,22 @@ type TemplatePatchRequest struct {
 	DocId        string `json:"docId,omitempty"`
 }
 
-func TemplateHandler(
-	cfg *config.Config,
-	l hclog.Logger,
-	ar *algolia.Client,
-	aw *algolia.Client,
-	s *gw.Service,
-	db *gorm.DB) http.Handler {
-
+// TemplateHandler handles requests to create document types from the web app's
+// template form at "/api/v1/custom-template". Templates are document types, so
+// this is equivalent to creating a document type at "/api/v1/document-types".
+func TemplateHandler(l hclog.Logger, db *gorm.DB) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		errResp := func(httpCode int, userErrMsg, logErrMsg string, err error) {
-			l.Error(logErrMsg,
-				"method", r.Method,
-				"path", r.URL.Path,
-				"error", err,
-			)
-			http.Error(w, userErrMsg, httpCode)
-		}
-
-		// Authorize request.
-		userEmail := r.Context().Value("userEmail").(string)
-		if userEmail == "" {
-			errResp(
-				http.StatusUnauthorized,
-				"No authorization information for request",
-				"no user email found in request context",
-				nil,
-			)
-			return
-		}
-
 		switch r.Method {
 		case "POST":
+			// Only global admins can manage templates.
+			if !authorizeAdmin(w, r, l, db) {
+				return
+			}
+
 			// Decode request.
 			var req TemplateRequest
 			if err := decodeRequest(r, &req); err != nil {
-				l.Error("error decoding drafts request", "error", err)
+				l.Error("error decoding template request", "error", err)
 				http.Error(w, fmt.Sprintf("Bad request: %q", err),
 					http.StatusBadRequest)
 				return
@@ -79,178 +55,71 @@ func TemplateHandler(
 				return
 			}
 
-			// a object of base doc
-			baseTemplateObj := &hcd.BaseTemplate{
-				TemplateName: req.TemplateName,
-				Description:  req.Description,
-				DocId:        req.DocId,
-			}
-
-			res, err := aw.Template.SaveObject(baseTemplateObj)
-			if err != nil {
-				l.Error("error saving template in Algolia", "error", err)
-				http.Error(w, "Error creating template",
-					http.StatusInternalServerError)
-				return
-			}
-			err = res.Wait()
-			if err != nil {
-				l.Error("error saving template in Algolia", "error", err)
-				http.Error(w, "Error creating template",
-					http.StatusInternalServerError)
-				return
-			}
-
-			// TODO: Delete draft file in the case of an error.
-
-			// Write response.
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-
-			resp := &TemplateResponse{
-				ID: req.TemplateName,
-			}
-
-			enc := json.NewEncoder(w)
-			err = enc.Encode(resp)
-			if err != nil {
-				l.Error("error encoding template response", "error", err)
-				http.Error(w, "Error creating template",
-					http.StatusInternalServerError)
-				return
-			}
+			serveCreateDocumentType(w, r, l, db, models.DocumentType{
+				Name:           req.TemplateName,
+				Description:    req.Description,
+				TemplateFileID: req.DocId,
+			})
 
-			l.Info("created template")
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
 	})
-
 }
 
-func TemplateUpdateDeleteHandler(
-	cfg *config.Config,
-	l hclog.Logger,
-	ar *algolia.Client,
-	aw *algolia.Client,
-	s *gw.Service,
-	db *gorm.DB) http.Handler {
-
+// TemplateUpdateDeleteHandler handles requests to update and delete document
+// types from the web app's template forms at "/api/v1/custom-template/{name}".
+func TemplateUpdateDeleteHandler(l hclog.Logger, db *gorm.DB) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get document ID from URL path
-		ObjectID, err := parseURLPath(r.URL.Path, "/api/v1/custom-template")
+		// The object ID of templates is the name of their document type.
+		name, err := parseURLPath(r.URL.Path, "/api/v1/custom-template")
 		if err != nil {
-			l.Error("error requesting template from algolia",
+			l.Error("error parsing template URL path",
 				"error", err,
 				"path", r.URL.Path,
 			)
-			http.Error(w, "Error requesting template", http.StatusInternalServerError)
+			http.Error(w, "Resource not found", http.StatusNotFound)
 			return
 		}
 
-		switch r.Method {
-		case "DELETE":
-
-			// Delete object in Algolia
-			res, err := aw.Template.DeleteObject(ObjectID)
-			if err != nil {
-				l.Error("error deleting document draft from algolia",
-					"error", err,
-					"doc_id", ObjectID,
-				)
-				http.Error(w, "Error deleting document draft",
-					http.StatusInternalServerError)
-				return
-			}
-			err = res.Wait()
-			if err != nil {
-				l.Error("error deleting template from algolia",
-					"error", err,
-					"doc_id", ObjectID,
-				)
-				http.Error(w, "Error deleting template",
-					http.StatusInternalServerError)
-				return
-			}
+		// Only global admins can manage templates.
+		if !authorizeAdmin(w, r, l, db) {
+			return
+		}
 
-			resp := &DraftsResponse{
-				ID: ObjectID,
-			}
+		dt, ok := getDocumentType(w, r, l, db, name)
+		if !ok {
+			return
+		}
 
-			// Write response.
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
+		switch r.Method {
+		case "DELETE":
+			serveDeleteDocumentType(w, r, l, db, dt)
 
-			enc := json.NewEncoder(w)
-			err = enc.Encode(resp)
-			if err != nil {
-				l.Error("error encoding document id", "error", err, "templateObjectId", ObjectID)
-				http.Error(w, "Error deleting document draft",
-					http.StatusInternalServerError)
-				return
-			}
 		case "PATCH":
-			// Copy request body so we can use both for validation using the request
-			// struct, and then afterwards for patching the document JSON.
-			buf, err := ioutil.ReadAll(r.Body)
-			if err != nil {
-				l.Error("error reading request body",
-					"error", err,
-					"method", r.Method,
-					"path", r.URL.Path,
-					"object_id", ObjectID)
-				http.Error(w, "Error patching template",
-					http.StatusInternalServerError)
-				return
-			}
-			// body := ioutil.NopCloser(bytes.NewBuffer(buf))
-			newBody := ioutil.NopCloser(bytes.NewBuffer(buf))
-			r.Body = newBody
-
 			// Decode request. The request struct validates that the request only
 			// contains fields that are allowed to be patched.
 			var req TemplatePatchRequest
 			if err := decodeRequest(r, &req); err != nil {
-				l.Error("error decoding draft patch request", "error", err)
+				l.Error("error decoding template patch request", "error", err)
 				http.Error(w, fmt.Sprintf("Bad request: %q", err),
 					http.StatusBadRequest)
 				return
 			}
 
-			// fmt.Printf("body: %v\n", body)
-			// fmt.Printf("req: %v\n", req)
-
-			type template struct {
-				ObjectID string `json:"objectID"`
-				TemplateName string `json:"templateName"`
-				Description string `json:"description"`
-				DocId string `json:"docId"`
+			before := dt
+			if req.TemplateName != "" {
+				dt.Name = req.TemplateName
 			}
-			
-			updateObj := template{
-				ObjectID: ObjectID,
-				TemplateName: req.TemplateName,
-				Description: req.Description,
-				DocId: req.DocId,
+			if req.Description != "" {
+				dt.Description = req.Description
 			}
-			
-			res, err := aw.Template.PartialUpdateObject(updateObj)
-
-			err = res.Wait()
-			if err != nil {
-				l.Error("error updating template in algolia",
-					"error", err,
-					"object_id", ObjectID,
-				)
-				http.Error(w, "Error deleting template",
-					http.StatusInternalServerError)
-				return
+			if req.DocId != "" {
+				dt.TemplateFileID = req.DocId
 			}
+			serveUpdateDocumentType(w, r, l, db, before, dt)
 
-
-			w.WriteHeader(http.StatusOK)
-			l.Info("patched draft document", "object_id", ObjectID)
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
@@ -1,139 +1,509 @@
 package api
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
-	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
-	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/internal/db"
-	"github.com/hashicorp-forge/hermes/internal/pkg/doctypes"
-	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
 	"github.com/hashicorp-forge/hermes/pkg/models"
 	"github.com/hashicorp/go-hclog"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
-// Define a variable to hold the retrieved object
-type template struct {
-	//
-	ObjectId     string `json:"objectId"`
-	Description  string `json:"description,omitempty"`
+// DocumentTypeRequest is a request to create or update a document type. Fields
+// that are not set are not updated.
+type DocumentTypeRequest struct {
+	Name           *string                     `json:"name,omitempty"`
+	Description    *string                     `json:"description,omitempty"`
+	TemplateFileID *string                     `json:"templateFileID,omitempty"`
+	MoreInfoLink   *models.DocumentTypeLink    `json:"moreInfoLink,omitempty"`
+	Checks         *[]models.DocumentTypeCheck `json:"checks,omitempty"`
+	CustomFields   *[]DocumentTypeCustomField  `json:"customFields,omitempty"`
+	ApprovalPolicy *models.ApprovalPolicy      `json:"approvalPolicy,omitempty"`
+	IndexContent   *bool                       `json:"indexContent,omitempty"`
+}
+
+// DocumentTypeCustomField is a custom field of a document type.
+type DocumentTypeCustomField struct {
+	Name string `json:"name"`
+
+	// Type is the type of the custom field: "string", "person", or "people".
+	Type     string `json:"type"`
+	ReadOnly bool   `json:"readOnly"`
+}
+
+// DocumentTypeResponse is a document type returned by the document type API
+// endpoints.
+type DocumentTypeResponse struct {
+	ID             uint                       `json:"id"`
+	Name           string                     `json:"name"`
+	Description    string                     `json:"description,omitempty"`
+	TemplateFileID string                     `json:"templateFileID"`
+	MoreInfoLink   *models.DocumentTypeLink   `json:"moreInfoLink,omitempty"`
+	Checks         []models.DocumentTypeCheck `json:"checks"`
+	CustomFields   []DocumentTypeCustomField  `json:"customFields"`
+	ApprovalPolicy models.ApprovalPolicy      `json:"approvalPolicy"`
+	IndexContent   bool                       `json:"indexContent"`
+
+	// ObjectID, TemplateName, and DocID are the fields of the Algolia templates
+	// that document types used to be stored as, which the web app still uses.
+	ObjectID     string `json:"objectId"`
 	TemplateName string `json:"templateName"`
-	DocId        string `json:"docId"`
+	DocID        string `json:"docId"`
 }
 
-// retrieve the doctypes and stores in a object
-func GetDocTypeArray(cfg config.Config) []template {
-	// Initialize the Algolia client
-	appID := cfg.Algolia.ApplicationID
-	apiKey := cfg.Algolia.WriteAPIKey
-	// Initialize the search client
-	client := search.NewClient(appID, apiKey)
-	// Specify the index name
-	indexName := cfg.Algolia.TemplateIndexName
-	// Create a search index instance
-	index := client.InitIndex(indexName)
-	// fetch all objectIds and append in
-	var record template
-	// use BrowseObjects to Get all records as an iterator
-	it, err := index.BrowseObjects()
-	if err != nil {
-		fmt.Println("error browsing document types")
-	}
-	//objectArray contains array of template objects
-	var objectArray []template
-	// loop to traverse through all the template objects
-	for {
-		rec, err := it.Next(&record)
-		if err != nil {
-			break
-		}
-		jsonData, err := json.Marshal(rec)
-		if err != nil {
-			fmt.Println("Error converting to JSON:", err)
-			break
-		}
-		// temporary object variable to store and append to object Array
-		var object template
-		error := json.Unmarshal([]byte(jsonData), &object)
-		if error != nil {
-			fmt.Println("Error converting JSON to object:", err)
-			break
+// DocumentTypesHandler handles requests to list document types, and to create
+// document types at "/api/v1/document-types". Only admins can create document
+// types.
+func DocumentTypesHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			var dts models.DocumentTypes
+			if err := dts.GetAll(db); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting document types",
+					"error getting document types", err)
+				return
+			}
+
+			resp := make([]DocumentTypeResponse, len(dts))
+			for i, dt := range dts {
+				var err error
+				if resp[i], err = newDocumentTypeResponse(dt); err != nil {
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error getting document types",
+						"error creating document type response", err,
+						"document_type", dt.Name,
+					)
+					return
+				}
+			}
+			respondJSON(w, r, l, http.StatusOK, resp)
+
+		case "POST":
+			// Only global admins can manage document types.
+			if !authorizeAdmin(w, r, l, db) {
+				return
+			}
+
+			var req DocumentTypeRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding document type request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			dt := models.DocumentType{}
+			if err := applyDocumentTypeRequest(&dt, req); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+			serveCreateDocumentType(w, r, l, db, dt)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
 		}
-		objectArray = append(objectArray, object)
-	}
-	// fmt.Printf("objectArray: %v\n", objectArray)
-	return objectArray
+	})
 }
 
-func registerDocumentTypes(cfg config.Config, db *gorm.DB) error {
-	var objectArray []template = GetDocTypeArray(cfg)
-	// fmt.Printf("objectArray from register document types : %v\n", objectArray)
-	for _, d := range objectArray {
-		dt := models.DocumentType{
-			Name:         d.TemplateName,
-			Description:  d.Description,
-			Checks:       nil,
-			CustomFields: nil,
+// DocumentTypeHandler handles requests to get, update, and delete a document
+// type at "/api/v1/document-types/{name}". Only admins can update and delete
+// document types.
+func DocumentTypeHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.Trim(
+			strings.TrimPrefix(r.URL.Path, "/api/v1/document-types/"), "/")
+		if name == "" || strings.Contains(name, "/") {
+			http.Error(w, "Resource not found", http.StatusNotFound)
+			return
 		}
-		// Upsert document type.
-		if err := dt.Upsert(db); err != nil {
-			return fmt.Errorf("error upserting document type: %w", err)
+
+		// Only global admins can manage document types.
+		if r.Method == "PATCH" || r.Method == "DELETE" {
+			if !authorizeAdmin(w, r, l, db) {
+				return
+			}
 		}
-	}
-	return nil
-}
 
-// new version of handler - retrieve data from algolia
-func DocumentTypesHandler(cfg config.Config, log hclog.Logger) http.Handler {
+		dt, ok := getDocumentType(w, r, l, db, name)
+		if !ok {
+			return
+		}
 
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case "GET":
-			w.Header().Set("Content-Type", "application/json")
-			//objectArray contains array of template objects
-			var objectArray []template = GetDocTypeArray(cfg)
-			// Convert the object to JSON
-			response, err := json.Marshal(objectArray)
+			resp, err := newDocumentTypeResponse(dt)
 			if err != nil {
-				log.Error("error encoding document types",
-					"error", err,
-					"method", r.Method,
-					"path", r.URL.Path)
-				http.Error(w, "{\"error\": \"Error getting document types\"}",
-					http.StatusInternalServerError)
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting document type",
+					"error creating document type response", err,
+					"document_type", dt.Name,
+				)
 				return
 			}
-			// Write the JSON response directly to the ResponseWriter
-			w.Write(response)
-			// Initialize database.
-			db, err := db.NewDB(*cfg.Postgres)
-			if err != nil {
-				fmt.Printf("err initializing db : %v\n", err)
-			}
-			if err := registerDocumentTypes(cfg, db); err != nil {
-				fmt.Printf("err registering document types : %v\n", err)
-			}
-			// Register document types.
-			// TODO: remove this and use the database for all document type lookups.
-			// var objectArray []template=GetDocTypeArray(cfg)
-			docTypes := map[string]hcd.Doc{}
-			for i := 0; i < len(objectArray); i++ {
-				doctype := objectArray[i].TemplateName
-				doctype = strings.ToLower(doctype)
-				docTypes[doctype] = &hcd.COMMONTEMPLATE{}
+			respondJSON(w, r, l, http.StatusOK, resp)
+
+		case "PATCH":
+			var req DocumentTypeRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding document type request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
 			}
-			for name, dt := range docTypes {
-				if err = doctypes.Register(name, dt); err != nil {
-					fmt.Printf("err: %v\n", err)
-				}
+			before := dt
+			if err := applyDocumentTypeRequest(&dt, req); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
 			}
+			serveUpdateDocumentType(w, r, l, db, before, dt)
+
+		case "DELETE":
+			serveDeleteDocumentType(w, r, l, db, dt)
+
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
 	})
 }
+
+// getDocumentType gets the document type with name name, responding with an
+// error and returning false if it isn't found or getting it fails.
+func getDocumentType(
+	w http.ResponseWriter,
+	r *http.Request,
+	l hclog.Logger,
+	db *gorm.DB,
+	name string,
+) (models.DocumentType, bool) {
+	dt := models.DocumentType{Name: name}
+	if err := dt.Get(db); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Document type not found", http.StatusNotFound)
+			return dt, false
+		}
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error getting document type",
+			"error getting document type", err,
+			"document_type", name,
+		)
+		return dt, false
+	}
+	return dt, true
+}
+
+// serveCreateDocumentType creates document type dt and responds with it.
+func serveCreateDocumentType(
+	w http.ResponseWriter,
+	r *http.Request,
+	l hclog.Logger,
+	db *gorm.DB,
+	dt models.DocumentType,
+) {
+	if err := dt.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %q", err),
+			http.StatusBadRequest)
+		return
+	}
+
+	// Document type names are unique.
+	existing := models.DocumentType{Name: dt.Name}
+	if err := existing.Get(db); err == nil {
+		http.Error(w, "Document type already exists", http.StatusConflict)
+		return
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error creating document type",
+			"error getting document type", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+
+	after, err := newDocumentTypeResponse(dt)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error creating document type",
+			"error creating document type response", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+	ae, err := newAuditEvent(r, models.DocumentTypeCreatedAuditAction,
+		"", dt.Name, nil, after)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error creating document type",
+			"error creating audit event", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+	if err := withAuditEvent(db, ae, func(tx *gorm.DB) error {
+		return dt.Create(tx)
+	}); err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error creating document type",
+			"error creating document type", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+
+	resp, err := newDocumentTypeResponse(dt)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error creating document type",
+			"error creating document type response", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+
+	l.Info("created document type",
+		"document_type", dt.Name,
+		"method", r.Method,
+		"path", r.URL.Path,
+	)
+	respondJSON(w, r, l, http.StatusCreated, resp)
+}
+
+// serveUpdateDocumentType updates document type before to dt and responds with
+// the updated document type. Document types can be renamed until documents have
+// the type.
+func serveUpdateDocumentType(
+	w http.ResponseWriter,
+	r *http.Request,
+	l hclog.Logger,
+	db *gorm.DB,
+	before models.DocumentType,
+	dt models.DocumentType,
+) {
+	if err := dt.Validate(); err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %q", err),
+			http.StatusBadRequest)
+		return
+	}
+
+	// Document type names are unique.
+	if dt.Name != before.Name {
+		existing := models.DocumentType{Name: dt.Name}
+		if err := existing.Get(db); err == nil {
+			http.Error(w, "Document type already exists", http.StatusConflict)
+			return
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(w, r, l, http.StatusInternalServerError,
+				"Error updating document type",
+				"error getting document type", err,
+				"document_type", dt.Name,
+			)
+			return
+		}
+	}
+
+	beforeResp, err := newDocumentTypeResponse(before)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error updating document type",
+			"error creating document type response", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+	afterResp, err := newDocumentTypeResponse(dt)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error updating document type",
+			"error creating document type response", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+	ae, err := newAuditEvent(r, models.DocumentTypeUpdatedAuditAction,
+		"", dt.Name, beforeResp, afterResp)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error updating document type",
+			"error creating audit event", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+	if err := withAuditEvent(db, ae, func(tx *gorm.DB) error {
+		return dt.Update(tx)
+	}); err != nil {
+		if errors.Is(err, models.ErrDocumentTypeInUse) {
+			http.Error(w, fmt.Sprintf("Conflict: %v", err), http.StatusConflict)
+			return
+		}
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error updating document type",
+			"error updating document type", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+
+	resp, err := newDocumentTypeResponse(dt)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error updating document type",
+			"error creating document type response", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+
+	l.Info("updated document type",
+		"document_type", dt.Name,
+		"method", r.Method,
+		"path", r.URL.Path,
+	)
+	respondJSON(w, r, l, http.StatusOK, resp)
+}
+
+// serveDeleteDocumentType deletes document type dt, which can't be deleted
+// while documents have the type.
+func serveDeleteDocumentType(
+	w http.ResponseWriter,
+	r *http.Request,
+	l hclog.Logger,
+	db *gorm.DB,
+	dt models.DocumentType,
+) {
+	before, err := newDocumentTypeResponse(dt)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error deleting document type",
+			"error creating document type response", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+	ae, err := newAuditEvent(r, models.DocumentTypeDeletedAuditAction,
+		"", dt.Name, before, nil)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error deleting document type",
+			"error creating audit event", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+	if err := withAuditEvent(db, ae, func(tx *gorm.DB) error {
+		return dt.Delete(tx)
+	}); err != nil {
+		if errors.Is(err, models.ErrDocumentTypeInUse) {
+			http.Error(w, fmt.Sprintf("Conflict: %v", err), http.StatusConflict)
+			return
+		}
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error deleting document type",
+			"error deleting document type", err,
+			"document_type", dt.Name,
+		)
+		return
+	}
+
+	l.Info("deleted document type",
+		"document_type", dt.Name,
+		"method", r.Method,
+		"path", r.URL.Path,
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyDocumentTypeRequest applies the fields set in request req to document
+// type dt.
+func applyDocumentTypeRequest(
+	dt *models.DocumentType, req DocumentTypeRequest) error {
+	if req.Name != nil {
+		dt.Name = *req.Name
+	}
+	if req.Description != nil {
+		dt.Description = *req.Description
+	}
+	if req.TemplateFileID != nil {
+		dt.TemplateFileID = *req.TemplateFileID
+	}
+	if req.MoreInfoLink != nil {
+		dt.MoreInfoLinkText = req.MoreInfoLink.Text
+		dt.MoreInfoLinkURL = req.MoreInfoLink.URL
+	}
+	if req.Checks != nil {
+		if err := dt.SetChecks(*req.Checks); err != nil {
+			return err
+		}
+	}
+	if req.CustomFields != nil {
+		cfs := make([]models.DocumentTypeCustomField, len(*req.CustomFields))
+		for i, c := range *req.CustomFields {
+			t, err := models.ParseDocumentTypeCustomFieldType(c.Type)
+			if err != nil {
+				return err
+			}
+			cfs[i] = models.DocumentTypeCustomField{
+				Name:     c.Name,
+				ReadOnly: c.ReadOnly,
+				Type:     t,
+			}
+		}
+		dt.CustomFields = cfs
+	}
+	if req.ApprovalPolicy != nil {
+		dt.ApprovalPolicy = datatypes.JSONType[models.ApprovalPolicy]{
+			Data: *req.ApprovalPolicy}
+	}
+	if req.IndexContent != nil {
+		dt.IndexContent = *req.IndexContent
+	}
+	return nil
+}
+
+// newDocumentTypeResponse returns the response for document type dt.
+func newDocumentTypeResponse(
+	dt models.DocumentType) (DocumentTypeResponse, error) {
+	checks, err := dt.GetChecks()
+	if err != nil {
+		return DocumentTypeResponse{}, err
+	}
+	if checks == nil {
+		checks = []models.DocumentTypeCheck{}
+	}
+
+	resp := DocumentTypeResponse{
+		ID:             dt.ID,
+		Name:           dt.Name,
+		Description:    dt.Description,
+		TemplateFileID: dt.TemplateFileID,
+		Checks:         checks,
+		CustomFields:   make([]DocumentTypeCustomField, len(dt.CustomFields)),
+		ApprovalPolicy: dt.ApprovalPolicy.Data,
+		IndexContent:   dt.IndexContent,
+		ObjectID:       dt.Name,
+		TemplateName:   dt.Name,
+		DocID:          dt.TemplateFileID,
+	}
+	if dt.MoreInfoLinkText != "" || dt.MoreInfoLinkURL != "" {
+		resp.MoreInfoLink = &models.DocumentTypeLink{
+			Text: dt.MoreInfoLinkText,
+			URL:  dt.MoreInfoLinkURL,
+		}
+	}
+	for i, c := range dt.CustomFields {
+		resp.CustomFields[i] = DocumentTypeCustomField{
+			Name:     c.Name,
+			Type:     c.Type.String(),
+			ReadOnly: c.ReadOnly,
+		}
+	}
+	return resp, nil
+}
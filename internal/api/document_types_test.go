@@ -0,0 +1,88 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDocumentTypeRequest(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	name := "RFC"
+	templateFileID := "template"
+	indexContent := true
+	req := DocumentTypeRequest{
+		Name:           &name,
+		TemplateFileID: &templateFileID,
+		MoreInfoLink: &models.DocumentTypeLink{
+			Text: "More info",
+			URL:  "https://example.com/rfc",
+		},
+		Checks: &[]models.DocumentTypeCheck{
+			{Label: "I have updated the status"},
+		},
+		CustomFields: &[]DocumentTypeCustomField{
+			{Name: "Stakeholders", Type: "people"},
+			{Name: "Current Version", Type: "string", ReadOnly: true},
+		},
+		ApprovalPolicy: &models.ApprovalPolicy{
+			Stages: []models.ApprovalStage{{Name: "Reviewers"}},
+		},
+		IndexContent: &indexContent,
+	}
+
+	dt := models.DocumentType{Description: "Description"}
+	require.NoError(applyDocumentTypeRequest(&dt, req))
+	require.NoError(dt.Validate())
+
+	resp, err := newDocumentTypeResponse(dt)
+	require.NoError(err)
+	assert.Equal(DocumentTypeResponse{
+		Name:           "RFC",
+		Description:    "Description",
+		TemplateFileID: "template",
+		MoreInfoLink: &models.DocumentTypeLink{
+			Text: "More info",
+			URL:  "https://example.com/rfc",
+		},
+		Checks: []models.DocumentTypeCheck{
+			{Label: "I have updated the status"},
+		},
+		CustomFields: []DocumentTypeCustomField{
+			{Name: "Stakeholders", Type: "people"},
+			{Name: "Current Version", Type: "string", ReadOnly: true},
+		},
+		ApprovalPolicy: models.ApprovalPolicy{
+			Stages: []models.ApprovalStage{{Name: "Reviewers"}},
+		},
+		IndexContent: true,
+		ObjectID:     "RFC",
+		TemplateName: "RFC",
+		DocID:        "template",
+	}, resp)
+
+	// Fields that aren't set aren't updated, and empty fields are cleared.
+	description := ""
+	require.NoError(applyDocumentTypeRequest(&dt, DocumentTypeRequest{
+		Description:  &description,
+		MoreInfoLink: &models.DocumentTypeLink{},
+		Checks:       &[]models.DocumentTypeCheck{},
+	}))
+	resp, err = newDocumentTypeResponse(dt)
+	require.NoError(err)
+	assert.Empty(resp.Description)
+	assert.Nil(resp.MoreInfoLink)
+	assert.Equal([]models.DocumentTypeCheck{}, resp.Checks)
+	assert.Len(resp.CustomFields, 2)
+	assert.Equal("template", resp.TemplateFileID)
+
+	// Custom fields must have a valid type.
+	assert.Error(applyDocumentTypeRequest(&dt, DocumentTypeRequest{
+		CustomFields: &[]DocumentTypeCustomField{
+			{Name: "Stakeholders", Type: "team"},
+		},
+	}))
+}
@@ -9,14 +9,13 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/algolia/algoliasearch-client-go/v3/algolia/errs"
 	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/internal/email"
-	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
+	"github.com/hashicorp-forge/hermes/internal/notify"
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
 	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
 	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"github.com/hashicorp/go-hclog"
 	"gorm.io/gorm"
 )
@@ -24,11 +23,11 @@ import (
 // DocumentPatchRequest contains a subset of documents fields that are allowed
 // to be updated with a PATCH request.
 type DocumentPatchRequest struct {
-	Reviewers    []string `json:"reviewers,omitempty"`
-	DueDate      string   `json:"dueDate,omitempty"`
-	Contributors []string `json:"contributors,omitempty"`
-	Status       string   `json:"status,omitempty"`
-	Summary      string   `json:"summary,omitempty"`
+	Reviewers    []string  `json:"reviewers,omitempty"`
+	DueDate      *hcd.Date `json:"dueDate,omitempty"`
+	Contributors []string  `json:"contributors,omitempty"`
+	Status       string    `json:"status,omitempty"`
+	Summary      string    `json:"summary,omitempty"`
 	// Tags                []string `json:"tags,omitempty"`
 	Title string `json:"title,omitempty"`
 
@@ -45,12 +44,21 @@ type DocumentPatchRequest struct {
 func DocumentHandler(
 	cfg *config.Config,
 	l hclog.Logger,
-	ar *algolia.Client,
-	aw *algolia.Client,
+	sp search.Provider,
+	st storage.Provider,
 	s *gw.Service,
-	db *gorm.DB) http.Handler {
+	db *gorm.DB,
+	n notify.Channel,
+) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Serve document history requests.
+		if docID, ok := parseDocumentHistoryURLPath(
+			r.URL.Path, "/api/v1/documents"); ok {
+			serveDocumentHistory(w, r, l, sp, db, docID)
+			return
+		}
+
 		// Parse document ID from the URL path.
 		docID, err := parseURLPath(r.URL.Path, "/api/v1/documents")
 		if err != nil {
@@ -63,12 +71,12 @@ func DocumentHandler(
 			return
 		}
 
-		// Get base document object from Algolia so we can determine the doc type.
+		// Get base document object from search index so we can determine the doc type.
 		baseDocObj := &hcd.BaseDoc{}
-		err = ar.Docs.GetObject(docID, &baseDocObj)
+		err = sp.Docs().GetObject(docID, &baseDocObj)
 		if err != nil {
-			// Handle 404 from Algolia and only log a warning.
-			if _, is404 := errs.IsAlgoliaErrWithCode(err, 404); is404 {
+			// Handle not found from the search index and only log a warning.
+			if errors.Is(err, search.ErrNotFound) {
 				l.Warn("base document object not found",
 					"error", err,
 					"path", r.URL.Path,
@@ -78,7 +86,7 @@ func DocumentHandler(
 				http.Error(w, "Document not found", http.StatusNotFound)
 				return
 			} else {
-				l.Error("error requesting base document object from Algolia",
+				l.Error("error requesting base document object from search index",
 					"error", err,
 					"path", r.URL.Path,
 					"method", r.Method,
@@ -104,10 +112,10 @@ func DocumentHandler(
 			return
 		}
 
-		// Get document object from Algolia.
-		err = ar.Docs.GetObject(docID, &docObj)
+		// Get document object from search index.
+		err = sp.Docs().GetObject(docID, &docObj)
 		if err != nil {
-			l.Error("error retrieving document object from Algolia",
+			l.Error("error retrieving document object from search index",
 				"error", err,
 				"path", r.URL.Path,
 				"method", r.Method,
@@ -122,7 +130,7 @@ func DocumentHandler(
 			now := time.Now()
 
 			// Get file from Google Drive so we can return the latest modified time.
-			file, err := s.GetFile(docID)
+			file, err := st.GetFile(docID)
 			if err != nil {
 				l.Error("error getting document file from Google",
 					"error", err,
@@ -168,9 +176,29 @@ func DocumentHandler(
 			}
 
 			// Set locked value for response to value from the database (this value
-			// isn't stored in Algolia).
+			// isn't stored in search index).
 			docObj.SetLocked(doc.Locked)
 
+			// Set the status of the approval policy of the document type, if it has
+			// one.
+			policy, err := getApprovalPolicy(db, docObj.GetDocType())
+			if err != nil {
+				l.Error("error getting approval policy",
+					"error", err,
+					"path", r.URL.Path,
+					"method", r.Method,
+					"doc_id", docID,
+				)
+				http.Error(w, "Error requesting document",
+					http.StatusInternalServerError)
+				return
+			}
+			if !policy.IsEmpty() {
+				status := policy.Evaluate(
+					docObj.GetReviewers(), docObj.GetReviewedBy())
+				docObj.SetApprovalStatus(&status)
+			}
+
 			// Write response.
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
@@ -267,7 +295,7 @@ func DocumentHandler(
 			}
 
 			// Check if document is locked.
-			locked, err := hcd.IsLocked(docID, db, s, l)
+			locked, err := hcd.IsLocked(docID, db, st, l)
 			if err != nil {
 				l.Error("error checking document locked status",
 					"error", err,
@@ -284,7 +312,7 @@ func DocumentHandler(
 				return
 			}
 
-			// Compare reviewers in req and stored object in Algolia
+			// Compare reviewers in req and stored object in search index
 			// before we save the patched objected
 			var reviewersToEmail []string
 			if len(docObj.GetReviewers()) == 0 && len(req.Reviewers) != 0 {
@@ -297,11 +325,10 @@ func DocumentHandler(
 				reviewersToEmail = compareSlices(docObj.GetReviewers(), req.Reviewers)
 			}
 
-			// Patch document by decoding the (now validated) request body JSON to the
-			// document object.
-			err = json.NewDecoder(body).Decode(docObj)
+			// Save the document state before patching for the audit log.
+			before, err := auditSnapshot(docObj)
 			if err != nil {
-				l.Error("error decoding request body to document object",
+				l.Error("error encoding document object for audit log",
 					"error", err,
 					"method", r.Method,
 					"path", r.URL.Path,
@@ -311,10 +338,13 @@ func DocumentHandler(
 				return
 			}
 
-			// Save new modified doc object in Algolia.
-			res, err := aw.Docs.SaveObject(docObj)
+			oldStatus := docObj.GetStatus()
+
+			// Patch document by decoding the (now validated) request body JSON to the
+			// document object.
+			err = json.NewDecoder(body).Decode(docObj)
 			if err != nil {
-				l.Error("error saving patched document in Algolia",
+				l.Error("error decoding request body to document object",
 					"error", err,
 					"method", r.Method,
 					"path", r.URL.Path,
@@ -323,9 +353,35 @@ func DocumentHandler(
 					http.StatusInternalServerError)
 				return
 			}
-			err = res.Wait()
+
+			// Only allow approving the document if the approval policy of the
+			// document type is satisfied.
+			if docObj.GetStatus() == "Reviewed" && oldStatus != "Reviewed" {
+				policy, err := getApprovalPolicy(db, docObj.GetDocType())
+				if err != nil {
+					l.Error("error getting approval policy",
+						"error", err,
+						"method", r.Method,
+						"path", r.URL.Path,
+						"doc_id", docID)
+					http.Error(w, "Error patching document",
+						http.StatusInternalServerError)
+					return
+				}
+				if status := policy.Evaluate(
+					docObj.GetReviewers(), docObj.GetReviewedBy()); !status.Satisfied {
+					http.Error(w, fmt.Sprintf(
+						"Document can't be approved until approval stage %q is satisfied",
+						status.CurrentStage),
+						http.StatusConflict)
+					return
+				}
+			}
+
+			// Save new modified doc object in search index.
+			err = sp.Docs().SaveObject(docObj)
 			if err != nil {
-				l.Error("error saving patched document in Algolia",
+				l.Error("error saving patched document in search index",
 					"error", err,
 					"method", r.Method,
 					"path", r.URL.Path,
@@ -389,8 +445,10 @@ func DocumentHandler(
 				Title:   docObj.GetTitle(),
 			}
 
-			if err := d.Upsert(db); err != nil {
-				l.Error("error upserting document in database",
+			ae, err := newAuditEvent(
+				r, models.DocumentUpdatedAuditAction, docID, "", before, docObj)
+			if err != nil {
+				l.Error("error creating audit event",
 					"error", err,
 					"doc_id", docID,
 					"method", r.Method,
@@ -399,130 +457,83 @@ func DocumentHandler(
 					http.StatusInternalServerError)
 				return
 			}
-
-			// Get owner name
-			// Fetch owner name by searching Google Workspace directory.
-			// The api has a bug please kindly see this before proceeding forward
-			ppls, err := s.SearchPeople(docObj.GetOwners()[0], "emailAddresses,names")
-			if err != nil {
-				l.Error(
-					"Error getting user information",
-					"error searching people directory",
-					err,
-				)
+			if err := withAuditEvent(db, ae, func(tx *gorm.DB) error {
+				if err := d.Upsert(tx); err != nil {
+					return err
+				}
+				if docObj.GetStatus() != oldStatus {
+					return enqueueWebhookEvent(tx, r, cfg.BaseURL,
+						models.DocumentStatusChangedWebhookEvent, docObj)
+				}
+				return nil
+			}); err != nil {
+				l.Error("error upserting document in database",
+					"error", err,
+					"doc_id", docID,
+					"method", r.Method,
+					"path", r.URL.Path)
+				http.Error(w, "Error patching doc in the database",
+					http.StatusInternalServerError)
 				return
 			}
 
-			// Verify that the result only contains one person.
-			if len(ppls) != 1 {
-				l.Error(
-					"Error getting user information",
-					fmt.Sprintf(
-						"wrong number of people in search result: %d", len(ppls)),
-					err,
-				)
-				return
-			}
-			ppl := ppls[0]
-
-			// Replace the names in the People API result with data from the Admin
-			// Directory API.
-			// TODO: remove this when the bug in the People API is fixed:
-			// https://issuetracker.google.com/issues/196235775
-			if err := replaceNamesWithAdminAPIResponse(
-				ppl, s,
-			); err != nil {
-				l.Error(
-					"Error getting user information",
-					"error replacing names with Admin API response",
-					err,
-				)
-				return
+			// Notify subscribers of the document entering review or being
+			// approved.
+			if docObj.GetStatus() != oldStatus {
+				switch docObj.GetStatus() {
+				case "In-Review":
+					notifySubscribers(cfg, l, db, n, r, docObj,
+						models.ReviewStartedSubscriptionEvent)
+					notifySlackChannelsInReview(cfg, l, db, r, docObj, "", false)
+				case "Reviewed":
+					notifySubscribers(cfg, l, db, n, r, docObj,
+						models.ApprovedSubscriptionEvent)
+				}
 			}
 
-			// Verify other required values are set.
-			if len(ppl.Names) == 0 {
-				l.Error(
-					"Error getting user information",
-					"no names in result",
-					err,
+			// Get owner name.
+			ownerName, err := getPersonDisplayName(s, docObj.GetOwners()[0])
+			if err != nil {
+				l.Error("error getting owner name",
+					"error", err,
+					"doc_id", docID,
+					"method", r.Method,
+					"path", r.URL.Path,
 				)
 				return
 			}
 
-			// Send emails to reviewers.
-			if cfg.Email != nil && cfg.Email.Enabled && len(reviewersToEmail) > 0 {
-				// TODO: use an asynchronous method for sending emails because we
-				// can't currently recover gracefully from a failure here.
-				// returns the "baseurl/documents/{docid}"
+			// Notify new reviewers by email and Slack. Failures are logged instead
+			// of failing the request because the document has already been
+			// patched.
+			if len(reviewersToEmail) > 0 {
 				docURL, err := getDocumentURL(cfg.BaseURL, docID)
-				if err != nil {
-					l.Error("error getting the base doc url!", "error", err)
-				}
-				for _, reviewerEmail := range reviewersToEmail {
-					err := email.SendReviewRequestedEmail(
-						email.ReviewRequestedEmailData{
-							BaseURL:            cfg.BaseURL,
-							DocumentOwner:      ppl.Names[0].DisplayName,
-							DocumentType:       docObj.GetDocType(),
-							DocumentShortName:  docObj.GetDocNumber(),
-							DocumentTitle:      docObj.GetTitle(),
-							DocumentURL:        docURL,
-							DocumentProd:       docObj.GetProduct(),
-							DocumentTeam:       docObj.GetTeam(),
-							DocumentOwnerEmail: docObj.GetOwners()[0],
-						},
-						[]string{reviewerEmail},
-						cfg.Email.FromAddress,
-						s,
-					)
-					if err != nil {
-						l.Error("error sending reviewer email",
-							"error", err,
-							"doc_id", docID,
-							"method", r.Method,
-							"path", r.URL.Path,
-						)
-						http.Error(w, "Error creating review",
-							http.StatusInternalServerError)
-						return
+				if err == nil {
+					var msg notify.Message
+					msg, err = newReviewRequestedMessage(cfg, docObj, docURL,
+						ownerName, reviewersToEmail)
+					if err == nil {
+						err = n.Send(msg)
 					}
-					l.Info("doc reviewer email sent",
+				}
+				if err != nil {
+					l.Error("error notifying reviewers",
+						"error", err,
 						"doc_id", docID,
 						"method", r.Method,
 						"path", r.URL.Path,
 					)
-				}
-
-				// Also send the slack message tagginhg all the reviewers in the
-				// dedicated channel
-				// tagging all reviewers emails
-				emails := make([]string, len(reviewersToEmail))
-				for i, c := range reviewersToEmail {
-					emails[i] = c
-				}
-				err = slackbot.SendSlackMessage_Reviewer(slackbot.ReviewerRequestedSlackData{
-					BaseURL:            cfg.BaseURL,
-					DocumentOwner:      ppl.Names[0].DisplayName,
-					DocumentType:       docObj.GetDocType(),
-					DocumentShortName:  docObj.GetDocNumber(),
-					DocumentTitle:      docObj.GetTitle(),
-					DocumentURL:        docURL,
-					DocumentProd:       docObj.GetProduct(),
-					DocumentTeam:       docObj.GetTeam(),
-					DocumentOwnerEmail: docObj.GetOwners()[0],
-				}, emails,
-				)
-				//handle error gracefully
-				if err != nil {
-					fmt.Printf("Some error occured while sendind the message: %s", err)
 				} else {
-					fmt.Println("Succesfully! Delivered the message to all new reviewers")
+					l.Info("doc reviewers notified",
+						"doc_id", docID,
+						"method", r.Method,
+						"path", r.URL.Path,
+					)
 				}
 			}
 
 			// Replace the doc header.
-			err = docObj.ReplaceHeader(docID, cfg.BaseURL, true, s)
+			err = docObj.ReplaceHeader(docID, cfg.BaseURL, true, st)
 			if err != nil {
 				l.Error("error replacing document header",
 					"error", err, "doc_id", docID)
@@ -532,7 +543,7 @@ func DocumentHandler(
 			}
 
 			// Rename file with new title.
-			s.RenameFile(docID,
+			st.RenameFile(docID,
 				fmt.Sprintf(req.Title))
 
 			w.WriteHeader(http.StatusOK)
@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -10,44 +11,40 @@ import (
 	"strings"
 	"time"
 
-	"github.com/hashicorp-forge/hermes/internal/email"
-	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
-
-	"github.com/algolia/algoliasearch-client-go/v3/algolia/errs"
-	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
-	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
 	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
+	"github.com/hashicorp-forge/hermes/internal/notify"
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
 	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
 	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"github.com/hashicorp/go-hclog"
 	"gorm.io/gorm"
 )
 
 type DraftsRequest struct {
-	Reviewers    []string `json:"reviewers,omitempty"`
-	DueDate      string   `json:"dueDate,omitempty"`
-	Contributors []string `json:"contributors,omitempty"`
-	DocType      string   `json:"docType,omitempty"`
-	Product      string   `json:"product,omitempty"`
-	Team         string   `json:"team,omitempty"`
-	Project      string   `json:"project,omitempty"`
-	Summary      string   `json:"summary,omitempty"`
-	Tags         []string `json:"tags,omitempty"`
-	Title        string   `json:"title"`
+	Reviewers    []string  `json:"reviewers,omitempty"`
+	DueDate      *hcd.Date `json:"dueDate,omitempty"`
+	Contributors []string  `json:"contributors,omitempty"`
+	DocType      string    `json:"docType,omitempty"`
+	Product      string    `json:"product,omitempty"`
+	Team         string    `json:"team,omitempty"`
+	Project      string    `json:"project,omitempty"`
+	Summary      string    `json:"summary,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+	Title        string    `json:"title"`
 }
 
 // DraftsPatchRequest contains a subset of drafts fields that are allowed to
 // be updated with a PATCH request.
 type DraftsPatchRequest struct {
-	Reviewers    []string `json:"reviewers,omitempty"`
-	DueDate      string   `json:"dueDate,omitempty"`
-	Contributors []string `json:"contributors,omitempty"`
-	Product      string   `json:"product,omitempty"`
-	Team         string   `json:"team,omitempty"`
-	Project      string   `json:"project,omitempty"`
-	Summary      string   `json:"summary,omitempty"`
+	Reviewers    []string  `json:"reviewers,omitempty"`
+	DueDate      *hcd.Date `json:"dueDate,omitempty"`
+	Contributors []string  `json:"contributors,omitempty"`
+	Product      string    `json:"product,omitempty"`
+	Team         string    `json:"team,omitempty"`
+	Project      string    `json:"project,omitempty"`
+	Summary      string    `json:"summary,omitempty"`
 	// Tags                []string `json:"tags,omitempty"`
 	Title string `json:"title,omitempty"`
 
@@ -68,10 +65,12 @@ type DraftsResponse struct {
 func DraftsHandler(
 	cfg *config.Config,
 	l hclog.Logger,
-	ar *algolia.Client,
-	aw *algolia.Client,
+	sp search.Provider,
+	st storage.Provider,
 	s *gw.Service,
-	db *gorm.DB) http.Handler {
+	db *gorm.DB,
+	n notify.Channel,
+) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		errResp := func(httpCode int, userErrMsg, logErrMsg string, err error) {
@@ -105,29 +104,33 @@ func DraftsHandler(
 					http.StatusBadRequest)
 				return
 			}
-			// Define a variable to hold the retrieved document type array
-			var doctypeArray []template = GetDocTypeArray(*cfg)
-			// replace switch case with loop
-			check := true
-			for i := 0; i < len(doctypeArray); i++ {
-				if doctypeArray[i].TemplateName == req.DocType {
-					check = false
-					break
-				}
-			}
-			if check {
-				l.Error("Bad request: docType is required", "doc_type", req.DocType)
-				http.Error(w, "Bad request: invalid docType", http.StatusBadRequest)
+			if req.DocType == "" {
+				http.Error(w, "Bad request: docType is required", http.StatusBadRequest)
 				return
 			}
-
 			if req.Title == "" {
 				http.Error(w, "Bad request: title is required", http.StatusBadRequest)
 				return
 			}
 
-			// Get doc type template new.
-			templateName := getDocTypeTemplate(doctypeArray, req.DocType)
+			// Get the template of the document type.
+			dt := models.DocumentType{Name: req.DocType}
+			if err := dt.Get(db); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					l.Error("Bad request: invalid docType", "doc_type", req.DocType)
+					http.Error(w, "Bad request: invalid docType",
+						http.StatusBadRequest)
+					return
+				}
+				l.Error("error getting document type",
+					"error", err,
+					"doc_type", req.DocType,
+				)
+				http.Error(w, "Error creating document draft",
+					http.StatusInternalServerError)
+				return
+			}
+			templateName := dt.TemplateFileID
 			if templateName == "" {
 				l.Error("Bad request: no templateName configured for doc type", "doc_type", req.DocType)
 				http.Error(w,
@@ -141,7 +144,7 @@ func DraftsHandler(
 			title := fmt.Sprintf("%s", req.Title)
 
 			// Copy template to new draft file.
-			f, err := s.CopyFile(templateName, title, cfg.GoogleWorkspace.DraftsFolder)
+			f, err := st.CopyFile(templateName, title, cfg.GoogleWorkspace.DraftsFolder)
 			if err != nil {
 				l.Error("error creating draft", "error", err, "template name ", templateName,
 					"drafts_folder", cfg.GoogleWorkspace.DraftsFolder)
@@ -160,19 +163,22 @@ func DraftsHandler(
 			}
 			cd := ct.Format("Jan 2, 2006")
 
-			// Get owner photo by searching Google Workspace directory.
+			// Get owner photo by searching Google Workspace directory, if
+			// configured.
 			op := []string{}
-			people, err := s.SearchPeople(userEmail, "photos")
-			if err != nil {
-				l.Error(
-					"error searching directory for person",
-					"err", err,
-					"person", userEmail,
-				)
-			}
-			if len(people) > 0 {
-				if len(people[0].Photos) > 0 {
-					op = append(op, people[0].Photos[0].Url)
+			if s != nil {
+				people, err := s.SearchPeople(userEmail, "photos")
+				if err != nil {
+					l.Error(
+						"error searching directory for person",
+						"err", err,
+						"person", userEmail,
+					)
+				}
+				if len(people) > 0 {
+					if len(people[0].Photos) > 0 {
+						op = append(op, people[0].Photos[0].Url)
+					}
 				}
 			}
 
@@ -193,6 +199,7 @@ func DraftsHandler(
 				Title:        req.Title,
 				AppCreated:   true,
 				Contributors: req.Contributors,
+				DueDate:      req.DueDate.ObjectDatePtr(),
 				Created:      cd,
 				CreatedTime:  ct.Unix(),
 				DocType:      req.DocType,
@@ -207,16 +214,9 @@ func DraftsHandler(
 				Tags:         req.Tags,
 			}
 
-			res, err := aw.Drafts.SaveObject(baseDocObj)
+			err = sp.Drafts().SaveObject(baseDocObj)
 			if err != nil {
-				l.Error("error saving draft doc in Algolia", "error", err, "doc_id", f.Id)
-				http.Error(w, "Error creating document draft",
-					http.StatusInternalServerError)
-				return
-			}
-			err = res.Wait()
-			if err != nil {
-				l.Error("error saving draft doc in Algolia", "error", err, "doc_id", f.Id)
+				l.Error("error saving draft doc in search index", "error", err, "doc_id", f.Id)
 				http.Error(w, "Error creating document draft",
 					http.StatusInternalServerError)
 				return
@@ -234,10 +234,10 @@ func DraftsHandler(
 				return
 			}
 
-			// Get document object from Algolia.
-			err = ar.Drafts.GetObject(f.Id, &docObj)
+			// Get document object from search index.
+			err = sp.Drafts().GetObject(f.Id, &docObj)
 			if err != nil {
-				l.Error("error requesting document draft from Algolia",
+				l.Error("error requesting document draft from search index",
 					"error", err,
 					"doc_id", f.Id,
 				)
@@ -283,7 +283,7 @@ func DraftsHandler(
 			d := models.Document{
 				GoogleFileID:       f.Id,
 				Reviewers:          reviewers,
-				DueDate:            req.DueDate,
+				DueDate:            req.DueDate.TimePtr(),
 				Contributors:       contributors,
 				DocumentCreatedAt:  createdTime,
 				DocumentModifiedAt: createdTime,
@@ -306,7 +306,18 @@ func DraftsHandler(
 				Summary: req.Summary,
 				Title:   req.Title,
 			}
-			if err := d.Create(db); err != nil {
+			ae, err := newAuditEvent(
+				r, models.DraftCreatedAuditAction, f.Id, "", nil, baseDocObj)
+			if err != nil {
+				l.Error("error creating audit event",
+					"error", err,
+					"doc_id", f.Id,
+				)
+				http.Error(w, "Error creating document draft",
+					http.StatusInternalServerError)
+				return
+			}
+			if err := withAuditEvent(db, ae, d.Create); err != nil {
 				l.Error("error creating document in database",
 					"error", err,
 					"doc_id", f.Id,
@@ -317,7 +328,7 @@ func DraftsHandler(
 			}
 
 			// Share file with the owner
-			if err := s.ShareFile(f.Id, userEmail, "writer"); err != nil {
+			if err := st.ShareFile(f.Id, userEmail, "writer"); err != nil {
 				l.Error("error sharing file with the owner",
 					"error", err, "doc_id", f.Id)
 				http.Error(w, "Error creating document draft",
@@ -330,7 +341,7 @@ func DraftsHandler(
 			// is that you can only share files
 			// with one user at a time
 			for _, c := range req.Contributors {
-				if err := s.ShareFile(f.Id, c, "writer"); err != nil {
+				if err := st.ShareFile(f.Id, c, "writer"); err != nil {
 					l.Error("error sharing file with the contributor",
 						"error", err, "doc_id", f.Id, "contributor", c)
 					http.Error(w, "Error creating document draft",
@@ -339,137 +350,44 @@ func DraftsHandler(
 				}
 			}
 
-			// Send emails to contributors.
-			// Get owner name
-			// Fetch owner name by searching Google Workspace directory.
-			// The api has a bug please kindly see this before proceeding forward
-			ppl, err := s.SearchPeople(userEmail, "emailAddresses,names")
+			// Get owner name.
+			ownerName, err := getPersonDisplayName(s, userEmail)
 			if err != nil {
 				errResp(
 					http.StatusInternalServerError,
 					"Error getting user information",
-					"error searching people directory",
-					err,
-				)
-				return
-			}
-
-			// Verify that the result only contains one person.
-			if len(ppl) != 1 {
-				errResp(
-					http.StatusInternalServerError,
-					"Error getting user information",
-					fmt.Sprintf(
-						"wrong number of people in search result: %d", len(ppl)),
-					err,
-				)
-				return
-			}
-			p := ppl[0]
-
-			// Replace the names in the People API result with data from the Admin
-			// Directory API.
-			// TODO: remove this when the bug in the People API is fixed:
-			// https://issuetracker.google.com/issues/196235775
-			if err := replaceNamesWithAdminAPIResponse(
-				p, s,
-			); err != nil {
-				errResp(
-					http.StatusInternalServerError,
-					"Error getting user information",
-					"error replacing names with Admin API response",
-					err,
-				)
-				return
-			}
-
-			// Verify other required values are set.
-			if len(p.Names) == 0 {
-				errResp(
-					http.StatusInternalServerError,
-					"Error getting user information",
-					"no names in result",
+					"error getting owner name",
 					err,
 				)
 				return
 			}
 
-			// Send emails, if enabled.
-			if cfg.Email != nil && cfg.Email.Enabled {
+			// Notify contributors by email and Slack. Failures are logged instead
+			// of failing the request because the draft has already been created.
+			if len(req.Contributors) > 0 {
 				docURL, err := getDocumentURL(cfg.BaseURL, docObj.GetObjectID())
+				if err == nil {
+					var msg notify.Message
+					msg, err = newContributorRequestedMessage(cfg, docObj,
+						fmt.Sprintf("%s?draft=true", docURL),
+						ownerName, req.Contributors)
+					if err == nil {
+						err = n.Send(msg)
+					}
+				}
 				if err != nil {
-					l.Error("error getting document URL",
+					l.Error("error notifying contributors",
 						"error", err,
 						"doc_id", docObj.GetObjectID(),
 						"method", r.Method,
 						"path", r.URL.Path,
 					)
-					http.Error(w, "Error creating review",
-						http.StatusInternalServerError)
-					return
-				}
-
-				if len(req.Contributors) > 0 {
-					// TODO: use an asynchronous method for sending emails because we
-					// can't currently recover gracefully from a failure here.
-					for _, c := range req.Contributors {
-						err := email.SendContributorRequestedEmail(
-							email.ContributorRequestedEmailData{
-								BaseURL:            cfg.BaseURL,
-								DocumentOwner:      p.Names[0].DisplayName,
-								DocumentOwnerEmail: docObj.GetOwners()[0],
-								DocumentType:       docObj.GetDocType(),
-								DocumentTitle:      docObj.GetTitle(),
-								DocumentURL:        fmt.Sprintf("%s?draft=true", docURL),
-								DocumentProd:       docObj.GetProduct(),
-								DocumentTeam:       docObj.GetTeam(),
-							},
-							[]string{c},
-							cfg.Email.FromAddress,
-							s,
-						)
-						if err != nil {
-							l.Error("error sending contributors email",
-								"error", err,
-								"doc_id", docObj.GetObjectID(),
-								"method", r.Method,
-								"path", r.URL.Path,
-							)
-							http.Error(w, "Error creating review",
-								http.StatusInternalServerError)
-							return
-						}
-						l.Info("doc contributors email sent",
-							"doc_id", docObj.GetObjectID(),
-							"method", r.Method,
-							"path", r.URL.Path,
-						)
-					}
-
-					// Also send the slack message tagginhg all the contributors in the
-					// dedicated channel
-					// extracting all contributors emails
-					emails := make([]string, len(req.Contributors))
-					for i, c := range req.Contributors {
-						emails[i] = c
-					}
-					err = slackbot.SendSlackMessage_Contributor(slackbot.ContributorInvitationSlackData{
-						BaseURL:            cfg.BaseURL,
-						DocumentOwner:      p.Names[0].DisplayName,
-						DocumentOwnerEmail: docObj.GetOwners()[0],
-						DocumentType:       docObj.GetDocType(),
-						DocumentTitle:      docObj.GetTitle(),
-						DocumentURL:        fmt.Sprintf("%s?draft=true", docURL),
-						DocumentProd:       docObj.GetProduct(),
-						DocumentTeam:       docObj.GetTeam(),
-					}, emails,
+				} else {
+					l.Info("doc contributors notified",
+						"doc_id", docObj.GetObjectID(),
+						"method", r.Method,
+						"path", r.URL.Path,
 					)
-					//handle error gracefully
-					if err != nil {
-						fmt.Printf("Some error occured while sendind the message: %s", err)
-					} else {
-						fmt.Println("Succesfully! Delivered the EMAIL AND SLACK messageS to all contributors")
-					}
 				}
 			}
 
@@ -530,30 +448,26 @@ func DraftsHandler(
 				return
 			}
 
-			// Build params
-			params := []interface{}{
-				opt.Facets(facets...),
+			// Build query.
+			query := search.Query{
+				Facets: facets,
 				// FacetFilters are supplied as follows:
 				// ['attribute1:value', 'attribute2:value'], 'owners:owner_email_value'
-				opt.FacetFilterAnd(
+				FacetFilters: [][]string{
 					facetFilters,
-					opt.FacetFilterOr("owners:"+userEmail, "contributors:"+userEmail),
-				),
-				opt.HitsPerPage(hitsPerPage),
-				opt.MaxValuesPerFacet(maxValuesPerFacet),
-				opt.Page(page),
+					{"owners:" + userEmail, "contributors:" + userEmail},
+				},
+				HitsPerPage:       hitsPerPage,
+				MaxValuesPerFacet: maxValuesPerFacet,
+				Page:              page,
+				SortBy:            "createdTime",
+				SortDesc:          q.Get("sortBy") != "dateAsc",
 			}
 
 			// Retrieve all documents
-			var resp search.QueryRes
-			sortBy := q.Get("sortBy")
-			if sortBy == "dateAsc" {
-				resp, err = ar.DraftsCreatedTimeAsc.Search("", params...)
-			} else {
-				resp, err = ar.DraftsCreatedTimeDesc.Search("", params...)
-			}
+			resp, err := sp.Drafts().Search(query)
 			if err != nil {
-				l.Error("error retrieving document drafts from Algolia", "error", err)
+				l.Error("error retrieving document drafts from search index", "error", err)
 				http.Error(w, "Error retrieving document drafts",
 					http.StatusInternalServerError)
 				return
@@ -584,16 +498,18 @@ func DraftsHandler(
 func DraftsDocumentHandler(
 	cfg *config.Config,
 	l hclog.Logger,
-	ar *algolia.Client,
-	aw *algolia.Client,
+	sp search.Provider,
+	st storage.Provider,
 	s *gw.Service,
-	db *gorm.DB) http.Handler {
+	db *gorm.DB,
+	n notify.Channel,
+) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Get document ID from URL path
 		docId, err := parseURLPath(r.URL.Path, "/api/v1/drafts")
 		if err != nil {
-			l.Error("error requesting document draft from algolia",
+			l.Error("error requesting document draft from search index",
 				"error", err,
 				"path", r.URL.Path,
 			)
@@ -601,12 +517,12 @@ func DraftsDocumentHandler(
 			return
 		}
 
-		// Get base document object from Algolia so we can determine the doc type.
+		// Get base document object from search index so we can determine the doc type.
 		baseDocObj := &hcd.BaseDoc{}
-		err = ar.Drafts.GetObject(docId, &baseDocObj)
+		err = sp.Drafts().GetObject(docId, &baseDocObj)
 		if err != nil {
-			// Handle 404 from Algolia and only log a warning.
-			if _, is404 := errs.IsAlgoliaErrWithCode(err, 404); is404 {
+			// Handle not found from the search index and only log a warning.
+			if errors.Is(err, search.ErrNotFound) {
 				l.Warn("base document object not found",
 					"error", err,
 					"path", r.URL.Path,
@@ -616,7 +532,7 @@ func DraftsDocumentHandler(
 				http.Error(w, "Draft document not found", http.StatusNotFound)
 				return
 			} else {
-				l.Error("error requesting base document object from Algolia",
+				l.Error("error requesting base document object from search index",
 					"error", err,
 					"path", r.URL.Path,
 					"method", r.Method,
@@ -640,10 +556,10 @@ func DraftsDocumentHandler(
 			return
 		}
 
-		// Get document object from Algolia.
-		err = ar.Drafts.GetObject(docId, &docObj)
+		// Get document object from search index.
+		err = sp.Drafts().GetObject(docId, &docObj)
 		if err != nil {
-			l.Error("error requesting document draft from Algolia",
+			l.Error("error requesting document draft from search index",
 				"error", err,
 				"doc_id", docId,
 			)
@@ -675,7 +591,7 @@ func DraftsDocumentHandler(
 			now := time.Now()
 
 			// Get file from Google Drive so we can return the latest modified time.
-			file, err := s.GetFile(docId)
+			file, err := st.GetFile(docId)
 			if err != nil {
 				l.Error("error getting document file from Google",
 					"error", err,
@@ -721,7 +637,7 @@ func DraftsDocumentHandler(
 			}
 
 			// Set locked value for response to value from the database (this value
-			// isn't stored in Algolia).
+			// isn't stored in search index).
 			docObj.SetLocked(doc.Locked)
 
 			// Write response.
@@ -769,7 +685,7 @@ func DraftsDocumentHandler(
 			}
 
 			// Delete document
-			err = s.DeleteFile(docId)
+			err = st.DeleteFile(docId)
 			if err != nil {
 				l.Error("error deleting document", "error", err, "doc_id", docId)
 				http.Error(w, "Error deleting document draft",
@@ -777,10 +693,14 @@ func DraftsDocumentHandler(
 				return
 			}
 
-			// Delete object in Algolia
-			res, err := aw.Drafts.DeleteObject(docId)
+			// Record the deletion in the audit log.
+			ae, err := newAuditEvent(
+				r, models.DraftDeletedAuditAction, docId, "", docObj, nil)
+			if err == nil {
+				err = ae.Create(db)
+			}
 			if err != nil {
-				l.Error("error deleting document draft from algolia",
+				l.Error("error recording draft deletion in audit log",
 					"error", err,
 					"doc_id", docId,
 				)
@@ -788,9 +708,13 @@ func DraftsDocumentHandler(
 					http.StatusInternalServerError)
 				return
 			}
-			err = res.Wait()
-			if err != nil {
-				l.Error("error deleting document draft from algolia",
+
+			// Delete object in search index. This is done after the deletion is
+			// recorded, as the search index can't be rolled back.
+			if err := sp.Drafts().DeleteObject(docId); err != nil {
+				l.Error("error deleting document draft from search index after "+
+					"recording the deletion in the audit log; the search index is "+
+					"out of sync",
 					"error", err,
 					"doc_id", docId,
 				)
@@ -878,7 +802,7 @@ func DraftsDocumentHandler(
 			}
 
 			// Check if document is locked.
-			locked, err := hcd.IsLocked(docId, db, s, l)
+			locked, err := hcd.IsLocked(docId, db, st, l)
 			if err != nil {
 				l.Error("error checking document locked status",
 					"error", err,
@@ -895,7 +819,7 @@ func DraftsDocumentHandler(
 				return
 			}
 
-			// Compare contributors in request and stored object in Algolia
+			// Compare contributors in request and stored object in search index
 			// before we save the patched objected
 			// Find out contributors to share the document with
 			var contributorsToAddSharing []string
@@ -918,6 +842,19 @@ func DraftsDocumentHandler(
 				contributorsToRemoveSharing = compareSlices(req.Contributors, docObj.GetContributors())
 			}
 
+			// Save the document state before patching for the audit log.
+			before, err := auditSnapshot(docObj)
+			if err != nil {
+				l.Error("error encoding document object for audit log",
+					"error", err,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"doc_id", docId)
+				http.Error(w, "Error patching document draft",
+					http.StatusInternalServerError)
+				return
+			}
+
 			// Patch document by decoding the (now validated) request body JSON to the
 			// document object.
 			err = json.NewDecoder(body).Decode(docObj)
@@ -937,7 +874,7 @@ func DraftsDocumentHandler(
 			// is that you can only share files
 			// with one user at a time
 			for _, c := range contributorsToAddSharing {
-				if err := s.ShareFile(docId, c, "writer"); err != nil {
+				if err := st.ShareFile(docId, c, "writer"); err != nil {
 					l.Error("error sharing file with the contributor",
 						"error", err,
 						"method", r.Method,
@@ -961,7 +898,7 @@ func DraftsDocumentHandler(
 				// associated with the permission doesn't
 				// match owner email(s).
 				if !contains(docObj.GetOwners(), c) {
-					if err := removeSharing(s, docId, c); err != nil {
+					if err := removeSharing(st, docId, c); err != nil {
 						l.Error("error removing contributor from file",
 							"error", err,
 							"method", r.Method,
@@ -979,68 +916,96 @@ func DraftsDocumentHandler(
 					"contributors_count", len(contributorsToRemoveSharing))
 			}
 
-			// Update product (if it is in the patch request).
-			if req.Product != "" {
-				// Update in database.
-				d := models.Document{
-					GoogleFileID: docId,
-					Product:      models.Product{Name: req.Product},
-				}
-				if err := d.Upsert(db); err != nil {
-					l.Error("error upserting document to update product",
-						"error", err,
-						"method", r.Method,
-						"path", r.URL.Path,
-						"product", req.Product,
-						"doc_id", docId)
-					http.Error(w, "Error patching document draft",
-						http.StatusInternalServerError)
-					return
-				}
+			ae, err := newAuditEvent(
+				r, models.DraftUpdatedAuditAction, docId, "", before, docObj)
+			if err != nil {
+				l.Error("error creating audit event",
+					"error", err,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"doc_id", docId)
+				http.Error(w, "Error patching document draft",
+					http.StatusInternalServerError)
+				return
 			}
 
-			// Update team (if it is in the patch request).
-			if req.Team != "" {
-				// Update in database.
-				d := models.Document{
-					GoogleFileID: docId,
-					Team:         models.Team{Name: req.Team},
+			// Update the database and record the patch in the audit log in a
+			// single transaction.
+			err = withAuditEvent(db, ae, func(tx *gorm.DB) error {
+				// Update product (if it is in the patch request).
+				if req.Product != "" {
+					d := models.Document{
+						GoogleFileID: docId,
+						Product:      models.Product{Name: req.Product},
+					}
+					if err := d.Upsert(tx); err != nil {
+						return fmt.Errorf(
+							"error upserting document to update product: %w", err)
+					}
 				}
-				if err := d.Upsert(db); err != nil {
-					l.Error("error upserting document to update team",
-						"error", err,
-						"method", r.Method,
-						"path", r.URL.Path,
-						"team", req.Team,
-						"doc_id", docId)
-					http.Error(w, "Error patching document draft",
-						http.StatusInternalServerError)
-					return
+
+				// Update team (if it is in the patch request).
+				if req.Team != "" {
+					d := models.Document{
+						GoogleFileID: docId,
+						Team:         models.Team{Name: req.Team},
+					}
+					if err := d.Upsert(tx); err != nil {
+						return fmt.Errorf(
+							"error upserting document to update team: %w", err)
+					}
 				}
 
-			}
+				// Update due date (if it is in the patch request). Get the document
+				// first so the upsert doesn't replace its associations.
+				if req.DueDate != nil {
+					d := models.Document{
+						GoogleFileID: docId,
+					}
+					if err := d.Get(tx); err != nil {
+						return fmt.Errorf("error getting document: %w", err)
+					}
+					d.DueDate = req.DueDate.TimePtr()
+					if err := d.Upsert(tx); err != nil {
+						return fmt.Errorf(
+							"error upserting document to update due date: %w", err)
+					}
+				}
 
-			// Save new modified draft doc object in Algolia.
-			res, err := aw.Drafts.SaveObject(docObj)
+				return nil
+			})
 			if err != nil {
-				l.Error("error saving patched draft doc in Algolia", "error", err,
+				l.Error("error patching document draft",
+					"error", err,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"product", req.Product,
+					"team", req.Team,
+					"due_date", req.DueDate.String(),
 					"doc_id", docId)
-				http.Error(w, "Error creating document draft",
+				http.Error(w, "Error patching document draft",
 					http.StatusInternalServerError)
 				return
 			}
-			err = res.Wait()
-			if err != nil {
-				l.Error("error saving patched draft doc in Algolia", "error", err,
+
+			// Save new modified draft doc object in search index. This is done
+			// after the database transaction is committed, as the search index
+			// can't be rolled back.
+			if err := sp.Drafts().SaveObject(docObj); err != nil {
+				l.Error("error saving patched draft doc in search index after "+
+					"updating the database; the search index is out of sync",
+					"error", err,
+					"method", r.Method,
+					"path", r.URL.Path,
 					"doc_id", docId)
-				http.Error(w, "Error creating document draft",
+				http.Error(w, "Error patching document draft",
 					http.StatusInternalServerError)
 				return
 			}
 
 			// Replace the doc header.
 			err = docObj.ReplaceHeader(
-				docId, cfg.BaseURL, true, s)
+				docId, cfg.BaseURL, true, st)
 			if err != nil {
 				l.Error("error replacing draft doc header",
 					"error", err, "doc_id", docId)
@@ -1050,7 +1015,7 @@ func DraftsDocumentHandler(
 			}
 
 			// Rename file with new title.
-			s.RenameFile(docId,
+			st.RenameFile(docId,
 				fmt.Sprintf(req.Title))
 
 			w.WriteHeader(http.StatusOK)
@@ -1064,7 +1029,7 @@ func DraftsDocumentHandler(
 }
 
 // validateID validates the whether the ID matches
-// the ID tag in the Algolia document object
+// the ID tag in the search document object
 func validateID(id string, tags []string) error {
 	// draft document should have tags set
 	// in order to verify the document
@@ -1124,42 +1089,9 @@ func parseURLPath(path, prefix string) (string, error) {
 	return resultPath[0], nil
 }
 
-// getDocTypeTemplate returns the file ID of the template for a specified
-// document type or an empty string if not found. new
-func getDocTypeTemplate(
-	docTypes []template,
-	docType string,
-) string {
-	docId := ""
-
-	for _, t := range docTypes {
-		if strings.ToUpper(t.TemplateName) == strings.ToUpper(docType) {
-			docId = t.DocId
-			break
-		}
-	}
-
-	return docId
-}
-
-// // document type or an empty string if not found. old
-// func getDocTypeTemplateOld(
-// 	docTypes []*config.DocumentType,
-// 	docType string,
-// ) string {
-// 	template := ""
-// 	for _, t := range docTypes {
-// 		if strings.ToUpper(t.Name) == docType {
-// 			template = t.Template
-// 			break
-// 		}
-// 	}
-// 	return template
-// }
-
 // removeSharing lists permissions for a document and then
 // deletes the permission for the supplied user email
-func removeSharing(s *gw.Service, docId, email string) error {
+func removeSharing(s storage.Provider, docId, email string) error {
 	permissions, err := s.ListPermissions(docId)
 	if err != nil {
 		return err
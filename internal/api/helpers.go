@@ -7,7 +7,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/hashicorp-forge/hermes/internal/auth/apitoken"
+	"github.com/hashicorp-forge/hermes/pkg/models"
 	"github.com/hashicorp/go-hclog"
+	"gorm.io/gorm"
 )
 
 // contains returns true if a string is present in a slice of strings.
@@ -20,6 +23,17 @@ func contains(values []string, s string) bool {
 	return false
 }
 
+// containsFold returns true if a string is present in a slice of strings,
+// ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
 // compareSlices compares the first slice with the second
 // and returns the elements that exist in the second slice
 // that don't exist in the first
@@ -43,6 +57,51 @@ func compareSlices(a, b []string) []string {
 	return diffElems
 }
 
+// authorizeAdmin returns true if the user making the request is a global admin
+// and, if the request was authenticated using an API token, the token has the
+// admin scope. Otherwise, it responds with an error and returns false.
+func authorizeAdmin(
+	w http.ResponseWriter, r *http.Request, l hclog.Logger, db *gorm.DB,
+) bool {
+	return authorizeAdminFor(w, r, l, db, models.GlobalRoleScope, "")
+}
+
+// authorizeAdminFor returns true if the user making the request is an admin for
+// the product or team with name name (see models.User.IsAdminFor) and, if the
+// request was authenticated using an API token, the token has the admin scope.
+// Otherwise, it responds with an error and returns false.
+func authorizeAdminFor(
+	w http.ResponseWriter, r *http.Request, l hclog.Logger, db *gorm.DB,
+	scope models.RoleScope, name string,
+) bool {
+	userEmail := r.Context().Value("userEmail").(string)
+	u := models.User{EmailAddress: userEmail}
+	isAdmin, err := u.IsAdminFor(db, scope, name)
+	if err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error authorizing request",
+			"error checking if user is an admin", err,
+			"scope", scope,
+			"name", name,
+		)
+		return false
+	}
+	if !isAdmin {
+		http.Error(w,
+			"Access denied: You must be an admin to perform this action.",
+			http.StatusForbidden)
+		return false
+	}
+	if tokenScope, ok := apitoken.ScopeFromContext(r.Context()); ok &&
+		tokenScope != models.AdminAPITokenScope {
+		http.Error(w,
+			"Access denied: API token scope doesn't allow admin actions.",
+			http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
 // decodeRequest decodes the JSON contents of a HTTP request body to a request
 // struct. An error is returned if the request contains fields that do not exist
 // in the request struct.
@@ -93,6 +152,23 @@ func parseResourceIDFromURL(url, apiPath string) (string, error) {
 	return resultPath[0], nil
 }
 
+// respondJSON responds to an HTTP request with status code httpCode and the
+// JSON encoding of v.
+func respondJSON(
+	w http.ResponseWriter, r *http.Request, l hclog.Logger,
+	httpCode int, v interface{},
+) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		l.Error("error encoding response",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+	}
+}
+
 // respondError responds to an HTTP request and logs an error.
 func respondError(
 	w http.ResponseWriter, r *http.Request, l hclog.Logger,
@@ -10,7 +10,8 @@ import (
 	"gorm.io/gorm"
 )
 
-// MakeUserAdminHandler handles the API request to make users admin.
+// MakeUserAdminHandler handles the API request to make users global admins.
+// Only global admins can make other users admins.
 func MakeUserAdminHandler(log hclog.Logger, db *gorm.DB) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
@@ -18,6 +19,11 @@ func MakeUserAdminHandler(log hclog.Logger, db *gorm.DB) http.Handler {
 			return
 		}
 
+		// Authorize request.
+		if !authorizeAdmin(w, r, log, db) {
+			return
+		}
+
 		switch r.Method {
 		case "POST":
 			// Decode the request to get the list of email IDs of the users to be made admins.
@@ -38,9 +44,27 @@ func MakeUserAdminHandler(log hclog.Logger, db *gorm.DB) http.Handler {
 					continue // Skip to the next user if the current one is not found.
 				}
 
-				// Update the user's role to "admin" (assuming "Role" is the field in the User model that stores roles).
-				user.Role = models.Admin
-				if err := db.Save(&user).Error; err != nil {
+				// Grant the user the global admin role.
+				before := struct {
+					Role models.RoleType `json:"role"`
+				}{Role: user.Role}
+				after := struct {
+					Role models.RoleType `json:"role"`
+				}{Role: models.Admin}
+				ae, err := newAuditEvent(
+					r, models.AdminGrantedAuditAction, "", user.EmailAddress, before, after)
+				if err != nil {
+					log.Error("error creating audit event", "error", err)
+					continue
+				}
+				ra := models.RoleAssignment{
+					User:      models.User{EmailAddress: user.EmailAddress},
+					Scope:     models.GlobalRoleScope,
+					GrantedBy: ae.Actor,
+				}
+				if err := withAuditEvent(db, ae, func(tx *gorm.DB) error {
+					return ra.Create(tx)
+				}); err != nil {
 					log.Error("error updating user role", "error", err)
 					continue // Skip to the next user if there is an error updating the role.
 				}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/hashicorp-forge/hermes/pkg/models"
 	"gorm.io/gorm"
@@ -31,6 +32,15 @@ type MeGetResponse struct {
 	Organization  string `json:"organization,omitempty"`
 	Profile       string `json:"profile,omitempty"`
 	Role          string `json:"role,omitempty"`
+
+	// DigestFrequency is how often the user is emailed about subscribed
+	// document events ("immediate", "daily", or "weekly").
+	DigestFrequency models.DigestFrequency `json:"digest_frequency"`
+}
+
+// MePatchRequest is a request to update the authenticated user's settings.
+type MePatchRequest struct {
+	DigestFrequency *models.DigestFrequency `json:"digest_frequency,omitempty"`
 }
 
 func MeHandler(
@@ -78,84 +88,19 @@ func MeHandler(
 				http.Error(w, userErrMsg, httpCode)
 			}
 
-			ppl, err := s.SearchPeople(userEmail, "emailAddresses,names,photos")
+			resp, err := newMeGetResponse(s, userEmail)
 			if err != nil {
 				errResp(
 					http.StatusInternalServerError,
 					"Error getting user information",
-					"error searching people directory",
-					err,
-				)
-				return
-			}
-
-			// Verify that the result only contains one person.
-			if len(ppl) != 1 {
-				errResp(
-					http.StatusInternalServerError,
-					"Error getting user information",
-					fmt.Sprintf(
-						"wrong number of people in search result: %d", len(ppl)),
-					err,
-				)
-				return
-			}
-			p := ppl[0]
-
-			// Make sure that the result's email address is the same as the
-			// authenticated user, is the primary email address, and is verified.
-			if len(p.EmailAddresses) == 0 ||
-				p.EmailAddresses[0].Value != userEmail ||
-				!p.EmailAddresses[0].Metadata.Primary ||
-				!p.EmailAddresses[0].Metadata.Verified {
-				errResp(
-					http.StatusInternalServerError,
-					"Error getting user information",
-					"wrong user in search result",
-					err,
-				)
-				return
-			}
-
-			// Replace the names in the People API result with data from the Admin
-			// Directory API.
-			// TODO: remove this when the bug in the People API is fixed:
-			// https://issuetracker.google.com/issues/196235775
-			if err := replaceNamesWithAdminAPIResponse(
-				p, s,
-			); err != nil {
-				errResp(
-					http.StatusInternalServerError,
-					"Error getting user information",
-					"error replacing names with Admin API response",
-					err,
-				)
-				return
-			}
-
-			// Verify other required values are set.
-			if len(p.Names) == 0 {
-				errResp(
-					http.StatusInternalServerError,
-					"Error getting user information",
-					"no names in result",
+					"error getting user information from directory",
 					err,
 				)
 				return
 			}
 
-			// Write response.
-			resp := MeGetResponse{
-				ID:            p.EmailAddresses[0].Metadata.Source.Id,
-				Email:         p.EmailAddresses[0].Value,
-				VerifiedEmail: p.EmailAddresses[0].Metadata.Verified,
-				Name:          p.Names[0].DisplayName,
-				GivenName:     p.Names[0].GivenName,
-				FamilyName:    p.Names[0].FamilyName,
-			}
-
 			// fetch whether user is admin or not
-			user := models.User{EmailAddress: p.EmailAddresses[0].Value}
+			user := models.User{EmailAddress: resp.Email}
 			if resp.Role, err = user.FetchRole(db); err != nil {
 				errResp(
 					http.StatusInternalServerError,
@@ -166,22 +111,18 @@ func MeHandler(
 				return
 			}
 
-			// Get additional information from user admin api
-			if err := getOtherUserInfo(
-				&resp, p, s,
-			); err != nil {
+			freqs, err := models.GetDigestFrequencies(db, []string{userEmail})
+			if err != nil {
 				errResp(
 					http.StatusInternalServerError,
 					"Error getting user information",
-					"error getting additional info with Admin API response",
+					"error getting user digest frequency",
 					err,
 				)
 				return
 			}
+			resp.DigestFrequency = freqs[strings.ToLower(userEmail)]
 
-			if len(p.Photos) > 0 {
-				resp.Picture = p.Photos[0].Url
-			}
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			enc := json.NewEncoder(w)
@@ -196,6 +137,43 @@ func MeHandler(
 				return
 			}
 
+		case "PATCH":
+			var req MePatchRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding me patch request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			if req.DigestFrequency != nil {
+				if err := req.DigestFrequency.Validate(); err != nil {
+					http.Error(w, fmt.Sprintf("Bad request: %v", err),
+						http.StatusBadRequest)
+					return
+				}
+
+				u := models.User{
+					EmailAddress:    userEmail,
+					DigestFrequency: *req.DigestFrequency,
+				}
+				if err := u.UpdateDigestFrequency(db); err != nil {
+					errResp(
+						http.StatusInternalServerError,
+						"Error updating user settings",
+						"error updating user digest frequency",
+						err,
+					)
+					return
+				}
+				l.Info("updated user digest frequency",
+					"digest_frequency", u.DigestFrequency,
+					"user", userEmail,
+				)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
@@ -203,6 +181,77 @@ func MeHandler(
 	})
 }
 
+// newMeGetResponse returns the directory information of the authenticated
+// user with email address userEmail. Only the email address is returned if
+// Google Workspace isn't configured.
+func newMeGetResponse(s *gw.Service, userEmail string) (MeGetResponse, error) {
+	if s == nil {
+		return MeGetResponse{
+			ID:            userEmail,
+			Email:         userEmail,
+			VerifiedEmail: true,
+			Name:          userEmail,
+		}, nil
+	}
+
+	ppl, err := s.SearchPeople(userEmail, "emailAddresses,names,photos")
+	if err != nil {
+		return MeGetResponse{}, fmt.Errorf(
+			"error searching people directory: %w", err)
+	}
+
+	// Verify that the result only contains one person.
+	if len(ppl) != 1 {
+		return MeGetResponse{}, fmt.Errorf(
+			"wrong number of people in search result: %d", len(ppl))
+	}
+	p := ppl[0]
+
+	// Make sure that the result's email address is the same as the
+	// authenticated user, is the primary email address, and is verified.
+	if len(p.EmailAddresses) == 0 ||
+		p.EmailAddresses[0].Value != userEmail ||
+		!p.EmailAddresses[0].Metadata.Primary ||
+		!p.EmailAddresses[0].Metadata.Verified {
+		return MeGetResponse{}, errors.New("wrong user in search result")
+	}
+
+	// Replace the names in the People API result with data from the Admin
+	// Directory API.
+	// TODO: remove this when the bug in the People API is fixed:
+	// https://issuetracker.google.com/issues/196235775
+	if err := replaceNamesWithAdminAPIResponse(p, s); err != nil {
+		return MeGetResponse{}, fmt.Errorf(
+			"error replacing names with Admin API response: %w", err)
+	}
+
+	// Verify other required values are set.
+	if len(p.Names) == 0 {
+		return MeGetResponse{}, errors.New("no names in result")
+	}
+
+	resp := MeGetResponse{
+		ID:            p.EmailAddresses[0].Metadata.Source.Id,
+		Email:         p.EmailAddresses[0].Value,
+		VerifiedEmail: p.EmailAddresses[0].Metadata.Verified,
+		Name:          p.Names[0].DisplayName,
+		GivenName:     p.Names[0].GivenName,
+		FamilyName:    p.Names[0].FamilyName,
+	}
+
+	// Get additional information from user admin api
+	if err := getOtherUserInfo(&resp, p, s); err != nil {
+		return MeGetResponse{}, fmt.Errorf(
+			"error getting additional info with Admin API response: %w", err)
+	}
+
+	if len(p.Photos) > 0 {
+		resp.Picture = p.Photos[0].Url
+	}
+
+	return resp, nil
+}
+
 // Replace the names in the People API result with data from the Admin Directory
 // API.
 // TODO: remove this when the bug in the People API is fixed:
@@ -256,3 +305,40 @@ func getOtherUserInfo(
 
 	return nil
 }
+
+// getPersonDisplayName returns the display name of the person with the
+// provided email address from the Google Workspace directory. The email
+// address is used as the display name if Google Workspace isn't configured.
+func getPersonDisplayName(s *gw.Service, email string) (string, error) {
+	if s == nil {
+		return email, nil
+	}
+
+	ppl, err := s.SearchPeople(email, "emailAddresses,names")
+	if err != nil {
+		return "", fmt.Errorf("error searching people directory: %w", err)
+	}
+
+	// Verify that the result only contains one person.
+	if len(ppl) != 1 {
+		return "", fmt.Errorf(
+			"wrong number of people in search result: %d", len(ppl))
+	}
+	p := ppl[0]
+
+	// Replace the names in the People API result with data from the Admin
+	// Directory API.
+	// TODO: remove this when the bug in the People API is fixed:
+	// https://issuetracker.google.com/issues/196235775
+	if err := replaceNamesWithAdminAPIResponse(p, s); err != nil {
+		return "", fmt.Errorf(
+			"error replacing names with Admin API response: %w", err)
+	}
+
+	// Verify other required values are set.
+	if len(p.Names) == 0 {
+		return "", errors.New("no names in result")
+	}
+
+	return p.Names[0].DisplayName, nil
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// NotificationPreferences are the authenticated user's notification
+// preferences, used for both requests and responses of
+// "/api/v1/me/notifications".
+type NotificationPreferences struct {
+	// Channels are the channels to notify the user through, keyed by event
+	// type. In requests, events without an entry are sent through all
+	// channels, and events with an empty list aren't sent. Responses have an
+	// entry for every event type.
+	Channels map[models.NotificationEventType][]models.NotificationChannel `json:"channels"`
+
+	// QuietHours are the user's quiet hours, or nil if disabled.
+	QuietHours *QuietHours `json:"quietHours"`
+}
+
+// QuietHours are the hours of the day that a user doesn't want to be
+// notified. Notifications during quiet hours are delivered when they end.
+type QuietHours struct {
+	// Start is the time of day ("HH:MM") that quiet hours start.
+	Start string `json:"start"`
+
+	// End is the time of day ("HH:MM") that quiet hours end. Quiet hours span
+	// midnight if End is before Start.
+	End string `json:"end"`
+
+	// TimeZone is the IANA time zone (e.g., "Asia/Kolkata") of Start and End.
+	// UTC is used if empty.
+	TimeZone string `json:"timeZone"`
+}
+
+// MeNotificationsHandler handles requests to get and replace the authenticated
+// user's notification preferences at "/api/v1/me/notifications".
+func MeNotificationsHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userEmail := r.Context().Value("userEmail").(string)
+
+		switch r.Method {
+		case "GET":
+			// Users without preferences are notified of all events through all
+			// channels.
+			p := models.NotificationPreference{
+				User: models.User{EmailAddress: userEmail},
+			}
+			if err := p.Get(db); err != nil &&
+				!errors.Is(err, gorm.ErrRecordNotFound) {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting notification preferences",
+					"error getting notification preference", err)
+				return
+			}
+
+			respondJSON(w, r, l, http.StatusOK,
+				newNotificationPreferencesResponse(p))
+
+		case "PUT":
+			var req NotificationPreferences
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding notification preferences request",
+					"error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			p := models.NotificationPreference{
+				User: models.User{EmailAddress: userEmail},
+				Channels: datatypes.JSONType[map[models.NotificationEventType][]models.NotificationChannel]{
+					Data: req.Channels,
+				},
+			}
+			if req.QuietHours != nil {
+				p.QuietHoursStart = req.QuietHours.Start
+				p.QuietHoursEnd = req.QuietHours.End
+				p.TimeZone = req.QuietHours.TimeZone
+			}
+			if err := p.Validate(); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %v", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			if err := p.Upsert(db); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error updating notification preferences",
+					"error upserting notification preference", err)
+				return
+			}
+
+			l.Info("updated notification preferences",
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			respondJSON(w, r, l, http.StatusOK,
+				newNotificationPreferencesResponse(p))
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+	})
+}
+
+// newNotificationPreferencesResponse returns the API response for
+// notification preference p.
+func newNotificationPreferencesResponse(
+	p models.NotificationPreference) NotificationPreferences {
+	resp := NotificationPreferences{
+		Channels: make(map[models.NotificationEventType][]models.NotificationChannel,
+			len(models.NotificationEventTypes)),
+	}
+	for _, e := range models.NotificationEventTypes {
+		chs := p.ChannelsFor(e)
+		if chs == nil {
+			chs = []models.NotificationChannel{}
+		}
+		resp.Channels[e] = chs
+	}
+
+	if p.QuietHoursStart != "" && p.QuietHoursEnd != "" {
+		tz := p.TimeZone
+		if tz == "" {
+			tz = "UTC"
+		}
+		resp.QuietHours = &QuietHours{
+			Start:    p.QuietHoursStart,
+			End:      p.QuietHoursEnd,
+			TimeZone: tz,
+		}
+	}
+
+	return resp
+}
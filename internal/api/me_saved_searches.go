@@ -0,0 +1,258 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// SavedSearchRequest is a request to create or update a saved search. Fields
+// that are nil aren't changed by updates.
+type SavedSearchRequest struct {
+	Name        *string              `json:"name,omitempty"`
+	Query       *string              `json:"query,omitempty"`
+	Filters     *map[string][]string `json:"filters,omitempty"`
+	NotifyEmail *bool                `json:"notifyEmail,omitempty"`
+	NotifySlack *bool                `json:"notifySlack,omitempty"`
+}
+
+// SavedSearchResponse is a saved search returned by the saved search
+// endpoints.
+type SavedSearchResponse struct {
+	ID              uint                `json:"id"`
+	CreatedTime     time.Time           `json:"createdTime"`
+	Name            string              `json:"name"`
+	Query           string              `json:"query"`
+	Filters         map[string][]string `json:"filters"`
+	NotifyEmail     bool                `json:"notifyEmail"`
+	NotifySlack     bool                `json:"notifySlack"`
+	LastCheckedTime *time.Time          `json:"lastCheckedTime"`
+}
+
+// MeSavedSearchesHandler handles requests to list and create the authenticated
+// user's saved searches at "/api/v1/me/saved-searches", and to get, update,
+// and delete them at "/api/v1/me/saved-searches/{id}".
+func MeSavedSearchesHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userEmail := r.Context().Value("userEmail").(string)
+
+		// Parse optional saved search ID from the URL path.
+		idStr := strings.Trim(
+			strings.TrimPrefix(r.URL.Path, "/api/v1/me/saved-searches"), "/")
+		if idStr != "" {
+			id, err := strconv.ParseUint(idStr, 10, 32)
+			if err != nil || id == 0 {
+				http.Error(w, "Bad request: invalid saved search ID",
+					http.StatusBadRequest)
+				return
+			}
+
+			s := models.SavedSearch{
+				User: models.User{EmailAddress: userEmail},
+			}
+			s.ID = uint(id)
+
+			switch r.Method {
+			case "GET":
+				if err := s.Get(db); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						http.Error(w, "Saved search not found", http.StatusNotFound)
+						return
+					}
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error getting saved search",
+						"error getting saved search", err,
+						"saved_search_id", id,
+					)
+					return
+				}
+
+				respondJSON(w, r, l, http.StatusOK, newSavedSearchResponse(s))
+
+			case "PATCH":
+				var req SavedSearchRequest
+				if err := decodeRequest(r, &req); err != nil {
+					l.Error("error decoding saved search request", "error", err)
+					http.Error(w, fmt.Sprintf("Bad request: %q", err),
+						http.StatusBadRequest)
+					return
+				}
+
+				if err := s.Get(db); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						http.Error(w, "Saved search not found", http.StatusNotFound)
+						return
+					}
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error updating saved search",
+						"error getting saved search", err,
+						"saved_search_id", id,
+					)
+					return
+				}
+				req.apply(&s)
+				if err := validateSavedSearch(s); err != nil {
+					http.Error(w, fmt.Sprintf("Bad request: %v", err),
+						http.StatusBadRequest)
+					return
+				}
+
+				if err := s.Update(db); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						http.Error(w, "Saved search not found", http.StatusNotFound)
+						return
+					}
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error updating saved search",
+						"error updating saved search", err,
+						"saved_search_id", id,
+					)
+					return
+				}
+
+				l.Info("updated saved search",
+					"saved_search_id", id,
+					"user", userEmail,
+				)
+				respondJSON(w, r, l, http.StatusOK, newSavedSearchResponse(s))
+
+			case "DELETE":
+				if err := s.Delete(db); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						http.Error(w, "Saved search not found", http.StatusNotFound)
+						return
+					}
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error deleting saved search",
+						"error deleting saved search", err,
+						"saved_search_id", id,
+					)
+					return
+				}
+
+				l.Info("deleted saved search",
+					"saved_search_id", id,
+					"user", userEmail,
+				)
+				w.WriteHeader(http.StatusNoContent)
+
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			var ss models.SavedSearches
+			if err := ss.FindByUser(db, userEmail); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting saved searches",
+					"error finding saved searches", err)
+				return
+			}
+
+			resp := make([]SavedSearchResponse, len(ss))
+			for i, s := range ss {
+				resp[i] = newSavedSearchResponse(s)
+			}
+			respondJSON(w, r, l, http.StatusOK, resp)
+
+		case "POST":
+			var req SavedSearchRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding saved search request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			s := models.SavedSearch{
+				User: models.User{EmailAddress: userEmail},
+			}
+			req.apply(&s)
+			if err := validateSavedSearch(s); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %v", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			if err := s.Create(db); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error creating saved search",
+					"error creating saved search", err)
+				return
+			}
+
+			l.Info("created saved search",
+				"saved_search_id", s.ID,
+				"user", userEmail,
+			)
+			respondJSON(w, r, l, http.StatusCreated, newSavedSearchResponse(s))
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// apply sets the fields of saved search s that are set in the request.
+func (req SavedSearchRequest) apply(s *models.SavedSearch) {
+	if req.Name != nil {
+		s.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Query != nil {
+		s.Query = *req.Query
+	}
+	if req.Filters != nil {
+		s.Filters = datatypes.JSONType[map[string][]string]{Data: *req.Filters}
+	}
+	if req.NotifyEmail != nil {
+		s.NotifyEmail = *req.NotifyEmail
+	}
+	if req.NotifySlack != nil {
+		s.NotifySlack = *req.NotifySlack
+	}
+}
+
+// validateSavedSearch validates a saved search created or updated by a user.
+func validateSavedSearch(s models.SavedSearch) error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(s.Name) > 100 {
+		return errors.New("name must be at most 100 characters")
+	}
+	for attr := range s.Filters.Data {
+		if !contains(models.SavedSearchFilterAttributes, attr) {
+			return fmt.Errorf("invalid filter attribute %q", attr)
+		}
+	}
+	return nil
+}
+
+// newSavedSearchResponse returns the API response for saved search s.
+func newSavedSearchResponse(s models.SavedSearch) SavedSearchResponse {
+	filters := s.Filters.Data
+	if filters == nil {
+		filters = map[string][]string{}
+	}
+	return SavedSearchResponse{
+		ID:              s.ID,
+		CreatedTime:     s.CreatedAt,
+		Name:            s.Name,
+		Query:           s.Query,
+		Filters:         filters,
+		NotifyEmail:     s.NotifyEmail,
+		NotifySlack:     s.NotifySlack,
+		LastCheckedTime: s.LastCheckedAt,
+	}
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// SubscriptionTargetRequest is a request to subscribe to a team, project,
+// document type, or document. All events are subscribed to if Events is
+// empty.
+type SubscriptionTargetRequest struct {
+	TargetType models.SubscriptionTargetType  `json:"targetType"`
+	Target     string                         `json:"target"`
+	Events     []models.SubscriptionEventType `json:"events"`
+}
+
+// SubscriptionTargetPatchRequest is a request to change the events of a
+// subscription.
+type SubscriptionTargetPatchRequest struct {
+	Events []models.SubscriptionEventType `json:"events"`
+}
+
+// SubscriptionTargetResponse is a subscription returned by the subscription
+// target endpoints.
+type SubscriptionTargetResponse struct {
+	ID          uint                           `json:"id"`
+	CreatedTime time.Time                      `json:"createdTime"`
+	TargetType  models.SubscriptionTargetType  `json:"targetType"`
+	Target      string                         `json:"target"`
+	Events      []models.SubscriptionEventType `json:"events"`
+}
+
+// MeSubscriptionTargetsHandler handles requests to list and create the
+// authenticated user's subscriptions to teams, projects, document types, and
+// documents at "/api/v1/me/subscriptions/targets", and to change and delete
+// them at "/api/v1/me/subscriptions/targets/{id}". Product subscriptions are
+// handled by MeSubscriptionsHandler.
+func MeSubscriptionTargetsHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userEmail := r.Context().Value("userEmail").(string)
+
+		// Parse optional subscription ID from the URL path.
+		idStr := strings.Trim(strings.TrimPrefix(
+			r.URL.Path, "/api/v1/me/subscriptions/targets"), "/")
+		if idStr != "" {
+			id, err := strconv.ParseUint(idStr, 10, 32)
+			if err != nil || id == 0 {
+				http.Error(w, "Bad request: invalid subscription ID",
+					http.StatusBadRequest)
+				return
+			}
+
+			sub := models.Subscription{
+				User: models.User{EmailAddress: userEmail},
+			}
+			sub.ID = uint(id)
+
+			switch r.Method {
+			case "PATCH":
+				var req SubscriptionTargetPatchRequest
+				if err := decodeRequest(r, &req); err != nil {
+					l.Error("error decoding subscription request", "error", err)
+					http.Error(w, fmt.Sprintf("Bad request: %q", err),
+						http.StatusBadRequest)
+					return
+				}
+				if err := validateSubscriptionEvents(req.Events); err != nil {
+					http.Error(w, fmt.Sprintf("Bad request: %v", err),
+						http.StatusBadRequest)
+					return
+				}
+
+				sub.Events = datatypes.JSONType[[]models.SubscriptionEventType]{
+					Data: req.Events,
+				}
+				if err := sub.UpdateEvents(db); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						http.Error(w, "Subscription not found", http.StatusNotFound)
+						return
+					}
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error updating subscription",
+						"error updating subscription events", err,
+						"subscription_id", id,
+					)
+					return
+				}
+
+				respondJSON(w, r, l, http.StatusOK,
+					newSubscriptionTargetResponse(sub))
+
+			case "DELETE":
+				if err := sub.Delete(db); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						http.Error(w, "Subscription not found", http.StatusNotFound)
+						return
+					}
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error deleting subscription",
+						"error deleting subscription", err,
+						"subscription_id", id,
+					)
+					return
+				}
+
+				l.Info("deleted subscription",
+					"subscription_id", id,
+					"user", userEmail,
+				)
+				w.WriteHeader(http.StatusNoContent)
+
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			var subs models.Subscriptions
+			if err := subs.FindByUser(db, userEmail); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting subscriptions",
+					"error finding subscriptions", err)
+				return
+			}
+
+			resp := make([]SubscriptionTargetResponse, len(subs))
+			for i, sub := range subs {
+				resp[i] = newSubscriptionTargetResponse(sub)
+			}
+			respondJSON(w, r, l, http.StatusOK, resp)
+
+		case "POST":
+			var req SubscriptionTargetRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding subscription request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+			if len(req.Events) == 0 {
+				req.Events = models.SubscriptionEventTypes
+			}
+			if err := validateSubscriptionEvents(req.Events); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %v", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			// Use the target's name as stored in the database, so it matches
+			// the values of document objects.
+			target, err := getSubscriptionTarget(db, req.TargetType, req.Target)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					http.Error(w, fmt.Sprintf("Bad request: %s %q not found",
+						req.TargetType, req.Target), http.StatusBadRequest)
+					return
+				}
+				if errors.Is(err, errInvalidSubscriptionTargetType) {
+					http.Error(w, fmt.Sprintf("Bad request: %v", err),
+						http.StatusBadRequest)
+					return
+				}
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error creating subscription",
+					"error getting subscription target", err,
+					"target_type", req.TargetType,
+					"target", req.Target,
+				)
+				return
+			}
+
+			sub := models.Subscription{
+				User:       models.User{EmailAddress: userEmail},
+				TargetType: req.TargetType,
+				Target:     target,
+				Events: datatypes.JSONType[[]models.SubscriptionEventType]{
+					Data: req.Events,
+				},
+			}
+			if err := sub.Upsert(db); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error creating subscription",
+					"error upserting subscription", err)
+				return
+			}
+
+			l.Info("subscribed to target",
+				"subscription_id", sub.ID,
+				"target", sub.Target,
+				"target_type", sub.TargetType,
+				"user", userEmail,
+			)
+			respondJSON(w, r, l, http.StatusCreated,
+				newSubscriptionTargetResponse(sub))
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// errInvalidSubscriptionTargetType is returned for an unknown subscription
+// target type.
+var errInvalidSubscriptionTargetType = errors.New(
+	"invalid subscription target type")
+
+// getSubscriptionTarget returns the name of the team, project, or document
+// type, or the Google file ID of the document, identified by target as stored
+// in database db. It returns gorm.ErrRecordNotFound if the target doesn't
+// exist.
+func getSubscriptionTarget(
+	db *gorm.DB, t models.SubscriptionTargetType, target string,
+) (string, error) {
+	if target == "" {
+		return "", gorm.ErrRecordNotFound
+	}
+
+	switch t {
+	case models.TeamSubscriptionTarget:
+		team := models.Team{Name: target}
+		if err := team.Get(db); err != nil {
+			return "", err
+		}
+		return team.Name, nil
+	case models.ProjectSubscriptionTarget:
+		var p models.Project
+		if err := db.Where("name = ?", target).First(&p).Error; err != nil {
+			return "", err
+		}
+		return p.Name, nil
+	case models.DocumentTypeSubscriptionTarget:
+		dt := models.DocumentType{Name: target}
+		if err := dt.Get(db); err != nil {
+			return "", err
+		}
+		return dt.Name, nil
+	case models.DocumentSubscriptionTarget:
+		d := models.Document{GoogleFileID: target}
+		if err := d.Get(db); err != nil {
+			return "", err
+		}
+		return d.GoogleFileID, nil
+	default:
+		return "", fmt.Errorf("%w: %q", errInvalidSubscriptionTargetType, t)
+	}
+}
+
+// validateSubscriptionEvents returns an error if events contains an unknown
+// event type.
+func validateSubscriptionEvents(events []models.SubscriptionEventType) error {
+	if len(events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, e := range events {
+		found := false
+		for _, et := range models.SubscriptionEventTypes {
+			if e == et {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid event %q", e)
+		}
+	}
+	return nil
+}
+
+// newSubscriptionTargetResponse returns the API response for subscription sub.
+func newSubscriptionTargetResponse(
+	sub models.Subscription) SubscriptionTargetResponse {
+	events := sub.Events.Data
+	if events == nil {
+		events = []models.SubscriptionEventType{}
+	}
+	return SubscriptionTargetResponse{
+		ID:          sub.ID,
+		CreatedTime: sub.CreatedAt,
+		TargetType:  sub.TargetType,
+		Target:      sub.Target,
+		Events:      events,
+	}
+}
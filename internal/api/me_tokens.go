@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/gorm"
+)
+
+// APITokenRequest is a request to create an API token.
+type APITokenRequest struct {
+	Name  string               `json:"name"`
+	Scope models.APITokenScope `json:"scope"`
+}
+
+// APITokenResponse is an API token returned by the API token endpoints. The
+// token is only returned when it is created.
+type APITokenResponse struct {
+	ID           uint                 `json:"id"`
+	CreatedTime  time.Time            `json:"createdTime"`
+	Name         string               `json:"name"`
+	Prefix       string               `json:"prefix"`
+	Scope        models.APITokenScope `json:"scope"`
+	LastUsedTime *time.Time           `json:"lastUsedTime"`
+	Token        string               `json:"token,omitempty"`
+}
+
+// MeTokensHandler handles requests to list and create the authenticated user's
+// API tokens at "/api/v1/me/tokens", and to revoke them at
+// "/api/v1/me/tokens/{id}".
+func MeTokensHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userEmail := r.Context().Value("userEmail").(string)
+
+		// Parse optional token ID from the URL path.
+		idStr := strings.Trim(
+			strings.TrimPrefix(r.URL.Path, "/api/v1/me/tokens"), "/")
+		if idStr != "" {
+			id, err := strconv.ParseUint(idStr, 10, 32)
+			if err != nil || id == 0 {
+				http.Error(w, "Bad request: invalid token ID",
+					http.StatusBadRequest)
+				return
+			}
+
+			switch r.Method {
+			case "DELETE":
+				t := models.APIToken{
+					User: models.User{EmailAddress: userEmail},
+				}
+				t.ID = uint(id)
+				if err := t.Delete(db); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						http.Error(w, "Token not found", http.StatusNotFound)
+						return
+					}
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error revoking token",
+						"error deleting API token", err,
+						"token_id", id,
+					)
+					return
+				}
+
+				l.Info("revoked API token",
+					"token_id", id,
+					"user", userEmail,
+				)
+				w.WriteHeader(http.StatusNoContent)
+
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			var ts models.APITokens
+			if err := ts.FindByUser(db, userEmail); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting tokens",
+					"error finding API tokens", err)
+				return
+			}
+
+			resp := make([]APITokenResponse, len(ts))
+			for i, t := range ts {
+				resp[i] = newAPITokenResponse(t, "")
+			}
+			respondJSON(w, r, l, http.StatusOK, resp)
+
+		case "POST":
+			var req APITokenRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding API token request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+			if err := req.Scope.Validate(); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+			if req.Name == "" {
+				http.Error(w, "Bad request: name is required",
+					http.StatusBadRequest)
+				return
+			}
+
+			// Only admins can create tokens with the admin scope.
+			if req.Scope == models.AdminAPITokenScope &&
+				!authorizeAdmin(w, r, l, db) {
+				return
+			}
+
+			t := models.APIToken{
+				User:  models.User{EmailAddress: userEmail},
+				Name:  req.Name,
+				Scope: req.Scope,
+			}
+			tok, err := t.Generate()
+			if err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error creating token",
+					"error generating API token", err)
+				return
+			}
+			if err := t.Create(db); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error creating token",
+					"error creating API token", err)
+				return
+			}
+
+			l.Info("created API token",
+				"scope", t.Scope,
+				"token_id", t.ID,
+				"user", userEmail,
+			)
+
+			// Return the token once so it can be used by the client.
+			respondJSON(w, r, l, http.StatusCreated, newAPITokenResponse(t, tok))
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// newAPITokenResponse returns the API response for API token t, including token
+// tok if not empty.
+func newAPITokenResponse(t models.APIToken, tok string) APITokenResponse {
+	return APITokenResponse{
+		ID:           t.ID,
+		CreatedTime:  t.CreatedAt,
+		Name:         t.Name,
+		Prefix:       t.Prefix,
+		Scope:        t.Scope,
+		LastUsedTime: t.LastUsedAt,
+		Token:        tok,
+	}
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"github.com/hashicorp-forge/hermes/internal/config"
+	"github.com/hashicorp-forge/hermes/internal/email"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/slack-go/slack"
+)
+
+// newReviewRequestedMessage returns the notification requesting reviewers to
+// review document docObj at URL docURL, owned by the user with name ownerName.
+func newReviewRequestedMessage(
+	cfg *config.Config,
+	docObj hcd.Doc,
+	docURL string,
+	ownerName string,
+	reviewers []string,
+) (notify.Message, error) {
+	e, err := email.NewReviewRequestedEmail(email.ReviewRequestedEmailData{
+		BaseURL:            cfg.BaseURL,
+		DocumentOwner:      ownerName,
+		DocumentType:       docObj.GetDocType(),
+		DocumentShortName:  docObj.GetDocNumber(),
+		DocumentTitle:      docObj.GetTitle(),
+		DocumentURL:        docURL,
+		DocumentProd:       docObj.GetProduct(),
+		DocumentTeam:       docObj.GetTeam(),
+		DocumentOwnerEmail: docObj.GetOwners()[0],
+	})
+	if err != nil {
+		return notify.Message{}, err
+	}
+
+	sd := slackbot.ReviewerRequestedSlackData{
+		BaseURL:            cfg.BaseURL,
+		DocumentID:         docObj.GetObjectID(),
+		DocumentOwner:      ownerName,
+		DocumentType:       docObj.GetDocType(),
+		DocumentShortName:  docObj.GetDocNumber(),
+		DocumentTitle:      docObj.GetTitle(),
+		DocumentURL:        docURL,
+		DocumentProd:       docObj.GetProduct(),
+		DocumentTeam:       docObj.GetTeam(),
+		DocumentOwnerEmail: docObj.GetOwners()[0],
+		Interactive:        cfg.Slack.SigningSecret != "",
+		Snoozable:          reviewSnoozeEnabled(cfg),
+	}
+	return notify.Message{
+		To:       reviewers,
+		Event:    models.ReviewRequestedNotificationEvent,
+		Subject:  e.Subject,
+		HTMLBody: e.Body,
+		SlackBlocks: func(username string) ([]slack.Block, error) {
+			msg, err := slackbot.GenerateUIRichBlocks_Reviewer(sd, username)
+			if err != nil {
+				return nil, err
+			}
+			return msg.Blocks.BlockSet, nil
+		},
+	}, nil
+}
+
+// newContributorRequestedMessage returns the notification inviting
+// contributors to contribute to draft docObj at URL docURL, owned by the user
+// with name ownerName.
+func newContributorRequestedMessage(
+	cfg *config.Config,
+	docObj hcd.Doc,
+	docURL string,
+	ownerName string,
+	contributors []string,
+) (notify.Message, error) {
+	e, err := email.NewContributorRequestedEmail(
+		email.ContributorRequestedEmailData{
+			BaseURL:            cfg.BaseURL,
+			DocumentOwner:      ownerName,
+			DocumentOwnerEmail: docObj.GetOwners()[0],
+			DocumentType:       docObj.GetDocType(),
+			DocumentTitle:      docObj.GetTitle(),
+			DocumentURL:        docURL,
+			DocumentProd:       docObj.GetProduct(),
+			DocumentTeam:       docObj.GetTeam(),
+		})
+	if err != nil {
+		return notify.Message{}, err
+	}
+
+	sd := slackbot.ContributorInvitationSlackData{
+		BaseURL:            cfg.BaseURL,
+		DocumentOwner:      ownerName,
+		DocumentOwnerEmail: docObj.GetOwners()[0],
+		DocumentType:       docObj.GetDocType(),
+		DocumentTitle:      docObj.GetTitle(),
+		DocumentURL:        docURL,
+		DocumentProd:       docObj.GetProduct(),
+		DocumentTeam:       docObj.GetTeam(),
+	}
+	return notify.Message{
+		To:       contributors,
+		Event:    models.ContributorInvitedNotificationEvent,
+		Subject:  e.Subject,
+		HTMLBody: e.Body,
+		SlackBlocks: func(username string) ([]slack.Block, error) {
+			msg, err := slackbot.GenerateUIRichBlocks_Contributor(sd, username)
+			if err != nil {
+				return nil, err
+			}
+			return msg.Blocks.BlockSet, nil
+		},
+	}, nil
+}
+
+// newApprovalReceivedMessage returns the notification to the owners of
+// document docObj at URL docURL that the user with email address approver
+// approved it. fullyApproved is true if the approval moved the document to the
+// approved status.
+func newApprovalReceivedMessage(
+	cfg *config.Config,
+	docObj hcd.Doc,
+	docURL string,
+	approver string,
+	fullyApproved bool,
+) (notify.Message, error) {
+	e, err := email.NewApprovalReceivedEmail(email.ApprovalReceivedEmailData{
+		BaseURL:           cfg.BaseURL,
+		Approver:          approver,
+		ApprovalCount:     len(docObj.GetReviewedBy()),
+		DocumentShortName: docObj.GetDocNumber(),
+		DocumentTitle:     docObj.GetTitle(),
+		DocumentURL:       docURL,
+		FullyApproved:     fullyApproved,
+		ReviewerCount:     len(docObj.GetReviewers()),
+	})
+	if err != nil {
+		return notify.Message{}, err
+	}
+
+	sd := slackbot.ApprovalReceivedSlackData{
+		BaseURL:       cfg.BaseURL,
+		Approver:      approver,
+		ApprovalCount: len(docObj.GetReviewedBy()),
+		DocumentType:  docObj.GetDocType(),
+		DocumentTitle: docObj.GetTitle(),
+		DocumentURL:   docURL,
+		ReviewerCount: len(docObj.GetReviewers()),
+		FullyApproved: fullyApproved,
+	}
+	return notify.Message{
+		To:       docObj.GetOwners(),
+		Event:    models.ApprovalReceivedNotificationEvent,
+		Subject:  e.Subject,
+		HTMLBody: e.Body,
+		SlackBlocks: func(username string) ([]slack.Block, error) {
+			msg, err := slackbot.GenerateUIRichBlocks_ApprovalReceived(
+				sd, username)
+			if err != nil {
+				return nil, err
+			}
+			return msg.Blocks.BlockSet, nil
+		},
+	}, nil
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/internal/config"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReviewRequestedMessage(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := &config.Config{
+		BaseURL: "https://docvault.example.com",
+		Slack:   &config.Slack{SigningSecret: "secret"},
+	}
+	docObj := &hcd.COMMONTEMPLATE{
+		BaseDoc: hcd.BaseDoc{
+			ObjectID:  "docID",
+			DocNumber: "PAYMT-042",
+			DocType:   "RFC",
+			Owners:    []string{"owner@example.com"},
+			Product:   "Payments",
+			Title:     "Payment Retries",
+		},
+	}
+
+	msg, err := newReviewRequestedMessage(cfg, docObj,
+		"https://docvault.example.com/document/docID", "Owner Name",
+		[]string{"a@example.com", "b@example.com"})
+	require.NoError(err)
+
+	rec := notify.NewRecorder()
+	n, err := notify.NewNotifier(
+		notify.WithChannel(models.EmailNotificationChannel, rec))
+	require.NoError(err)
+	require.NoError(n.Send(msg))
+	msgs := rec.Messages()
+	require.Len(msgs, 1)
+	assert.Equal([]string{"a@example.com", "b@example.com"}, msgs[0].To)
+	assert.Equal(models.ReviewRequestedNotificationEvent, msgs[0].Event)
+	assert.Equal("Payment Retries | Document Review Request from "+
+		"Owner Name [owner@example.com]", msgs[0].Subject)
+	assert.Contains(msgs[0].HTMLBody,
+		"https://docvault.example.com/document/docID")
+
+	// Interactive review requests have review action buttons, but can't be
+	// snoozed unless Slack reminders are enabled.
+	actionIDs := func(msg notify.Message) []string {
+		require.NotNil(msg.SlackBlocks)
+		blocks, err := msg.SlackBlocks("reviewer")
+		require.NoError(err)
+		var ids []string
+		for _, b := range blocks {
+			if ab, ok := b.(*slack.ActionBlock); ok &&
+				ab.BlockID == slackbot.ReviewActionsBlockID {
+				for _, e := range ab.Elements.ElementSet {
+					ids = append(ids, e.(*slack.ButtonBlockElement).ActionID)
+				}
+			}
+		}
+		return ids
+	}
+	assert.Equal([]string{
+		slackbot.ApproveActionID,
+		slackbot.RequestChangesActionID,
+	}, actionIDs(msgs[0]))
+
+	cfg.Reminders = &config.Reminders{Enabled: true, Slack: true}
+	msg, err = newReviewRequestedMessage(cfg, docObj,
+		"https://docvault.example.com/document/docID", "Owner Name",
+		[]string{"a@example.com"})
+	require.NoError(err)
+	assert.Equal([]string{
+		slackbot.ApproveActionID,
+		slackbot.RequestChangesActionID,
+		slackbot.SnoozeActionID,
+	}, actionIDs(msg))
+}
+
+func TestNewContributorRequestedMessage(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := &config.Config{
+		BaseURL: "https://docvault.example.com",
+		Slack:   &config.Slack{},
+	}
+	docObj := &hcd.COMMONTEMPLATE{
+		BaseDoc: hcd.BaseDoc{
+			ObjectID: "docID",
+			DocType:  "RFC",
+			Owners:   []string{"owner@example.com"},
+			Title:    "Payment Retries",
+		},
+	}
+
+	msg, err := newContributorRequestedMessage(cfg, docObj,
+		"https://docvault.example.com/document/docID?draft=true", "Owner Name",
+		[]string{"c@example.com"})
+	require.NoError(err)
+	assert.Equal([]string{"c@example.com"}, msg.To)
+	assert.Equal(models.ContributorInvitedNotificationEvent, msg.Event)
+	assert.Equal("Payment Retries | Document Contribution Request from "+
+		"Owner Name [owner@example.com]", msg.Subject)
+	assert.Contains(msg.HTMLBody, "?draft=true")
+
+	require.NotNil(msg.SlackBlocks)
+	blocks, err := msg.SlackBlocks("contributor")
+	require.NoError(err)
+	assert.NotEmpty(blocks)
+
+	// Messages without an owner name can't be rendered.
+	_, err = newContributorRequestedMessage(cfg, docObj,
+		"https://docvault.example.com/document/docID?draft=true", "",
+		[]string{"c@example.com"})
+	assert.Error(err)
+}
+
+func TestNewApprovalReceivedMessage(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := &config.Config{
+		BaseURL: "https://docvault.example.com",
+		Slack:   &config.Slack{},
+	}
+	docObj := &hcd.COMMONTEMPLATE{
+		BaseDoc: hcd.BaseDoc{
+			ObjectID:   "docID",
+			DocNumber:  "PAYMT-042",
+			DocType:    "RFC",
+			Owners:     []string{"owner@example.com"},
+			Reviewers:  []string{"a@example.com", "b@example.com"},
+			ReviewedBy: []string{"a@example.com"},
+			Title:      "Payment Retries",
+		},
+	}
+
+	msg, err := newApprovalReceivedMessage(cfg, docObj,
+		"https://docvault.example.com/document/docID", "a@example.com", false)
+	require.NoError(err)
+	assert.Equal([]string{"owner@example.com"}, msg.To)
+	assert.Equal(models.ApprovalReceivedNotificationEvent, msg.Event)
+	assert.Equal("Payment Retries | Document Approved by a@example.com",
+		msg.Subject)
+	assert.Contains(msg.HTMLBody, "(1/2 approvals)")
+	require.NotNil(msg.SlackBlocks)
+	blocks, err := msg.SlackBlocks("owner")
+	require.NoError(err)
+	assert.NotEmpty(blocks)
+
+	// Fully approved documents have a different subject.
+	msg, err = newApprovalReceivedMessage(cfg, docObj,
+		"https://docvault.example.com/document/docID", "a@example.com", true)
+	require.NoError(err)
+	assert.Equal("Payment Retries | Document Approved", msg.Subject)
+}
+
+func TestNewNotificationPreferencesResponse(t *testing.T) {
+	// Users without preferences are notified of all events through all
+	// channels, without quiet hours.
+	resp := newNotificationPreferencesResponse(models.NotificationPreference{})
+	assert.Len(t, resp.Channels, len(models.NotificationEventTypes))
+	for _, chs := range resp.Channels {
+		assert.Equal(t, models.NotificationChannels, chs)
+	}
+	assert.Nil(t, resp.QuietHours)
+
+	p := models.NotificationPreference{
+		QuietHoursStart: "22:00",
+		QuietHoursEnd:   "07:00",
+	}
+	p.Channels.Data = map[models.NotificationEventType][]models.NotificationChannel{
+		models.ReviewRequestedNotificationEvent: {models.SlackNotificationChannel},
+		models.DueSoonNotificationEvent:         nil,
+	}
+	resp = newNotificationPreferencesResponse(p)
+	assert.Equal(t,
+		[]models.NotificationChannel{models.SlackNotificationChannel},
+		resp.Channels[models.ReviewRequestedNotificationEvent])
+	assert.Equal(t, []models.NotificationChannel{},
+		resp.Channels[models.DueSoonNotificationEvent])
+	assert.Equal(t, models.NotificationChannels,
+		resp.Channels[models.ApprovalReceivedNotificationEvent])
+	assert.Equal(t,
+		&QuietHours{Start: "22:00", End: "07:00", TimeZone: "UTC"},
+		resp.QuietHours)
+}
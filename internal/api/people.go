@@ -27,6 +27,14 @@ func PeopleDataHandler(
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		req := &PeopleDataRequest{}
+
+		// Without Google Workspace there is no directory to search, so people are
+		// only identified by their email addresses.
+		if s == nil {
+			servePeopleWithoutDirectory(w, r, log)
+			return
+		}
+
 		switch r.Method {
 		// Using POST method to avoid logging the query in browser history
 		// and server logs
@@ -107,3 +115,39 @@ func PeopleDataHandler(
 		}
 	})
 }
+
+// servePeopleWithoutDirectory serves people requests when Google Workspace
+// isn't configured. Searches return no results and lookups return people with
+// only their email address.
+func servePeopleWithoutDirectory(
+	w http.ResponseWriter, r *http.Request, log hclog.Logger,
+) {
+	ppl := []*people.Person{}
+	switch r.Method {
+	case "POST":
+	case "GET":
+		query := r.URL.Query()
+		if len(query["emails"]) != 1 {
+			log.Error("attempted to get users without providing any email addresses")
+			http.Error(w, "Attempted to get users without providing a single value for the emails query parameter.", http.StatusBadRequest)
+			return
+		}
+		for _, email := range strings.Split(query["emails"][0], ",") {
+			ppl = append(ppl, &people.Person{
+				EmailAddresses: []*people.EmailAddress{{Value: email}},
+			})
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(ppl); err != nil {
+		log.Error("error encoding people response", "error", err)
+		http.Error(w, "Error getting people responses",
+			http.StatusInternalServerError)
+		return
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
 	"github.com/hashicorp-forge/hermes/pkg/models"
 	"github.com/hashicorp/go-hclog"
 )
@@ -18,12 +17,17 @@ type ProductRequest struct {
 }
 
 // ProductsHandler returns the product mappings to the Hermes frontend.
-func ProductsHandler(cfg *config.Config, ar *algolia.Client,
-	aw *algolia.Client, db *gorm.DB, log hclog.Logger) http.Handler {
+func ProductsHandler(
+	cfg *config.Config, db *gorm.DB, log hclog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		switch r.Method {
 		case "POST":
+			// Only global admins can create products.
+			if !authorizeAdmin(w, r, log, db) {
+				return
+			}
+
 			// Decode request.
 			var req ProductRequest
 			if err := decodeRequest(r, &req); err != nil {
@@ -34,7 +38,7 @@ func ProductsHandler(cfg *config.Config, ar *algolia.Client,
 			}
 
 			// Add the data to both algolia and the Postgres Database
-			err := AddNewProducts(ar, aw, db, req)
+			err := AddNewProducts(db, req)
 			if err != nil {
 				log.Error("error inserting new product/Business Unit", "error", err)
 				http.Error(w, "Error inserting products",
@@ -111,8 +115,7 @@ func getProductsData(db *gorm.DB) (map[string]struct {
 }
 
 // AddNewProducts This helper fuction add the newly added product upserted in the postgres Database
-func AddNewProducts(ar *algolia.Client,
-	aw *algolia.Client, db *gorm.DB, req ProductRequest) error {
+func AddNewProducts(db *gorm.DB, req ProductRequest) error {
 
 	// Step 1: upsert in the db
 	pm := models.Product{
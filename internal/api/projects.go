@@ -6,7 +6,6 @@ import (
 	"net/http"
 
 	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
 	"github.com/hashicorp-forge/hermes/pkg/models"
 	"github.com/hashicorp/go-hclog"
 	"gorm.io/gorm"
@@ -18,8 +17,8 @@ type ProjectRequest struct {
 }
 
 // ProjectsHandler handles the post req to add new projects from the Hermes frontend.
-func ProjectsHandler(cfg *config.Config, ar *algolia.Client,
-	aw *algolia.Client, db *gorm.DB, log hclog.Logger) http.Handler {
+func ProjectsHandler(
+	cfg *config.Config, db *gorm.DB, log hclog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		// ONly allow the post requests
@@ -37,8 +36,14 @@ func ProjectsHandler(cfg *config.Config, ar *algolia.Client,
 			return
 		}
 
+		// Only admins of the project's team can create projects.
+		if !authorizeAdminFor(
+			w, r, log, db, models.TeamRoleScope, req.TeamName) {
+			return
+		}
+
 		// Add the data to the Postgres Database
-		err := CreateNewProject(ar, aw, db, req)
+		err := CreateNewProject(db, req)
 		if err != nil {
 			log.Error("error inserting new Projects", "error", err)
 			http.Error(w, "Error inserting projects",
@@ -69,8 +74,7 @@ func ProjectsHandler(cfg *config.Config, ar *algolia.Client,
 
 // Function to add new projects inside pre-existing team in the
 // Database
-func CreateNewProject(ar *algolia.Client,
-	aw *algolia.Client, db *gorm.DB, req ProjectRequest) error {
+func CreateNewProject(db *gorm.DB, req ProjectRequest) error {
 	// Find the team with the given name in the database.
 	team := &models.Team{}
 	if err := db.Where("name = ?", req.TeamName).First(team).Error; err != nil {
@@ -10,14 +10,14 @@ import (
 	"time"
 
 	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/internal/email"
-	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	"github.com/hashicorp-forge/hermes/internal/notify"
 
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
 	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
 	"github.com/hashicorp-forge/hermes/pkg/links"
 	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-multierror"
 	"google.golang.org/api/drive/v3"
@@ -27,10 +27,11 @@ import (
 func ReviewHandler(
 	cfg *config.Config,
 	l hclog.Logger,
-	ar *algolia.Client,
-	aw *algolia.Client,
+	sp search.Provider,
+	st storage.Provider,
 	s *gw.Service,
 	db *gorm.DB,
+	n notify.Channel,
 ) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -49,7 +50,7 @@ func ReviewHandler(
 			}
 
 			// Check if document is locked.
-			locked, err := hcd.IsLocked(docID, db, s, l)
+			locked, err := hcd.IsLocked(docID, db, st, l)
 			if err != nil {
 				l.Error("error checking document locked status",
 					"error", err,
@@ -66,11 +67,11 @@ func ReviewHandler(
 				return
 			}
 
-			// Get base document object from Algolia so we can determine the doc type.
+			// Get base document object from search index so we can determine the doc type.
 			baseDocObj := &hcd.BaseDoc{}
-			err = ar.Drafts.GetObject(docID, &baseDocObj)
+			err = sp.Drafts().GetObject(docID, &baseDocObj)
 			if err != nil {
-				l.Error("error requesting base document object from Algolia",
+				l.Error("error requesting base document object from search index",
 					"error", err,
 					"path", r.URL.Path,
 					"method", r.Method,
@@ -95,10 +96,10 @@ func ReviewHandler(
 				return
 			}
 
-			// Get document object from Algolia.
-			err = ar.Drafts.GetObject(docID, &docObj)
+			// Get document object from search index.
+			err = sp.Drafts().GetObject(docID, &docObj)
 			if err != nil {
-				l.Error("error getting document from Algolia",
+				l.Error("error getting document from search index",
 					"error", err,
 					"doc_id", docID,
 					"method", r.Method,
@@ -114,6 +115,20 @@ func ReviewHandler(
 				"path", r.URL.Path,
 			)
 
+			// Save the draft state for the audit log.
+			before, err := auditSnapshot(docObj)
+			if err != nil {
+				l.Error("error encoding document object for audit log",
+					"error", err,
+					"doc_id", docID,
+					"method", r.Method,
+					"path", r.URL.Path,
+				)
+				http.Error(w, "Error creating review",
+					http.StatusInternalServerError)
+				return
+			}
+
 			// Get product from database so we can get the product abbreviation.
 			product := models.Product{
 				Name: docObj.GetProduct(),
@@ -135,7 +150,7 @@ func ReviewHandler(
 
 			// Replace the doc header.
 			err = docObj.ReplaceHeader(
-				docID, cfg.BaseURL, true, s)
+				docID, cfg.BaseURL, true, st)
 			if err != nil {
 				l.Error("error replacing doc header",
 					"error", err, "doc_id", docID)
@@ -143,7 +158,7 @@ func ReviewHandler(
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, "", nil, cfg, aw, s,
+					docObj, "", nil, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -161,7 +176,7 @@ func ReviewHandler(
 			)
 
 			// Get file from Google Drive so we can get the latest modified time.
-			file, err := s.GetFile(docID)
+			file, err := st.GetFile(docID)
 			if err != nil {
 				l.Error("error getting document file from Google",
 					"error", err,
@@ -188,7 +203,7 @@ func ReviewHandler(
 			docObj.SetModifiedTime(modifiedTime.Unix())
 
 			// Get latest Google Drive file revision.
-			latestRev, err := s.GetLatestRevision(docID)
+			latestRev, err := st.GetLatestRevision(docID)
 			if err != nil {
 				l.Error("error getting latest revision",
 					"error", err,
@@ -199,7 +214,7 @@ func ReviewHandler(
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, "", nil, cfg, aw, s,
+					docObj, "", nil, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -211,7 +226,7 @@ func ReviewHandler(
 			}
 
 			// Mark latest revision to be kept forever.
-			_, err = s.KeepRevisionForever(docID, latestRev.Id)
+			_, err = st.KeepRevisionForever(docID, latestRev.Id)
 			if err != nil {
 				l.Error("error marking revision to keep forever",
 					"error", err,
@@ -223,7 +238,7 @@ func ReviewHandler(
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, "", nil, cfg, aw, s,
+					docObj, "", nil, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -240,57 +255,32 @@ func ReviewHandler(
 				"path", r.URL.Path,
 			)
 
-			// Record file revision in the Algolia document object.
+			// Record file revision in the search document object.
 			revisionName := "Requested review"
 			docObj.SetFileRevision(latestRev.Id, revisionName)
 
-			// Move document object to docs index in Algolia.
-			saveRes, err := aw.Docs.SaveObject(docObj)
+			// Move document object to docs index.
+			err = sp.Docs().SaveObject(docObj)
 			if err != nil {
-				l.Error("error saving doc in Algolia", "error", err, "doc_id", docID)
+				l.Error("error saving doc in search index", "error", err, "doc_id", docID)
 				http.Error(w, "Error creating review",
 					http.StatusInternalServerError)
 				return
 			}
-			err = saveRes.Wait()
-			if err != nil {
-				l.Error("error saving doc in Algolia", "error", err, "doc_id", docID)
-				http.Error(w, "Error creating review",
-					http.StatusInternalServerError)
-				return
-			}
-			l.Info("doc saved in Algolia",
+			l.Info("doc saved in search index",
 				"doc_id", docID,
 				"method", r.Method,
 				"path", r.URL.Path,
 			)
-			delRes, err := aw.Drafts.DeleteObject(docID)
-			if err != nil {
-				l.Error("error deleting draft in Algolia",
-					"error", err, "doc_id", docID)
-				http.Error(w, "Error creating review",
-					http.StatusInternalServerError)
-
-				if err := revertReviewCreation(
-					docObj, latestRev.Id, nil, cfg, aw, s,
-				); err != nil {
-					l.Error("error reverting review creation",
-						"error", err,
-						"doc_id", docID,
-						"method", r.Method,
-						"path", r.URL.Path)
-				}
-				return
-			}
-			err = delRes.Wait()
+			err = sp.Drafts().DeleteObject(docID)
 			if err != nil {
-				l.Error("error deleting draft in Algolia",
+				l.Error("error deleting draft in search index",
 					"error", err, "doc_id", docID)
 				http.Error(w, "Error creating review",
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, latestRev.Id, nil, cfg, aw, s,
+					docObj, latestRev.Id, nil, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -302,7 +292,7 @@ func ReviewHandler(
 			}
 
 			// Move document to published docs location in Google Drive.
-			if _, err := s.MoveFile(
+			if _, err := st.MoveFile(
 				docID, cfg.GoogleWorkspace.DocsFolder); err != nil {
 				l.Error("error moving file",
 					"error", err,
@@ -313,7 +303,7 @@ func ReviewHandler(
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, latestRev.Id, nil, cfg, aw, s,
+					docObj, latestRev.Id, nil, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -330,7 +320,7 @@ func ReviewHandler(
 			)
 
 			// Create shortcut in hierarchical folder structure.
-			shortcut, err := createShortcut(cfg, docObj, s)
+			shortcut, err := createShortcut(cfg, docObj, st)
 			if err != nil {
 				l.Error("error creating shortcut",
 					"error", err,
@@ -341,7 +331,7 @@ func ReviewHandler(
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, latestRev.Id, shortcut, cfg, aw, s,
+					docObj, latestRev.Id, shortcut, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -359,7 +349,7 @@ func ReviewHandler(
 
 			// Create go-link.
 			if err := links.SaveDocumentRedirectDetails(
-				aw, docID, docObj.GetDocType(), docObj.GetDocNumber()); err != nil {
+				sp, docID, docObj.GetDocType(), docObj.GetDocNumber()); err != nil {
 				l.Error("error saving redirect details",
 					"error", err,
 					"doc_id", docID,
@@ -369,7 +359,7 @@ func ReviewHandler(
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, latestRev.Id, shortcut, cfg, aw, s,
+					docObj, latestRev.Id, shortcut, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -399,7 +389,7 @@ func ReviewHandler(
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, latestRev.Id, shortcut, cfg, aw, s,
+					docObj, latestRev.Id, shortcut, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -410,7 +400,19 @@ func ReviewHandler(
 				return
 			}
 			d.Status = models.InReviewDocumentStatus
-			if err := d.Upsert(db); err != nil {
+			d.DueDate = docObj.GetDueDate()
+			ae, err := newAuditEvent(
+				r, models.ReviewStartedAuditAction, docID, "", before, docObj)
+			if err == nil {
+				err = withAuditEvent(db, ae, func(tx *gorm.DB) error {
+					if err := d.Upsert(tx); err != nil {
+						return err
+					}
+					return enqueueWebhookEvent(tx, r, cfg.BaseURL,
+						models.DocumentPublishedWebhookEvent, docObj)
+				})
+			}
+			if err != nil {
 				l.Error("error upserting document in database",
 					"error", err,
 					"doc_id", docID,
@@ -420,7 +422,7 @@ func ReviewHandler(
 					http.StatusInternalServerError)
 
 				if err := revertReviewCreation(
-					docObj, latestRev.Id, shortcut, cfg, aw, s,
+					docObj, latestRev.Id, shortcut, cfg, sp, st,
 				); err != nil {
 					l.Error("error reverting review creation",
 						"error", err,
@@ -431,192 +433,65 @@ func ReviewHandler(
 				return
 			}
 
-			// Send emails, if enabled.
-			if cfg.Email != nil && cfg.Email.Enabled {
-				docURL, err := getDocumentURL(cfg.BaseURL, docID)
+			// Send notifications.
+			docURL, err := getDocumentURL(cfg.BaseURL, docID)
+			if err != nil {
+				l.Error("error getting document URL",
+					"error", err,
+					"doc_id", docID,
+					"method", r.Method,
+					"path", r.URL.Path,
+				)
+				http.Error(w, "Error creating review",
+					http.StatusInternalServerError)
+				return
+			}
+
+			// Get owner name.
+			ownerName, err := getPersonDisplayName(s, docObj.GetOwners()[0])
+			if err != nil {
+				l.Error("error getting owner name",
+					"error", err,
+					"doc_id", docID,
+					"method", r.Method,
+					"path", r.URL.Path,
+				)
+				return
+			}
+
+			// Notify reviewers by email and Slack. Failures are logged instead of
+			// failing the request because the review has already been created.
+			if len(docObj.GetReviewers()) > 0 {
+				msg, err := newReviewRequestedMessage(cfg, docObj, docURL,
+					ownerName, docObj.GetReviewers())
+				if err == nil {
+					err = n.Send(msg)
+				}
 				if err != nil {
-					l.Error("error getting document URL",
+					l.Error("error notifying reviewers",
 						"error", err,
 						"doc_id", docID,
 						"method", r.Method,
 						"path", r.URL.Path,
 					)
-					http.Error(w, "Error creating review",
-						http.StatusInternalServerError)
-					return
-				}
-
-				// Get owner name
-				// Fetch owner name by searching Google Workspace directory.
-				// The api has a bug please kindly see this before proceeding forward
-				ppls, err := s.SearchPeople(docObj.GetOwners()[0], "emailAddresses,names")
-				if err != nil {
-					l.Error(
-						"Error getting user information",
-						"error searching people directory",
-						err,
-					)
-					return
-				}
-
-				// Verify that the result only contains one person.
-				if len(ppls) != 1 {
-					l.Error(
-						"Error getting user information",
-						fmt.Sprintf(
-							"wrong number of people in search result: %d", len(ppls)),
-						err,
-					)
-					return
-				}
-				ppl := ppls[0]
-
-				// Replace the names in the People API result with data from the Admin
-				// Directory API.
-				// TODO: remove this when the bug in the People API is fixed:
-				// https://issuetracker.google.com/issues/196235775
-				if err := replaceNamesWithAdminAPIResponse(
-					ppl, s,
-				); err != nil {
-					l.Error(
-						"Error getting user information",
-						"error replacing names with Admin API response",
-						err,
-					)
-					return
-				}
-
-				// Verify other required values are set.
-				if len(ppl.Names) == 0 {
-					l.Error(
-						"Error getting user information",
-						"no names in result",
-						err,
-					)
-					return
-				}
-
-				// Send emails to reviewers.
-				if len(docObj.GetReviewers()) > 0 {
-					// TODO: use an asynchronous method for sending emails because we
-					// can't currently recover gracefully from a failure here.
-					for _, reviewerEmail := range docObj.GetReviewers() {
-						err := email.SendReviewRequestedEmail(
-							email.ReviewRequestedEmailData{
-								BaseURL:            cfg.BaseURL,
-								DocumentOwner:      ppl.Names[0].DisplayName,
-								DocumentType:       docObj.GetDocType(),
-								DocumentShortName:  docObj.GetDocNumber(),
-								DocumentTitle:      docObj.GetTitle(),
-								DocumentURL:        docURL,
-								DocumentProd:       docObj.GetProduct(),
-								DocumentTeam:       docObj.GetTeam(),
-								DocumentOwnerEmail: docObj.GetOwners()[0],
-							},
-							[]string{reviewerEmail},
-							cfg.Email.FromAddress,
-							s,
-						)
-						if err != nil {
-							l.Error("error sending reviewer email",
-								"error", err,
-								"doc_id", docID,
-								"method", r.Method,
-								"path", r.URL.Path,
-							)
-							http.Error(w, "Error creating review",
-								http.StatusInternalServerError)
-							return
-						}
-						l.Info("doc reviewer email sent",
-							"doc_id", docID,
-							"method", r.Method,
-							"path", r.URL.Path,
-						)
-					}
-
-					// Also send the slack message tagginhg all the reviewers in the
-					// dedicated channel
-					// tagging all reviewers emails
-					emails := make([]string, len(docObj.GetReviewers()))
-					for i, c := range docObj.GetReviewers() {
-						emails[i] = c
-					}
-					err = slackbot.SendSlackMessage_Reviewer(slackbot.ReviewerRequestedSlackData{
-						BaseURL:            cfg.BaseURL,
-						DocumentOwner:      ppl.Names[0].DisplayName,
-						DocumentType:       docObj.GetDocType(),
-						DocumentShortName:  docObj.GetDocNumber(),
-						DocumentTitle:      docObj.GetTitle(),
-						DocumentURL:        docURL,
-						DocumentProd:       docObj.GetProduct(),
-						DocumentTeam:       docObj.GetTeam(),
-						DocumentOwnerEmail: docObj.GetOwners()[0],
-					}, emails,
-					)
-					//handle error gracefully
-					if err != nil {
-						fmt.Printf("Some error occured while sendind the message: %s", err)
-					} else {
-						fmt.Println("Succesfully! Delivered the message to all reviewers")
-					}
-				}
-
-				// Send emails to product subscribers.
-				p := models.Product{
-					Name: docObj.GetProduct(),
-				}
-				if err := p.Get(db); err != nil {
-					l.Error("error getting product from database",
-						"error", err,
+				} else {
+					l.Info("doc reviewers notified",
 						"doc_id", docID,
 						"method", r.Method,
 						"path", r.URL.Path,
 					)
-					http.Error(w, "Error sending subscriber email",
-						http.StatusInternalServerError)
-					return
-				}
-
-				if len(p.UserSubscribers) > 0 {
-					// TODO: use an asynchronous method for sending emails because we
-					// can't currently recover gracefully from a failure here.
-					for _, subscriber := range p.UserSubscribers {
-						err := email.SendSubscriberDocumentPublishedEmail(
-							email.SubscriberDocumentPublishedEmailData{
-								BaseURL:           cfg.BaseURL,
-								DocumentOwner:     docObj.GetOwners()[0],
-								DocumentShortName: docObj.GetDocNumber(),
-								DocumentTitle:     docObj.GetTitle(),
-								DocumentType:      docObj.GetDocType(),
-								DocumentURL:       docURL,
-								Product:           docObj.GetProduct(),
-								Team:              docObj.GetTeam(),
-							},
-							[]string{subscriber.EmailAddress},
-							cfg.Email.FromAddress,
-							s,
-						)
-						if err != nil {
-							l.Error("error sending subscriber email",
-								"error", err,
-								"doc_id", docID,
-								"method", r.Method,
-								"path", r.URL.Path,
-							)
-							http.Error(w, "Error sending subscriber email",
-								http.StatusInternalServerError)
-							return
-						}
-						l.Info("doc subscriber email sent",
-							"doc_id", docID,
-							"method", r.Method,
-							"path", r.URL.Path,
-						)
-					}
 				}
 			}
 
+			// Notify subscribers of publication and start of review.
+			notifySubscribers(cfg, l, db, n, r, docObj,
+				models.PublishedSubscriptionEvent,
+				models.ReviewStartedSubscriptionEvent,
+			)
+
+			// Post the published document to mapped Slack channels.
+			notifySlackChannelsInReview(cfg, l, db, r, docObj, "", true)
+
 			// Write response.
 			w.WriteHeader(http.StatusOK)
 
@@ -639,7 +514,7 @@ func ReviewHandler(
 func createShortcut(
 	cfg *config.Config,
 	docObj hcd.Doc,
-	s *gw.Service) (shortcut *drive.File, retErr error) {
+	s storage.Provider) (shortcut *drive.File, retErr error) {
 
 	// Get folder for  the product/BU
 	productFolder, err := s.GetSubfolder(cfg.GoogleWorkspace.ShortcutsFolder, docObj.GetProduct())
@@ -737,15 +612,15 @@ func revertReviewCreation(
 	fileRevision string,
 	shortcut *drive.File,
 	cfg *config.Config,
-	a *algolia.Client,
-	s *gw.Service) error {
+	sp search.Provider,
+	s storage.Provider) error {
 
 	// Use go-multierror so we can return all cleanup errors.
 	var result error
 
 	// Delete go-link if it exists.
 	if err := links.DeleteDocumentRedirectDetails(
-		a, docObj.GetObjectID(), docObj.GetDocType(), docObj.GetDocNumber(),
+		sp, docObj.GetObjectID(), docObj.GetDocType(), docObj.GetDocNumber(),
 	); err != nil {
 		result = multierror.Append(
 			result, fmt.Errorf("error deleting go-link: %w", err))
@@ -777,31 +652,24 @@ func revertReviewCreation(
 			result, fmt.Errorf("error replacing the doc header: %w", err))
 	}
 
-	// Delete file revision from Algolia document object.
+	// Delete file revision from search document object.
 	if fileRevision != "" {
 		docObj.DeleteFileRevision(fileRevision)
 	}
 
 	// Save doc back in the drafts index and delete it from the docs index.
-	saveRes, err := a.Drafts.SaveObject(docObj)
-	if err != nil {
+	if err := sp.Drafts().SaveObject(docObj); err != nil {
 		result = multierror.Append(
-			result, fmt.Errorf("error saving draft in Algolia: %w", err))
+			result, fmt.Errorf("error saving draft in search index: %w", err))
 	}
-	err = saveRes.Wait()
-	if err != nil {
+	if err := sp.Docs().DeleteObject(docObj.GetObjectID()); err != nil {
 		result = multierror.Append(
-			result, fmt.Errorf("error saving draft in Algolia: %w", err))
+			result, fmt.Errorf("error deleting doc in search index: %w", err))
 	}
-	delRes, err := a.Docs.DeleteObject(docObj.GetObjectID())
-	if err != nil {
-		result = multierror.Append(
-			result, fmt.Errorf("error deleting doc in Algolia: %w", err))
-	}
-	err = delRes.Wait()
-	if err != nil {
+	if err := sp.Sections().DeleteBy(
+		[][]string{{"docID:" + docObj.GetObjectID()}}); err != nil {
 		result = multierror.Append(
-			result, fmt.Errorf("error deleting doc in Algolia: %w", err))
+			result, fmt.Errorf("error deleting doc sections in search index: %w", err))
 	}
 
 	return result
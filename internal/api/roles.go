@@ -0,0 +1,265 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/gorm"
+)
+
+// RoleRequest is a request to grant an admin role to a user.
+type RoleRequest struct {
+	Email   string           `json:"email"`
+	Scope   models.RoleScope `json:"scope"`
+	Product string           `json:"product,omitempty"`
+	Team    string           `json:"team,omitempty"`
+}
+
+// RoleResponse is an admin role granted to a user.
+type RoleResponse struct {
+	ID          uint             `json:"id"`
+	CreatedTime time.Time        `json:"createdTime"`
+	Email       string           `json:"email"`
+	Scope       models.RoleScope `json:"scope"`
+	Product     string           `json:"product,omitempty"`
+	Team        string           `json:"team,omitempty"`
+	GrantedBy   string           `json:"grantedBy,omitempty"`
+}
+
+// RolesHandler handles requests to list admin roles (optionally filtered by the
+// "email" query parameter) and to grant them. Users can list their own roles,
+// and global admins can list all roles. Global and product roles can only be
+// granted by global admins, and team roles by admins of the team's product.
+func RolesHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			email := r.URL.Query().Get("email")
+			userEmail := r.Context().Value("userEmail").(string)
+			if !strings.EqualFold(email, userEmail) &&
+				!authorizeAdmin(w, r, l, db) {
+				return
+			}
+
+			var ras models.RoleAssignments
+			if err := ras.Find(db, email); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting roles",
+					"error finding role assignments", err)
+				return
+			}
+
+			resp := make([]RoleResponse, len(ras))
+			for i, ra := range ras {
+				resp[i] = newRoleResponse(ra)
+			}
+			respondJSON(w, r, l, http.StatusOK, resp)
+
+		case "POST":
+			var req RoleRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding role request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+			if req.Email == "" {
+				http.Error(w, "Bad request: email is required",
+					http.StatusBadRequest)
+				return
+			}
+
+			ra := models.RoleAssignment{
+				User:  models.User{EmailAddress: req.Email},
+				Scope: req.Scope,
+			}
+			if req.Product != "" {
+				ra.Product = &models.Product{Name: req.Product}
+			}
+			if req.Team != "" {
+				ra.Team = &models.Team{Name: req.Team}
+			}
+
+			// Authorize request.
+			if req.Scope == models.TeamRoleScope && ra.Team != nil {
+				if err := ra.Team.Get(db); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						http.Error(w, "Bad request: team not found",
+							http.StatusBadRequest)
+						return
+					}
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error granting role",
+						"error getting team", err,
+						"team", req.Team,
+					)
+					return
+				}
+			}
+			if !authorizeRoleManagement(w, r, l, db, ra) {
+				return
+			}
+
+			ae, err := newAuditEvent(
+				r, models.RoleGrantedAuditAction, "", req.Email, nil, req)
+			if err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error granting role",
+					"error creating audit event", err)
+				return
+			}
+			ra.GrantedBy = ae.Actor
+			if err := withAuditEvent(db, ae, func(tx *gorm.DB) error {
+				return ra.Create(tx)
+			}); err != nil {
+				var verr validation.Errors
+				switch {
+				case errors.Is(err, gorm.ErrRecordNotFound):
+					http.Error(w, "Bad request: product or team not found",
+						http.StatusBadRequest)
+				case errors.As(err, &verr):
+					http.Error(w, fmt.Sprintf("Bad request: %q", verr),
+						http.StatusBadRequest)
+				default:
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error granting role",
+						"error creating role assignment", err,
+						"scope", req.Scope,
+						"user", req.Email,
+					)
+				}
+				return
+			}
+
+			l.Info("granted role",
+				"product", req.Product,
+				"role_id", ra.ID,
+				"scope", ra.Scope,
+				"team", req.Team,
+				"user", req.Email,
+			)
+			respondJSON(w, r, l, http.StatusCreated, newRoleResponse(ra))
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+	})
+}
+
+// RoleHandler handles requests to get and revoke an admin role at
+// "/api/v1/roles/{id}". Users can get their own roles. Otherwise, roles can
+// only be viewed and revoked by admins who could have granted them.
+func RoleHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr, err := parseResourceIDFromURL(r.URL.Path, "roles")
+		if err != nil {
+			http.Error(w, "Resource not found", http.StatusNotFound)
+			return
+		}
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil || id == 0 {
+			http.Error(w, "Bad request: invalid role ID", http.StatusBadRequest)
+			return
+		}
+
+		ra := models.RoleAssignment{ID: uint(id)}
+		if err := ra.Get(db); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				http.Error(w, "Role not found", http.StatusNotFound)
+				return
+			}
+			respondError(w, r, l, http.StatusInternalServerError,
+				"Error getting role",
+				"error getting role assignment", err,
+				"role_id", id,
+			)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			userEmail := r.Context().Value("userEmail").(string)
+			if !strings.EqualFold(ra.User.EmailAddress, userEmail) &&
+				!authorizeRoleManagement(w, r, l, db, ra) {
+				return
+			}
+			respondJSON(w, r, l, http.StatusOK, newRoleResponse(ra))
+
+		case "DELETE":
+			if !authorizeRoleManagement(w, r, l, db, ra) {
+				return
+			}
+
+			ae, err := newAuditEvent(r, models.RoleRevokedAuditAction,
+				"", ra.User.EmailAddress, newRoleResponse(ra), nil)
+			if err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error revoking role",
+					"error creating audit event", err)
+				return
+			}
+			if err := withAuditEvent(db, ae, func(tx *gorm.DB) error {
+				return ra.Delete(tx)
+			}); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error revoking role",
+					"error deleting role assignment", err,
+					"role_id", id,
+				)
+				return
+			}
+
+			l.Info("revoked role",
+				"role_id", ra.ID,
+				"scope", ra.Scope,
+				"user", ra.User.EmailAddress,
+			)
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+	})
+}
+
+// authorizeRoleManagement returns true if the user making the request can grant
+// or revoke role assignment ra, which requires being an admin of a higher scope.
+// For team roles, ra.Team must include the team's product. Otherwise, it
+// responds with an error and returns false.
+func authorizeRoleManagement(
+	w http.ResponseWriter, r *http.Request, l hclog.Logger, db *gorm.DB,
+	ra models.RoleAssignment,
+) bool {
+	if ra.Scope == models.TeamRoleScope && ra.Team != nil {
+		return authorizeAdminFor(
+			w, r, l, db, models.ProductRoleScope, ra.Team.BU.Name)
+	}
+	return authorizeAdmin(w, r, l, db)
+}
+
+// newRoleResponse returns the API response for role assignment ra.
+func newRoleResponse(ra models.RoleAssignment) RoleResponse {
+	resp := RoleResponse{
+		ID:          ra.ID,
+		CreatedTime: ra.CreatedAt,
+		Email:       ra.User.EmailAddress,
+		Scope:       ra.Scope,
+		GrantedBy:   ra.GrantedBy,
+	}
+	if ra.Product != nil {
+		resp.Product = ra.Product.Name
+	}
+	if ra.Team != nil {
+		resp.Team = ra.Team.Name
+	}
+	return resp
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultSearchHitsPerPage is the default number of search results per page.
+	defaultSearchHitsPerPage = 20
+
+	// maxSearchHitsPerPage is the maximum number of search results per page.
+	maxSearchHitsPerPage = 100
+)
+
+// searchFilterParams are the query parameters that filter search results,
+// mapped to the search index attributes they filter on.
+var searchFilterParams = map[string]string{
+	"docType": "docType",
+	"owner":   "owners",
+	"product": "product",
+	"project": "project",
+	"status":  "status",
+	"team":    "team",
+}
+
+// searchFacets are the attributes that search result facet counts can be
+// requested for.
+var searchFacets = []string{
+	"docType",
+	"owners",
+	"product",
+	"project",
+	"status",
+	"team",
+}
+
+// searchSortOrders are the attributes that search results can be sorted by, and
+// the orders that each attribute supports.
+var searchSortOrders = map[string][]string{
+	"createdTime":  {"asc", "desc"},
+	"modifiedTime": {"desc"},
+}
+
+// searchSectionAttributes are the attributes of objects in the sections index
+// that can be filtered, faceted, or sorted on. Section objects only copy some
+// attributes from their document.
+var searchSectionAttributes = []string{
+	"docType",
+	"modifiedTime",
+	"owners",
+	"product",
+	"status",
+}
+
+// searchSectionDocAttributes are the document attributes copied from section
+// hits to the document hits that group them.
+var searchSectionDocAttributes = []string{
+	"docID",
+	"docNumber",
+	"docType",
+	"modifiedTime",
+	"owners",
+	"product",
+	"status",
+	"title",
+}
+
+// searchRequest is a parsed search API request.
+type searchRequest struct {
+	// Drafts searches document drafts instead of published documents.
+	Drafts bool
+
+	// Sections searches sections of published document content instead of
+	// published documents.
+	Sections bool
+
+	// Query is the full-text query.
+	Query string
+
+	// Filters are attribute values to filter results by. Values for the same
+	// attribute are combined with OR, and attributes are combined with AND.
+	Filters map[string][]string
+
+	// Facets are the attributes to return value counts for.
+	Facets []string
+
+	// SortBy is the attribute to sort results by, or empty to sort by
+	// relevance.
+	SortBy string
+
+	// SortAsc sorts results in ascending order.
+	SortAsc bool
+
+	// Page is the zero-based page of results.
+	Page int
+
+	// HitsPerPage is the number of results per page.
+	HitsPerPage int
+}
+
+// SearchHandler searches published documents, drafts, or sections of
+// published document content. Visibility rules are enforced server-side:
+// published documents and their sections are visible to all users, while
+// drafts are only visible to their owners and contributors (or admins).
+// Section hits are grouped by the document that they belong to.
+func SearchHandler(l hclog.Logger, sp search.Provider, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		req, err := parseSearchRequest(r.URL.Query())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Bad request: %v", err),
+				http.StatusBadRequest)
+			return
+		}
+
+		// Only admins can search drafts that they don't own or contribute to.
+		userEmail := r.Context().Value("userEmail").(string)
+		var isAdmin bool
+		if req.Drafts {
+			u := models.User{EmailAddress: userEmail}
+			isAdmin, err = u.IsUserAdmin(db)
+			if err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error searching documents",
+					"error checking if user is an admin", err,
+				)
+				return
+			}
+		}
+
+		idx := sp.Docs()
+		switch {
+		case req.Drafts:
+			idx = sp.Drafts()
+		case req.Sections:
+			// Sections are only indexed for published documents, so they have
+			// the same visibility as published documents.
+			idx = sp.Sections()
+		}
+		resp, err := idx.Search(req.searchQuery(userEmail, isAdmin))
+		if err != nil {
+			respondError(w, r, l, http.StatusInternalServerError,
+				"Error searching documents",
+				"error searching index", err,
+				"drafts", req.Drafts,
+				"sections", req.Sections,
+			)
+			return
+		}
+		if req.Sections {
+			resp.Hits = groupSectionHits(resp.Hits)
+		}
+
+		respondJSON(w, r, l, http.StatusOK, resp)
+	})
+}
+
+// parseSearchRequest parses the query parameters of a search API request.
+func parseSearchRequest(q url.Values) (searchRequest, error) {
+	req := searchRequest{
+		Query:       q.Get("q"),
+		Filters:     make(map[string][]string),
+		HitsPerPage: defaultSearchHitsPerPage,
+	}
+
+	switch idx := q.Get("index"); idx {
+	case "", "docs":
+	case "drafts":
+		req.Drafts = true
+	case "sections":
+		req.Sections = true
+	default:
+		return searchRequest{}, fmt.Errorf("invalid index %q", idx)
+	}
+
+	for param, attr := range searchFilterParams {
+		for _, v := range splitParam(q[param]) {
+			if !req.supportsAttribute(attr) {
+				return searchRequest{}, fmt.Errorf(
+					"invalid filter %q for index %q", param, q.Get("index"))
+			}
+			req.Filters[attr] = append(req.Filters[attr], v)
+		}
+	}
+
+	for _, f := range splitParam(q["facets"]) {
+		if !contains(searchFacets, f) || !req.supportsAttribute(f) {
+			return searchRequest{}, fmt.Errorf("invalid facet %q", f)
+		}
+		req.Facets = append(req.Facets, f)
+	}
+
+	order := q.Get("order")
+	if order == "" {
+		order = "desc"
+	}
+	if sortBy := q.Get("sortBy"); sortBy != "" {
+		orders, ok := searchSortOrders[sortBy]
+		if !ok || !req.supportsAttribute(sortBy) {
+			return searchRequest{}, fmt.Errorf("invalid sortBy %q", sortBy)
+		}
+		if !contains(orders, order) {
+			return searchRequest{}, fmt.Errorf(
+				"invalid order %q for sortBy %q", order, sortBy)
+		}
+		req.SortBy = sortBy
+		req.SortAsc = order == "asc"
+	} else if order != "desc" && order != "asc" {
+		return searchRequest{}, fmt.Errorf("invalid order %q", order)
+	}
+
+	if s := q.Get("page"); s != "" {
+		page, err := strconv.Atoi(s)
+		if err != nil || page < 0 {
+			return searchRequest{}, fmt.Errorf("invalid page %q", s)
+		}
+		req.Page = page
+	}
+	if s := q.Get("hitsPerPage"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxSearchHitsPerPage {
+			return searchRequest{}, fmt.Errorf(
+				"hitsPerPage must be between 1 and %d", maxSearchHitsPerPage)
+		}
+		req.HitsPerPage = n
+	}
+
+	return req, nil
+}
+
+// supportsAttribute returns true if the index searched by the request supports
+// filtering, faceting, and sorting on attribute attr.
+func (req searchRequest) supportsAttribute(attr string) bool {
+	return !req.Sections || contains(searchSectionAttributes, attr)
+}
+
+// searchQuery returns the search query for the request made by user
+// userEmail. Drafts are restricted to those the user owns or contributes to,
+// unless the user is an admin.
+func (req searchRequest) searchQuery(userEmail string, isAdmin bool) search.Query {
+	query := search.Query{
+		Text:        req.Query,
+		Facets:      req.Facets,
+		HitsPerPage: req.HitsPerPage,
+		Page:        req.Page,
+		SortBy:      req.SortBy,
+		SortDesc:    req.SortBy != "" && !req.SortAsc,
+	}
+
+	// Add filters in a stable order.
+	for _, attr := range searchFacets {
+		var group []string
+		for _, v := range req.Filters[attr] {
+			group = append(group, attr+":"+v)
+		}
+		if len(group) > 0 {
+			query.FacetFilters = append(query.FacetFilters, group)
+		}
+	}
+
+	if req.Drafts && !isAdmin {
+		query.FacetFilters = append(query.FacetFilters, []string{
+			"owners:" + userEmail,
+			"contributors:" + userEmail,
+		})
+	}
+
+	return query
+}
+
+// groupSectionHits groups section hits by the document that they belong to, in
+// order of each document's first hit. Each group is a hit with the document's
+// attributes and its matching section hits in "sections". Pagination and hit
+// counts still apply to section hits.
+func groupSectionHits(hits []map[string]interface{}) []map[string]interface{} {
+	groups := []map[string]interface{}{}
+	byDocID := make(map[string]map[string]interface{})
+	for _, h := range hits {
+		docID, _ := h["docID"].(string)
+		g, ok := byDocID[docID]
+		if !ok {
+			g = map[string]interface{}{
+				"objectID": docID,
+				"sections": []map[string]interface{}{},
+			}
+			for _, attr := range searchSectionDocAttributes {
+				if v, ok := h[attr]; ok {
+					g[attr] = v
+				}
+			}
+			byDocID[docID] = g
+			groups = append(groups, g)
+		}
+		g["sections"] = append(g["sections"].([]map[string]interface{}), h)
+	}
+	return groups
+}
+
+// splitParam returns the non-empty values of a query parameter that may be
+// repeated or contain comma-separated values.
+func splitParam(vals []string) []string {
+	var res []string
+	for _, v := range vals {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchRequest(t *testing.T) {
+	cases := map[string]struct {
+		query string
+
+		want      searchRequest
+		shouldErr bool
+	}{
+		"defaults": {
+			query: "",
+
+			want: searchRequest{
+				Filters:     map[string][]string{},
+				HitsPerPage: defaultSearchHitsPerPage,
+			},
+		},
+		"all parameters": {
+			query: "q=cache&index=drafts&product=Payments,Banking&owner=a@x.com" +
+				"&status=Draft&facets=product,status&sortBy=createdTime&order=asc" +
+				"&page=2&hitsPerPage=50",
+
+			want: searchRequest{
+				Drafts: true,
+				Query:  "cache",
+				Filters: map[string][]string{
+					"owners":  {"a@x.com"},
+					"product": {"Payments", "Banking"},
+					"status":  {"Draft"},
+				},
+				Facets:      []string{"product", "status"},
+				SortBy:      "createdTime",
+				SortAsc:     true,
+				Page:        2,
+				HitsPerPage: 50,
+			},
+		},
+		"repeated filter": {
+			query: "team=a&team=b",
+
+			want: searchRequest{
+				Filters:     map[string][]string{"team": {"a", "b"}},
+				HitsPerPage: defaultSearchHitsPerPage,
+			},
+		},
+		"sections": {
+			query: "q=retries&index=sections&product=Payments&facets=docType" +
+				"&sortBy=modifiedTime",
+
+			want: searchRequest{
+				Sections:    true,
+				Query:       "retries",
+				Filters:     map[string][]string{"product": {"Payments"}},
+				Facets:      []string{"docType"},
+				SortBy:      "modifiedTime",
+				HitsPerPage: defaultSearchHitsPerPage,
+			},
+		},
+		"unsupported sections filter": {
+			query:     "index=sections&team=a",
+			shouldErr: true,
+		},
+		"unsupported sections facet": {
+			query:     "index=sections&facets=project",
+			shouldErr: true,
+		},
+		"unsupported sections sortBy": {
+			query:     "index=sections&sortBy=createdTime",
+			shouldErr: true,
+		},
+		"invalid index": {
+			query:     "index=internal",
+			shouldErr: true,
+		},
+		"invalid facet": {
+			query:     "facets=contributors",
+			shouldErr: true,
+		},
+		"invalid sortBy": {
+			query:     "sortBy=title",
+			shouldErr: true,
+		},
+		"unsupported order": {
+			query:     "sortBy=modifiedTime&order=asc",
+			shouldErr: true,
+		},
+		"invalid page": {
+			query:     "page=-1",
+			shouldErr: true,
+		},
+		"too many hits per page": {
+			query:     "hitsPerPage=1000",
+			shouldErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			q, err := url.ParseQuery(c.query)
+			assert.NoError(t, err)
+
+			got, err := parseSearchRequest(q)
+			if c.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestSearchRequestSearchQuery(t *testing.T) {
+	req := searchRequest{
+		Query: "cache",
+		Filters: map[string][]string{
+			"product": {"Payments", "Banking"},
+			"status":  {"Draft"},
+		},
+		HitsPerPage: 20,
+		SortBy:      "createdTime",
+	}
+
+	t.Run("docs", func(t *testing.T) {
+		assert.Equal(t, search.Query{
+			Text: "cache",
+			FacetFilters: [][]string{
+				{"product:Payments", "product:Banking"},
+				{"status:Draft"},
+			},
+			HitsPerPage: 20,
+			SortBy:      "createdTime",
+			SortDesc:    true,
+		}, req.searchQuery("a@x.com", false))
+	})
+
+	drafts := req
+	drafts.Drafts = true
+
+	t.Run("drafts", func(t *testing.T) {
+		assert.Equal(t, [][]string{
+			{"product:Payments", "product:Banking"},
+			{"status:Draft"},
+			{"owners:a@x.com", "contributors:a@x.com"},
+		}, drafts.searchQuery("a@x.com", false).FacetFilters)
+	})
+
+	t.Run("drafts as admin", func(t *testing.T) {
+		assert.Equal(t, [][]string{
+			{"product:Payments", "product:Banking"},
+			{"status:Draft"},
+		}, drafts.searchQuery("a@x.com", true).FacetFilters)
+	})
+}
+
+func TestGroupSectionHits(t *testing.T) {
+	hits := []map[string]interface{}{
+		{"objectID": "doc1_2", "docID": "doc1", "title": "Retries", "heading": "Backoff"},
+		{"objectID": "doc2_0", "docID": "doc2", "title": "Caching", "heading": ""},
+		{"objectID": "doc1_0", "docID": "doc1", "title": "Retries", "heading": "Intro"},
+	}
+
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"objectID": "doc1",
+			"docID":    "doc1",
+			"title":    "Retries",
+			"sections": []map[string]interface{}{hits[0], hits[2]},
+		},
+		{
+			"objectID": "doc2",
+			"docID":    "doc2",
+			"title":    "Caching",
+			"sections": []map[string]interface{}{hits[1]},
+		},
+	}, groupSectionHits(hits))
+
+	assert.Empty(t, groupSectionHits(nil))
+}
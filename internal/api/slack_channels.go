@@ -0,0 +1,379 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/internal/config"
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// SlackChannelRequest is a request to map a Slack channel to a product, team,
+// or project.
+type SlackChannelRequest struct {
+	ChannelID  string                        `json:"channelId"`
+	TargetType models.SlackChannelTargetType `json:"targetType"`
+	Target     string                        `json:"target"`
+}
+
+// SlackChannelResponse is a Slack channel mapping returned by the Slack channel
+// API endpoints.
+type SlackChannelResponse struct {
+	ID          uint                          `json:"id"`
+	CreatedTime time.Time                     `json:"createdTime"`
+	ChannelID   string                        `json:"channelId"`
+	TargetType  models.SlackChannelTargetType `json:"targetType"`
+	Target      string                        `json:"target"`
+}
+
+// SlackChannelsHandler handles requests to list and create Slack channel
+// mappings at "/api/v1/slack/channels", and to get and delete a mapping at
+// "/api/v1/slack/channels/{id}". Documents of a mapped product, team, or
+// project are posted to the channel when they enter review. Only admins can
+// manage Slack channel mappings.
+func SlackChannelsHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Authorize request.
+		if !authorizeAdmin(w, r, l, db) {
+			return
+		}
+
+		path := strings.Trim(
+			strings.TrimPrefix(r.URL.Path, "/api/v1/slack/channels"), "/")
+		if path != "" {
+			serveSlackChannel(w, r, l, db, path)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			var cs models.SlackChannels
+			if err := cs.Find(db); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting Slack channels",
+					"error finding Slack channels", err)
+				return
+			}
+
+			resp := make([]SlackChannelResponse, len(cs))
+			for i, c := range cs {
+				resp[i] = newSlackChannelResponse(c)
+			}
+			respondJSON(w, r, l, http.StatusOK, resp)
+
+		case "POST":
+			var req SlackChannelRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding Slack channel request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			// Use the canonical name of the target.
+			target, err := getSlackChannelTarget(db, req.TargetType, req.Target)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					http.Error(w, fmt.Sprintf("Bad request: %s %q not found",
+						req.TargetType, req.Target), http.StatusBadRequest)
+					return
+				}
+				if errors.Is(err, errInvalidSlackChannelTargetType) {
+					http.Error(w, fmt.Sprintf("Bad request: %q", err),
+						http.StatusBadRequest)
+					return
+				}
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error creating Slack channel",
+					"error getting Slack channel target", err,
+					"target_type", req.TargetType,
+					"target", req.Target,
+				)
+				return
+			}
+
+			c := models.SlackChannel{
+				ChannelID:  strings.TrimSpace(req.ChannelID),
+				TargetType: req.TargetType,
+				Target:     target,
+			}
+			if err := c.Create(db); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			l.Info("created Slack channel",
+				"slack_channel_id", c.ID,
+				"channel_id", c.ChannelID,
+				"target_type", c.TargetType,
+				"target", c.Target,
+			)
+			respondJSON(w, r, l, http.StatusCreated, newSlackChannelResponse(c))
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+	})
+}
+
+// serveSlackChannel handles requests to get and delete the Slack channel
+// mapping with ID idStr.
+func serveSlackChannel(
+	w http.ResponseWriter,
+	r *http.Request,
+	l hclog.Logger,
+	db *gorm.DB,
+	idStr string,
+) {
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil || id == 0 {
+		http.Error(w, "Bad request: invalid Slack channel ID",
+			http.StatusBadRequest)
+		return
+	}
+
+	c := models.SlackChannel{ID: uint(id)}
+	if err := c.Get(db); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			http.Error(w, "Slack channel not found", http.StatusNotFound)
+			return
+		}
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error getting Slack channel",
+			"error getting Slack channel", err,
+			"slack_channel_id", id,
+		)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		respondJSON(w, r, l, http.StatusOK, newSlackChannelResponse(c))
+
+	case "DELETE":
+		if err := c.Delete(db); err != nil {
+			respondError(w, r, l, http.StatusInternalServerError,
+				"Error deleting Slack channel",
+				"error deleting Slack channel", err,
+				"slack_channel_id", id,
+			)
+			return
+		}
+
+		l.Info("deleted Slack channel", "slack_channel_id", c.ID)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+}
+
+var errInvalidSlackChannelTargetType = errors.New(
+	"invalid Slack channel target type")
+
+// getSlackChannelTarget returns the canonical name of the product, team, or
+// project target of type t. It returns gorm.ErrRecordNotFound if the target
+// doesn't exist.
+func getSlackChannelTarget(
+	db *gorm.DB, t models.SlackChannelTargetType, target string,
+) (string, error) {
+	if target == "" {
+		return "", gorm.ErrRecordNotFound
+	}
+
+	switch t {
+	case models.ProductSlackChannelTarget:
+		p := models.Product{Name: target}
+		if err := p.Get(db); err != nil {
+			return "", err
+		}
+		return p.Name, nil
+	case models.TeamSlackChannelTarget:
+		team := models.Team{Name: target}
+		if err := team.Get(db); err != nil {
+			return "", err
+		}
+		return team.Name, nil
+	case models.ProjectSlackChannelTarget:
+		var p models.Project
+		if err := db.Where("name = ?", target).First(&p).Error; err != nil {
+			return "", err
+		}
+		return p.Name, nil
+	default:
+		return "", fmt.Errorf("%w: %q", errInvalidSlackChannelTargetType, t)
+	}
+}
+
+// newSlackChannelResponse returns the API response for Slack channel mapping
+// c.
+func newSlackChannelResponse(c models.SlackChannel) SlackChannelResponse {
+	return SlackChannelResponse{
+		ID:          c.ID,
+		CreatedTime: c.CreatedAt,
+		ChannelID:   c.ChannelID,
+		TargetType:  c.TargetType,
+		Target:      c.Target,
+	}
+}
+
+// notifySlackChannelsInReview posts a summary of document docObj entering
+// review to the Slack channels mapped to its product, team, or project, and
+// records the posted messages so approvals can be posted as thread replies.
+// published is true if the document was published from a draft. Errors are
+// logged instead of returned because the change that caused the event has
+// already been saved.
+func notifySlackChannelsInReview(
+	cfg *config.Config,
+	l hclog.Logger,
+	db *gorm.DB,
+	r *http.Request,
+	docObj hcd.Doc,
+	ownerName string,
+	published bool,
+) {
+	if cfg.Slack.BotToken == "" {
+		return
+	}
+	docID := docObj.GetObjectID()
+	logError := func(msg string, err error) {
+		l.Error(msg,
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+	}
+
+	channelIDs, err := models.FindSlackChannelIDs(db,
+		docObj.GetProduct(), docObj.GetTeam(), docObj.GetProject())
+	if err != nil {
+		logError("error finding Slack channels", err)
+		return
+	}
+	if len(channelIDs) == 0 {
+		return
+	}
+
+	docURL, err := getDocumentURL(cfg.BaseURL, docID)
+	if err != nil {
+		logError("error getting document URL", err)
+		return
+	}
+	if ownerName == "" && len(docObj.GetOwners()) > 0 {
+		ownerName = docObj.GetOwners()[0]
+	}
+
+	timestamps, err := slackbot.PostSlackChannelMessage_DocumentInReview(
+		slackbot.DocumentChannelSlackData{
+			BaseURL:           cfg.BaseURL,
+			DocumentOwner:     ownerName,
+			DocumentType:      docObj.GetDocType(),
+			DocumentShortName: docObj.GetDocNumber(),
+			DocumentTitle:     docObj.GetTitle(),
+			DocumentURL:       docURL,
+			DocumentProd:      docObj.GetProduct(),
+			DocumentTeam:      docObj.GetTeam(),
+			DocumentProject:   docObj.GetProject(),
+			Reviewers:         docObj.GetReviewers(),
+			Published:         published,
+		},
+		channelIDs,
+		slack.New(cfg.Slack.BotToken),
+	)
+	if err != nil {
+		logError("error posting document to Slack channels", err)
+	}
+
+	for channelID, ts := range timestamps {
+		m := models.SlackChannelMessage{
+			ChannelID:    channelID,
+			GoogleFileID: docID,
+			Timestamp:    ts,
+		}
+		if err := m.Upsert(db); err != nil {
+			logError("error saving Slack channel message", err)
+		}
+	}
+
+	l.Info("document posted to Slack channels",
+		"doc_id", docID,
+		"channels", len(timestamps),
+		"method", r.Method,
+		"path", r.URL.Path,
+	)
+}
+
+// notifySlackChannelsApproved posts the approval of document docObj by the
+// user with email address approver as a reply to the threads of the document's
+// in-review Slack channel messages. fullyApproved is true if the approval moved
+// the document to the approved status. Errors are logged instead of returned
+// because the approval has already been saved.
+func notifySlackChannelsApproved(
+	cfg *config.Config,
+	l hclog.Logger,
+	db *gorm.DB,
+	r *http.Request,
+	docObj hcd.Doc,
+	approver string,
+	fullyApproved bool,
+) {
+	if cfg.Slack.BotToken == "" {
+		return
+	}
+	docID := docObj.GetObjectID()
+	logError := func(msg string, err error) {
+		l.Error(msg,
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+	}
+
+	var ms models.SlackChannelMessages
+	if err := ms.FindByGoogleFileID(db, docID); err != nil {
+		logError("error finding Slack channel messages", err)
+		return
+	}
+	if len(ms) == 0 {
+		return
+	}
+	threads := make(map[string]string, len(ms))
+	for _, m := range ms {
+		threads[m.ChannelID] = m.Timestamp
+	}
+
+	docURL, err := getDocumentURL(cfg.BaseURL, docID)
+	if err != nil {
+		logError("error getting document URL", err)
+		return
+	}
+
+	if err := slackbot.PostSlackThreadReply_DocumentApproved(
+		slackbot.DocumentApprovalSlackData{
+			Approver:      approver,
+			ApprovalCount: len(docObj.GetReviewedBy()),
+			DocumentTitle: docObj.GetTitle(),
+			DocumentURL:   docURL,
+			ReviewerCount: len(docObj.GetReviewers()),
+			FullyApproved: fullyApproved,
+		},
+		threads,
+		slack.New(cfg.Slack.BotToken),
+	); err != nil {
+		logError("error posting approval to Slack channel threads", err)
+	}
+}
@@ -0,0 +1,439 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/hashicorp-forge/hermes/internal/config"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
+	"github.com/hashicorp/go-hclog"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	// slackCommandSearchHits is the number of search results returned by the
+	// "search" subcommand.
+	slackCommandSearchHits = 5
+
+	// slackCommandUsage is the help text of the slash command.
+	slackCommandUsage = "*Usage:*\n" +
+		"• `/docvault search <query>` to search published documents\n" +
+		"• `/docvault status <document number>` to show the reviewers and " +
+		"approvals of a document (e.g., `PAYMT-042`)\n" +
+		"• `/docvault draft <type> <title>` to create a draft"
+)
+
+// SlackCommandsHandler handles the "/docvault" Slack slash command at
+// "/api/v1/slack/commands". Requests are verified using the Slack app's
+// signing secret, and the Slack user is mapped to a user by email address,
+// who the subcommand is run as. Drafts are created by DraftsHandler.
+func SlackCommandsHandler(
+	cfg *config.Config,
+	l hclog.Logger,
+	sp search.Provider,
+	st storage.Provider,
+	s *gw.Service,
+	db *gorm.DB,
+	n notify.Channel,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxSlackRequestSize))
+		if err != nil {
+			respondError(w, r, l, http.StatusBadRequest,
+				"Bad request", "error reading Slack request body", err)
+			return
+		}
+		if err := verifySlackRequest(
+			r.Header, body, cfg.Slack.SigningSecret); err != nil {
+			l.Warn("invalid Slack request",
+				"error", err,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Parse slash command.
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		cmd, err := slack.SlashCommandParse(r)
+		if err != nil {
+			respondError(w, r, l, http.StatusBadRequest,
+				"Bad request", "error parsing Slack slash command", err)
+			return
+		}
+		subcommand, args := parseSlackCommandText(cmd.Text)
+		logArgs := []interface{}{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"slack_user_id", cmd.UserID,
+			"subcommand", subcommand,
+		}
+
+		if subcommand == "" || subcommand == "help" {
+			respondSlackCommand(w, r, l, slackCommandUsage)
+			return
+		}
+
+		// Map the Slack user to a user to authorize the request.
+		api := slack.New(cfg.Slack.BotToken)
+		userEmail, err := slackbot.GetUserEmailByID(cmd.UserID, api)
+		if err != nil {
+			l.Error("error getting Slack user email",
+				append([]interface{}{"error", err}, logArgs...)...)
+			respondSlackCommand(w, r, l, ":warning: Your Slack account "+
+				"couldn't be matched to a DocVault user.")
+			return
+		}
+		logArgs = append(logArgs, "user", userEmail)
+		r = r.WithContext(
+			context.WithValue(r.Context(), "userEmail", userEmail))
+
+		switch subcommand {
+		case "search":
+			if args == "" {
+				respondSlackCommand(w, r, l, slackCommandUsage)
+				return
+			}
+			text, err := slackCommandSearch(cfg, sp, userEmail, args)
+			if err != nil {
+				l.Error("error searching documents from Slack",
+					append([]interface{}{"error", err}, logArgs...)...)
+				respondSlackCommand(w, r, l,
+					":warning: Documents couldn't be searched.")
+				return
+			}
+			respondSlackCommand(w, r, l, text)
+
+		case "status":
+			if args == "" {
+				respondSlackCommand(w, r, l, slackCommandUsage)
+				return
+			}
+			text, err := slackCommandStatus(cfg, sp, args)
+			if err != nil {
+				l.Error("error getting document status from Slack",
+					append([]interface{}{"error", err}, logArgs...)...)
+				respondSlackCommand(w, r, l,
+					":warning: The document status couldn't be retrieved.")
+				return
+			}
+			respondSlackCommand(w, r, l, text)
+
+		case "draft":
+			docType, title := parseSlackCommandText(args)
+			if docType == "" || title == "" {
+				respondSlackCommand(w, r, l, slackCommandUsage)
+				return
+			}
+
+			// Acknowledge the command right away, as Slack requires a response
+			// within 3 seconds, and respond with the draft when it's created.
+			respondSlackCommand(w, r, l, fmt.Sprintf(
+				":hourglass_flowing_sand: Creating draft *%s*...", title))
+			r = r.Clone(context.Background())
+			go func() {
+				text := slackCommandDraft(
+					cfg, l, sp, st, s, db, n, r, docType, title)
+				if err := slack.PostWebhook(cmd.ResponseURL, &slack.WebhookMessage{
+					Text:         text,
+					ResponseType: slack.ResponseTypeEphemeral,
+				}); err != nil {
+					l.Error("error responding to Slack slash command",
+						append([]interface{}{"error", err}, logArgs...)...)
+				}
+			}()
+
+		default:
+			respondSlackCommand(w, r, l, fmt.Sprintf(
+				"Unknown command `%s`.\n%s", subcommand, slackCommandUsage))
+		}
+	})
+}
+
+// respondSlackCommand responds to a slash command with an ephemeral message
+// with text text, which is only visible to the user that ran the command.
+func respondSlackCommand(
+	w http.ResponseWriter, r *http.Request, l hclog.Logger, text string) {
+	respondJSON(w, r, l, http.StatusOK, &slack.Msg{
+		ResponseType: slack.ResponseTypeEphemeral,
+		Text:         text,
+	})
+}
+
+// parseSlackCommandText splits slash command text into its first word, in
+// lower case, and the rest of the text.
+func parseSlackCommandText(text string) (string, string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	first := strings.ToLower(fields[0])
+	rest := strings.TrimSpace(strings.TrimPrefix(
+		strings.TrimSpace(text), fields[0]))
+	return first, rest
+}
+
+// slackCommandSearch returns the message text for the top published documents
+// matching query, searched as the user with email address userEmail.
+func slackCommandSearch(
+	cfg *config.Config, sp search.Provider, userEmail, query string,
+) (string, error) {
+	req := searchRequest{
+		Query:       query,
+		HitsPerPage: slackCommandSearchHits,
+	}
+	resp, err := sp.Docs().Search(req.searchQuery(userEmail, false))
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Hits) == 0 {
+		return fmt.Sprintf("No documents found for *%s*.", query), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Top results for *%s*:", query)
+	for _, hit := range resp.Hits {
+		var doc hcd.BaseDoc
+		if err := decodeSearchHit(hit, &doc); err != nil {
+			return "", err
+		}
+		link, err := getDocumentShortLinkURL(
+			cfg.BaseURL, doc.ObjectID, doc.DocType, doc.DocNumber)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("\n• ")
+		b.WriteString(formatSlackDocumentLink(doc.Title, doc.DocNumber, link))
+		if doc.Status != "" {
+			fmt.Fprintf(&b, " — %s", doc.Status)
+		}
+		if len(doc.Owners) > 0 {
+			fmt.Fprintf(&b, " — %s", doc.Owners[0])
+		}
+	}
+	if resp.NbHits > len(resp.Hits) {
+		fmt.Fprintf(&b, "\n_%d more results in DocVault._",
+			resp.NbHits-len(resp.Hits))
+	}
+	return b.String(), nil
+}
+
+// slackCommandStatus returns the message text for the reviewers and approvals
+// of the published document with number docNumber (e.g., "PAYMT-042").
+func slackCommandStatus(
+	cfg *config.Config, sp search.Provider, docNumber string,
+) (string, error) {
+	doc, err := findDocumentByNumber(sp, docNumber)
+	if err != nil {
+		return "", err
+	}
+	if doc == nil {
+		return fmt.Sprintf("No document found with number *%s*.", docNumber),
+			nil
+	}
+
+	link, err := getDocumentShortLinkURL(cfg.BaseURL,
+		doc.GetObjectID(), doc.GetDocType(), doc.GetDocNumber())
+	if err != nil {
+		return "", err
+	}
+	return formatSlackDocumentStatus(doc, link), nil
+}
+
+// slackCommandDraft creates a draft of document type docType with title title
+// using DraftsHandler, as the user of request r, and returns the message text
+// with the result.
+func slackCommandDraft(
+	cfg *config.Config,
+	l hclog.Logger,
+	sp search.Provider,
+	st storage.Provider,
+	s *gw.Service,
+	db *gorm.DB,
+	n notify.Channel,
+	r *http.Request,
+	docType string,
+	title string,
+) string {
+	// Use the name of the document type as it was created.
+	var dts models.DocumentTypes
+	if err := dts.GetAll(db); err != nil {
+		l.Error("error getting document types", "error", err)
+		return ":warning: The draft couldn't be created."
+	}
+	for _, dt := range dts {
+		if strings.EqualFold(dt.Name, docType) {
+			docType = dt.Name
+			break
+		}
+	}
+
+	body, err := json.Marshal(DraftsRequest{
+		DocType: docType,
+		Title:   title,
+	})
+	if err != nil {
+		l.Error("error encoding drafts request", "error", err)
+		return ":warning: The draft couldn't be created."
+	}
+	req, err := http.NewRequestWithContext(
+		r.Context(), "POST", "/api/v1/drafts", bytes.NewReader(body))
+	if err != nil {
+		l.Error("error creating drafts request", "error", err)
+		return ":warning: The draft couldn't be created."
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	DraftsHandler(cfg, l, sp, st, s, db, n).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return fmt.Sprintf(":warning: The draft couldn't be created: %s",
+			strings.TrimSpace(rec.Body.String()))
+	}
+
+	var resp DraftsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		l.Error("error decoding drafts response", "error", err)
+		return ":warning: The draft couldn't be created."
+	}
+	docURL, err := getDocumentURL(cfg.BaseURL, resp.ID)
+	if err != nil {
+		l.Error("error getting document URL", "error", err, "doc_id", resp.ID)
+		return ":warning: The draft couldn't be created."
+	}
+
+	// Only link to the draft in DocVault, as the URL of the document's editor
+	// depends on the storage provider.
+	return fmt.Sprintf(":white_check_mark: Created draft *%s*.\n"+
+		"• <%s?draft=true|Open in DocVault>",
+		title, docURL)
+}
+
+// findDocumentByNumber returns the published document with number docNumber
+// from the search index, or nil if there isn't one.
+func findDocumentByNumber(
+	sp search.Provider, docNumber string) (hcd.Doc, error) {
+	resp, err := sp.Docs().Search(search.Query{
+		Text:        docNumber,
+		HitsPerPage: maxSearchHitsPerPage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error searching documents: %w", err)
+	}
+
+	for _, hit := range resp.Hits {
+		var baseDoc hcd.BaseDoc
+		if err := decodeSearchHit(hit, &baseDoc); err != nil {
+			return nil, err
+		}
+		if baseDoc.DocNumber == "" ||
+			!strings.EqualFold(baseDoc.DocNumber, docNumber) {
+			continue
+		}
+
+		doc, err := hcd.NewEmptyDoc(baseDoc.DocType)
+		if err != nil {
+			return nil, fmt.Errorf("error creating new empty doc: %w", err)
+		}
+		if err := decodeSearchHit(hit, &doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+	return nil, nil
+}
+
+// decodeSearchHit decodes search result hit into obj.
+func decodeSearchHit(hit map[string]interface{}, obj interface{}) error {
+	b, err := json.Marshal(hit)
+	if err != nil {
+		return fmt.Errorf("error encoding search hit: %w", err)
+	}
+	if err := json.Unmarshal(b, obj); err != nil {
+		return fmt.Errorf("error decoding search hit: %w", err)
+	}
+	return nil
+}
+
+// getDocumentShortLinkURL returns the short link URL
+// ("{baseURL}/l/{docType}/{docNumber}") of the document with ID docID, or its
+// document URL if it doesn't have a number.
+func getDocumentShortLinkURL(
+	baseURL, docID, docType, docNumber string) (string, error) {
+	if docNumber == "" || docType == "" {
+		return getDocumentURL(baseURL, docID)
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing base URL: %w", err)
+	}
+	u.Path = path.Join(u.Path, "l",
+		strings.ToLower(docType), strings.ToLower(docNumber))
+	return u.String(), nil
+}
+
+// formatSlackDocumentLink returns a Slack link to URL link with text
+// "[{docNumber}] {title}".
+func formatSlackDocumentLink(title, docNumber, link string) string {
+	if docNumber != "" {
+		title = fmt.Sprintf("[%s] %s", docNumber, title)
+	}
+	return fmt.Sprintf("<%s|%s>", link, title)
+}
+
+// formatSlackDocumentStatus returns the message text for the status,
+// reviewers, and approvals of document doc at URL link.
+func formatSlackDocumentStatus(doc hcd.Doc, link string) string {
+	var b strings.Builder
+	b.WriteString(
+		formatSlackDocumentLink(doc.GetTitle(), doc.GetDocNumber(), link))
+	fmt.Fprintf(&b, "\n*Status:* %s", doc.GetStatus())
+	if len(doc.GetOwners()) > 0 {
+		fmt.Fprintf(&b, "\n*Owner:* %s", doc.GetOwners()[0])
+	}
+	if dd := doc.GetDueDate(); dd != nil {
+		fmt.Fprintf(&b, "\n*Due date:* %s", dd.Format("Jan 2, 2006"))
+	}
+
+	reviewers := doc.GetReviewers()
+	if len(reviewers) == 0 {
+		b.WriteString("\n*Reviewers:* _none_")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\n*Approvals:* %d/%d",
+		len(doc.GetReviewedBy()), len(reviewers))
+	b.WriteString("\n*Reviewers:*")
+	for _, rv := range reviewers {
+		switch {
+		case containsFold(doc.GetReviewedBy(), rv):
+			fmt.Fprintf(&b, "\n• :white_check_mark: %s approved", rv)
+		case containsFold(doc.GetChangesRequestedBy(), rv):
+			fmt.Fprintf(&b, "\n• :memo: %s requested changes", rv)
+		default:
+			fmt.Fprintf(&b, "\n• :hourglass_flowing_sand: %s pending", rv)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"testing"
+
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSlackCommandText(t *testing.T) {
+	cases := map[string]struct {
+		text      string
+		wantFirst string
+		wantRest  string
+	}{
+		"empty": {
+			text: "  ",
+		},
+		"subcommand only": {
+			text:      "Help",
+			wantFirst: "help",
+		},
+		"subcommand with args": {
+			text:      " search  payment  gateway ",
+			wantFirst: "search",
+			wantRest:  "payment  gateway",
+		},
+		"draft": {
+			text:      "draft RFC Payment Retries",
+			wantFirst: "draft",
+			wantRest:  "RFC Payment Retries",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			first, rest := parseSlackCommandText(c.text)
+			assert.Equal(t, c.wantFirst, first)
+			assert.Equal(t, c.wantRest, rest)
+		})
+	}
+}
+
+func TestGetDocumentShortLinkURL(t *testing.T) {
+	link, err := getDocumentShortLinkURL(
+		"https://docvault.example.com", "docID", "RFC", "PAYMT-042")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://docvault.example.com/l/rfc/paymt-042", link)
+
+	// Documents without a number link to the document page.
+	link, err = getDocumentShortLinkURL(
+		"https://docvault.example.com/", "docID", "RFC", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://docvault.example.com/document/docID", link)
+}
+
+func TestFormatSlackDocumentStatus(t *testing.T) {
+	doc := &hcd.COMMONTEMPLATE{
+		BaseDoc: hcd.BaseDoc{
+			DocNumber:          "PAYMT-042",
+			Owners:             []string{"owner@example.com"},
+			Reviewers:          []string{"a@example.com", "b@example.com", "c@example.com"},
+			ReviewedBy:         []string{"A@example.com"},
+			ChangesRequestedBy: []string{"b@example.com"},
+			Status:             "In-Review",
+			Title:              "Payment Retries",
+		},
+	}
+
+	assert.Equal(t,
+		"<https://l/rfc/paymt-042|[PAYMT-042] Payment Retries>\n"+
+			"*Status:* In-Review\n"+
+			"*Owner:* owner@example.com\n"+
+			"*Approvals:* 1/3\n"+
+			"*Reviewers:*\n"+
+			"• :white_check_mark: a@example.com approved\n"+
+			"• :memo: b@example.com requested changes\n"+
+			"• :hourglass_flowing_sand: c@example.com pending",
+		formatSlackDocumentStatus(doc, "https://l/rfc/paymt-042"))
+
+	doc.Reviewers = nil
+	assert.Equal(t,
+		"<https://l|[PAYMT-042] Payment Retries>\n"+
+			"*Status:* In-Review\n"+
+			"*Owner:* owner@example.com\n"+
+			"*Reviewers:* _none_",
+		formatSlackDocumentStatus(doc, "https://l"))
+}
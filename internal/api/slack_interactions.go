@@ -0,0 +1,344 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/internal/config"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
+	"github.com/hashicorp/go-hclog"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxSlackRequestSize is the maximum size of a request from Slack.
+	maxSlackRequestSize = 1 << 20
+
+	// reviewSnoozeDuration is how long a review request is snoozed for.
+	reviewSnoozeDuration = 24 * time.Hour
+)
+
+// SlackInteractionsHandler handles interactions with the action buttons of
+// review request Slack messages at "/api/v1/slack/interactions". Requests are
+// verified using the Slack app's signing secret, and the Slack user is mapped
+// to a user by email address. Approving and requesting changes run the same
+// logic as ApprovalHandler. The original message is then updated with the
+// result.
+func SlackInteractionsHandler(
+	cfg *config.Config,
+	l hclog.Logger,
+	sp search.Provider,
+	st storage.Provider,
+	s *gw.Service,
+	db *gorm.DB,
+	n notify.Channel,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxSlackRequestSize))
+		if err != nil {
+			respondError(w, r, l, http.StatusBadRequest,
+				"Bad request", "error reading Slack request body", err)
+			return
+		}
+		if err := verifySlackRequest(
+			r.Header, body, cfg.Slack.SigningSecret); err != nil {
+			l.Warn("invalid Slack request",
+				"error", err,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Parse interaction payload.
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			respondError(w, r, l, http.StatusBadRequest,
+				"Bad request", "error parsing Slack request body", err)
+			return
+		}
+		var cb slack.InteractionCallback
+		if err := json.Unmarshal(
+			[]byte(form.Get("payload")), &cb); err != nil {
+			respondError(w, r, l, http.StatusBadRequest,
+				"Bad request", "error decoding Slack interaction payload", err)
+			return
+		}
+
+		// Ignore interactions other than the review action buttons.
+		action := reviewAction(cb)
+		if action == nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		// Acknowledge the interaction right away, as Slack requires a response
+		// within 3 seconds, and update the message when the action is done.
+		w.WriteHeader(http.StatusOK)
+		r = r.Clone(context.Background())
+		go handleSlackReviewAction(cfg, l, sp, st, db, n, r, cb, *action)
+	})
+}
+
+// reviewAction returns the review action button of interaction callback cb,
+// or nil if cb isn't for one.
+func reviewAction(cb slack.InteractionCallback) *slack.BlockAction {
+	if cb.Type != slack.InteractionTypeBlockActions {
+		return nil
+	}
+	for _, a := range cb.ActionCallback.BlockActions {
+		if a.BlockID != slackbot.ReviewActionsBlockID || a.Value == "" {
+			continue
+		}
+		switch a.ActionID {
+		case slackbot.ApproveActionID,
+			slackbot.RequestChangesActionID,
+			slackbot.SnoozeActionID:
+			return a
+		}
+	}
+	return nil
+}
+
+// handleSlackReviewAction approves, requests changes of, or snoozes the
+// review request for the document of review action button action, as the
+// Slack user that clicked it, and updates the message with the result.
+func handleSlackReviewAction(
+	cfg *config.Config,
+	l hclog.Logger,
+	sp search.Provider,
+	st storage.Provider,
+	db *gorm.DB,
+	n notify.Channel,
+	r *http.Request,
+	cb slack.InteractionCallback,
+	action slack.BlockAction,
+) {
+	docID := action.Value
+	logArgs := []interface{}{
+		"action", action.ActionID,
+		"doc_id", docID,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"slack_user_id", cb.User.ID,
+	}
+
+	api := slack.New(cfg.Slack.BotToken)
+	userEmail, err := slackbot.GetUserEmailByID(cb.User.ID, api)
+	if err != nil {
+		l.Error("error getting Slack user email",
+			append([]interface{}{"error", err}, logArgs...)...)
+		respondSlackReviewAction(l, cb, false,
+			":warning: Your Slack account couldn't be matched to a DocVault user.",
+			logArgs)
+		return
+	}
+	logArgs = append(logArgs, "user", userEmail)
+
+	// Run the action as the user.
+	r = r.WithContext(
+		context.WithValue(r.Context(), "userEmail", userEmail))
+
+	switch action.ActionID {
+	case slackbot.ApproveActionID:
+		if err := approveDocument(
+			cfg, l, sp, st, db, n, r, docID, userEmail); err != nil {
+			respondSlackReviewAction(l, cb, false, fmt.Sprintf(
+				":warning: The document couldn't be approved: %s",
+				slackReviewActionErrorMessage(err)), logArgs)
+			return
+		}
+		l.Info("approval created from Slack", logArgs...)
+		respondSlackReviewAction(l, cb, true,
+			":white_check_mark: You approved this document.", logArgs)
+
+	case slackbot.RequestChangesActionID:
+		if err := requestDocumentChanges(
+			cfg, l, sp, st, db, r, docID, userEmail); err != nil {
+			respondSlackReviewAction(l, cb, false, fmt.Sprintf(
+				":warning: Changes couldn't be requested: %s",
+				slackReviewActionErrorMessage(err)), logArgs)
+			return
+		}
+		l.Info("changes requested from Slack", logArgs...)
+		respondSlackReviewAction(l, cb, true,
+			":memo: You requested changes of this document. "+
+				"Please leave your comments in the document.", logArgs)
+
+	case slackbot.SnoozeActionID:
+		// Messages sent before snoozing was disabled may still have the button.
+		if !reviewSnoozeEnabled(cfg) {
+			respondSlackReviewAction(l, cb, false,
+				":warning: Snoozing review requests isn't enabled.", logArgs)
+			return
+		}
+		remindAt := time.Now().Add(reviewSnoozeDuration)
+		if err := snoozeReview(
+			l, sp, db, r, docID, userEmail, remindAt); err != nil {
+			respondSlackReviewAction(l, cb, false, fmt.Sprintf(
+				":warning: The review request couldn't be snoozed: %s",
+				slackReviewActionErrorMessage(err)), logArgs)
+			return
+		}
+		l.Info("review request snoozed from Slack", logArgs...)
+		respondSlackReviewAction(l, cb, true, fmt.Sprintf(
+			":zzz: Snoozed. You'll be reminded of this review request "+
+				"<!date^%d^{date_short_pretty} at {time}|tomorrow>.",
+			remindAt.Unix()), logArgs)
+	}
+}
+
+// respondSlackReviewAction responds to review action interaction cb with
+// message text. If replace is true, the action buttons of the original message
+// are replaced with text. Otherwise, text is sent as an ephemeral message so
+// the user can try again.
+func respondSlackReviewAction(
+	l hclog.Logger,
+	cb slack.InteractionCallback,
+	replace bool,
+	text string,
+	logArgs []interface{},
+) {
+	var err error
+	if replace {
+		err = slackbot.UpdateSlackMessage_ReviewerResult(
+			cb.ResponseURL, cb.Message, text)
+	} else {
+		err = slack.PostWebhook(cb.ResponseURL, &slack.WebhookMessage{
+			Text:         text,
+			ResponseType: slack.ResponseTypeEphemeral,
+		})
+	}
+	if err != nil {
+		l.Error("error responding to Slack interaction",
+			append([]interface{}{"error", err}, logArgs...)...)
+	}
+}
+
+// slackReviewActionErrorMessage returns the message to show a Slack user for
+// error err of a review action.
+func slackReviewActionErrorMessage(err error) string {
+	var ae *approvalError
+	if errors.As(err, &ae) {
+		return ae.msg
+	}
+	return "an unexpected error occurred"
+}
+
+// reviewSnoozeEnabled returns true if review requests can be snoozed from
+// Slack with configuration cfg. Snoozed review requests are sent again by the
+// reminders scheduler, which only does so when it sends Slack messages.
+func reviewSnoozeEnabled(cfg *config.Config) bool {
+	return cfg.Reminders != nil && cfg.Reminders.Enabled && cfg.Reminders.Slack
+}
+
+// snoozeReview snoozes the review request of the in-review document with ID
+// docID for the reviewer with email address userEmail, to be sent again at
+// time remindAt. It returns an *approvalError if the review request can't be
+// snoozed.
+func snoozeReview(
+	l hclog.Logger,
+	sp search.Provider,
+	db *gorm.DB,
+	r *http.Request,
+	docID string,
+	userEmail string,
+	remindAt time.Time,
+) error {
+	logError := func(msg string, err error) {
+		l.Error(msg,
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+	}
+
+	// Get base document object from search index so we can determine the doc
+	// type.
+	baseDocObj := &hcd.BaseDoc{}
+	if err := sp.Docs().GetObject(docID, &baseDocObj); err != nil {
+		logError("error requesting base document object from search index", err)
+		return &approvalError{http.StatusInternalServerError,
+			"Error accessing document"}
+	}
+	docObj, err := hcd.NewEmptyDoc(baseDocObj.DocType)
+	if err != nil {
+		logError("error creating new empty doc", err)
+		return &approvalError{http.StatusInternalServerError,
+			"Error accessing document"}
+	}
+	if err := sp.Docs().GetObject(docID, &docObj); err != nil {
+		logError("error getting document from search index", err)
+		return &approvalError{http.StatusInternalServerError,
+			"Error accessing document"}
+	}
+
+	// Authorize request.
+	if docObj.GetStatus() != "In-Review" && docObj.GetStatus() != "In Review" {
+		return &approvalError{http.StatusBadRequest,
+			"Only documents in the \"In-Review\" status can be reviewed"}
+	}
+	if !contains(docObj.GetReviewers(), userEmail) {
+		return &approvalError{http.StatusUnauthorized,
+			"Not authorized as a document reviewer"}
+	}
+	if contains(docObj.GetReviewedBy(), userEmail) {
+		return &approvalError{http.StatusBadRequest,
+			"Document already reviewed by user"}
+	}
+
+	d := models.Document{GoogleFileID: docID}
+	if err := d.Get(db); err != nil {
+		logError("error getting document from database", err)
+		return &approvalError{http.StatusInternalServerError,
+			"Error accessing document"}
+	}
+	rs := models.ReviewSnooze{
+		DocumentID:           d.ID,
+		ReviewerEmailAddress: userEmail,
+		RemindAt:             remindAt,
+	}
+	if err := rs.Upsert(db); err != nil {
+		logError("error upserting review snooze", err)
+		return &approvalError{http.StatusInternalServerError,
+			"Error snoozing review request"}
+	}
+
+	return nil
+}
+
+// verifySlackRequest returns an error if the request with header header and
+// body body wasn't signed by Slack using signing secret secret, or is too old.
+func verifySlackRequest(header http.Header, body []byte, secret string) error {
+	if secret == "" {
+		return errors.New("Slack signing secret isn't configured")
+	}
+	sv, err := slack.NewSecretsVerifier(header, secret)
+	if err != nil {
+		return err
+	}
+	if _, err := sv.Write(body); err != nil {
+		return err
+	}
+	return sv.Ensure()
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySlackRequest(t *testing.T) {
+	const secret = "secret"
+	body := []byte("payload=%7B%7D")
+
+	signedHeader := func(secret string, ts time.Time) http.Header {
+		tsStr := strconv.FormatInt(ts.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		fmt.Fprintf(mac, "v0:%s:%s", tsStr, body)
+		h := http.Header{}
+		h.Set("X-Slack-Request-Timestamp", tsStr)
+		h.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		return h
+	}
+
+	cases := map[string]struct {
+		header    http.Header
+		secret    string
+		shouldErr bool
+	}{
+		"valid": {
+			header: signedHeader(secret, time.Now()),
+			secret: secret,
+		},
+		"wrong secret": {
+			header:    signedHeader("other", time.Now()),
+			secret:    secret,
+			shouldErr: true,
+		},
+		"expired timestamp": {
+			header:    signedHeader(secret, time.Now().Add(-time.Hour)),
+			secret:    secret,
+			shouldErr: true,
+		},
+		"no signature": {
+			header:    http.Header{},
+			secret:    secret,
+			shouldErr: true,
+		},
+		"no configured secret": {
+			header:    signedHeader("", time.Now()),
+			shouldErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := verifySlackRequest(c.header, body, c.secret)
+			if c.shouldErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReviewAction(t *testing.T) {
+	callback := func(
+		typ slack.InteractionType, actions ...*slack.BlockAction,
+	) slack.InteractionCallback {
+		return slack.InteractionCallback{
+			Type: typ,
+			ActionCallback: slack.ActionCallbacks{
+				BlockActions: actions,
+			},
+		}
+	}
+	approve := &slack.BlockAction{
+		ActionID: slackbot.ApproveActionID,
+		BlockID:  slackbot.ReviewActionsBlockID,
+		Value:    "docID",
+	}
+
+	assert.Equal(t, approve, reviewAction(
+		callback(slack.InteractionTypeBlockActions, approve)))
+
+	// Actions of other blocks are ignored.
+	assert.Nil(t, reviewAction(callback(slack.InteractionTypeBlockActions,
+		&slack.BlockAction{
+			ActionID: slackbot.ApproveActionID,
+			BlockID:  "other",
+			Value:    "docID",
+		})))
+
+	// Unknown actions and actions without a document ID are ignored.
+	assert.Nil(t, reviewAction(callback(slack.InteractionTypeBlockActions,
+		&slack.BlockAction{
+			ActionID: "other",
+			BlockID:  slackbot.ReviewActionsBlockID,
+			Value:    "docID",
+		},
+		&slack.BlockAction{
+			ActionID: slackbot.SnoozeActionID,
+			BlockID:  slackbot.ReviewActionsBlockID,
+		})))
+
+	// Other interaction types are ignored.
+	assert.Nil(t, reviewAction(
+		callback(slack.InteractionTypeViewSubmission, approve)))
+}
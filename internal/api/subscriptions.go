@@ -0,0 +1,206 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp-forge/hermes/internal/config"
+	"github.com/hashicorp-forge/hermes/internal/email"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/gorm"
+)
+
+// notifySubscribers emails users subscribed to any of the events of document
+// docObj using notification channel n, except the user that made the request.
+// Users subscribed to the document's product are notified of publication.
+// Notifications for users in digest mode are queued for their next digest
+// email instead. Errors are logged instead of returned because the change that
+// caused the events has already been saved.
+func notifySubscribers(
+	cfg *config.Config,
+	l hclog.Logger,
+	db *gorm.DB,
+	n notify.Channel,
+	r *http.Request,
+	docObj hcd.Doc,
+	events ...models.SubscriptionEventType,
+) {
+	if cfg.Email == nil || !cfg.Email.Enabled || len(events) == 0 {
+		return
+	}
+	docID := docObj.GetObjectID()
+
+	subscribers, err := documentSubscribers(db, docObj, events...)
+	if err != nil {
+		l.Error("error finding document subscribers",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return
+	}
+	userEmail := r.Context().Value("userEmail").(string)
+
+	docURL, err := getDocumentURL(cfg.BaseURL, docID)
+	if err != nil {
+		l.Error("error getting document URL",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return
+	}
+	var owner string
+	if len(docObj.GetOwners()) > 0 {
+		owner = docObj.GetOwners()[0]
+	}
+
+	published := false
+	for _, e := range events {
+		if e == models.PublishedSubscriptionEvent {
+			published = true
+		}
+	}
+	event := events[0]
+	if published {
+		event = models.PublishedSubscriptionEvent
+	}
+
+	frequencies, err := models.GetDigestFrequencies(db, subscribers)
+	if err != nil {
+		l.Error("error getting subscriber digest frequencies",
+			"error", err,
+			"doc_id", docID,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		return
+	}
+
+	for _, subscriber := range subscribers {
+		if strings.EqualFold(subscriber, userEmail) {
+			continue
+		}
+
+		if frequencies[strings.ToLower(subscriber)].IsDigest() {
+			qn := models.QueuedNotification{
+				User:           models.User{EmailAddress: subscriber},
+				Event:          event,
+				GoogleFileID:   docID,
+				DocumentNumber: docObj.GetDocNumber(),
+				DocumentTitle:  docObj.GetTitle(),
+				DocumentType:   docObj.GetDocType(),
+				DocumentURL:    docURL,
+				Owner:          owner,
+				Product:        docObj.GetProduct(),
+				Team:           docObj.GetTeam(),
+			}
+			if err := qn.Create(db); err != nil {
+				l.Error("error queueing subscriber notification",
+					"error", err,
+					"doc_id", docID,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"subscriber", subscriber,
+				)
+			}
+			continue
+		}
+
+		var e *email.Email
+		if published {
+			e, err = email.NewSubscriberDocumentPublishedEmail(
+				email.SubscriberDocumentPublishedEmailData{
+					BaseURL:           cfg.BaseURL,
+					DocumentOwner:     owner,
+					DocumentShortName: docObj.GetDocNumber(),
+					DocumentTitle:     docObj.GetTitle(),
+					DocumentType:      docObj.GetDocType(),
+					DocumentURL:       docURL,
+					Product:           docObj.GetProduct(),
+					Team:              docObj.GetTeam(),
+				},
+			)
+		} else {
+			e, err = email.NewSubscriberDocumentEventEmail(
+				email.SubscriberDocumentEventEmailData{
+					BaseURL:           cfg.BaseURL,
+					DocumentOwner:     owner,
+					DocumentShortName: docObj.GetDocNumber(),
+					DocumentTitle:     docObj.GetTitle(),
+					DocumentURL:       docURL,
+					Event:             string(event),
+				},
+			)
+		}
+		if err == nil {
+			m := notify.Message{
+				To:       []string{subscriber},
+				Subject:  e.Subject,
+				HTMLBody: e.Body,
+			}
+			if published {
+				m.Event = models.DocumentPublishedNotificationEvent
+			}
+			err = n.Send(m)
+		}
+		if err != nil {
+			l.Error("error sending subscriber email",
+				"error", err,
+				"doc_id", docID,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"subscriber", subscriber,
+			)
+			continue
+		}
+		l.Info("doc subscriber email sent",
+			"doc_id", docID,
+			"events", events,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+	}
+}
+
+// documentSubscribers returns the email addresses of users subscribed to any
+// of the events of document docObj.
+func documentSubscribers(
+	db *gorm.DB, docObj hcd.Doc, events ...models.SubscriptionEventType,
+) ([]string, error) {
+	subscribers, err := models.FindSubscribers(db, models.SubscriptionDocument{
+		DocumentType: docObj.GetDocType(),
+		GoogleFileID: docObj.GetObjectID(),
+		Project:      docObj.GetProject(),
+		Team:         docObj.GetTeam(),
+	}, events...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding subscriptions: %w", err)
+	}
+
+	// Product subscriptions only notify users of publication.
+	for _, e := range events {
+		if e != models.PublishedSubscriptionEvent {
+			continue
+		}
+		p := models.Product{
+			Name: docObj.GetProduct(),
+		}
+		if err := p.Get(db); err != nil {
+			return nil, fmt.Errorf("error getting product: %w", err)
+		}
+		for _, u := range p.UserSubscribers {
+			if !containsFold(subscribers, u.EmailAddress) {
+				subscribers = append(subscribers, u.EmailAddress)
+			}
+		}
+	}
+
+	return subscribers, nil
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 
 	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
 	"github.com/hashicorp-forge/hermes/pkg/models"
 	"github.com/hashicorp/go-hclog"
 	"gorm.io/gorm"
@@ -25,8 +24,8 @@ type TeamData struct {
 }
 
 // TeamsHandler returns the product mappings to the Hermes frontend.
-func TeamsHandler(cfg *config.Config, ar *algolia.Client,
-	aw *algolia.Client, db *gorm.DB, log hclog.Logger) http.Handler {
+func TeamsHandler(
+	cfg *config.Config, db *gorm.DB, log hclog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		switch r.Method {
@@ -40,8 +39,14 @@ func TeamsHandler(cfg *config.Config, ar *algolia.Client,
 				return
 			}
 
+			// Only admins of the team's product can create teams.
+			if !authorizeAdminFor(
+				w, r, log, db, models.ProductRoleScope, req.TeamBU) {
+				return
+			}
+
 			// Add the data to both algolia and the Postgres Database
-			err := AddNewTeams(ar, aw, db, req)
+			err := AddNewTeams(db, req)
 			if err != nil {
 				log.Error("error inserting new product/Business Unit", "error", err)
 				http.Error(w, "Error inserting products",
@@ -130,8 +135,7 @@ func getTeamsData(db *gorm.DB) (map[string]TeamData, error) {
 
 // AddNewTeams This helper function adds the newly added product and upserts it
 // in the postgres Database
-func AddNewTeams(ar *algolia.Client,
-	aw *algolia.Client, db *gorm.DB, req TeamRequest) error {
+func AddNewTeams(db *gorm.DB, req TeamRequest) error {
 	pm := models.Team{
 		Name: req.TeamName,
 	}
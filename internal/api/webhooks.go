@@ -0,0 +1,400 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/internal/webhooks"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maxWebhookDeliveriesLimit is the maximum number of webhook deliveries
+// returned in a single response.
+const maxWebhookDeliveriesLimit = 100
+
+// WebhookRequest is a request to create or update a webhook. Fields that are
+// not set are not updated.
+type WebhookRequest struct {
+	URL           *string                    `json:"url,omitempty"`
+	Secret        *string                    `json:"secret,omitempty"`
+	Description   *string                    `json:"description,omitempty"`
+	Disabled      *bool                      `json:"disabled,omitempty"`
+	Events        *[]models.WebhookEventType `json:"events,omitempty"`
+	Products      *[]string                  `json:"products,omitempty"`
+	Teams         *[]string                  `json:"teams,omitempty"`
+	DocumentTypes *[]string                  `json:"documentTypes,omitempty"`
+}
+
+// WebhookResponse is a webhook returned by the webhook API endpoints. The
+// secret is only returned when a webhook is created.
+type WebhookResponse struct {
+	ID            uint                      `json:"id"`
+	CreatedTime   time.Time                 `json:"createdTime"`
+	ModifiedTime  time.Time                 `json:"modifiedTime"`
+	URL           string                    `json:"url"`
+	Secret        string                    `json:"secret,omitempty"`
+	Description   string                    `json:"description"`
+	Disabled      bool                      `json:"disabled"`
+	Events        []models.WebhookEventType `json:"events"`
+	Products      []string                  `json:"products"`
+	Teams         []string                  `json:"teams"`
+	DocumentTypes []string                  `json:"documentTypes"`
+}
+
+// WebhookDeliveryResponse is a webhook delivery returned by the webhook API
+// endpoints.
+type WebhookDeliveryResponse struct {
+	ID               uint                    `json:"id"`
+	CreatedTime      time.Time               `json:"createdTime"`
+	Event            models.WebhookEventType `json:"event"`
+	Status           string                  `json:"status"`
+	Attempts         int                     `json:"attempts"`
+	NextAttemptTime  *time.Time              `json:"nextAttemptTime,omitempty"`
+	LastError        string                  `json:"lastError,omitempty"`
+	LastResponseCode int                     `json:"lastResponseCode,omitempty"`
+	DeliveredTime    *time.Time              `json:"deliveredTime,omitempty"`
+	Payload          json.RawMessage         `json:"payload"`
+}
+
+// WebhooksHandler handles requests to list and create webhooks. Only admins can
+// manage webhooks.
+func WebhooksHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Authorize request.
+		if !authorizeAdmin(w, r, l, db) {
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			var whs models.Webhooks
+			if err := whs.Find(db); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error getting webhooks",
+					"error finding webhooks", err)
+				return
+			}
+
+			resp := make([]WebhookResponse, len(whs))
+			for i, wh := range whs {
+				resp[i] = newWebhookResponse(wh, false)
+			}
+			respondJSON(w, r, l, http.StatusOK, resp)
+
+		case "POST":
+			var req WebhookRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding webhook request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			wh := models.Webhook{}
+			if req.Secret == nil || *req.Secret == "" {
+				secret, err := webhooks.NewSecret()
+				if err != nil {
+					respondError(w, r, l, http.StatusInternalServerError,
+						"Error creating webhook",
+						"error generating webhook secret", err)
+					return
+				}
+				req.Secret = &secret
+			}
+			if err := applyWebhookRequest(db, &wh, req); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			if err := wh.Create(db); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			l.Info("created webhook",
+				"webhook_id", wh.ID,
+				"url", wh.URL,
+			)
+
+			// Return the secret once so it can be configured by the receiver.
+			respondJSON(w, r, l, http.StatusCreated, newWebhookResponse(wh, true))
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+	})
+}
+
+// WebhookHandler handles requests to get, update, and delete a webhook at
+// "/api/v1/webhooks/{id}", and to list its recent deliveries at
+// "/api/v1/webhooks/{id}/deliveries". Only admins can manage webhooks.
+func WebhookHandler(l hclog.Logger, db *gorm.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Authorize request.
+		if !authorizeAdmin(w, r, l, db) {
+			return
+		}
+
+		// Parse webhook ID and optional subresource from the URL path.
+		path := strings.Trim(
+			strings.TrimPrefix(r.URL.Path, "/api/v1/webhooks/"), "/")
+		parts := strings.Split(path, "/")
+		if len(parts) > 2 || (len(parts) == 2 && parts[1] != "deliveries") {
+			http.Error(w, "Resource not found", http.StatusNotFound)
+			return
+		}
+		id, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil || id == 0 {
+			http.Error(w, "Bad request: invalid webhook ID",
+				http.StatusBadRequest)
+			return
+		}
+
+		wh := models.Webhook{}
+		wh.ID = uint(id)
+		if err := wh.Get(db); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				http.Error(w, "Webhook not found", http.StatusNotFound)
+				return
+			}
+			respondError(w, r, l, http.StatusInternalServerError,
+				"Error getting webhook",
+				"error getting webhook", err,
+				"webhook_id", id,
+			)
+			return
+		}
+
+		if len(parts) == 2 {
+			serveWebhookDeliveries(w, r, l, db, wh)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			respondJSON(w, r, l, http.StatusOK, newWebhookResponse(wh, false))
+
+		case "PATCH":
+			var req WebhookRequest
+			if err := decodeRequest(r, &req); err != nil {
+				l.Error("error decoding webhook request", "error", err)
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+			if err := applyWebhookRequest(db, &wh, req); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			if err := wh.Update(db); err != nil {
+				http.Error(w, fmt.Sprintf("Bad request: %q", err),
+					http.StatusBadRequest)
+				return
+			}
+
+			l.Info("updated webhook", "webhook_id", wh.ID)
+			respondJSON(w, r, l, http.StatusOK, newWebhookResponse(wh, false))
+
+		case "DELETE":
+			if err := wh.Delete(db); err != nil {
+				respondError(w, r, l, http.StatusInternalServerError,
+					"Error deleting webhook",
+					"error deleting webhook", err,
+					"webhook_id", id,
+				)
+				return
+			}
+
+			l.Info("deleted webhook", "webhook_id", wh.ID)
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+	})
+}
+
+// serveWebhookDeliveries responds with the most recent deliveries of webhook
+// wh.
+func serveWebhookDeliveries(
+	w http.ResponseWriter,
+	r *http.Request,
+	l hclog.Logger,
+	db *gorm.DB,
+	wh models.Webhook,
+) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var dels models.WebhookDeliveries
+	if err := dels.FindByWebhook(
+		db, wh.ID, maxWebhookDeliveriesLimit); err != nil {
+		respondError(w, r, l, http.StatusInternalServerError,
+			"Error getting webhook deliveries",
+			"error finding webhook deliveries", err,
+			"webhook_id", wh.ID,
+		)
+		return
+	}
+
+	resp := make([]WebhookDeliveryResponse, len(dels))
+	for i, d := range dels {
+		resp[i] = WebhookDeliveryResponse{
+			ID:               d.ID,
+			CreatedTime:      d.CreatedAt,
+			Event:            d.Event,
+			Status:           webhookDeliveryStatusString(d.Status),
+			Attempts:         d.Attempts,
+			LastError:        d.LastError,
+			LastResponseCode: d.LastResponseCode,
+			DeliveredTime:    d.DeliveredAt,
+			Payload:          json.RawMessage(d.Payload),
+		}
+		if d.Status == models.PendingWebhookDeliveryStatus {
+			next := d.NextAttemptAt
+			resp[i].NextAttemptTime = &next
+		}
+	}
+	respondJSON(w, r, l, http.StatusOK, resp)
+}
+
+// enqueueWebhookEvent enqueues delivery of an event of type t for document doc
+// to subscribed webhooks. Call it in the same database transaction as the
+// change that caused the event.
+func enqueueWebhookEvent(
+	db *gorm.DB, r *http.Request, baseURL string,
+	t models.WebhookEventType, doc hcd.Doc,
+) error {
+	docURL, err := getDocumentURL(baseURL, doc.GetObjectID())
+	if err != nil {
+		return fmt.Errorf("error getting document URL: %w", err)
+	}
+
+	return webhooks.Enqueue(db, webhooks.Event{
+		Type:      t,
+		Timestamp: time.Now().UTC(),
+		Actor:     r.Context().Value("userEmail").(string),
+		Document: webhooks.Document{
+			ID:        doc.GetObjectID(),
+			DocNumber: doc.GetDocNumber(),
+			DocType:   doc.GetDocType(),
+			Owners:    doc.GetOwners(),
+			Product:   doc.GetProduct(),
+			Reviewers: doc.GetReviewers(),
+			Status:    doc.GetStatus(),
+			Team:      doc.GetTeam(),
+			Title:     doc.GetTitle(),
+			URL:       docURL,
+		},
+	})
+}
+
+// applyWebhookRequest applies the fields set in request req to webhook wh. An
+// error is returned if a product or team in the request doesn't exist.
+func applyWebhookRequest(
+	db *gorm.DB, wh *models.Webhook, req WebhookRequest) error {
+	if req.URL != nil {
+		wh.URL = *req.URL
+	}
+	if req.Secret != nil && *req.Secret != "" {
+		wh.Secret = *req.Secret
+	}
+	if req.Description != nil {
+		wh.Description = *req.Description
+	}
+	if req.Disabled != nil {
+		wh.Disabled = *req.Disabled
+	}
+	if req.Events != nil {
+		wh.Events = datatypes.JSONType[[]models.WebhookEventType]{
+			Data: *req.Events}
+	}
+	if req.Products != nil {
+		for _, name := range *req.Products {
+			p := models.Product{Name: name}
+			if err := p.Get(db); err != nil {
+				return fmt.Errorf("product %q not found", name)
+			}
+		}
+		wh.Products = datatypes.JSONType[[]string]{Data: *req.Products}
+	}
+	if req.Teams != nil {
+		for _, name := range *req.Teams {
+			t := models.Team{Name: name}
+			if err := t.Get(db); err != nil {
+				return fmt.Errorf("team %q not found", name)
+			}
+		}
+		wh.Teams = datatypes.JSONType[[]string]{Data: *req.Teams}
+	}
+	if req.DocumentTypes != nil {
+		wh.DocumentTypes = datatypes.JSONType[[]string]{
+			Data: *req.DocumentTypes}
+	}
+	return nil
+}
+
+// newWebhookResponse returns the response for webhook wh, including the secret
+// if withSecret is true.
+func newWebhookResponse(wh models.Webhook, withSecret bool) WebhookResponse {
+	resp := WebhookResponse{
+		ID:            wh.ID,
+		CreatedTime:   wh.CreatedAt,
+		ModifiedTime:  wh.UpdatedAt,
+		URL:           wh.URL,
+		Description:   wh.Description,
+		Disabled:      wh.Disabled,
+		Events:        wh.Events.Data,
+		Products:      wh.Products.Data,
+		Teams:         wh.Teams.Data,
+		DocumentTypes: wh.DocumentTypes.Data,
+	}
+	if withSecret {
+		resp.Secret = wh.Secret
+	}
+	if resp.Events == nil {
+		resp.Events = []models.WebhookEventType{}
+	}
+	if resp.Products == nil {
+		resp.Products = []string{}
+	}
+	if resp.Teams == nil {
+		resp.Teams = []string{}
+	}
+	if resp.DocumentTypes == nil {
+		resp.DocumentTypes = []string{}
+	}
+	return resp
+}
+
+// webhookDeliveryStatusString returns the API representation of webhook
+// delivery status s.
+func webhookDeliveryStatusString(s models.WebhookDeliveryStatus) string {
+	switch s {
+	case models.PendingWebhookDeliveryStatus:
+		return "pending"
+	case models.SucceededWebhookDeliveryStatus:
+		return "succeeded"
+	case models.FailedWebhookDeliveryStatus:
+		return "failed"
+	default:
+		return "unspecified"
+	}
+}
@@ -0,0 +1,131 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/gorm"
+)
+
+const (
+	// bearerPrefix is the prefix of the Authorization header value for bearer
+	// tokens.
+	bearerPrefix = "Bearer "
+
+	// scopeContextKey is the request context key of the authenticating API
+	// token's scope.
+	scopeContextKey = "apiTokenScope"
+
+	// tokensPath is the path of the API endpoints used to manage API tokens,
+	// which can't be accessed using API tokens.
+	tokensPath = "/api/v1/me/tokens"
+)
+
+// HasBearerToken returns true if the request has a bearer token in the
+// Authorization header.
+func HasBearerToken(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Authorization"), bearerPrefix)
+}
+
+// ScopeFromContext returns the scope of the API token that authenticated the
+// request with context ctx. The second return value is false if the request
+// wasn't authenticated using an API token.
+func ScopeFromContext(ctx context.Context) (models.APITokenScope, bool) {
+	scope, ok := ctx.Value(scopeContextKey).(models.APITokenScope)
+	return scope, ok
+}
+
+// AuthenticateRequest is middleware that authenticates an HTTP request using
+// an API token in the Authorization header, and authorizes it using the token's
+// scope.
+func AuthenticateRequest(
+	db *gorm.DB, log hclog.Logger, next http.Handler,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tok := strings.TrimPrefix(r.Header.Get("Authorization"), bearerPrefix)
+		if !models.IsAPIToken(tok) {
+			log.Error("invalid API token format",
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var t models.APIToken
+		if err := t.GetByToken(db, tok); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Error("API token not found",
+					"method", r.Method,
+					"path", r.URL.Path,
+				)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			log.Error("error getting API token",
+				"error", err,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if !allowed(t.Scope, r.Method, r.URL.Path) {
+			log.Warn("request not allowed by API token scope",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"scope", t.Scope,
+				"token_id", t.ID,
+			)
+			http.Error(w,
+				"Forbidden: request not allowed by API token scope",
+				http.StatusForbidden)
+			return
+		}
+
+		// Recording the last use isn't critical, so only log errors.
+		if err := t.UpdateLastUsedAt(db, time.Now()); err != nil {
+			log.Warn("error updating API token last used time",
+				"error", err,
+				"token_id", t.ID,
+			)
+		}
+
+		// Set userEmail and the token scope in request context.
+		ctx := context.WithValue(r.Context(), "userEmail", t.User.EmailAddress)
+		ctx = context.WithValue(ctx, scopeContextKey, t.Scope)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowed returns true if an API token with scope is allowed to make a request
+// with method to path. Admin actions are additionally authorized by the API
+// handlers using ScopeFromContext.
+func allowed(scope models.APITokenScope, method, path string) bool {
+	// API tokens can't be used to create more API tokens.
+	if path == tokensPath || strings.HasPrefix(path, tokensPath+"/") {
+		return false
+	}
+
+	switch scope {
+	case models.AdminAPITokenScope:
+		return true
+	case models.DraftsAPITokenScope:
+		if path == "/api/v1/drafts" || strings.HasPrefix(path, "/api/v1/drafts/") {
+			return true
+		}
+	case models.ReadOnlyAPITokenScope:
+	default:
+		return false
+	}
+
+	return method == http.MethodGet || method == http.MethodHead
+}
@@ -0,0 +1,89 @@
+package apitoken
+
+import (
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowed(t *testing.T) {
+	cases := map[string]struct {
+		scope  models.APITokenScope
+		method string
+		path   string
+		want   bool
+	}{
+		"read-only GET": {
+			scope:  models.ReadOnlyAPITokenScope,
+			method: "GET",
+			path:   "/api/v1/documents/doc1",
+			want:   true,
+		},
+		"read-only PATCH": {
+			scope:  models.ReadOnlyAPITokenScope,
+			method: "PATCH",
+			path:   "/api/v1/documents/doc1",
+		},
+		"read-only draft POST": {
+			scope:  models.ReadOnlyAPITokenScope,
+			method: "POST",
+			path:   "/api/v1/drafts",
+		},
+		"drafts draft POST": {
+			scope:  models.DraftsAPITokenScope,
+			method: "POST",
+			path:   "/api/v1/drafts",
+			want:   true,
+		},
+		"drafts draft PATCH": {
+			scope:  models.DraftsAPITokenScope,
+			method: "PATCH",
+			path:   "/api/v1/drafts/doc1",
+			want:   true,
+		},
+		"drafts document GET": {
+			scope:  models.DraftsAPITokenScope,
+			method: "GET",
+			path:   "/api/v1/documents/doc1",
+			want:   true,
+		},
+		"drafts review POST": {
+			scope:  models.DraftsAPITokenScope,
+			method: "POST",
+			path:   "/api/v1/reviews/doc1",
+		},
+		"drafts path prefix": {
+			scope:  models.DraftsAPITokenScope,
+			method: "POST",
+			path:   "/api/v1/draftsx",
+		},
+		"admin POST": {
+			scope:  models.AdminAPITokenScope,
+			method: "POST",
+			path:   "/api/v1/products",
+			want:   true,
+		},
+		"admin tokens GET": {
+			scope:  models.AdminAPITokenScope,
+			method: "GET",
+			path:   "/api/v1/me/tokens",
+		},
+		"admin tokens DELETE": {
+			scope:  models.AdminAPITokenScope,
+			method: "DELETE",
+			path:   "/api/v1/me/tokens/1",
+		},
+		"unknown scope": {
+			scope:  "other",
+			method: "GET",
+			path:   "/api/v1/documents/doc1",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, allowed(c.scope, c.method, c.path))
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package apitoken implements authentication using personal API tokens sent as
+// bearer tokens in the Authorization header.
+package apitoken
@@ -3,17 +3,64 @@ package auth
 import (
 	"net/http"
 
+	"github.com/hashicorp-forge/hermes/internal/auth/apitoken"
 	"github.com/hashicorp-forge/hermes/internal/auth/google"
+	"github.com/hashicorp-forge/hermes/internal/auth/oidc"
 	"github.com/hashicorp-forge/hermes/internal/auth/oktaalb"
 	"github.com/hashicorp-forge/hermes/internal/config"
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
 	"github.com/hashicorp/go-hclog"
+	"gorm.io/gorm"
 )
 
-// AuthenticateRequest is middleware that authenticates an HTTP request.
+// AuthenticateRequest is middleware that authenticates an HTTP request. Requests
+// with a bearer token are authenticated using API tokens, and other requests
+// using the configured authentication provider.
 func AuthenticateRequest(
+	cfg config.Config, gwSvc *gw.Service, db *gorm.DB, log hclog.Logger,
+	next http.Handler,
+) http.Handler {
+	tokenAuth := apitoken.AuthenticateRequest(db, log,
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			validateUserEmail(w, r, log)
+			next.ServeHTTP(w, r)
+		}))
+	providerAuth := authenticateProviderRequest(cfg, gwSvc, log, next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apitoken.HasBearerToken(r) {
+			tokenAuth.ServeHTTP(w, r)
+		} else {
+			providerAuth.ServeHTTP(w, r)
+		}
+	})
+}
+
+// authenticateProviderRequest is middleware that authenticates an HTTP request
+// using the configured authentication provider.
+func authenticateProviderRequest(
 	cfg config.Config, gwSvc *gw.Service, log hclog.Logger, next http.Handler,
 ) http.Handler {
+	// If OIDC is configured and isn't disabled, authenticate using OIDC.
+	if cfg.OIDC != nil && !cfg.OIDC.Disabled {
+		// Create OIDC authenticator.
+		oa, err := oidc.New(*cfg.OIDC, log)
+		if err != nil {
+			log.Error("error creating OIDC authenticator", "error", err)
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			})
+		}
+
+		// Return handler wrapped with OIDC auth.
+		return oa.EnforceAuth(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				validateUserEmail(w, r, log)
+				next.ServeHTTP(w, r)
+			}))
+	}
+
 	// If Okta isn't disabled, authenticate using Okta.
 	if cfg.Okta != nil && !cfg.Okta.Disabled {
 		// Create Okta authorizer.
@@ -34,6 +81,16 @@ func AuthenticateRequest(
 			}))
 	}
 
+	// Google authentication requires the Google Workspace service, which is
+	// only configured for the Google storage provider.
+	if gwSvc == nil {
+		log.Error("no authentication provider configured")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		})
+	}
+
 	// Authenticate using Google.
 	return google.AuthenticateRequest(gwSvc, log,
 		// Return handler wrapped with Google auth.
@@ -0,0 +1,4 @@
+// Package oidc implements authentication using a generic OpenID Connect
+// provider (e.g., Keycloak) with the authorization code flow and session
+// cookies.
+package oidc
@@ -0,0 +1,362 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIssuer is a mock OpenID provider.
+type mockIssuer struct {
+	*httptest.Server
+
+	key *rsa.PrivateKey
+
+	// claims are the claims of ID tokens issued by the token endpoint, in
+	// addition to the nonce.
+	claims jwt.MapClaims
+
+	// nonce is the nonce included in issued ID tokens.
+	nonce string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	m := &mockIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(providerMetadata{
+			Issuer:                m.URL,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JWKSURI:               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]jsonWebKey{
+			"keys": {{
+				Kty: "RSA",
+				Kid: "key1",
+				Use: "sig",
+				N: base64.RawURLEncoding.EncodeToString(
+					key.PublicKey.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(
+					big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("code") != "code1" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := jwt.MapClaims{"nonce": m.nonce}
+		for k, v := range m.claims {
+			claims[k] = v
+		}
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		tok.Header["kid"] = "key1"
+		idToken, err := tok.SignedString(m.key)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access1",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	m.Server = httptest.NewServer(mux)
+
+	return m
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	issuer := newMockIssuer(t)
+	defer issuer.Close()
+
+	a, err := New(Config{
+		ClientID:      "hermes",
+		ClientSecret:  "secret",
+		IssuerURL:     issuer.URL,
+		RedirectURL:   "http://hermes.example.com" + CallbackPath,
+		SessionSecret: "0123456789abcdef0123456789abcdef",
+	}, hclog.NewNullLogger())
+	require.NoError(t, err)
+	handler := a.Handler(nil)
+
+	// protected responds with the authenticated user's email address.
+	protected := a.EnforceAuth(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Context().Value("userEmail").(string)))
+		}))
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            issuer.URL,
+			"aud":            "hermes",
+			"sub":            "user1",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+			"email":          "user1@example.com",
+			"email_verified": true,
+		}
+	}
+
+	// login starts the flow and returns the state cookie and the authorization
+	// request query.
+	login := func(t *testing.T) (*http.Cookie, url.Values) {
+		require := require.New(t)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(
+			"GET", LoginPath+"?redirect=/document/doc1", nil))
+		require.Equal(http.StatusFound, rr.Code)
+
+		loc, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(err)
+		require.Equal(issuer.URL+"/authorize",
+			loc.Scheme+"://"+loc.Host+loc.Path)
+		q := loc.Query()
+		require.Equal("hermes", q.Get("client_id"))
+		require.Equal("code", q.Get("response_type"))
+		require.NotEmpty(q.Get("state"))
+		require.NotEmpty(q.Get("nonce"))
+
+		cookies := rr.Result().Cookies()
+		require.Len(cookies, 1)
+		require.Equal(stateCookieName, cookies[0].Name)
+		return cookies[0], q
+	}
+
+	// callback completes the flow and returns the response.
+	callback := func(
+		stateCookie *http.Cookie, state, code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", CallbackPath+"?"+url.Values{
+			"code":  {code},
+			"state": {state},
+		}.Encode(), nil)
+		req.AddCookie(stateCookie)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("unauthenticated API request", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		protected.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/me", nil))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("unauthenticated page request", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/document/doc1", nil)
+		req.Header.Set("Accept", "text/html")
+		rr := httptest.NewRecorder()
+		protected.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusFound, rr.Code)
+		assert.Equal(t, LoginPath+"?redirect=%2Fdocument%2Fdoc1",
+			rr.Header().Get("Location"))
+	})
+
+	t.Run("successful sign-in", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		stateCookie, q := login(t)
+		issuer.nonce = q.Get("nonce")
+		issuer.claims = validClaims()
+
+		rr := callback(stateCookie, q.Get("state"), "code1")
+		require.Equal(http.StatusFound, rr.Code, rr.Body.String())
+		assert.Equal("/document/doc1", rr.Header().Get("Location"))
+
+		var session *http.Cookie
+		for _, c := range rr.Result().Cookies() {
+			if c.Name == sessionCookieName {
+				session = c
+			}
+		}
+		require.NotNil(session)
+		assert.True(session.HttpOnly)
+
+		// Use the session.
+		req := httptest.NewRequest("GET", "/api/v1/me", nil)
+		req.AddCookie(session)
+		rr = httptest.NewRecorder()
+		protected.ServeHTTP(rr, req)
+		assert.Equal(http.StatusOK, rr.Code)
+		assert.Equal("user1@example.com", rr.Body.String())
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		stateCookie, q := login(t)
+		issuer.nonce = q.Get("nonce")
+		issuer.claims = validClaims()
+
+		rr := callback(stateCookie, "other", "code1")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		stateCookie, q := login(t)
+		issuer.nonce = q.Get("nonce")
+		issuer.claims = validClaims()
+
+		rr := callback(stateCookie, q.Get("state"), "other")
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("invalid ID tokens", func(t *testing.T) {
+		cases := map[string]func(jwt.MapClaims){
+			"wrong audience": func(c jwt.MapClaims) { c["aud"] = "other" },
+			"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://other" },
+			"expired": func(c jwt.MapClaims) {
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+			},
+			"no expiration":    func(c jwt.MapClaims) { delete(c, "exp") },
+			"no email":         func(c jwt.MapClaims) { delete(c, "email") },
+			"unverified email": func(c jwt.MapClaims) { c["email_verified"] = false },
+			"wrong nonce":      func(c jwt.MapClaims) { c["nonce"] = "other" },
+		}
+
+		for name, modify := range cases {
+			t.Run(name, func(t *testing.T) {
+				stateCookie, q := login(t)
+				issuer.nonce = q.Get("nonce")
+				issuer.claims = validClaims()
+				modify(issuer.claims)
+
+				rr := callback(stateCookie, q.Get("state"), "code1")
+				assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			})
+		}
+	})
+
+	t.Run("forged session", func(t *testing.T) {
+		tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, sessionClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				Issuer:    sessionIssuer,
+				Subject:   "admin@example.com",
+			},
+		}).SignedString([]byte("another secret that is long enough!"))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest("GET", "/api/v1/me", nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: tok})
+		rr := httptest.NewRecorder()
+		protected.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestIdentity(t *testing.T) {
+	a, err := New(Config{
+		AdminGroups:   []string{"hermes-admins"},
+		ClientID:      "hermes",
+		GroupsClaim:   "groups",
+		IssuerURL:     "https://issuer.example.com",
+		RedirectURL:   "https://hermes.example.com" + CallbackPath,
+		SessionSecret: "0123456789abcdef0123456789abcdef",
+	}, hclog.NewNullLogger())
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		claims    jwt.MapClaims
+		wantEmail string
+		wantAdmin bool
+		wantErr   bool
+	}{
+		"admin group": {
+			claims: jwt.MapClaims{
+				"email":  "a@example.com",
+				"groups": []interface{}{"engineering", "hermes-admins"},
+			},
+			wantEmail: "a@example.com",
+			wantAdmin: true,
+		},
+		"single group": {
+			claims: jwt.MapClaims{
+				"email":  "a@example.com",
+				"groups": "hermes-admins",
+			},
+			wantEmail: "a@example.com",
+			wantAdmin: true,
+		},
+		"other groups": {
+			claims: jwt.MapClaims{
+				"email":  "a@example.com",
+				"groups": []interface{}{"engineering"},
+			},
+			wantEmail: "a@example.com",
+		},
+		"no groups": {
+			claims: jwt.MapClaims{
+				"email": "a@example.com",
+			},
+			wantEmail: "a@example.com",
+		},
+		"no email": {
+			claims:  jwt.MapClaims{},
+			wantErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			email, groups, err := a.identity(c.claims)
+			if c.wantErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(c.wantEmail, email)
+			assert.Equal(c.wantAdmin, a.isAdmin(groups))
+		})
+	}
+}
+
+func TestIsOIDCGrant(t *testing.T) {
+	assert := assert.New(t)
+
+	grant := func(by string) models.RoleAssignment {
+		return models.RoleAssignment{
+			Scope:     models.GlobalRoleScope,
+			GrantedBy: by,
+		}
+	}
+	assert.True(isOIDCGrant(grant(oidcGrantedBy), "a@example.com"))
+	assert.True(isOIDCGrant(grant("A@example.com"), "a@example.com"))
+	assert.False(isOIDCGrant(grant("admin@example.com"), "a@example.com"))
+	assert.False(isOIDCGrant(grant(""), "a@example.com"))
+}
+
+func TestSanitizeRedirect(t *testing.T) {
+	cases := map[string]string{
+		"":                     "/",
+		"/document/doc1?x=1":   "/document/doc1?x=1",
+		"https://evil.example": "/",
+		"//evil.example":       "/",
+		"/\\evil.example":      "/",
+	}
+
+	for redirect, want := range cases {
+		assert.Equal(t, want, sanitizeRedirect(redirect), redirect)
+	}
+}
@@ -0,0 +1,225 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// discoveryPath is the path of the OpenID provider configuration document,
+	// relative to the issuer URL.
+	discoveryPath = "/.well-known/openid-configuration"
+
+	// minKeysRefreshInterval is the minimum time between fetches of the
+	// provider's JSON Web Key Set, which is refreshed when an ID token is signed
+	// with an unknown key.
+	minKeysRefreshInterval = time.Minute
+)
+
+// idTokenSigningMethods are the allowed ID token signing algorithms.
+var idTokenSigningMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+}
+
+// providerMetadata is the subset of the OpenID provider configuration used for
+// authentication.
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// jsonWebKey is a JSON Web Key as defined in RFC 7517.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC keys.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// getProvider returns the OpenID provider configuration, discovering it from
+// the issuer on first use.
+func (a *Authenticator) getProvider() (*providerMetadata, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.provider != nil {
+		return a.provider, nil
+	}
+
+	u := strings.TrimSuffix(a.cfg.IssuerURL, "/") + discoveryPath
+	var p providerMetadata
+	if err := a.getJSON(u, &p); err != nil {
+		return nil, fmt.Errorf("error getting provider configuration: %w", err)
+	}
+	if p.Issuer != a.cfg.IssuerURL {
+		return nil, fmt.Errorf(
+			"issuer %q in provider configuration doesn't match issuer URL %q",
+			p.Issuer, a.cfg.IssuerURL)
+	}
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" ||
+		p.JWKSURI == "" {
+		return nil, fmt.Errorf("incomplete provider configuration")
+	}
+
+	a.provider = &p
+	return a.provider, nil
+}
+
+// getKey returns the provider's public key with key ID kid, refreshing the
+// provider's JSON Web Key Set if the key isn't known.
+func (a *Authenticator) getKey(p *providerMetadata, kid string) (
+	interface{}, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if key, ok := a.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(a.keysFetchedAt) < minKeysRefreshInterval {
+		return nil, fmt.Errorf("unknown key ID %q", kid)
+	}
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := a.getJSON(p.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("error getting JSON Web Key Set: %w", err)
+	}
+	a.keysFetchedAt = time.Now()
+
+	a.keys = make(map[string]interface{}, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			a.log.Warn("skipping invalid JSON Web Key",
+				"error", err,
+				"kid", k.Kid,
+			)
+			continue
+		}
+		a.keys[k.Kid] = key
+	}
+
+	if key, ok := a.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// verifyIDToken verifies the signature and claims of ID token raw, and returns
+// its claims.
+func (a *Authenticator) verifyIDToken(
+	p *providerMetadata, raw, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return a.getKey(p, kid)
+		},
+		jwt.WithValidMethods(idTokenSigningMethods),
+		jwt.WithIssuer(p.Issuer),
+		jwt.WithAudience(a.cfg.ClientID),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("ID token has no expiration time")
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, fmt.Errorf("ID token nonce doesn't match")
+	}
+
+	return claims, nil
+}
+
+// getJSON gets URL u and decodes the JSON response body into v.
+func (a *Authenticator) getJSON(u string, v interface{}) error {
+	resp, err := a.httpClient.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// publicKey returns the public key of the JSON Web Key.
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding exponent: %w", err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
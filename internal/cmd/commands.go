@@ -22,6 +22,16 @@ func initCommands(log hclog.Logger, ui cli.Ui) {
 				Command: b,
 			}, nil
 		},
+		"indexer failures": func() (cli.Command, error) {
+			return &indexer.FailuresCommand{
+				Command: b,
+			}, nil
+		},
+		"indexer reconcile": func() (cli.Command, error) {
+			return &indexer.ReconcileCommand{
+				Command: b,
+			}, nil
+		},
 		"server": func() (cli.Command, error) {
 			return &server.Command{
 				Command: b,
@@ -0,0 +1,190 @@
+package indexer
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/internal/cmd/base"
+	"github.com/hashicorp-forge/hermes/internal/config"
+	"github.com/hashicorp-forge/hermes/internal/db"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+// FailuresCommand manages documents that the indexer failed to index.
+type FailuresCommand struct {
+	*base.Command
+
+	flagAll    bool
+	flagConfig string
+}
+
+func (c *FailuresCommand) Synopsis() string {
+	return "List, retry, or ignore documents that failed to index"
+}
+
+func (c *FailuresCommand) Help() string {
+	return `Usage: hermes indexer failures [options] [list | retry <file ID> | ignore <file ID>]
+
+This command manages documents that the indexer failed to index. Failed
+documents are retried with exponential backoff in later indexer runs.
+
+  list              List failed documents (default).
+  retry <file ID>   Retry a document in the next indexer run.
+  ignore <file ID>  Stop retrying a document.` + c.Flags().Help()
+}
+
+func (c *FailuresCommand) Flags() *base.FlagSet {
+	f := base.NewFlagSet(flag.NewFlagSet("indexer failures", flag.ExitOnError))
+
+	f.BoolVar(
+		&c.flagAll, "all", false, "Include ignored documents when listing",
+	)
+	f.StringVar(
+		&c.flagConfig, "config", "", "Path to config file",
+	)
+	return f
+}
+
+func (c *FailuresCommand) Run(args []string) int {
+	ui := c.UI
+
+	// Parse flags.
+	f := c.Flags()
+	if err := f.Parse(args); err != nil {
+		ui.Error(fmt.Sprintf("error parsing flags: %v", err))
+		return 1
+	}
+	if c.flagConfig == "" {
+		ui.Error("error parsing flags: config argument is required")
+		return 1
+	}
+
+	action, fileID := "list", ""
+	switch args := f.Args(); len(args) {
+	case 0:
+	case 1:
+		action = args[0]
+	case 2:
+		action, fileID = args[0], args[1]
+	default:
+		ui.Error("too many arguments")
+		return 1
+	}
+
+	db, err := newDatabase(c.flagConfig)
+	if err != nil {
+		ui.Error(err.Error())
+		return 1
+	}
+
+	switch action {
+	case "list":
+		return c.list(db)
+
+	case "retry", "ignore":
+		if fileID == "" {
+			ui.Error(fmt.Sprintf("file ID is required to %s a document", action))
+			return 1
+		}
+
+		fail := models.IndexerFailure{GoogleFileID: fileID}
+		if action == "retry" {
+			err = fail.Retry(db, time.Now().UTC())
+		} else {
+			err = fail.Ignore(db)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ui.Error(fmt.Sprintf("no indexer failure found for file %q", fileID))
+			return 1
+		} else if err != nil {
+			ui.Error(fmt.Sprintf("error updating indexer failure: %v", err))
+			return 1
+		}
+
+		if action == "retry" {
+			ui.Info(fmt.Sprintf(
+				"document %q will be retried in the next indexer run", fileID))
+		} else {
+			ui.Info(fmt.Sprintf("document %q will no longer be retried", fileID))
+		}
+		return 0
+
+	default:
+		ui.Error(fmt.Sprintf("unknown action %q", action))
+		return 1
+	}
+}
+
+// list lists indexer failures.
+func (c *FailuresCommand) list(db *gorm.DB) int {
+	var fs models.IndexerFailures
+	if err := fs.Find(db, c.flagAll); err != nil {
+		c.UI.Error(fmt.Sprintf("error finding indexer failures: %v", err))
+		return 1
+	}
+	if len(fs) == 0 {
+		c.UI.Info("no indexer failures")
+		return 0
+	}
+
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE ID\tATTEMPTS\tLAST ATTEMPT\tNEXT ATTEMPT\tLAST ERROR")
+	for _, f := range fs {
+		next := f.NextAttemptAt.UTC().Format(time.RFC3339)
+		if f.Ignored {
+			next = "ignored"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n",
+			f.GoogleFileID,
+			f.Attempts,
+			f.LastAttemptAt.UTC().Format(time.RFC3339),
+			next,
+			f.LastError,
+		)
+	}
+	tw.Flush()
+	c.UI.Output(strings.TrimSuffix(b.String(), "\n"))
+
+	return 0
+}
+
+// newDatabase returns a database connection using the configuration file at
+// path configPath. Postgres credentials are read from the environment, if set.
+func newDatabase(configPath string) (*gorm.DB, error) {
+	cfg, err := config.NewConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing configuration file: %w", err)
+	}
+
+	if cfg.Postgres == nil {
+		cfg.Postgres = &config.Postgres{}
+	}
+
+	// Load environment variables from a .env file, if it exists.
+	_ = godotenv.Load()
+
+	for env, val := range map[string]*string{
+		"POSTGRES_DBNAME":   &cfg.Postgres.DBName,
+		"POSTGRES_HOST":     &cfg.Postgres.Host,
+		"POSTGRES_PASSWORD": &cfg.Postgres.Password,
+		"POSTGRES_USER":     &cfg.Postgres.User,
+	} {
+		if v, ok := os.LookupEnv(env); ok {
+			*val = v
+		}
+	}
+
+	db, err := db.NewDB(*cfg.Postgres)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing database: %w", err)
+	}
+	return db, nil
+}
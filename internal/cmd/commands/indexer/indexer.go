@@ -3,17 +3,26 @@ package indexer
 import (
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
 	"github.com/hashicorp-forge/hermes/internal/cmd/base"
 	"github.com/hashicorp-forge/hermes/internal/config"
 	"github.com/hashicorp-forge/hermes/internal/db"
 	"github.com/hashicorp-forge/hermes/internal/indexer"
+	"github.com/hashicorp-forge/hermes/internal/notify"
 	"github.com/hashicorp-forge/hermes/pkg/algolia"
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
+	"github.com/hashicorp/go-hclog"
 	"github.com/joho/godotenv"
+	"github.com/mitchellh/cli"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Command struct {
@@ -32,8 +41,8 @@ func (c *Command) Help() string {
 
 This command runs the indexer.
 
-The indexer is a background process that indexes documents in Algolia, refreshes
-document headers, sends notifications, etc.` + c.Flags().Help()
+The indexer is a background process that indexes documents in the configured
+search provider, refreshes document headers, sends notifications, etc.` + c.Flags().Help()
 }
 
 func (c *Command) Flags() *base.FlagSet {
@@ -71,11 +80,55 @@ func (c *Command) Run(args []string) int {
 		return 1
 	}
 
+	cfg, idx, ok := newIndexer(ui, log, c.flagConfig)
+	if !ok {
+		return 1
+	}
+
+	// Serve metrics, health, and Drive push notifications.
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsMux.Handle(indexer.HealthPath, idx.HealthHandler())
+	metricsMux.Handle(indexer.NotificationsPath, idx.NotificationHandler())
+	metricsServer := &http.Server{
+		Addr:    cfg.Indexer.MetricsAddr,
+		Handler: metricsMux,
+	}
+	go func() {
+		log.Info("serving metrics, health, and Drive push notifications",
+			"address", cfg.Indexer.MetricsAddr)
+		if err := metricsServer.ListenAndServe(); err != nil &&
+			err != http.ErrServerClosed {
+			log.Error("error serving metrics", "error", err)
+		}
+	}()
+
+	ui.Info("starting indexer...")
+	go func() int {
+		if err := idx.Run(); err != nil {
+			ui.Error(err.Error())
+			// TODO: get this return value from indexer.Run().
+			return 1
+		}
+		return 0
+	}()
+	return c.WaitForInterrupt(func() {
+		idx.ReleaseLeadership()
+		metricsServer.Close()
+	})
+}
+
+// newIndexer parses the configuration file at path configPath, reads
+// credentials from the environment, and returns the configuration and a new
+// indexer. Errors are output to ui.
+func newIndexer(
+	ui cli.Ui, log hclog.Logger, configPath string,
+) (*config.Config, *indexer.Indexer, bool) {
 	// Parse configuration file.
-	cfg, err := config.NewConfig(c.flagConfig)
+	cfg, err := config.NewConfig(configPath)
 	if err != nil {
 		ui.Error(fmt.Sprintf("error parsing configuration file: %v", err))
-		return 1
+		return nil, nil, false
 	}
 
 	/* Remove this just for explicitly setting up the env variables*/
@@ -96,114 +149,129 @@ func (c *Command) Run(args []string) int {
 	//fmt.Println(os.Getenv("POSTGRES_USER"))
 
 	// Get the sensitive details if present in the environment
-	if val, ok := os.LookupEnv("ALGOLIA_APPLICATION_ID"); ok {
-		cfg.Algolia.ApplicationID = val
-	} else {
-		c.UI.Error("ALGOLIA_APPLICATION_ID must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("ALGOLIA_SEARCH_API_KEY"); ok {
-		cfg.Algolia.SearchAPIKey = val
-	} else {
-		c.UI.Error("ALGOLIA_SEARCH_API_KEY must be provided as an env variable!")
-		return 1
-	}
+	// Algolia credentials are only required by the Algolia search provider.
+	if cfg.Search.Provider == search.ProviderAlgolia {
+		if val, ok := os.LookupEnv("ALGOLIA_APPLICATION_ID"); ok {
+			cfg.Algolia.ApplicationID = val
+		} else {
+			ui.Error("ALGOLIA_APPLICATION_ID must be provided as an env variable!")
+			return nil, nil, false
+		}
+		if val, ok := os.LookupEnv("ALGOLIA_SEARCH_API_KEY"); ok {
+			cfg.Algolia.SearchAPIKey = val
+		} else {
+			ui.Error("ALGOLIA_SEARCH_API_KEY must be provided as an env variable!")
+			return nil, nil, false
+		}
 
-	if val, ok := os.LookupEnv("ALGOLIA_WRITE_API_KEY"); ok {
-		cfg.Algolia.WriteAPIKey = val
-	} else {
-		c.UI.Error("ALGOLIA_SEARCH_API_KEY must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_CLIENT_ID"); ok {
-		cfg.GoogleWorkspace.OAuth2.ClientID = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_CLIENT_ID must be provided as an env variable!")
-		return 1
+		if val, ok := os.LookupEnv("ALGOLIA_WRITE_API_KEY"); ok {
+			cfg.Algolia.WriteAPIKey = val
+		} else {
+			ui.Error("ALGOLIA_SEARCH_API_KEY must be provided as an env variable!")
+			return nil, nil, false
+		}
 	}
+	// Google Workspace credentials are only required by the Google storage
+	// provider.
+	if cfg.Storage.Provider == storage.ProviderGoogle {
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_CLIENT_ID"); ok {
+			cfg.GoogleWorkspace.OAuth2.ClientID = val
+		} else {
+			ui.Error("GOOGLE_WORKSPACE_OAUTH2_CLIENT_ID must be provided as an env variable!")
+			return nil, nil, false
+		}
 
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_HD"); ok {
-		cfg.GoogleWorkspace.OAuth2.HD = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_HD must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_REDIRECT_URI"); ok {
-		cfg.GoogleWorkspace.OAuth2.RedirectURI = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_REDIRECT_URI must be provided as an env variable!")
-		return 1
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_HD"); ok {
+			cfg.GoogleWorkspace.OAuth2.HD = val
+		} else {
+			ui.Error("GOOGLE_WORKSPACE_OAUTH2_HD must be provided as an env variable!")
+			return nil, nil, false
+		}
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_REDIRECT_URI"); ok {
+			cfg.GoogleWorkspace.OAuth2.RedirectURI = val
+		} else {
+			ui.Error("GOOGLE_WORKSPACE_OAUTH2_REDIRECT_URI must be provided as an env variable!")
+			return nil, nil, false
+		}
 	}
 	if val, ok := os.LookupEnv("POSTGRES_PASSWORD"); ok {
 		cfg.Postgres.Password = val
 	} else {
-		c.UI.Error("POSTGRES_PASSWORD must be provided as an env variable!")
-		return 1
+		ui.Error("POSTGRES_PASSWORD must be provided as an env variable!")
+		return nil, nil, false
 	}
 
 	if val, ok := os.LookupEnv("POSTGRES_USER"); ok {
 		cfg.Postgres.User = val
 	} else {
-		c.UI.Error("POSTGRES_USER_Name must be provided as an env variable!")
-		return 1
+		ui.Error("POSTGRES_USER_Name must be provided as an env variable!")
+		return nil, nil, false
 	}
 
 	if val, ok := os.LookupEnv("POSTGRES_DBNAME"); ok {
 		cfg.Postgres.DBName = val
 	} else {
-		c.UI.Error("POSTGRES_dbname must be provided as an env variable!")
-		return 1
+		ui.Error("POSTGRES_dbname must be provided as an env variable!")
+		return nil, nil, false
 	}
 	if val, ok := os.LookupEnv("POSTGRES_HOST"); ok {
 		cfg.Postgres.Host = val
 	} else {
-		c.UI.Error("POSTGRES_host must be provided as an env variable!")
-		return 1
+		ui.Error("POSTGRES_host must be provided as an env variable!")
+		return nil, nil, false
 	}
 
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_CLIENT_EMAIL"); ok {
-		cfg.GoogleWorkspace.Auth.ClientEmail = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_AUTH_CLIENT_EMAIL must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_PRIVATE_KEY"); ok {
-		cfg.GoogleWorkspace.Auth.PrivateKey = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_AUTH_PRIVATE_KEY must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_SUBJECT"); ok {
-		cfg.GoogleWorkspace.Auth.Subject = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_AUTH_SUBJECT must be provided as an env variable!")
-		return 1
-	}
+	// Google Workspace credentials are only required by the Google storage
+	// provider.
+	if cfg.Storage.Provider == storage.ProviderGoogle {
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_CLIENT_EMAIL"); ok {
+			cfg.GoogleWorkspace.Auth.ClientEmail = val
+		} else {
+			ui.Error("GOOGLE_WORKSPACE_AUTH_CLIENT_EMAIL must be provided as an env variable!")
+			return nil, nil, false
+		}
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_PRIVATE_KEY"); ok {
+			cfg.GoogleWorkspace.Auth.PrivateKey = val
+		} else {
+			ui.Error("GOOGLE_WORKSPACE_AUTH_PRIVATE_KEY must be provided as an env variable!")
+			return nil, nil, false
+		}
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_SUBJECT"); ok {
+			cfg.GoogleWorkspace.Auth.Subject = val
+		} else {
+			ui.Error("GOOGLE_WORKSPACE_AUTH_SUBJECT must be provided as an env variable!")
+			return nil, nil, false
+		}
 
+	}
 	// scanning doc folder drive ids
 	if val, ok := os.LookupEnv("DOCS_DRIVE_FOLDER_ID"); ok {
 		cfg.GoogleWorkspace.DocsFolder = val
 	} else {
-		c.UI.Error("DOCS_DRIVE_FOLDER_ID must be provided as an env variable!")
-		return 1
+		ui.Error("DOCS_DRIVE_FOLDER_ID must be provided as an env variable!")
+		return nil, nil, false
 	}
 	if val, ok := os.LookupEnv("DRAFTS_DRIVE_FOLDER_ID"); ok {
 		cfg.GoogleWorkspace.DraftsFolder = val
 	} else {
-		c.UI.Error("DRAFTS_DRIVE_FOLDER_ID must be provided as an env variable!")
-		return 1
+		ui.Error("DRAFTS_DRIVE_FOLDER_ID must be provided as an env variable!")
+		return nil, nil, false
 	}
 	if val, ok := os.LookupEnv("SHORTCUTS_DRIVE_FOLDER_ID"); ok {
 		cfg.GoogleWorkspace.ShortcutsFolder = val
 	} else {
-		c.UI.Error("SHORTCUTS_DRIVE_FOLDER_ID must be provided as an env variable!")
-		return 1
+		ui.Error("SHORTCUTS_DRIVE_FOLDER_ID must be provided as an env variable!")
+		return nil, nil, false
 	}
 	if val, ok := os.LookupEnv("EMAIL_FROM_ADDRESS"); ok {
 		cfg.Email.FromAddress = val
 	} else {
-		c.UI.Error("EMAIL_FROM_ADDRESS must be provided as an env variable!")
-		return 1
+		ui.Error("EMAIL_FROM_ADDRESS must be provided as an env variable!")
+		return nil, nil, false
+	}
+
+	if val, ok := os.LookupEnv("SMTP_PASSWORD"); ok {
+		cfg.Email.SMTP.Password = val
 	}
 
 	/* Scanned all env variables successfully */
@@ -212,35 +280,50 @@ func (c *Command) Run(args []string) int {
 	db, err := db.NewDB(*cfg.Postgres)
 	if err != nil {
 		ui.Error(fmt.Sprintf("error initializing database: %v", err))
-		return 1
+		return nil, nil, false
 	}
 
-	// Initialize Algolia client.
+	// Initialize search provider.
 	var algo *algolia.Client
-	algo, err = algolia.New(cfg.Algolia)
+	if cfg.Search.Provider == search.ProviderAlgolia {
+		algo, err = algolia.New(cfg.Algolia)
+		if err != nil {
+			ui.Error(fmt.Sprintf("error initializing Algolia: %v", err))
+			return nil, nil, false
+		}
+	}
+	sp, err := search.NewProvider(cfg.Search.Provider, algo, algo, db)
 	if err != nil {
-		c.UI.Error(fmt.Sprintf("error initializing Algolia: %v", err))
-		return 1
+		ui.Error(fmt.Sprintf("error initializing search provider: %v", err))
+		return nil, nil, false
 	}
 
-	// Initialize Google Workspace service.
+	// Initialize storage provider.
 	var goog *gw.Service
-	if cfg.GoogleWorkspace.Auth != nil {
-		// Use Google Workspace auth if it is defined in the config.
-		goog = gw.NewFromConfig(cfg.GoogleWorkspace.Auth)
-	} else {
-		// Use OAuth if Google Workspace auth is not defined in the config.
-		goog = gw.New()
+	if cfg.Storage.Provider == storage.ProviderGoogle {
+		if cfg.GoogleWorkspace.Auth != nil {
+			// Use Google Workspace auth if it is defined in the config.
+			goog = gw.NewFromConfig(cfg.GoogleWorkspace.Auth)
+		} else {
+			// Use OAuth if Google Workspace auth is not defined in the config.
+			goog = gw.New()
+		}
+	}
+	st, err := storage.NewProvider(
+		cfg.Storage.Provider, goog, cfg.Storage.LocalRoot)
+	if err != nil {
+		ui.Error(fmt.Sprintf("error initializing storage provider: %v", err))
+		return nil, nil, false
 	}
 
 	idxOpts := []indexer.IndexerOption{
-		indexer.WithAlgoliaClient(algo),
 		indexer.WithBaseURL(cfg.BaseURL),
 		indexer.WithDatabase(db),
 		indexer.WithDocumentsFolderID(cfg.GoogleWorkspace.DocsFolder),
 		indexer.WithDraftsFolderID(cfg.GoogleWorkspace.DraftsFolder),
-		indexer.WithGoogleWorkspaceService(goog),
 		indexer.WithLogger(log),
+		indexer.WithSearchProvider(sp),
+		indexer.WithStorageProvider(st),
 	}
 	if cfg.Indexer.MaxParallelDocs != 0 {
 		idxOpts = append(idxOpts,
@@ -254,20 +337,59 @@ func (c *Command) Run(args []string) int {
 		idxOpts = append(idxOpts,
 			indexer.WithUpdateDraftHeaders(true))
 	}
+	pollInterval, err := time.ParseDuration(cfg.Indexer.PollInterval)
+	if err != nil {
+		ui.Error(fmt.Sprintf("error parsing indexer poll interval: %v", err))
+		return nil, nil, false
+	}
+	idxOpts = append(idxOpts, indexer.WithPollInterval(pollInterval))
+	if cfg.Indexer.UseDriveChanges {
+		idxOpts = append(idxOpts,
+			indexer.WithUseDriveChanges(true))
+	}
+	if cfg.Indexer.DriveNotificationURL != "" {
+		idxOpts = append(idxOpts,
+			indexer.WithDriveNotifications(
+				cfg.Indexer.DriveNotificationURL,
+				cfg.Indexer.DriveNotificationToken,
+			))
+	}
+	if cfg.Email != nil && cfg.Email.Enabled {
+		// Subscriber emails are sent through the notifier, so they use the
+		// configured email provider and the notification preferences of each
+		// subscriber.
+		n, err := notify.NewFromConfig(cfg, goog,
+			notify.WithDatabase(db),
+			notify.WithLogger(log),
+		)
+		if err != nil {
+			ui.Error(fmt.Sprintf("error initializing notifier: %v", err))
+			return nil, nil, false
+		}
+		idxOpts = append(idxOpts, indexer.WithSubscriberEmails(n))
+	}
+	if cfg.Indexer.LeaderElection {
+		leaseDuration, err := time.ParseDuration(cfg.Indexer.LeaseDuration)
+		if err != nil {
+			ui.Error(fmt.Sprintf("error parsing indexer lease duration: %v", err))
+			return nil, nil, false
+		}
+		hostname, err := os.Hostname()
+		if err != nil {
+			ui.Error(fmt.Sprintf("error getting hostname: %v", err))
+			return nil, nil, false
+		}
+		idxOpts = append(idxOpts,
+			indexer.WithLeaderElection(
+				fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8]),
+				leaseDuration,
+			))
+	}
 	idx, err := indexer.NewIndexer(idxOpts...)
 	if err != nil {
 		ui.Error(fmt.Sprintf("error creating indexer: %v", err))
-		return 1
+		return nil, nil, false
 	}
 
-	ui.Info("starting indexer...")
-	go func() int {
-		if err := idx.Run(); err != nil {
-			ui.Error(err.Error())
-			// TODO: get this return value from indexer.Run().
-			return 1
-		}
-		return 0
-	}()
-	return c.WaitForInterrupt(func() {})
+	return cfg, idx, true
 }
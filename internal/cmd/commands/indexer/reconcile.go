@@ -0,0 +1,109 @@
+package indexer
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/hashicorp-forge/hermes/internal/cmd/base"
+)
+
+// ReconcileCommand finds and fixes inconsistencies between documents in the
+// database, search indexes, and storage provider.
+type ReconcileCommand struct {
+	*base.Command
+
+	flagConfig string
+	flagDryRun bool
+	flagFix    bool
+}
+
+func (c *ReconcileCommand) Synopsis() string {
+	return "Find and fix documents that are out of sync between stores"
+}
+
+func (c *ReconcileCommand) Help() string {
+	return `Usage: hermes indexer reconcile [options]
+
+This command compares documents in the database, the docs, drafts, and links
+search indexes, and the documents and drafts folders, and reports documents
+that are missing from a store, orphaned, or have mismatched status, reviewers,
+or product fields.
+
+With -fix, search index objects and redirect links are repaired from the
+database. Documents missing from or orphaned in storage are only reported.` +
+		c.Flags().Help()
+}
+
+func (c *ReconcileCommand) Flags() *base.FlagSet {
+	f := base.NewFlagSet(flag.NewFlagSet("indexer reconcile", flag.ExitOnError))
+
+	f.StringVar(
+		&c.flagConfig, "config", "", "Path to config file",
+	)
+	f.BoolVar(
+		&c.flagDryRun, "dry-run", false,
+		"Print the fixes that would be made instead of making them",
+	)
+	f.BoolVar(
+		&c.flagFix, "fix", false, "Fix issues that can be fixed automatically",
+	)
+	return f
+}
+
+func (c *ReconcileCommand) Run(args []string) int {
+	log, ui := c.Log, c.UI
+
+	// Parse flags.
+	f := c.Flags()
+	if err := f.Parse(args); err != nil {
+		ui.Error(fmt.Sprintf("error parsing flags: %v", err))
+		return 1
+	}
+	if c.flagConfig == "" {
+		ui.Error("error parsing flags: config argument is required")
+		return 1
+	}
+
+	_, idx, ok := newIndexer(ui, log, c.flagConfig)
+	if !ok {
+		return 1
+	}
+
+	issues, err := idx.Reconcile(c.flagFix, c.flagDryRun)
+	if err != nil {
+		ui.Error(fmt.Sprintf("error reconciling documents: %v", err))
+		return 1
+	}
+	if len(issues) == 0 {
+		ui.Info("no issues found")
+		return 0
+	}
+
+	var fixed, fixable int
+	for _, is := range issues {
+		var status string
+		switch {
+		case is.Fixed:
+			fixed++
+			status = "fixed"
+		case is.FixError != nil:
+			status = fmt.Sprintf("fix failed: %v", is.FixError)
+		case is.Fixable() && c.flagFix && c.flagDryRun:
+			fixable++
+			status = "would fix"
+		case is.Fixable():
+			fixable++
+			status = "fixable"
+		default:
+			status = "needs manual fix"
+		}
+		ui.Output(fmt.Sprintf("%s (%s)", is, status))
+	}
+	ui.Info(fmt.Sprintf("%d issues found, %d fixed, %d fixable",
+		len(issues), fixed, fixable))
+
+	if fixed < len(issues) {
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// documentMetricsInterval is the time between updates of document metrics.
+const documentMetricsInterval = time.Minute
+
+// documentsByStatus is the number of documents, by status.
+var documentsByStatus = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "hermes_documents",
+		Help: "Documents in the database, by status.",
+	},
+	[]string{"status"},
+)
+
+func init() {
+	prometheus.MustRegister(documentsByStatus)
+}
+
+// recordDocumentMetrics periodically updates document metrics from database
+// db. It runs forever.
+func recordDocumentMetrics(db *gorm.DB, log hclog.Logger) {
+	for {
+		counts, err := models.CountDocumentsByStatus(db)
+		if err != nil {
+			log.Warn("error counting documents by status for metrics",
+				"error", err)
+		} else {
+			// Report all known statuses so that statuses without documents are 0.
+			for s := models.DraftDocumentStatus; s <= models.ObsoleteDocumentStatus; s++ {
+				if _, ok := counts[s]; !ok {
+					counts[s] = 0
+				}
+			}
+			documentsByStatus.Reset()
+			for s, n := range counts {
+				documentsByStatus.WithLabelValues(s.String()).Set(float64(n))
+			}
+		}
+
+		time.Sleep(documentMetricsInterval)
+	}
+}
@@ -2,33 +2,40 @@ package server
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
-	"github.com/hashicorp/go-hclog"
-
-	"github.com/algolia/algoliasearch-client-go/v3/algolia/search"
 	"github.com/hashicorp-forge/hermes/internal/api"
 	"github.com/hashicorp-forge/hermes/internal/auth"
+	"github.com/hashicorp-forge/hermes/internal/auth/oidc"
 	"github.com/hashicorp-forge/hermes/internal/cmd/base"
 	"github.com/hashicorp-forge/hermes/internal/config"
 	"github.com/hashicorp-forge/hermes/internal/db"
-	"github.com/hashicorp-forge/hermes/internal/pkg/doctypes"
+	"github.com/hashicorp-forge/hermes/internal/digests"
+	"github.com/hashicorp-forge/hermes/internal/notify"
 	"github.com/hashicorp-forge/hermes/internal/pub"
+	"github.com/hashicorp-forge/hermes/internal/reminders"
+	"github.com/hashicorp-forge/hermes/internal/savedsearches"
 	"github.com/hashicorp-forge/hermes/internal/structs"
+	"github.com/hashicorp-forge/hermes/internal/webhooks"
 	"github.com/hashicorp-forge/hermes/pkg/algolia"
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
 	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
 	"github.com/hashicorp-forge/hermes/pkg/links"
+	"github.com/hashicorp-forge/hermes/pkg/metrics"
 	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"github.com/hashicorp-forge/hermes/web"
+	"github.com/hashicorp/go-hclog"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/gorm"
 )
 
@@ -126,63 +133,70 @@ func (c *Command) Run(args []string) int {
 	//fmt.Println(os.Getenv("POSTGRES_USER"))
 
 	// Get the sensitive details if present in the environment
-	if val, ok := os.LookupEnv("ALGOLIA_APPLICATION_ID"); ok {
-		cfg.Algolia.ApplicationID = val
-	} else {
-		c.UI.Error("ALGOLIA_APPLICATION_ID must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("ALGOLIA_SEARCH_API_KEY"); ok {
-		cfg.Algolia.SearchAPIKey = val
-	} else {
-		c.UI.Error("ALGOLIA_SEARCH_API_KEY must be provided as an env variable!")
-		return 1
-	}
+	// Algolia credentials are only required by the Algolia search provider.
+	if cfg.Search.Provider == search.ProviderAlgolia {
+		if val, ok := os.LookupEnv("ALGOLIA_APPLICATION_ID"); ok {
+			cfg.Algolia.ApplicationID = val
+		} else {
+			c.UI.Error("ALGOLIA_APPLICATION_ID must be provided as an env variable!")
+			return 1
+		}
+		if val, ok := os.LookupEnv("ALGOLIA_SEARCH_API_KEY"); ok {
+			cfg.Algolia.SearchAPIKey = val
+		} else {
+			c.UI.Error("ALGOLIA_SEARCH_API_KEY must be provided as an env variable!")
+			return 1
+		}
 
-	if val, ok := os.LookupEnv("ALGOLIA_WRITE_API_KEY"); ok {
-		cfg.Algolia.WriteAPIKey = val
-	} else {
-		c.UI.Error("ALGOLIA_SEARCH_API_KEY must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_CLIENT_ID"); ok {
-		cfg.GoogleWorkspace.OAuth2.ClientID = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_CLIENT_ID must be provided as an env variable!")
-		return 1
+		if val, ok := os.LookupEnv("ALGOLIA_WRITE_API_KEY"); ok {
+			cfg.Algolia.WriteAPIKey = val
+		} else {
+			c.UI.Error("ALGOLIA_SEARCH_API_KEY must be provided as an env variable!")
+			return 1
+		}
 	}
+	// Google Workspace credentials are only required by the Google storage
+	// provider.
+	if cfg.Storage.Provider == storage.ProviderGoogle {
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_CLIENT_ID"); ok {
+			cfg.GoogleWorkspace.OAuth2.ClientID = val
+		} else {
+			c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_CLIENT_ID must be provided as an env variable!")
+			return 1
+		}
 
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_HD"); ok {
-		cfg.GoogleWorkspace.OAuth2.HD = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_HD must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_REDIRECT_URI"); ok {
-		cfg.GoogleWorkspace.OAuth2.RedirectURI = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_REDIRECT_URI must be provided as an env variable!")
-		return 1
-	}
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_HD"); ok {
+			cfg.GoogleWorkspace.OAuth2.HD = val
+		} else {
+			c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_HD must be provided as an env variable!")
+			return 1
+		}
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_OAUTH2_REDIRECT_URI"); ok {
+			cfg.GoogleWorkspace.OAuth2.RedirectURI = val
+		} else {
+			c.UI.Error("GOOGLE_WORKSPACE_OAUTH2_REDIRECT_URI must be provided as an env variable!")
+			return 1
+		}
 
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_CLIENT_EMAIL"); ok {
-		cfg.GoogleWorkspace.Auth.ClientEmail = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_AUTH_CLIENT_EMAIL must be provided as an env variable!")
-		return 1
-	}
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_PRIVATE_KEY"); ok {
-		cfg.GoogleWorkspace.Auth.PrivateKey = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_AUTH_PRIVATE_KEY must be provided as an env variable!")
-		return 1
-	}
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_CLIENT_EMAIL"); ok {
+			cfg.GoogleWorkspace.Auth.ClientEmail = val
+		} else {
+			c.UI.Error("GOOGLE_WORKSPACE_AUTH_CLIENT_EMAIL must be provided as an env variable!")
+			return 1
+		}
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_PRIVATE_KEY"); ok {
+			cfg.GoogleWorkspace.Auth.PrivateKey = val
+		} else {
+			c.UI.Error("GOOGLE_WORKSPACE_AUTH_PRIVATE_KEY must be provided as an env variable!")
+			return 1
+		}
 
-	if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_SUBJECT"); ok {
-		cfg.GoogleWorkspace.Auth.Subject = val
-	} else {
-		c.UI.Error("GOOGLE_WORKSPACE_AUTH_SUBJECT must be provided as an env variable!")
-		return 1
+		if val, ok := os.LookupEnv("GOOGLE_WORKSPACE_AUTH_SUBJECT"); ok {
+			cfg.GoogleWorkspace.Auth.Subject = val
+		} else {
+			c.UI.Error("GOOGLE_WORKSPACE_AUTH_SUBJECT must be provided as an env variable!")
+			return 1
+		}
 	}
 
 	if val, ok := os.LookupEnv("POSTGRES_PASSWORD"); ok {
@@ -237,6 +251,15 @@ func (c *Command) Run(args []string) int {
 		c.UI.Error("EMAIL_FROM_ADDRESS must be provided as an env variable!")
 		return 1
 	}
+	if val, ok := os.LookupEnv("SLACK_SIGNING_SECRET"); ok {
+		cfg.Slack.SigningSecret = val
+	}
+	if val, ok := os.LookupEnv("SLACK_BOT_ACCESS_TOKEN"); ok {
+		cfg.Slack.BotToken = val
+	}
+	if val, ok := os.LookupEnv("SMTP_PASSWORD"); ok {
+		cfg.Email.SMTP.Password = val
+	}
 
 	/* Scanned all env variables succesfully */
 
@@ -293,10 +316,18 @@ func (c *Command) Run(args []string) int {
 			c.UI.Error("email from_address must be set if email is enabled")
 			return 1
 		}
+		if cfg.Email.Provider != "gmail" && cfg.Email.Provider != "smtp" {
+			c.UI.Error(fmt.Sprintf(
+				"invalid email provider %q: must be \"gmail\" or \"smtp\"",
+				cfg.Email.Provider))
+			return 1
+		}
 	}
 
-	// Build configuration for Okta authentication.
-	if !cfg.Okta.Disabled {
+	// Build configuration for Okta authentication. OIDC takes precedence over
+	// Okta, so Okta isn't used if OIDC is enabled.
+	oidcEnabled := cfg.OIDC != nil && !cfg.OIDC.Disabled
+	if !cfg.Okta.Disabled && !oidcEnabled {
 		// Check for required Okta configuration.
 		if cfg.Okta.AuthServerURL == "" {
 			c.UI.Error("error initializing server: Okta authorization server URL is required")
@@ -312,24 +343,39 @@ func (c *Command) Run(args []string) int {
 		}
 	}
 
-	// Initialize Google Workspace service.
+	// Build OIDC authenticator.
+	var oidcAuth *oidc.Authenticator
+	if oidcEnabled {
+		oidcAuth, err = oidc.New(*cfg.OIDC, c.Log)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error initializing server: %v", err))
+			return 1
+		}
+	}
+
+	// Initialize Google Workspace service, which is only used by the Google
+	// storage provider.
 	var goog *gw.Service
-	if cfg.GoogleWorkspace.Auth != nil {
-		// Use Google Workspace auth if it is defined in the config.
-		goog = gw.NewFromConfig(cfg.GoogleWorkspace.Auth)
-	} else {
-		// Use OAuth if Google Workspace auth is not defined in the config.
-		goog = gw.New()
+	if cfg.Storage.Provider == storage.ProviderGoogle {
+		if cfg.GoogleWorkspace.Auth != nil {
+			// Use Google Workspace auth if it is defined in the config.
+			goog = gw.NewFromConfig(cfg.GoogleWorkspace.Auth)
+		} else {
+			// Use OAuth if Google Workspace auth is not defined in the config.
+			goog = gw.New()
+		}
 	}
 
 	reqOpts := map[interface{}]string{
-		cfg.Algolia.ApplicationID:           "Algolia Application ID is required",
-		cfg.Algolia.SearchAPIKey:            "Algolia Search API Key is required",
 		cfg.BaseURL:                         "Base URL is required",
 		cfg.GoogleWorkspace.DocsFolder:      "Google Workspace Docs Folder is required",
 		cfg.GoogleWorkspace.DraftsFolder:    "Google Workspace Drafts Folder is required",
 		cfg.GoogleWorkspace.ShortcutsFolder: "Google Workspace Shortcuts Folder is required",
 	}
+	if cfg.Search.Provider == search.ProviderAlgolia {
+		reqOpts[cfg.Algolia.ApplicationID] = "Algolia Application ID is required"
+		reqOpts[cfg.Algolia.SearchAPIKey] = "Algolia Search API Key is required"
+	}
 	for r, msg := range reqOpts {
 		if r == "" {
 			c.UI.Error(fmt.Sprintf("error initializing server: %s", msg))
@@ -337,36 +383,68 @@ func (c *Command) Run(args []string) int {
 		}
 	}
 
-	// Initialize Algolia search client.
-	algoSearch, err := algolia.NewSearchClient(cfg.Algolia)
+	// Initialize Algolia search and write clients, which are only used by the
+	// Algolia search provider.
+	var algoSearch, algoWrite *algolia.Client
+	if cfg.Search.Provider == search.ProviderAlgolia {
+		algoSearch, err = algolia.NewSearchClient(cfg.Algolia)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error initializing Algolia search client: %v", err))
+			return 1
+		}
+
+		algoWrite, err = algolia.New(cfg.Algolia)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error initializing Algolia write client: %v", err))
+			return 1
+		}
+	}
+
+	// Initialize database.
+	db, err := db.NewDB(*cfg.Postgres)
 	if err != nil {
-		c.UI.Error(fmt.Sprintf("error initializing Algolia search client: %v", err))
+		c.UI.Error(fmt.Sprintf("error initializing database: %v", err))
 		return 1
 	}
 
-	// Initialize Algolia write client.
-	algoWrite, err := algolia.New(cfg.Algolia)
+	// Initialize notifier. Notifications are sent by email, if enabled, and as
+	// Slack direct messages, if the Slack bot token is set, according to the
+	// notification preferences of each user.
+	notifier, err := notify.NewFromConfig(cfg, goog,
+		notify.WithDatabase(db),
+		notify.WithLogger(c.Log),
+	)
 	if err != nil {
-		c.UI.Error(fmt.Sprintf("error initializing Algolia write client: %v", err))
+		c.UI.Error(fmt.Sprintf("error initializing notifier: %v", err))
 		return 1
 	}
+	go notifier.Run()
 
-	// Initialize database.
-	db, err := db.NewDB(*cfg.Postgres)
+	// Initialize search provider.
+	sp, err := search.NewProvider(
+		cfg.Search.Provider, algoSearch, algoWrite, db)
 	if err != nil {
-		c.UI.Error(fmt.Sprintf("error initializing database: %v", err))
+		c.UI.Error(fmt.Sprintf("error initializing search provider: %v", err))
 		return 1
 	}
 
-	// Register document types.
-	// for _, d := range cfg.DocumentTypes.DocumentType {
-	// 	if err := models.RegisterDocumentType(*d, db); err != nil {
-	// 		c.UI.Error(fmt.Sprintf("error registering document type: %v", err))
-	// 		return 1
-	// 	}
-	// }
-	if err := registerDocumentTypes(*cfg, db); err != nil {
-		c.UI.Error(fmt.Sprintf("error registering document types: %v", err))
+	// Initialize storage provider.
+	st, err := storage.NewProvider(
+		cfg.Storage.Provider, goog, cfg.Storage.LocalRoot)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("error initializing storage provider: %v", err))
+		return 1
+	}
+
+	// Seed document types.
+	if err := seedDocumentTypes(cfg, algoSearch, db, c.Log); err != nil {
+		c.UI.Error(fmt.Sprintf("error seeding document types: %v", err))
+		return 1
+	}
+
+	// Create global admin roles for existing admins.
+	if err := models.MigrateAdminRoles(db); err != nil {
+		c.UI.Error(fmt.Sprintf("error migrating admin roles: %v", err))
 		return 1
 	}
 
@@ -376,25 +454,107 @@ func (c *Command) Run(args []string) int {
 	//	return 1
 	//}
 
-	// Register document types.
-	// TODO: remove this and use the database for all document type lookups.
-	// array of object object to store doctype objects fetched from algolia
-	var objectArray []template = GetDocTypeArray(*cfg)
-	docTypes := map[string]hcd.Doc{
-		// "prd": &hcd.PRD{},
+	// Start review reminder scheduler.
+	if cfg.Reminders.Enabled {
+		interval, err := time.ParseDuration(cfg.Reminders.Interval)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error parsing reminders interval: %v", err))
+			return 1
+		}
+		rs, err := reminders.NewScheduler(
+			reminders.WithBaseURL(cfg.BaseURL),
+			reminders.WithDatabase(db),
+			reminders.WithDaysBefore(cfg.Reminders.DaysBefore),
+			reminders.WithInterval(interval),
+			reminders.WithLogger(c.Log),
+			reminders.WithNotifier(notifier),
+			reminders.WithSearchProvider(sp),
+			reminders.WithSendEmails(cfg.Email != nil && cfg.Email.Enabled),
+			reminders.WithSendSlackMessages(cfg.Reminders.Slack),
+		)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error initializing reminder scheduler: %v", err))
+			return 1
+		}
+		go rs.Run()
 	}
-	for i := 0; i < len(objectArray); i++ {
-		doctype := objectArray[i].TemplateName
-		doctype = strings.ToLower(doctype)
-		docTypes[doctype] = &hcd.COMMONTEMPLATE{}
+
+	// Start digest scheduler.
+	if cfg.Digests.Enabled {
+		if cfg.Email == nil || !cfg.Email.Enabled {
+			c.UI.Error("email must be enabled to send digests")
+			return 1
+		}
+		interval, err := time.ParseDuration(cfg.Digests.Interval)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error parsing digests interval: %v", err))
+			return 1
+		}
+		weekday, err := digests.ParseWeekday(cfg.Digests.WeeklyDay)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error parsing digests weekly day: %v", err))
+			return 1
+		}
+		ds, err := digests.NewScheduler(
+			digests.WithBaseURL(cfg.BaseURL),
+			digests.WithDatabase(db),
+			digests.WithEmailNotifier(notifier),
+			digests.WithHour(cfg.Digests.Hour),
+			digests.WithInterval(interval),
+			digests.WithLogger(c.Log),
+			digests.WithWeekday(weekday),
+		)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error initializing digest scheduler: %v", err))
+			return 1
+		}
+		go ds.Run()
 	}
 
-	for name, dt := range docTypes {
-		if err = doctypes.Register(name, dt); err != nil {
-			c.UI.Error(fmt.Sprintf("error registering %q doc type: %v", name, err))
+	// Start saved search notifier.
+	if cfg.SavedSearches.Enabled {
+		interval, err := time.ParseDuration(cfg.SavedSearches.Interval)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error parsing saved searches interval: %v", err))
+			return 1
+		}
+		sn, err := savedsearches.NewNotifier(
+			savedsearches.WithBaseURL(cfg.BaseURL),
+			savedsearches.WithDatabase(db),
+			savedsearches.WithInterval(interval),
+			savedsearches.WithLogger(c.Log),
+			savedsearches.WithNotifier(notifier),
+			savedsearches.WithSearchProvider(sp),
+			savedsearches.WithSendEmails(cfg.Email != nil && cfg.Email.Enabled),
+			savedsearches.WithSendSlackMessages(cfg.SavedSearches.Slack),
+		)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("error initializing saved search notifier: %v", err))
 			return 1
 		}
+		go sn.Run()
+	}
+
+	// Start webhook dispatcher.
+	webhooksPollInterval, err := time.ParseDuration(cfg.Webhooks.PollInterval)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("error parsing webhooks poll interval: %v", err))
+		return 1
+	}
+	wd, err := webhooks.NewDispatcher(
+		webhooks.WithDatabase(db),
+		webhooks.WithInterval(webhooksPollInterval),
+		webhooks.WithLogger(c.Log),
+		webhooks.WithMaxAttempts(cfg.Webhooks.MaxAttempts),
+	)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("error initializing webhook dispatcher: %v", err))
+		return 1
 	}
+	go wd.Run()
+
+	// Start recording document metrics.
+	go recordDocumentMetrics(db, c.Log)
 
 	mux := http.NewServeMux()
 
@@ -402,44 +562,58 @@ func (c *Command) Run(args []string) int {
 	// TODO: stop passing around all these arguments to handlers and use a struct
 	// with (functional) options.
 	authenticatedEndpoints := []endpoint{
-		{"/1/indexes/",
-			algolia.AlgoliaProxyHandler(algoSearch, cfg.Algolia, c.Log)},
 		{"/api/v1/approvals/",
-			api.ApprovalHandler(cfg, c.Log, algoSearch, algoWrite, goog, db)},
-		{"/api/v1/document-types", api.DocumentTypesHandler(*cfg, c.Log)},
+			api.ApprovalHandler(cfg, c.Log, sp, st, goog, db, notifier)},
+		{"/api/v1/audit", api.AuditHandler(c.Log, db)},
+		{"/api/v1/document-types", api.DocumentTypesHandler(c.Log, db)},
+		{"/api/v1/document-types/", api.DocumentTypeHandler(c.Log, db)},
 		{"/api/v1/documents/",
-			api.DocumentHandler(cfg, c.Log, algoSearch, algoWrite, goog, db)},
+			api.DocumentHandler(cfg, c.Log, sp, st, goog, db, notifier)},
 		{"/api/v1/drafts",
-			api.DraftsHandler(cfg, c.Log, algoSearch, algoWrite, goog, db)},
+			api.DraftsHandler(cfg, c.Log, sp, st, goog, db, notifier)},
 		{"/api/v1/drafts/",
-			api.DraftsDocumentHandler(cfg, c.Log, algoSearch, algoWrite, goog, db)},
-		{"/api/v1/custom-template",
-			api.TemplateHandler(cfg, c.Log, algoSearch, algoWrite, goog, db)},
-		{"/api/v1/custom-template/",
-			api.TemplateUpdateDeleteHandler(cfg, c.Log, algoSearch, algoWrite, goog, db)},
+			api.DraftsDocumentHandler(cfg, c.Log, sp, st, goog, db, notifier)},
+		{"/api/v1/custom-template", api.TemplateHandler(c.Log, db)},
+		{"/api/v1/custom-template/", api.TemplateUpdateDeleteHandler(c.Log, db)},
 		{"/api/v1/make-admin", api.MakeUserAdminHandler(c.Log, db)},
 		{"/api/v1/me", api.MeHandler(c.Log, goog, db)},
+		{"/api/v1/me/notifications", api.MeNotificationsHandler(c.Log, db)},
 		{"/api/v1/me/recently-viewed-docs",
 			api.MeRecentlyViewedDocsHandler(cfg, c.Log, db)},
+		{"/api/v1/me/saved-searches", api.MeSavedSearchesHandler(c.Log, db)},
+		{"/api/v1/me/saved-searches/", api.MeSavedSearchesHandler(c.Log, db)},
 		{"/api/v1/me/subscriptions",
 			api.MeSubscriptionsHandler(cfg, c.Log, goog, db)},
+		{"/api/v1/me/subscriptions/targets",
+			api.MeSubscriptionTargetsHandler(c.Log, db)},
+		{"/api/v1/me/subscriptions/targets/",
+			api.MeSubscriptionTargetsHandler(c.Log, db)},
+		{"/api/v1/me/tokens", api.MeTokensHandler(c.Log, db)},
+		{"/api/v1/me/tokens/", api.MeTokensHandler(c.Log, db)},
 		{"/api/v1/people", api.PeopleDataHandler(cfg, c.Log, goog)},
-		{"/api/v1/products", api.ProductsHandler(cfg, algoSearch, algoWrite, db, c.Log)},
-		{"/api/v1/teams", api.TeamsHandler(cfg, algoSearch, algoWrite, db, c.Log)},
-		{"/api/v1/projects", api.ProjectsHandler(cfg, algoSearch, algoWrite, db, c.Log)},
+		{"/api/v1/products", api.ProductsHandler(cfg, db, c.Log)},
+		{"/api/v1/teams", api.TeamsHandler(cfg, db, c.Log)},
+		{"/api/v1/projects", api.ProjectsHandler(cfg, db, c.Log)},
 		{"/api/v1/reviews/",
-			api.ReviewHandler(cfg, c.Log, algoSearch, algoWrite, goog, db)},
+			api.ReviewHandler(cfg, c.Log, sp, st, goog, db, notifier)},
+		{"/api/v1/roles", api.RolesHandler(c.Log, db)},
+		{"/api/v1/roles/", api.RoleHandler(c.Log, db)},
+		{"/api/v1/search", api.SearchHandler(c.Log, sp, db)},
+		{"/api/v1/slack/channels", api.SlackChannelsHandler(c.Log, db)},
+		{"/api/v1/slack/channels/", api.SlackChannelsHandler(c.Log, db)},
 		{"/api/v1/web/analytics", api.AnalyticsHandler(c.Log)},
+		{"/api/v1/webhooks", api.WebhooksHandler(c.Log, db)},
+		{"/api/v1/webhooks/", api.WebhookHandler(c.Log, db)},
 	}
 
-	// Define a slice of patterns that require admin access.
-	adminRequiredPatterns := []string{
-		"/api/v1/products",
-		"/api/v1/teams",
-		"/api/v1/custom-template",
-		"/api/v1/custom-template/",
-		"/api/v1/make-admin",
-		// Add more patterns here if needed.
+	// Proxy Algolia queries from the web app when using the Algolia search
+	// provider, unless disabled in favor of the search API.
+	if cfg.Search.Provider == search.ProviderAlgolia &&
+		!cfg.Server.DisableAlgoliaProxy {
+		authenticatedEndpoints = append(authenticatedEndpoints, endpoint{
+			"/1/indexes/",
+			algolia.AlgoliaProxyHandler(algoSearch, cfg.Algolia, c.Log),
+		})
 	}
 
 	// Define handlers for unauthenticated endpoints.
@@ -448,16 +622,34 @@ func (c *Command) Run(args []string) int {
 		{"/pub/", http.StripPrefix("/pub/", pub.Handler())},
 	}
 
-	// Web endpoints are conditionally authenticated based on if Okta is enabled.
+	// Add Slack interactivity and slash command endpoints if the Slack signing
+	// secret is set.
+	// Requests are authenticated using the signing secret.
+	if cfg.Slack.SigningSecret != "" {
+		unauthenticatedEndpoints = append(unauthenticatedEndpoints,
+			endpoint{"/api/v1/slack/commands",
+				api.SlackCommandsHandler(cfg, c.Log, sp, st, goog, db, notifier)},
+			endpoint{"/api/v1/slack/interactions",
+				api.SlackInteractionsHandler(cfg, c.Log, sp, st, goog, db, notifier)})
+	}
+
+	// Add OIDC sign-in endpoints if OIDC is enabled.
+	if oidcAuth != nil {
+		unauthenticatedEndpoints = append(unauthenticatedEndpoints,
+			endpoint{"/auth/oidc/", oidcAuth.Handler(db)})
+	}
+
+	// Web endpoints are conditionally authenticated based on if Okta or OIDC is
+	// enabled.
 	webEndpoints := []endpoint{
 		{"/", web.Handler()},
-		{"/api/v1/web/config", web.ConfigHandler(cfg, algoSearch, c.Log)},
-		{"/l/", links.RedirectHandler(algoSearch, cfg.Algolia, c.Log)},
+		{"/api/v1/web/config", web.ConfigHandler(cfg, sp, c.Log)},
+		{"/l/", links.RedirectHandler(sp, c.Log)},
 	}
 
-	// If Okta is enabled, add the web endpoints for the single page app as
-	// authenticated endpoints.
-	if cfg.Okta != nil && !cfg.Okta.Disabled {
+	// If Okta or OIDC is enabled, add the web endpoints for the single page app
+	// as authenticated endpoints.
+	if (cfg.Okta != nil && !cfg.Okta.Disabled) || oidcAuth != nil {
 		authenticatedEndpoints = append(authenticatedEndpoints, webEndpoints...)
 	} else {
 		// If Okta is disabled, we need to add the web endpoints for the SPA as
@@ -467,24 +659,15 @@ func (c *Command) Run(args []string) int {
 
 	// Register handlers.
 	for _, e := range authenticatedEndpoints {
-		// Check if the current pattern requires admin access.
-		if containsPattern(adminRequiredPatterns, e.pattern) {
-			// If it does, apply the isAdminForProduct middleware after AuthenticateRequest.
-			mux.Handle(
-				e.pattern,
-				auth.AuthenticateRequest(*cfg, goog, c.Log, isAdminForProduct(e.handler, db, c.Log)),
-			)
-		} else {
-			// For other endpoints, use the existing authentication middleware.
-			mux.Handle(
-				e.pattern,
-				auth.AuthenticateRequest(*cfg, goog, c.Log, e.handler),
-			)
-		}
+		mux.Handle(
+			e.pattern,
+			metrics.InstrumentHandler(e.pattern,
+				auth.AuthenticateRequest(*cfg, goog, db, c.Log, e.handler)),
+		)
 	}
 
 	for _, e := range unauthenticatedEndpoints {
-		mux.Handle(e.pattern, e.handler)
+		mux.Handle(e.pattern, metrics.InstrumentHandler(e.pattern, e.handler))
 	}
 
 	server := &http.Server{
@@ -500,7 +683,27 @@ func (c *Command) Run(args []string) int {
 		}
 	}()
 
-	return c.WaitForInterrupt(c.ShutdownServer(server))
+	// Serve metrics on a separate listener so that they aren't exposed with the
+	// API.
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsServer := &http.Server{
+		Addr:    cfg.Server.MetricsAddr,
+		Handler: metricsMux,
+	}
+	go func() {
+		c.Log.Info(fmt.Sprintf("serving metrics on %s...", cfg.Server.MetricsAddr))
+
+		if err := metricsServer.ListenAndServe(); err != http.ErrServerClosed {
+			c.Log.Error(fmt.Sprintf("error starting metrics listener: %v", err))
+			os.Exit(1)
+		}
+	}()
+
+	return c.WaitForInterrupt(func() {
+		c.ShutdownServer(server)()
+		c.ShutdownServer(metricsServer)()
+	})
 }
 
 // healthHandler responds with the health of the service.
@@ -523,74 +726,122 @@ func (c *Command) ShutdownServer(s *http.Server) func() {
 	}
 }
 
-// registerDocumentTypes registers all products configured in the application
-// config in the database.
-// Define a variable to hold the retrieved object
-type template struct {
-	ObjectId     string `json:"objectId"`
-	Description  string `json:"description,omitempty"`
-	TemplateName string `json:"templateName"`
-	DocId        string `json:"docId"`
-}
+// seedDocumentTypes creates the document types configured in the application
+// config, and the legacy document type templates in Algolia (if algo isn't
+// nil), in the database.
+// Document types are managed with the API once they exist, so ones that
+// already exist (or were deleted) aren't changed, except to migrate template
+// file IDs to document types created before they were stored.
+func seedDocumentTypes(
+	cfg *config.Config, algo *algolia.Client, db *gorm.DB, l hclog.Logger,
+) error {
+	if cfg.DocumentTypes != nil {
+		for _, d := range cfg.DocumentTypes.DocumentType {
+			dt := models.DocumentType{
+				Name:           d.Name,
+				Description:    d.Description,
+				TemplateFileID: d.Template,
+				IndexContent:   d.IndexContent,
+			}
+			if d.MoreInfoLink != nil {
+				dt.MoreInfoLinkText = d.MoreInfoLink.Text
+				dt.MoreInfoLinkURL = d.MoreInfoLink.URL
+			}
+			var checks []models.DocumentTypeCheck
+			for _, c := range d.Checks {
+				check := models.DocumentTypeCheck{
+					Label:      c.Label,
+					HelperText: c.HelperText,
+				}
+				for _, link := range c.Links {
+					check.Links = append(check.Links, models.DocumentTypeLink{
+						Text: link.Text,
+						URL:  link.URL,
+					})
+				}
+				checks = append(checks, check)
+			}
+			if err := dt.SetChecks(checks); err != nil {
+				return fmt.Errorf("error setting checks of document type %q: %w",
+					d.Name, err)
+			}
+			for _, c := range d.CustomFields {
+				t, err := models.ParseDocumentTypeCustomFieldType(c.Type)
+				if err != nil {
+					return fmt.Errorf(
+						"error parsing custom field %q of document type %q: %w",
+						c.Name, d.Name, err)
+				}
+				dt.CustomFields = append(dt.CustomFields,
+					models.DocumentTypeCustomField{
+						Name:     c.Name,
+						ReadOnly: c.ReadOnly,
+						Type:     t,
+					})
+			}
+			if p := d.ApprovalPolicy; p != nil {
+				for _, s := range p.Stages {
+					dt.ApprovalPolicy.Data.Stages = append(
+						dt.ApprovalPolicy.Data.Stages, models.ApprovalStage{
+							Name:         s.Name,
+							Approvers:    s.Approvers,
+							MinApprovals: s.MinApprovals,
+						})
+				}
+			}
 
-func GetDocTypeArray(cfg config.Config) []template {
-	// Initialize the Algolia client
-	appID := cfg.Algolia.ApplicationID
-	apiKey := cfg.Algolia.WriteAPIKey
-	// Initialize the search client
-	client := search.NewClient(appID, apiKey)
-
-	// Specify the index name
-	indexName := cfg.Algolia.TemplateIndexName
-	// Create a search index instance
-	index := client.InitIndex(indexName)
-	// fetch all objectIds and append in
-	var record template
-	// use BrowseObjects to Get all records as an iterator
-	it, err := index.BrowseObjects()
+			created, err := dt.Seed(db)
+			if err != nil {
+				return fmt.Errorf("error seeding document type %q: %w", d.Name, err)
+			}
+			if created {
+				l.Info("created document type from config", "document_type", d.Name)
+			}
+		}
+	}
+
+	// Document types used to be stored as templates in Algolia, which is only
+	// configured for the Algolia search provider.
+	// TODO: remove this once existing templates have been migrated.
+	if algo == nil {
+		return nil
+	}
+	it, err := algo.Template.BrowseObjects()
 	if err != nil {
-		fmt.Println("error browsing document types")
+		l.Warn("error browsing legacy document type templates", "error", err)
+		return nil
 	}
-	//objectArray contains array of template objects
-	var objectArray []template
-	// loop to traverse through all the template objects
 	for {
-		rec, err := it.Next(&record)
-		if err != nil {
-			break
-		}
-		jsonData, err := json.Marshal(rec)
-		if err != nil {
-			fmt.Println("Error converting to JSON:", err)
+		var t hcd.BaseTemplate
+		if _, err := it.Next(&t); err != nil {
+			if !errors.Is(err, io.EOF) {
+				l.Warn("error browsing legacy document type templates",
+					"error", err)
+			}
 			break
 		}
-		// temporary object variable to store and append to object Array
-		var object template
-		error := json.Unmarshal([]byte(jsonData), &object)
-		if error != nil {
-			fmt.Println("Error converting JSON to object:", err)
-			break
+		if t.TemplateName == "" || t.DocId == "" {
+			l.Warn("skipping invalid legacy document type template",
+				"template_name", t.TemplateName)
+			continue
 		}
-		objectArray = append(objectArray, object)
-	}
-	// fmt.Printf("objectArray: %v\n", objectArray)
-	return objectArray
-}
 
-func registerDocumentTypes(cfg config.Config, db *gorm.DB) error {
-	var objectArray []template = GetDocTypeArray(cfg)
-	for _, d := range objectArray {
 		dt := models.DocumentType{
-			Name:         d.TemplateName,
-			Description:  d.Description,
-			Checks:       nil,
-			CustomFields: nil,
+			Name:           t.TemplateName,
+			Description:    t.Description,
+			TemplateFileID: t.DocId,
 		}
-		// Upsert document type.
-		if err := dt.Upsert(db); err != nil {
-			return fmt.Errorf("error upserting document type: %w", err)
+		created, err := dt.Seed(db)
+		if err != nil {
+			return fmt.Errorf("error seeding document type %q: %w",
+				t.TemplateName, err)
+		}
+		if created {
+			l.Info("created document type from legacy template",
+				"document_type", t.TemplateName)
 		}
 	}
+
 	return nil
 }
 
@@ -631,45 +882,3 @@ func registerProducts(
 
 	return nil
 }
-
-// isAdminForProduct is a middleware function that checks if the user is an admin
-// for POST requests within the only admin access endpoint.
-func isAdminForProduct(next http.Handler, db *gorm.DB, log hclog.Logger) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check if the request method is POST.
-		if r.Method != http.MethodGet {
-			if r.Context().Value("userEmail") == nil {
-				log.Error("userEmail is not set in the request context",
-					"method", r.Method,
-					"path", r.URL.Path)
-				http.Error(w, "Internal server error", http.StatusInternalServerError)
-				return
-			}
-			userEmail := r.Context().Value("userEmail").(string)
-			var user models.User = models.User{EmailAddress: userEmail}
-			// Check if the user is an admin (you need to implement this logic).
-			isAdmin, err := user.IsUserAdmin(db)
-			if err != nil {
-				http.Error(w, "Error Fetching User details from Database!", http.StatusInternalServerError)
-			}
-			if !isAdmin {
-				http.Error(w, "Access denied: You must be an admin to perform this action.", http.StatusForbidden)
-				return
-			}
-		}
-
-		// If the request is not a Get request or the user is an admin for a POST request or any other crud,
-		// call the next handler in the chain.
-		next.ServeHTTP(w, r)
-	})
-}
-
-// Helper function to check if a pattern is present in the slice.
-func containsPattern(patterns []string, target string) bool {
-	for _, p := range patterns {
-		if p == target {
-			return true
-		}
-	}
-	return false
-}
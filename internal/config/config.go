@@ -3,9 +3,12 @@ package config
 import (
 	"fmt"
 
+	"github.com/hashicorp-forge/hermes/internal/auth/oidc"
 	"github.com/hashicorp-forge/hermes/internal/auth/oktaalb"
 	"github.com/hashicorp-forge/hermes/pkg/algolia"
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"github.com/hashicorp/hcl/v2/hclsimple"
 )
 
@@ -17,7 +20,13 @@ type Config struct {
 	// BaseURL is the base URL used for building links.
 	BaseURL string `hcl:"base_url,optional"`
 
-	// DocumentTypes contain available document types.
+	// Digests configures daily and weekly digest emails of subscribed document
+	// events.
+	Digests *Digests `hcl:"digests,block"`
+
+	// DocumentTypes contain document types that are created in the database at
+	// startup if they don't exist. Document types are then managed with the
+	// "/api/v1/document-types" API.
 	DocumentTypes *DocumentTypes `hcl:"document_types,block"`
 
 	// Email configures Hermes to send email notifications.
@@ -38,19 +47,63 @@ type Config struct {
 	// Okta configures Hermes to work with Okta.
 	Okta *oktaalb.Config `hcl:"okta,block"`
 
+	// OIDC configures Hermes to authenticate users using a generic OpenID
+	// Connect provider. If enabled, it takes precedence over Okta and Google
+	// authentication.
+	OIDC *oidc.Config `hcl:"oidc,block"`
+
 	// Products contain available products.
 	Products *Products `hcl:"products,block"`
 
 	// Postgres configures PostgreSQL as the app database.
 	Postgres *Postgres `hcl:"postgres,block"`
 
+	// Reminders configures reminders sent to reviewers of documents with a due
+	// date.
+	Reminders *Reminders `hcl:"reminders,block"`
+
+	// SavedSearches configures notifications of new documents matching users'
+	// saved searches.
+	SavedSearches *SavedSearches `hcl:"saved_searches,block"`
+
+	// Search configures the search provider.
+	Search *Search `hcl:"search,block"`
+
 	// Server contains the configuration for the Hermes server.
 	Server *Server `hcl:"server,block"`
 
+	// Slack configures the Slack app.
+	Slack *Slack `hcl:"slack,block"`
+
+	// Storage configures the document storage provider.
+	Storage *Storage `hcl:"storage,block"`
+
+	// Webhooks configures delivery of document events to outbound webhooks.
+	Webhooks *Webhooks `hcl:"webhooks,block"`
+
 	// ShortenerBaseURL is the base URL for building short links.
 	ShortenerBaseURL string `hcl:"shortener_base_url,optional"`
 }
 
+// Digests configures daily and weekly digest emails of subscribed document
+// events, for users who chose to receive them instead of an email per event.
+type Digests struct {
+	// Enabled enables sending digest emails in the server. Email must also be
+	// enabled.
+	Enabled bool `hcl:"enabled,optional"`
+
+	// Hour is the hour of the day (0-23, UTC) to send digests. Defaults to 0.
+	Hour int `hcl:"hour,optional"`
+
+	// Interval is the time between checks for digests to send (e.g., "30m").
+	// Defaults to "1h".
+	Interval string `hcl:"interval,optional"`
+
+	// WeeklyDay is the day of the week to send weekly digests (e.g.,
+	// "monday"). Defaults to "monday".
+	WeeklyDay string `hcl:"weekly_day,optional"`
+}
+
 // DocumentTypes contain available document types.
 type DocumentTypes struct {
 	// DocumentType defines a document type.
@@ -82,6 +135,39 @@ type DocumentType struct {
 
 	// CustomFields are custom fields specific to the document type.
 	CustomFields []*DocumentTypeCustomField `hcl:"custom_field,block" json:"customFields"`
+
+	// ApprovalPolicy is the policy of approvals required for documents of this
+	// type to be approved. If not set, document owners mark documents as
+	// approved.
+	ApprovalPolicy *DocumentTypeApprovalPolicy `hcl:"approval_policy,block" json:"approvalPolicy,omitempty"`
+
+	// IndexContent enables indexing the content of published documents of this
+	// type for full-text search.
+	IndexContent bool `hcl:"index_content,optional" json:"indexContent,omitempty"`
+}
+
+// DocumentTypeApprovalPolicy is a policy of approvals required for documents
+// of a document type to be approved. Documents are approved once all stages
+// are satisfied, in order.
+type DocumentTypeApprovalPolicy struct {
+	// Stages are the stages of the policy, in the order they need to be
+	// satisfied.
+	Stages []*DocumentTypeApprovalStage `hcl:"stage,block" json:"stages"`
+}
+
+// DocumentTypeApprovalStage is a stage of a document type approval policy.
+type DocumentTypeApprovalStage struct {
+	// Name is the name of the stage.
+	// Example: "Security"
+	Name string `hcl:"name,label" json:"name"`
+
+	// Approvers are the email addresses of the users who can approve the stage.
+	// If not set, the stage can be approved by the document's reviewers.
+	Approvers []string `hcl:"approvers,optional" json:"approvers,omitempty"`
+
+	// MinApprovals is the minimum number of approvals required to satisfy the
+	// stage. If not set, approvals are required from all approvers.
+	MinApprovals int `hcl:"min_approvals,optional" json:"minApprovals,omitempty"`
 }
 
 // DocumentTypeCheck is a document type check, which require acknowledging a
@@ -126,6 +212,32 @@ type Email struct {
 
 	// FromAddress is the email address to send emails from.
 	FromAddress string `hcl:"from_address,optional"`
+
+	// Provider is the provider used to send emails. Valid values are "gmail"
+	// (default), which uses the Gmail API of the Google Workspace service, and
+	// "smtp".
+	Provider string `hcl:"provider,optional"`
+
+	// SMTP configures the SMTP server used to send emails, if Provider is
+	// "smtp".
+	SMTP *SMTP `hcl:"smtp,block"`
+}
+
+// SMTP configures an SMTP server used to send emails.
+type SMTP struct {
+	// Host is the host of the SMTP server.
+	Host string `hcl:"host,optional"`
+
+	// Port is the port of the SMTP server.
+	Port int `hcl:"port,optional"`
+
+	// Username is the username to authenticate with. Authentication is
+	// disabled if not set.
+	Username string `hcl:"username,optional"`
+
+	// Password is the password to authenticate with. It can also be set using
+	// the SMTP_PASSWORD environment variable.
+	Password string `hcl:"password,optional"`
 }
 
 // FeatureFlags contain available feature flags.
@@ -148,10 +260,37 @@ type FeatureFlag struct {
 
 // Indexer contains the configuration for the Hermes indexer.
 type Indexer struct {
+	// LeaderElection enables leader election between indexer replicas, so that
+	// only one replica indexes documents at a time.
+	LeaderElection bool `hcl:"leader_election,optional"`
+
+	// LeaseDuration is the duration of the leader lease (e.g., "30s"), which is
+	// the maximum time before another replica takes over if the leader stops.
+	// Defaults to "30s".
+	LeaseDuration string `hcl:"lease_duration,optional"`
+
 	// MaxParallelDocs is the maximum number of documents that will be
 	// simultaneously indexed.
 	MaxParallelDocs int `hcl:"max_parallel_docs,optional"`
 
+	// MetricsAddr is the address that the indexer serves HTTP endpoints on:
+	// Prometheus metrics (at /metrics), health (at /health), and Drive push
+	// notifications (at /drive/notifications). Defaults to ":9091".
+	MetricsAddr string `hcl:"metrics_addr,optional"`
+
+	// DriveNotificationToken is a secret token that Drive push notifications
+	// must include. Required if DriveNotificationURL is set.
+	DriveNotificationToken string `hcl:"drive_notification_token,optional"`
+
+	// DriveNotificationURL is the public HTTPS URL of the indexer's
+	// /drive/notifications endpoint. If set, Drive push notifications trigger
+	// indexer runs as soon as documents change. Requires UseDriveChanges.
+	DriveNotificationURL string `hcl:"drive_notification_url,optional"`
+
+	// PollInterval is the time between indexer runs (e.g., "30s"), which is a
+	// fallback when push notifications are used. Defaults to "1m".
+	PollInterval string `hcl:"poll_interval,optional"`
+
 	// UpdateDocHeaders enables the indexer to automatically update document
 	// headers for Hermes-managed documents with Hermes document metadata.
 	UpdateDocHeaders bool `hcl:"update_doc_headers,optional"`
@@ -159,6 +298,73 @@ type Indexer struct {
 	// UpdateDraftHeaders enables the indexer to automatically update document
 	// headers for draft documents with Hermes document metadata.
 	UpdateDraftHeaders bool `hcl:"update_draft_headers,optional"`
+
+	// UseDriveChanges enables the indexer to find updated documents using the
+	// Google Drive Changes API instead of searching the documents folder.
+	UseDriveChanges bool `hcl:"use_drive_changes,optional"`
+}
+
+// Reminders configures reminders sent to reviewers of documents with a due
+// date.
+type Reminders struct {
+	// Enabled enables sending reminders to reviewers who haven't approved a
+	// document as its due date approaches and again once it is overdue.
+	Enabled bool `hcl:"enabled,optional"`
+
+	// DaysBefore is the number of days before a document's due date to start
+	// reminding reviewers. Defaults to 2.
+	DaysBefore int `hcl:"days_before,optional"`
+
+	// Interval is the time between checks for reminders to send (e.g., "30m").
+	// Defaults to "1h".
+	Interval string `hcl:"interval,optional"`
+
+	// Slack enables sending reminders as Slack direct messages. Email reminders
+	// are sent if email is enabled.
+	Slack bool `hcl:"slack,optional"`
+}
+
+// SavedSearches configures notifications of new documents matching users'
+// saved searches.
+type SavedSearches struct {
+	// Enabled enables periodically checking saved searches for new matching
+	// documents and notifying their users.
+	Enabled bool `hcl:"enabled,optional"`
+
+	// Interval is the time between checks of saved searches (e.g., "30m").
+	// Defaults to "1h".
+	Interval string `hcl:"interval,optional"`
+
+	// Slack enables sending notifications as Slack direct messages. Email
+	// notifications are sent if email is enabled.
+	Slack bool `hcl:"slack,optional"`
+}
+
+// Slack configures the Slack app used to send Slack messages.
+type Slack struct {
+	// BotToken is the bot token of the Slack app, used to send Slack direct
+	// messages. Slack notifications are disabled if not set. It can also be
+	// set using the SLACK_BOT_ACCESS_TOKEN environment variable.
+	BotToken string `hcl:"bot_token,optional"`
+
+	// SigningSecret is the signing secret of the Slack app, used to verify
+	// requests from Slack. Interactive review request messages (with Approve
+	// and Request changes buttons, and a Snooze button if Slack reminders are
+	// enabled) and the "/docvault" slash command are enabled if set. It can
+	// also be set using the SLACK_SIGNING_SECRET environment variable.
+	SigningSecret string `hcl:"signing_secret,optional"`
+}
+
+// Webhooks configures delivery of document events to outbound webhooks, which
+// are managed by admins using the API.
+type Webhooks struct {
+	// MaxAttempts is the number of attempts to deliver an event to a webhook
+	// before giving up. Defaults to 10.
+	MaxAttempts int `hcl:"max_attempts,optional"`
+
+	// PollInterval is the time between checks for pending deliveries (e.g.,
+	// "30s"). Defaults to "10s".
+	PollInterval string `hcl:"poll_interval,optional"`
 }
 
 // GoogleWorkspace is the configuration to work with Google Workspace.
@@ -233,27 +439,115 @@ type Product struct {
 	Abbreviation string `hcl:"abbreviation" json:"abbreviation"`
 }
 
+// Search configures the search provider.
+type Search struct {
+	// Provider is the search provider to use for document, draft, internal,
+	// and link indexes. Valid values are "algolia" (default) and "postgres".
+	Provider string `hcl:"provider,optional"`
+}
+
 // Server contains the configuration for the Hermes server.
 type Server struct {
 	// Addr is the address to bind to for listening.
 	Addr string `hcl:"addr,optional"`
+
+	// DisableAlgoliaProxy disables the /1/indexes/ endpoint that proxies
+	// Algolia queries from the web app with the search API key. Clients should
+	// use the /api/v1/search endpoint instead, which enforces visibility rules.
+	DisableAlgoliaProxy bool `hcl:"disable_algolia_proxy,optional"`
+
+	// MetricsAddr is the address that the server serves Prometheus metrics on
+	// (at /metrics). Metrics are served on a separate listener so that they
+	// aren't exposed with the API. Defaults to "127.0.0.1:9090", so metrics are
+	// only reachable locally unless configured otherwise.
+	MetricsAddr string `hcl:"metrics_addr,optional"`
+}
+
+// Storage configures the document storage provider.
+type Storage struct {
+	// Provider is the storage provider to use for documents. Valid values are
+	// "google" (default) and "local".
+	Provider string `hcl:"provider,optional"`
+
+	// LocalRoot is the directory used to store documents by the "local"
+	// provider.
+	LocalRoot string `hcl:"local_root,optional"`
 }
 
 // NewConfig parses an HCL configuration file and returns the Hermes config.
 func NewConfig(filename string) (*Config, error) {
 	c := &Config{
 		Algolia:         &algolia.Config{},
+		Digests:         &Digests{},
 		Email:           &Email{},
 		FeatureFlags:    &FeatureFlags{},
 		GoogleWorkspace: &GoogleWorkspace{},
 		Indexer:         &Indexer{},
 		Okta:            &oktaalb.Config{},
+		Reminders:       &Reminders{},
+		SavedSearches:   &SavedSearches{},
+		Search:          &Search{},
 		Server:          &Server{},
+		Slack:           &Slack{},
+		Storage:         &Storage{},
+		Webhooks:        &Webhooks{},
 	}
 	err := hclsimple.DecodeFile(filename, nil, c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	if c.Search.Provider == "" {
+		c.Search.Provider = search.ProviderAlgolia
+	}
+	if c.Storage.Provider == "" {
+		c.Storage.Provider = storage.ProviderGoogle
+	}
+	if c.Digests.Interval == "" {
+		c.Digests.Interval = "1h"
+	}
+	if c.Digests.WeeklyDay == "" {
+		c.Digests.WeeklyDay = "monday"
+	}
+	if c.Email.Provider == "" {
+		c.Email.Provider = "gmail"
+	}
+	if c.Email.SMTP == nil {
+		c.Email.SMTP = &SMTP{}
+	}
+	if c.Email.SMTP.Host == "" {
+		c.Email.SMTP.Host = "localhost"
+	}
+	if c.Email.SMTP.Port == 0 {
+		c.Email.SMTP.Port = 25
+	}
+	if c.Indexer.LeaseDuration == "" {
+		c.Indexer.LeaseDuration = "30s"
+	}
+	if c.Indexer.MetricsAddr == "" {
+		c.Indexer.MetricsAddr = ":9091"
+	}
+	if c.Indexer.PollInterval == "" {
+		c.Indexer.PollInterval = "1m"
+	}
+	if c.Reminders.DaysBefore == 0 {
+		c.Reminders.DaysBefore = 2
+	}
+	if c.Reminders.Interval == "" {
+		c.Reminders.Interval = "1h"
+	}
+	if c.Server.MetricsAddr == "" {
+		c.Server.MetricsAddr = "127.0.0.1:9090"
+	}
+	if c.SavedSearches.Interval == "" {
+		c.SavedSearches.Interval = "1h"
+	}
+	if c.Webhooks.MaxAttempts == 0 {
+		c.Webhooks.MaxAttempts = 10
+	}
+	if c.Webhooks.PollInterval == "" {
+		c.Webhooks.PollInterval = "10s"
+	}
+
 	return c, nil
 }
@@ -2,9 +2,11 @@ package db
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/hashicorp-forge/hermes/internal/config"
 	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -63,8 +65,12 @@ func NewDB(cfg config.Postgres) (*gorm.DB, error) {
 	}
 
 	// adding uuid.ossp extensioin in the postgres for using the uuid_generate_v4()
-	if err = EnableUUIDExtension(db); err != nil {
-		fmt.Errorf("error  installing uuid_ossp database: %w", err)
+	// Errors are ignored, as the extension may already be installed by a user
+	// with the privilege to create it.
+	_ = EnableUUIDExtension(db)
+
+	if err := migrateDocumentDueDate(db); err != nil {
+		return nil, fmt.Errorf("error migrating document due dates: %w", err)
 	}
 
 	// Automatically migrate models.
@@ -77,3 +83,69 @@ func NewDB(cfg config.Postgres) (*gorm.DB, error) {
 
 	return db, nil
 }
+
+// migrateDocumentDueDate converts the documents due_date column from text to
+// date. Values that aren't valid dates in the "YYYY-MM-DD" format can't be
+// converted, so they are logged and cleared before the column is converted.
+func migrateDocumentDueDate(db *gorm.DB) error {
+	var dataType string
+	if err := db.Raw(`SELECT data_type FROM information_schema.columns
+		WHERE table_schema = CURRENT_SCHEMA()
+			AND table_name = 'documents'
+			AND column_name = 'due_date'`).
+		Scan(&dataType).
+		Error; err != nil {
+		return err
+	}
+	if dataType != "text" {
+		return nil
+	}
+
+	log := hclog.Default()
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID      uint
+			DueDate string
+		}
+		if err := tx.Raw(`SELECT id, due_date FROM documents
+			WHERE due_date IS NOT NULL`).
+			Scan(&rows).
+			Error; err != nil {
+			return fmt.Errorf("error getting due dates: %w", err)
+		}
+
+		var invalid []uint
+		for _, row := range rows {
+			if isValidDueDate(row.DueDate) {
+				continue
+			}
+			invalid = append(invalid, row.ID)
+			if row.DueDate != "" {
+				log.Warn("clearing invalid document due date",
+					"document_id", row.ID,
+					"due_date", row.DueDate,
+				)
+			}
+		}
+		if len(invalid) > 0 {
+			if err := tx.Exec(`UPDATE documents SET due_date = NULL
+				WHERE id IN ?`, invalid).
+				Error; err != nil {
+				return fmt.Errorf("error clearing invalid due dates: %w", err)
+			}
+			log.Info("cleared invalid document due dates",
+				"count", len(invalid),
+			)
+		}
+
+		return tx.Exec(`ALTER TABLE documents ALTER COLUMN due_date TYPE date
+			USING due_date::date`).Error
+	})
+}
+
+// isValidDueDate returns true if s is a valid date in the "YYYY-MM-DD" format
+// that can be stored in a date column.
+func isValidDueDate(s string) bool {
+	t, err := time.Parse("2006-01-02", s)
+	return err == nil && t.Year() >= 1
+}
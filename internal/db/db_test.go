@@ -0,0 +1,22 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidDueDate(t *testing.T) {
+	for s, want := range map[string]bool{
+		"2023-02-28": true,
+		"2024-02-29": true,
+		"2023-02-30": false,
+		"2023-13-01": false,
+		"0000-01-01": false,
+		"2023-2-1":   false,
+		"next week":  false,
+		"":           false,
+	} {
+		assert.Equal(t, want, isValidDueDate(s), s)
+	}
+}
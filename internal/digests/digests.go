@@ -0,0 +1,325 @@
+package digests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hashicorp-forge/hermes/internal/email"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"gorm.io/gorm"
+)
+
+const (
+	// loggerName is the name of the logger.
+	loggerName = "digests"
+
+	// defaultHour is the default hour of the day (UTC) to send digests.
+	defaultHour = 0
+
+	// defaultInterval is the default time between runs of the scheduler.
+	defaultInterval = time.Hour
+
+	// defaultWeekday is the default day of the week to send weekly digests.
+	defaultWeekday = time.Monday
+)
+
+// Scheduler sends digest emails of queued subscription notifications to users
+// in daily or weekly digest mode.
+type Scheduler struct {
+	// BaseURL is the base URL for the application.
+	BaseURL string
+
+	// Database is the database connection.
+	Database *gorm.DB
+
+	// EmailNotifier is the notification channel used to send digest emails.
+	EmailNotifier notify.Channel
+
+	// Hour is the hour of the day (UTC) to send digests.
+	Hour int
+
+	// Interval is the time between runs of the scheduler.
+	Interval time.Duration
+
+	// Logger is the logger to use.
+	Logger hclog.Logger
+
+	// Weekday is the day of the week to send weekly digests.
+	Weekday time.Weekday
+}
+
+type SchedulerOption func(*Scheduler)
+
+// NewScheduler creates a new digest scheduler.
+func NewScheduler(opts ...SchedulerOption) (*Scheduler, error) {
+	// Initialize a new scheduler with defaults.
+	s := &Scheduler{
+		Hour:     defaultHour,
+		Interval: defaultInterval,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name: loggerName,
+		}),
+		Weekday: defaultWeekday,
+	}
+
+	// Apply functional options.
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// Validate scheduler configuration.
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// validate validates the scheduler configuration.
+func (s *Scheduler) validate() error {
+	return validation.ValidateStruct(s,
+		validation.Field(&s.BaseURL, validation.Required),
+		validation.Field(&s.Database, validation.Required),
+		validation.Field(&s.EmailNotifier, validation.Required),
+		validation.Field(&s.Hour, validation.Min(0), validation.Max(23)),
+		validation.Field(&s.Interval, validation.Required),
+		validation.Field(&s.Weekday,
+			validation.Min(time.Sunday), validation.Max(time.Saturday)),
+	)
+}
+
+// WithBaseURL sets the base URL.
+func WithBaseURL(b string) SchedulerOption {
+	return func(s *Scheduler) {
+		s.BaseURL = b
+	}
+}
+
+// WithDatabase sets the database.
+func WithDatabase(db *gorm.DB) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Database = db
+	}
+}
+
+// WithEmailNotifier sets the notification channel used to send digest emails.
+func WithEmailNotifier(n notify.Channel) SchedulerOption {
+	return func(s *Scheduler) {
+		s.EmailNotifier = n
+	}
+}
+
+// WithHour sets the hour of the day (UTC) to send digests.
+func WithHour(h int) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Hour = h
+	}
+}
+
+// WithInterval sets the time between runs of the scheduler.
+func WithInterval(i time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Interval = i
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(l hclog.Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Logger = l.Named(loggerName)
+	}
+}
+
+// WithWeekday sets the day of the week to send weekly digests.
+func WithWeekday(d time.Weekday) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Weekday = d
+	}
+}
+
+// Run runs the scheduler until the process exits.
+func (s *Scheduler) Run() {
+	for {
+		if err := s.RunOnce(time.Now()); err != nil {
+			s.Logger.Error("error sending digests", "error", err)
+		}
+		time.Sleep(s.Interval)
+	}
+}
+
+// RunOnce sends digests that are due as of time now to all users with queued
+// notifications. Notifications queued for users who have since switched to
+// immediate emails are sent right away.
+func (s *Scheduler) RunOnce(now time.Time) error {
+	users, err := models.FindUsersWithQueuedNotifications(s.Database)
+	if err != nil {
+		return fmt.Errorf("error finding users with queued notifications: %w",
+			err)
+	}
+
+	for _, u := range users {
+		if err := s.sendDigest(u, now); err != nil {
+			s.Logger.Error("error sending digest",
+				"error", err,
+				"user", u.EmailAddress,
+			)
+		}
+	}
+
+	return nil
+}
+
+// sendDigest sends user u a digest of their queued notifications, if it is due
+// as of time now.
+func (s *Scheduler) sendDigest(u models.User, now time.Time) error {
+	var ns models.QueuedNotifications
+	if err := ns.FindByUserID(s.Database, u.ID); err != nil {
+		return fmt.Errorf("error finding queued notifications: %w", err)
+	}
+	if len(ns) == 0 {
+		return nil
+	}
+
+	// If no digest has been sent yet, the digest is due at the first scheduled
+	// time after the oldest notification was queued.
+	since := ns[0].CreatedAt
+	if u.LastDigestSentAt != nil {
+		since = *u.LastDigestSentAt
+	}
+	if !digestDue(u.DigestFrequency, since, now, s.Hour, s.Weekday) {
+		return nil
+	}
+
+	freq := u.DigestFrequency
+	if freq == "" {
+		freq = models.ImmediateDigestFrequency
+	}
+	e, err := email.NewDigestEmail(email.DigestEmailData{
+		BaseURL:   s.BaseURL,
+		Frequency: string(freq),
+		Products:  groupByProductAndTeam(ns),
+	})
+	if err == nil {
+		err = s.EmailNotifier.Send(notify.Message{
+			To:       []string{u.EmailAddress},
+			Event:    models.DigestNotificationEvent,
+			Subject:  e.Subject,
+			HTMLBody: e.Body,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("error sending digest email: %w", err)
+	}
+
+	if err := models.RecordDigestSent(s.Database, u.ID, ns, now); err != nil {
+		return fmt.Errorf("error recording digest: %w", err)
+	}
+	s.Logger.Info("sent digest",
+		"frequency", freq,
+		"notifications", len(ns),
+		"user", u.EmailAddress,
+	)
+
+	return nil
+}
+
+// digestDue returns true if a digest with frequency freq is due at time now,
+// i.e., a digest was scheduled (at hour of the day, and weekday for weekly
+// digests) after time since. Digests for other frequencies are always due.
+func digestDue(
+	freq models.DigestFrequency,
+	since, now time.Time,
+	hour int,
+	weekday time.Weekday,
+) bool {
+	if !freq.IsDigest() {
+		return true
+	}
+	return lastScheduledTime(freq, now, hour, weekday).After(since)
+}
+
+// lastScheduledTime returns the last time no later than now that a digest
+// with frequency freq was scheduled, at hour (UTC) every day for daily digests
+// or on weekday for weekly digests.
+func lastScheduledTime(
+	freq models.DigestFrequency,
+	now time.Time,
+	hour int,
+	weekday time.Weekday,
+) time.Time {
+	now = now.UTC()
+	t := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if t.After(now) {
+		t = t.AddDate(0, 0, -1)
+	}
+	if freq == models.WeeklyDigestFrequency {
+		for t.Weekday() != weekday {
+			t = t.AddDate(0, 0, -1)
+		}
+	}
+	return t
+}
+
+// groupByProductAndTeam groups queued notifications ns by product and team,
+// sorted by name. Notifications keep the order they were queued in.
+func groupByProductAndTeam(ns models.QueuedNotifications) []email.DigestProduct {
+	var products []email.DigestProduct
+	productIdx := map[string]int{}
+	teamIdx := map[string]map[string]int{}
+
+	for _, n := range ns {
+		pi, ok := productIdx[n.Product]
+		if !ok {
+			pi = len(products)
+			productIdx[n.Product] = pi
+			teamIdx[n.Product] = map[string]int{}
+			products = append(products, email.DigestProduct{Name: n.Product})
+		}
+
+		ti, ok := teamIdx[n.Product][n.Team]
+		if !ok {
+			ti = len(products[pi].Teams)
+			teamIdx[n.Product][n.Team] = ti
+			products[pi].Teams = append(products[pi].Teams,
+				email.DigestTeam{Name: n.Team})
+		}
+
+		products[pi].Teams[ti].Entries = append(products[pi].Teams[ti].Entries,
+			email.DigestEntry{
+				Commenters:        n.Commenters.Data,
+				DocumentOwner:     n.Owner,
+				DocumentShortName: n.DocumentNumber,
+				DocumentTitle:     n.DocumentTitle,
+				DocumentType:      n.DocumentType,
+				DocumentURL:       n.DocumentURL,
+				Event:             string(n.Event),
+			})
+	}
+
+	sort.SliceStable(products, func(i, j int) bool {
+		return products[i].Name < products[j].Name
+	})
+	for _, p := range products {
+		sort.SliceStable(p.Teams, func(i, j int) bool {
+			return p.Teams[i].Name < p.Teams[j].Name
+		})
+	}
+
+	return products
+}
+
+// ParseWeekday parses the name of a day of the week (e.g., "monday"), ignoring
+// case.
+func ParseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
@@ -0,0 +1,173 @@
+package digests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/internal/email"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+)
+
+func TestLastScheduledTime(t *testing.T) {
+	// 2023-05-10 is a Wednesday.
+	cases := map[string]struct {
+		freq models.DigestFrequency
+		now  time.Time
+		want time.Time
+	}{
+		"daily after scheduled hour": {
+			freq: models.DailyDigestFrequency,
+			now:  time.Date(2023, 5, 10, 10, 30, 0, 0, time.UTC),
+			want: time.Date(2023, 5, 10, 9, 0, 0, 0, time.UTC),
+		},
+		"daily at scheduled hour": {
+			freq: models.DailyDigestFrequency,
+			now:  time.Date(2023, 5, 10, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 5, 10, 9, 0, 0, 0, time.UTC),
+		},
+		"daily before scheduled hour": {
+			freq: models.DailyDigestFrequency,
+			now:  time.Date(2023, 5, 10, 8, 59, 0, 0, time.UTC),
+			want: time.Date(2023, 5, 9, 9, 0, 0, 0, time.UTC),
+		},
+		"daily in another time zone": {
+			freq: models.DailyDigestFrequency,
+			now: time.Date(2023, 5, 10, 12, 0, 0, 0,
+				time.FixedZone("IST", 5*60*60+30*60)),
+			want: time.Date(2023, 5, 9, 9, 0, 0, 0, time.UTC),
+		},
+		"weekly later in the week": {
+			freq: models.WeeklyDigestFrequency,
+			now:  time.Date(2023, 5, 10, 10, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 5, 8, 9, 0, 0, 0, time.UTC),
+		},
+		"weekly on weekday before scheduled hour": {
+			freq: models.WeeklyDigestFrequency,
+			now:  time.Date(2023, 5, 8, 8, 0, 0, 0, time.UTC),
+			want: time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC),
+		},
+		"weekly on weekday after scheduled hour": {
+			freq: models.WeeklyDigestFrequency,
+			now:  time.Date(2023, 5, 8, 9, 30, 0, 0, time.UTC),
+			want: time.Date(2023, 5, 8, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want,
+				lastScheduledTime(c.freq, c.now, 9, time.Monday))
+		})
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	now := time.Date(2023, 5, 10, 10, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		freq  models.DigestFrequency
+		since time.Time
+		want  bool
+	}{
+		"daily sent yesterday": {
+			freq:  models.DailyDigestFrequency,
+			since: time.Date(2023, 5, 9, 9, 5, 0, 0, time.UTC),
+			want:  true,
+		},
+		"daily sent today": {
+			freq:  models.DailyDigestFrequency,
+			since: time.Date(2023, 5, 10, 9, 5, 0, 0, time.UTC),
+			want:  false,
+		},
+		"weekly sent this week": {
+			freq:  models.WeeklyDigestFrequency,
+			since: time.Date(2023, 5, 8, 9, 5, 0, 0, time.UTC),
+			want:  false,
+		},
+		"weekly sent last week": {
+			freq:  models.WeeklyDigestFrequency,
+			since: time.Date(2023, 5, 1, 9, 5, 0, 0, time.UTC),
+			want:  true,
+		},
+		"immediate": {
+			freq:  models.ImmediateDigestFrequency,
+			since: now,
+			want:  true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want,
+				digestDue(c.freq, c.since, now, 9, time.Monday))
+		})
+	}
+}
+
+func TestGroupByProductAndTeam(t *testing.T) {
+	ns := models.QueuedNotifications{
+		{
+			Event:          models.PublishedSubscriptionEvent,
+			DocumentNumber: "PAYMT-001",
+			Product:        "Payments",
+			Team:           "Checkout",
+		},
+		{
+			Event:          models.ApprovedSubscriptionEvent,
+			DocumentNumber: "CAP-001",
+			Product:        "Capital",
+			Team:           "Lending",
+		},
+		{
+			Event:          models.CommentedSubscriptionEvent,
+			DocumentNumber: "PAYMT-002",
+			Product:        "Payments",
+			Team:           "Acquiring",
+			Commenters:     datatypes.JSONType[[]string]{Data: []string{"A"}},
+		},
+		{
+			Event:          models.ReviewStartedSubscriptionEvent,
+			DocumentNumber: "PAYMT-003",
+			Product:        "Payments",
+			Team:           "Checkout",
+		},
+	}
+
+	assert.Equal(t, []email.DigestProduct{
+		{
+			Name: "Capital",
+			Teams: []email.DigestTeam{
+				{
+					Name: "Lending",
+					Entries: []email.DigestEntry{
+						{DocumentShortName: "CAP-001", Event: "approved"},
+					},
+				},
+			},
+		},
+		{
+			Name: "Payments",
+			Teams: []email.DigestTeam{
+				{
+					Name: "Acquiring",
+					Entries: []email.DigestEntry{
+						{
+							Commenters:        []string{"A"},
+							DocumentShortName: "PAYMT-002",
+							Event:             "commented",
+						},
+					},
+				},
+				{
+					Name: "Checkout",
+					Entries: []email.DigestEntry{
+						{DocumentShortName: "PAYMT-001", Event: "published"},
+						{DocumentShortName: "PAYMT-003", Event: "reviewStarted"},
+					},
+				},
+			},
+		},
+	}, groupByProductAndTeam(ns))
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
-	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
 )
 
 //go:embed templates/*
@@ -39,6 +38,17 @@ type SubscriberDocumentPublishedEmailData struct {
 	Team              string
 }
 
+type SubscriberDocumentEventEmailData struct {
+	BaseURL           string
+	Commenters        []string
+	CurrentYear       int
+	DocumentOwner     string
+	DocumentShortName string
+	DocumentTitle     string
+	DocumentURL       string
+	Event             string
+}
+
 type ContributorRequestedEmailData struct {
 	BaseURL            string
 	CurrentYear        int
@@ -52,12 +62,17 @@ type ContributorRequestedEmailData struct {
 	DocumentTeam       string
 }
 
-func SendReviewRequestedEmail(
-	d ReviewRequestedEmailData,
-	to []string,
-	from string,
-	s *gw.Service,
-) error {
+// Email is a rendered email.
+type Email struct {
+	// Subject is the subject of the email.
+	Subject string
+
+	// Body is the HTML body of the email.
+	Body string
+}
+
+// NewReviewRequestedEmail renders the email requesting a review of a document.
+func NewReviewRequestedEmail(d ReviewRequestedEmailData) (*Email, error) {
 	// Validate data.
 	if err := validation.ValidateStruct(&d,
 		validation.Field(&d.BaseURL, validation.Required),
@@ -65,109 +80,345 @@ func SendReviewRequestedEmail(
 		validation.Field(&d.DocumentTitle, validation.Required),
 		validation.Field(&d.DocumentURL, validation.Required),
 	); err != nil {
-		return fmt.Errorf("error validating email data: %w", err)
+		return nil, fmt.Errorf("error validating email data: %w", err)
 	}
 
-	var body bytes.Buffer
-	tmpl, err := template.ParseFS(tmplFS, "templates/review-requested.html")
+	// Set current year.
+	d.CurrentYear = time.Now().Year()
+
+	body, err := renderTemplate("templates/review-requested.html", d)
 	if err != nil {
-		return fmt.Errorf("error parsing template: %w", err)
+		return nil, err
+	}
+
+	return &Email{
+		Subject: fmt.Sprintf("%s | Document Review Request from %s [%s]", d.DocumentTitle, d.DocumentOwner, d.DocumentOwnerEmail),
+		Body:    body,
+	}, nil
+}
+
+// NewContributorRequestedEmail renders the email inviting a user to
+// contribute to a document.
+func NewContributorRequestedEmail(
+	d ContributorRequestedEmailData) (*Email, error) {
+	// Validate data.
+	if err := validation.ValidateStruct(&d,
+		validation.Field(&d.BaseURL, validation.Required),
+		validation.Field(&d.DocumentOwner, validation.Required),
+		validation.Field(&d.DocumentTitle, validation.Required),
+		validation.Field(&d.DocumentURL, validation.Required),
+	); err != nil {
+		return nil, fmt.Errorf("error validating email data: %w", err)
 	}
 
 	// Set current year.
 	d.CurrentYear = time.Now().Year()
 
-	if err := tmpl.Execute(&body, d); err != nil {
-		return fmt.Errorf("error executing template: %w", err)
+	body, err := renderTemplate("templates/contributor.html", d)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = s.SendEmail(
-		to,
-		from,
-		fmt.Sprintf("%s | Document Review Request from %s [%s]", d.DocumentTitle, d.DocumentOwner, d.DocumentOwnerEmail),
-		body.String(),
-	)
-	return err
+	return &Email{
+		Subject: fmt.Sprintf("%s | Document Contribution Request from %s [%s]", d.DocumentTitle, d.DocumentOwner, d.DocumentOwnerEmail),
+		Body:    body,
+	}, nil
 }
 
-func SendContributorRequestedEmail(
-	d ContributorRequestedEmailData,
-	to []string,
-	from string,
-	s *gw.Service,
-) error {
+// NewSubscriberDocumentPublishedEmail renders the email notifying subscribers
+// of the publication of a document.
+func NewSubscriberDocumentPublishedEmail(
+	d SubscriberDocumentPublishedEmailData) (*Email, error) {
 	// Validate data.
 	if err := validation.ValidateStruct(&d,
 		validation.Field(&d.BaseURL, validation.Required),
 		validation.Field(&d.DocumentOwner, validation.Required),
 		validation.Field(&d.DocumentTitle, validation.Required),
 		validation.Field(&d.DocumentURL, validation.Required),
+		validation.Field(&d.Product, validation.Required),
 	); err != nil {
-		return fmt.Errorf("error validating email data: %w", err)
+		return nil, fmt.Errorf("error validating email data: %w", err)
 	}
 
-	var body bytes.Buffer
-	tmpl, err := template.ParseFS(tmplFS, "templates/contributor.html")
+	// Set current year.
+	d.CurrentYear = time.Now().Year()
+
+	body, err := renderTemplate(
+		"templates/subscriber-document-published.html", d)
 	if err != nil {
-		return fmt.Errorf("error parsing template: %w", err)
+		return nil, err
+	}
+
+	return &Email{
+		Subject: fmt.Sprintf("New %s: [%s] %s",
+			d.DocumentType,
+			d.DocumentShortName,
+			d.DocumentTitle,
+		),
+		Body: body,
+	}, nil
+}
+
+// NewSubscriberDocumentEventEmail renders the email notifying subscribers of
+// an event of a document other than its publication. Event is one of
+// "reviewStarted", "approved", or "commented".
+func NewSubscriberDocumentEventEmail(
+	d SubscriberDocumentEventEmailData) (*Email, error) {
+	// Validate data.
+	if err := validation.ValidateStruct(&d,
+		validation.Field(&d.BaseURL, validation.Required),
+		validation.Field(&d.DocumentOwner, validation.Required),
+		validation.Field(&d.DocumentTitle, validation.Required),
+		validation.Field(&d.DocumentURL, validation.Required),
+		validation.Field(&d.Event, validation.Required,
+			validation.In("reviewStarted", "approved", "commented")),
+	); err != nil {
+		return nil, fmt.Errorf("error validating email data: %w", err)
 	}
 
 	// Set current year.
 	d.CurrentYear = time.Now().Year()
 
+	body, err := renderTemplate(
+		"templates/subscriber-document-event.html", d)
+	if err != nil {
+		return nil, err
+	}
+
+	var subject string
+	switch d.Event {
+	case "reviewStarted":
+		subject = "In Review"
+	case "approved":
+		subject = "Approved"
+	case "commented":
+		subject = "New Comments"
+	}
+	return &Email{
+		Subject: fmt.Sprintf("%s: [%s] %s",
+			subject,
+			d.DocumentShortName,
+			d.DocumentTitle,
+		),
+		Body: body,
+	}, nil
+}
+
+// renderTemplate executes the email template with name name using data d.
+func renderTemplate(name string, d interface{}) (string, error) {
+	tmpl, err := template.ParseFS(tmplFS, name)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template: %w", err)
+	}
+
+	var body bytes.Buffer
 	if err := tmpl.Execute(&body, d); err != nil {
-		return fmt.Errorf("error executing template: %w", err)
+		return "", fmt.Errorf("error executing template: %w", err)
 	}
+	return body.String(), nil
+}
 
-	_, err = s.SendEmail(
-		to,
-		from,
-		fmt.Sprintf("%s | Document Contribution Request from %s [%s]", d.DocumentTitle, d.DocumentOwner, d.DocumentOwnerEmail),
-		body.String(),
-	)
-	return err
+type ReviewReminderEmailData struct {
+	BaseURL            string
+	CurrentYear        int
+	DocumentOwner      string
+	DocumentOwnerEmail string
+	DocumentTitle      string
+	DocumentURL        string
+	DocumentProd       string
+	DocumentTeam       string
+	DueDate            string
+	Overdue            bool
 }
 
-func SendSubscriberDocumentPublishedEmail(
-	d SubscriberDocumentPublishedEmailData,
-	to []string,
-	from string,
-	s *gw.Service,
-) error {
+// NewReviewReminderEmail renders the email reminding a reviewer that the review
+// of a document is due soon or overdue.
+func NewReviewReminderEmail(d ReviewReminderEmailData) (*Email, error) {
 	// Validate data.
 	if err := validation.ValidateStruct(&d,
 		validation.Field(&d.BaseURL, validation.Required),
 		validation.Field(&d.DocumentOwner, validation.Required),
 		validation.Field(&d.DocumentTitle, validation.Required),
 		validation.Field(&d.DocumentURL, validation.Required),
-		validation.Field(&d.Product, validation.Required),
+		validation.Field(&d.DueDate, validation.Required),
 	); err != nil {
-		return fmt.Errorf("error validating email data: %w", err)
+		return nil, fmt.Errorf("error validating email data: %w", err)
 	}
 
-	var body bytes.Buffer
-	tmpl, err := template.ParseFS(
-		tmplFS, "templates/subscriber-document-published.html")
+	// Set current year.
+	d.CurrentYear = time.Now().Year()
+
+	body, err := renderTemplate("templates/review-reminder.html", d)
 	if err != nil {
-		return fmt.Errorf("error parsing template: %w", err)
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("%s | Document Review Due on %s", d.DocumentTitle, d.DueDate)
+	if d.Overdue {
+		subject = fmt.Sprintf("%s | Document Review Overdue since %s", d.DocumentTitle, d.DueDate)
+	}
+	return &Email{
+		Subject: subject,
+		Body:    body,
+	}, nil
+}
+
+type ApprovalReceivedEmailData struct {
+	BaseURL           string
+	CurrentYear       int
+	Approver          string
+	ApprovalCount     int
+	DocumentShortName string
+	DocumentTitle     string
+	DocumentURL       string
+	FullyApproved     bool
+	ReviewerCount     int
+}
+
+// NewApprovalReceivedEmail renders the email notifying a document owner that a
+// reviewer approved their document.
+func NewApprovalReceivedEmail(d ApprovalReceivedEmailData) (*Email, error) {
+	// Validate data.
+	if err := validation.ValidateStruct(&d,
+		validation.Field(&d.BaseURL, validation.Required),
+		validation.Field(&d.Approver, validation.Required),
+		validation.Field(&d.DocumentTitle, validation.Required),
+		validation.Field(&d.DocumentURL, validation.Required),
+	); err != nil {
+		return nil, fmt.Errorf("error validating email data: %w", err)
 	}
 
 	// Set current year.
 	d.CurrentYear = time.Now().Year()
 
-	if err := tmpl.Execute(&body, d); err != nil {
-		return fmt.Errorf("error executing template: %w", err)
+	body, err := renderTemplate("templates/approval-received.html", d)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = s.SendEmail(
-		to,
-		from,
-		fmt.Sprintf("New %s: [%s] %s",
-			d.DocumentType,
-			d.DocumentShortName,
-			d.DocumentTitle,
-		),
-		body.String(),
-	)
-	return err
+	subject := fmt.Sprintf("%s | Document Approved by %s", d.DocumentTitle, d.Approver)
+	if d.FullyApproved {
+		subject = fmt.Sprintf("%s | Document Approved", d.DocumentTitle)
+	}
+	return &Email{
+		Subject: subject,
+		Body:    body,
+	}, nil
+}
+
+type SavedSearchMatchesEmailData struct {
+	BaseURL         string
+	CurrentYear     int
+	Documents       []SavedSearchMatchDocument
+	SavedSearchName string
+}
+
+// SavedSearchMatchDocument is a document that newly matched a saved search.
+type SavedSearchMatchDocument struct {
+	DocumentOwner string
+	DocumentProd  string
+	DocumentTitle string
+	DocumentType  string
+	DocumentURL   string
+}
+
+// NewSavedSearchMatchesEmail renders the email notifying a user of documents
+// that newly matched one of their saved searches.
+func NewSavedSearchMatchesEmail(d SavedSearchMatchesEmailData) (*Email, error) {
+	// Validate data.
+	if err := validation.ValidateStruct(&d,
+		validation.Field(&d.BaseURL, validation.Required),
+		validation.Field(&d.Documents, validation.Required),
+		validation.Field(&d.SavedSearchName, validation.Required),
+	); err != nil {
+		return nil, fmt.Errorf("error validating email data: %w", err)
+	}
+
+	// Set current year.
+	d.CurrentYear = time.Now().Year()
+
+	body, err := renderTemplate("templates/saved-search-matches.html", d)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("%s | %d New Matching Documents",
+		d.SavedSearchName, len(d.Documents))
+	if len(d.Documents) == 1 {
+		subject = fmt.Sprintf("%s | New Matching Document", d.SavedSearchName)
+	}
+	return &Email{
+		Subject: subject,
+		Body:    body,
+	}, nil
+}
+
+type DigestEmailData struct {
+	BaseURL     string
+	CurrentYear int
+	Frequency   string
+	Products    []DigestProduct
+}
+
+// DigestProduct is a product (business unit) in a digest email, with its
+// document events grouped by team.
+type DigestProduct struct {
+	Name  string
+	Teams []DigestTeam
+}
+
+// DigestTeam is a team in a digest email, with its document events.
+type DigestTeam struct {
+	Name    string
+	Entries []DigestEntry
+}
+
+// DigestEntry is a document event in a digest email. Event is one of
+// "published", "reviewStarted", "approved", or "commented".
+type DigestEntry struct {
+	Commenters        []string
+	DocumentOwner     string
+	DocumentShortName string
+	DocumentTitle     string
+	DocumentType      string
+	DocumentURL       string
+	Event             string
+}
+
+// NewDigestEmail renders the email summarizing the document events a user was
+// subscribed to since their last digest. Frequency is one of "daily",
+// "weekly", or "immediate" (for events queued before the user stopped
+// receiving digests).
+func NewDigestEmail(d DigestEmailData) (*Email, error) {
+	// Validate data.
+	if err := validation.ValidateStruct(&d,
+		validation.Field(&d.BaseURL, validation.Required),
+		validation.Field(&d.Frequency, validation.Required,
+			validation.In("immediate", "daily", "weekly")),
+		validation.Field(&d.Products, validation.Required),
+	); err != nil {
+		return nil, fmt.Errorf("error validating email data: %w", err)
+	}
+
+	// Set current year.
+	d.CurrentYear = time.Now().Year()
+
+	body, err := renderTemplate("templates/digest.html", d)
+	if err != nil {
+		return nil, err
+	}
+
+	var subject string
+	switch d.Frequency {
+	case "daily":
+		subject = "Your Daily DocVault Digest"
+	case "weekly":
+		subject = "Your Weekly DocVault Digest"
+	default:
+		subject = "Your DocVault Digest"
+	}
+	return &Email{
+		Subject: subject,
+		Body:    body,
+	}, nil
 }
@@ -0,0 +1,218 @@
+package indexer
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
+	"google.golang.org/api/drive/v3"
+)
+
+const (
+	// NotificationsPath is the path that Drive push notifications are served
+	// on.
+	NotificationsPath = "/drive/notifications"
+
+	// changesChannelLifetime is the lifetime of push notification channels.
+	changesChannelLifetime = 24 * time.Hour
+
+	// changesChannelRenewBefore is the time before a push notification channel
+	// expires that it is renewed.
+	changesChannelRenewBefore = time.Hour
+
+	// minRunInterval is the minimum time between the start of indexer runs
+	// triggered by push notifications, which limits runs when many changes are
+	// made in a short time.
+	minRunInterval = 10 * time.Second
+)
+
+// NotificationHandler returns an HTTP handler for Drive push notifications,
+// which trigger an indexer run.
+func (idx *Indexer) NotificationHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		tok := r.Header.Get("X-Goog-Channel-Token")
+		if idx.NotificationToken == "" || subtle.ConstantTimeCompare(
+			[]byte(tok), []byte(idx.NotificationToken)) != 1 {
+			idx.Logger.Warn("invalid Drive push notification token",
+				"channel_id", r.Header.Get("X-Goog-Channel-ID"),
+			)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		// The "sync" notification is sent when a channel is created.
+		if r.Header.Get("X-Goog-Resource-State") != "sync" {
+			idx.notifyChanges()
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// notifyChanges triggers an indexer run without waiting for the poll interval.
+// Notifications received before the next run are coalesced.
+func (idx *Indexer) notifyChanges() {
+	select {
+	case idx.changesNotifications <- struct{}{}:
+	default:
+	}
+}
+
+// waitForNextRun waits until the next indexer run, which starts after the poll
+// interval or when changes are notified, but no earlier than minRunInterval
+// after the start of the previous run at runStartedAt.
+func (idx *Indexer) waitForNextRun(runStartedAt time.Time) {
+	select {
+	case <-idx.changesNotifications:
+		idx.Logger.Info("received Drive change notification")
+		time.Sleep(time.Until(runStartedAt.Add(minRunInterval)))
+	case <-time.After(idx.PollInterval):
+	}
+}
+
+// getChangedDocs returns documents in the documents folder that were changed
+// since the folder's Changes API page token, and updates the folder's page
+// token. If the folder doesn't have a page token yet, it returns documents
+// modified between afterTime and beforeTime (RFC 3339 timestamps) instead.
+func (idx *Indexer) getChangedDocs(
+	cw storage.ChangeWatcher,
+	fd *models.IndexerFolder,
+	afterTime, beforeTime string,
+) ([]*drive.File, error) {
+	if fd.ChangesPageToken == "" {
+		// Get the page token before listing documents so that changes made in
+		// between aren't missed.
+		tok, err := cw.GetChangesStartPageToken()
+		if err != nil {
+			return nil, err
+		}
+		files, err := idx.StorageProvider.GetUpdatedDocsBetween(
+			idx.DocumentsFolderID, afterTime, beforeTime)
+		if err != nil {
+			return nil, err
+		}
+		fd.ChangesPageToken = tok
+
+		return files, nil
+	}
+
+	changes, tok, err := cw.ListChanges(fd.ChangesPageToken)
+	if err != nil {
+		return nil, err
+	}
+	fd.ChangesPageToken = tok
+
+	return changedDocs(changes, idx.DocumentsFolderID), nil
+}
+
+// changedDocs returns the documents in folder folderID whose last change in
+// changes wasn't a removal or trashing, in order of their last change.
+func changedDocs(changes []*drive.Change, folderID string) []*drive.File {
+	var (
+		files []*drive.File
+		seen  = make(map[string]bool)
+	)
+
+	// Iterate in reverse to only consider the last change to each file.
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if seen[c.FileId] {
+			continue
+		}
+		seen[c.FileId] = true
+
+		f := c.File
+		if c.Removed || f == nil || f.Trashed ||
+			f.MimeType != storage.DocumentMimeType || !hasParent(f, folderID) {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	// Restore the order of changes.
+	for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+		files[i], files[j] = files[j], files[i]
+	}
+	return files
+}
+
+// hasParent returns true if folder folderID is a parent of file f.
+func hasParent(f *drive.File, folderID string) bool {
+	for _, p := range f.Parents {
+		if p == folderID {
+			return true
+		}
+	}
+	return false
+}
+
+// watchChanges subscribes to push notifications for changes since page token
+// pageToken, if push notifications are configured and the current
+// subscription doesn't exist or expires soon.
+func (idx *Indexer) watchChanges(cw storage.ChangeWatcher, pageToken string) {
+	log := idx.Logger
+
+	if idx.NotificationURL == "" || pageToken == "" {
+		return
+	}
+	if ch := idx.changesChannel; ch != nil && time.Until(
+		time.UnixMilli(ch.Expiration)) > changesChannelRenewBefore {
+		return
+	}
+
+	ch, err := cw.WatchChanges(pageToken, uuid.NewString(),
+		idx.NotificationURL, idx.NotificationToken,
+		time.Now().Add(changesChannelLifetime))
+	if err != nil {
+		log.Error("error subscribing to Drive push notifications",
+			"error", err,
+		)
+		return
+	}
+	log.Info("subscribed to Drive push notifications",
+		"channel_id", ch.Id,
+		"expiration", time.UnixMilli(ch.Expiration).UTC(),
+	)
+
+	// Stop the previous subscription.
+	if old := idx.changesChannel; old != nil {
+		if err := cw.StopChannel(old); err != nil {
+			log.Warn("error stopping previous Drive push notification channel",
+				"error", err,
+				"channel_id", old.Id,
+			)
+		}
+	}
+	idx.changesChannel = ch
+}
+
+// changeWatcher returns the storage provider as a change watcher, if the
+// indexer uses the Drive Changes API.
+func (idx *Indexer) changeWatcher() (storage.ChangeWatcher, bool) {
+	if !idx.UseDriveChanges {
+		return nil, false
+	}
+	cw, ok := idx.StorageProvider.(storage.ChangeWatcher)
+	return cw, ok
+}
+
+// validateChangeWatcher validates that the storage provider supports the Drive
+// Changes API, if it is used.
+func (idx *Indexer) validateChangeWatcher(interface{}) error {
+	if !idx.UseDriveChanges {
+		return nil
+	}
+	if _, ok := idx.StorageProvider.(storage.ChangeWatcher); !ok {
+		return fmt.Errorf("storage provider doesn't support the Changes API")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package indexer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/pkg/storage"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestChangedDocs(t *testing.T) {
+	doc := func(id string, parents ...string) *drive.File {
+		return &drive.File{
+			Id:       id,
+			MimeType: storage.DocumentMimeType,
+			Parents:  parents,
+		}
+	}
+
+	trashed := doc("doc3", "docs")
+	trashed.Trashed = true
+	folder := &drive.File{
+		Id:       "folder1",
+		MimeType: storage.FolderMimeType,
+		Parents:  []string{"docs"},
+	}
+
+	changes := []*drive.Change{
+		{FileId: "doc1", File: doc("doc1", "docs")},
+		{FileId: "doc2", File: doc("doc2", "drafts")},
+		{FileId: "doc3", File: doc("doc3", "docs")},
+		{FileId: "doc3", File: trashed},
+		{FileId: "doc4", File: doc("doc4", "docs")},
+		{FileId: "doc4", Removed: true},
+		{FileId: "folder1", File: folder},
+		{FileId: "doc5", File: doc("doc5", "docs")},
+		{FileId: "doc1", File: doc("doc1", "docs")},
+	}
+
+	var ids []string
+	for _, f := range changedDocs(changes, "docs") {
+		ids = append(ids, f.Id)
+	}
+	assert.Equal(t, []string{"doc5", "doc1"}, ids)
+}
+
+func TestNotificationHandler(t *testing.T) {
+	cases := map[string]struct {
+		method        string
+		token         string
+		resourceState string
+		wantCode      int
+		wantNotified  bool
+	}{
+		"change": {
+			method:        "POST",
+			token:         "secret",
+			resourceState: "change",
+			wantCode:      http.StatusOK,
+			wantNotified:  true,
+		},
+		"sync": {
+			method:        "POST",
+			token:         "secret",
+			resourceState: "sync",
+			wantCode:      http.StatusOK,
+		},
+		"invalid token": {
+			method:        "POST",
+			token:         "other",
+			resourceState: "change",
+			wantCode:      http.StatusForbidden,
+		},
+		"invalid method": {
+			method:   "GET",
+			token:    "secret",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			idx := &Indexer{
+				Logger:               hclog.NewNullLogger(),
+				NotificationToken:    "secret",
+				changesNotifications: make(chan struct{}, 1),
+			}
+
+			req := httptest.NewRequest(c.method, NotificationsPath, nil)
+			req.Header.Set("X-Goog-Channel-Token", c.token)
+			req.Header.Set("X-Goog-Resource-State", c.resourceState)
+			rr := httptest.NewRecorder()
+			idx.NotificationHandler().ServeHTTP(rr, req)
+
+			assert.Equal(c.wantCode, rr.Code)
+			assert.Equal(c.wantNotified, len(idx.changesNotifications) == 1)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/internal/email"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
+	"google.golang.org/api/drive/v3"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// commentsFolderPrefix prefixes the documents folder ID in the indexer folder
+// record that stores when comments were last checked, to not conflict with the
+// last indexed time of the folder.
+const commentsFolderPrefix = "comments:"
+
+// notifyCommentSubscribers emails subscribers of documents in review about
+// comments created since comments were last checked, and no later than now.
+// Comments are only checked if subscriber emails are enabled and the storage
+// provider supports listing comments. Existing comments are skipped the first
+// time comments are checked.
+func (idx *Indexer) notifyCommentSubscribers(now time.Time) error {
+	if idx.EmailNotifier == nil {
+		return nil
+	}
+	cl, ok := idx.StorageProvider.(storage.CommentLister)
+	if !ok {
+		return nil
+	}
+	db := idx.Database
+
+	fd := models.IndexerFolder{
+		GoogleDriveID: commentsFolderPrefix + idx.DocumentsFolderID,
+	}
+	if err := fd.Get(db); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("error getting comments folder data: %w", err)
+		}
+		fd.LastIndexedAt = now
+		return fd.Upsert(db)
+	}
+	afterTime := fd.LastIndexedAt.UTC().Format(time.RFC3339Nano)
+
+	var docs models.Documents
+	if err := docs.Find(db, "status = ?", models.InReviewDocumentStatus); err != nil {
+		return fmt.Errorf("error finding in-review documents: %w", err)
+	}
+
+	for _, d := range docs {
+		comments, err := cl.ListComments(d.GoogleFileID, afterTime)
+		if err != nil {
+			idx.Logger.Error("error listing document comments",
+				"error", err,
+				"google_file_id", d.GoogleFileID,
+			)
+			continue
+		}
+
+		commenters, commenterEmails := newCommenters(comments, now)
+		if len(commenters) == 0 {
+			continue
+		}
+		if err := idx.emailCommentSubscribers(
+			d, commenters, commenterEmails); err != nil {
+			idx.Logger.Error("error notifying comment subscribers",
+				"error", err,
+				"google_file_id", d.GoogleFileID,
+			)
+		}
+	}
+
+	fd.LastIndexedAt = now
+	return fd.Upsert(db)
+}
+
+// emailCommentSubscribers emails subscribers of comments on document d, except
+// the commenters. Notifications for subscribers in digest mode are queued for
+// their next digest email instead.
+func (idx *Indexer) emailCommentSubscribers(
+	d models.Document, commenters, commenterEmails []string) error {
+	subscribers, err := models.FindSubscribers(idx.Database,
+		models.SubscriptionDocument{
+			DocumentType: d.DocumentType.Name,
+			GoogleFileID: d.GoogleFileID,
+			Project:      d.Project.Name,
+			Team:         d.Team.Name,
+		},
+		models.CommentedSubscriptionEvent,
+	)
+	if err != nil {
+		return fmt.Errorf("error finding subscribers: %w", err)
+	}
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	docObj, err := hcd.NewEmptyDoc(d.DocumentType.Name)
+	if err != nil {
+		return fmt.Errorf("error creating new empty document: %w", err)
+	}
+	if err := idx.SearchProvider.Docs().GetObject(
+		d.GoogleFileID, &docObj); err != nil {
+		return fmt.Errorf("error getting document object: %w", err)
+	}
+	var owner string
+	if len(docObj.GetOwners()) > 0 {
+		owner = docObj.GetOwners()[0]
+	}
+
+	docURL, err := url.Parse(idx.BaseURL)
+	if err != nil {
+		return fmt.Errorf("error parsing base URL: %w", err)
+	}
+	docURL.Path = path.Join(docURL.Path, "document", d.GoogleFileID)
+
+	frequencies, err := models.GetDigestFrequencies(idx.Database, subscribers)
+	if err != nil {
+		return fmt.Errorf("error getting subscriber digest frequencies: %w", err)
+	}
+
+	for _, subscriber := range subscribers {
+		if containsFold(commenterEmails, subscriber) {
+			continue
+		}
+
+		if frequencies[strings.ToLower(subscriber)].IsDigest() {
+			qn := models.QueuedNotification{
+				User:           models.User{EmailAddress: subscriber},
+				Event:          models.CommentedSubscriptionEvent,
+				GoogleFileID:   d.GoogleFileID,
+				DocumentNumber: docObj.GetDocNumber(),
+				DocumentTitle:  docObj.GetTitle(),
+				DocumentType:   d.DocumentType.Name,
+				DocumentURL:    docURL.String(),
+				Owner:          owner,
+				Product:        docObj.GetProduct(),
+				Team:           d.Team.Name,
+				Commenters: datatypes.JSONType[[]string]{
+					Data: commenters,
+				},
+			}
+			if err := qn.Create(idx.Database); err != nil {
+				idx.Logger.Error("error queueing comment subscriber notification",
+					"error", err,
+					"google_file_id", d.GoogleFileID,
+					"subscriber", subscriber,
+				)
+			}
+			continue
+		}
+		e, err := email.NewSubscriberDocumentEventEmail(
+			email.SubscriberDocumentEventEmailData{
+				BaseURL:           idx.BaseURL,
+				Commenters:        commenters,
+				DocumentOwner:     owner,
+				DocumentShortName: docObj.GetDocNumber(),
+				DocumentTitle:     docObj.GetTitle(),
+				DocumentURL:       docURL.String(),
+				Event:             string(models.CommentedSubscriptionEvent),
+			},
+		)
+		if err == nil {
+			err = idx.EmailNotifier.Send(notify.Message{
+				To:       []string{subscriber},
+				Subject:  e.Subject,
+				HTMLBody: e.Body,
+			})
+		}
+		if err != nil {
+			idx.Logger.Error("error sending comment subscriber email",
+				"error", err,
+				"google_file_id", d.GoogleFileID,
+				"subscriber", subscriber,
+			)
+			continue
+		}
+		idx.Logger.Info("comment subscriber email sent",
+			"google_file_id", d.GoogleFileID,
+		)
+	}
+
+	return nil
+}
+
+// newCommenters returns the unique display names and email addresses of the
+// authors of comments created no later than before.
+func newCommenters(
+	comments []*drive.Comment, before time.Time) (names, emails []string) {
+	for _, c := range comments {
+		created, err := time.Parse(time.RFC3339Nano, c.CreatedTime)
+		if err != nil || created.After(before) {
+			continue
+		}
+
+		name := "Someone"
+		var addr string
+		if c.Author != nil {
+			if c.Author.DisplayName != "" {
+				name = c.Author.DisplayName
+			}
+			addr = c.Author.EmailAddress
+		}
+		if !containsFold(names, name) {
+			names = append(names, name)
+		}
+		if addr != "" && !containsFold(emails, addr) {
+			emails = append(emails, addr)
+		}
+	}
+	return names, emails
+}
+
+// containsFold returns true if a string is present in a slice of strings,
+// ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
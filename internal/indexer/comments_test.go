@@ -0,0 +1,45 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/drive/v3"
+)
+
+func TestNewCommenters(t *testing.T) {
+	before := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+	comments := []*drive.Comment{
+		{
+			CreatedTime: "2023-05-10T11:00:00.000Z",
+			Author: &drive.User{
+				DisplayName:  "Alice",
+				EmailAddress: "alice@example.com",
+			},
+		},
+		{
+			CreatedTime: "2023-05-10T11:30:00.000Z",
+			Author: &drive.User{
+				DisplayName:  "Alice",
+				EmailAddress: "ALICE@example.com",
+			},
+		},
+		{
+			CreatedTime: "2023-05-10T11:45:00.000Z",
+			Author:      &drive.User{DisplayName: "Bob"},
+		},
+		{
+			// Created after the check started.
+			CreatedTime: "2023-05-10T12:00:01.000Z",
+			Author:      &drive.User{DisplayName: "Carol"},
+		},
+		{
+			CreatedTime: "2023-05-10T11:50:00.000Z",
+		},
+	}
+
+	names, emails := newCommenters(comments, before)
+	assert.Equal(t, []string{"Alice", "Bob", "Someone"}, names)
+	assert.Equal(t, []string{"alice@example.com"}, emails)
+}
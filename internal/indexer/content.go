@@ -0,0 +1,169 @@
+package indexer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+)
+
+// maxSectionContentSize is the maximum size of the content of a section object
+// in bytes. Longer sections are split into multiple objects.
+const maxSectionContentSize = 8000
+
+// sectionObject is a section of a document's content in the sections search
+// index.
+type sectionObject struct {
+	// ObjectID is the ID of the section object, which is the document's ID and
+	// the section's position.
+	ObjectID string `json:"objectID"`
+
+	// DocID is the ID of the document that the section belongs to.
+	DocID string `json:"docID"`
+
+	// Position is the zero-based position of the section object in the
+	// document.
+	Position int `json:"position"`
+
+	// Heading is the heading of the section.
+	Heading string `json:"heading,omitempty"`
+
+	// HeadingLevel is the level of the section's heading (1-6), 0 for the
+	// document title, or -1 for content before the first heading.
+	HeadingLevel int `json:"headingLevel"`
+
+	// Content is the text of the section's paragraphs and tables.
+	Content string `json:"content"`
+
+	// The following fields are copied from the document so that sections can
+	// be filtered and displayed without looking up their document.
+	DocNumber    string   `json:"docNumber,omitempty"`
+	DocType      string   `json:"docType"`
+	ModifiedTime int64    `json:"modifiedTime"`
+	Owners       []string `json:"owners,omitempty"`
+	Product      string   `json:"product,omitempty"`
+	Status       string   `json:"status,omitempty"`
+	Title        string   `json:"title"`
+}
+
+// indexContent indexes the content of document doc as sections in the search
+// index, replacing any previously indexed sections, and returns the content to
+// store in the document object, trimmed to maxContentSize.
+func (idx *Indexer) indexContent(doc hcd.Doc) (string, error) {
+	sp := idx.SearchProvider
+	docID := doc.GetObjectID()
+
+	gDoc, err := idx.StorageProvider.GetDoc(docID)
+	if err != nil {
+		return "", fmt.Errorf("error getting document content: %w", err)
+	}
+	var sections []gw.DocSection
+	if gDoc.Body != nil {
+		sections = gw.GetSections(gDoc.Body)
+	}
+
+	// Delete previously indexed sections, which may no longer exist.
+	if err := sp.Sections().DeleteBy(
+		[][]string{{"docID:" + docID}}); err != nil {
+		return "", fmt.Errorf("error deleting document sections: %w", err)
+	}
+
+	for _, o := range newSectionObjects(doc, sections) {
+		if err := sp.Sections().SaveObject(o); err != nil {
+			return "", fmt.Errorf("error saving document section: %w", err)
+		}
+	}
+
+	var content []string
+	for _, s := range sections {
+		if s.Heading != "" {
+			content = append(content, s.Heading)
+		}
+		if text := s.Text(); text != "" {
+			content = append(content, text)
+		}
+	}
+	return trimContent(strings.Join(content, "\n"), maxContentSize), nil
+}
+
+// newSectionObjects returns the section objects for sections of document doc.
+// Sections with content longer than maxSectionContentSize are split into
+// multiple objects with the same heading.
+func newSectionObjects(doc hcd.Doc, sections []gw.DocSection) []sectionObject {
+	var objs []sectionObject
+	for _, s := range sections {
+		for _, content := range splitContent(s.Text(), maxSectionContentSize) {
+			objs = append(objs, sectionObject{
+				ObjectID:     fmt.Sprintf("%s_%d", doc.GetObjectID(), len(objs)),
+				DocID:        doc.GetObjectID(),
+				Position:     len(objs),
+				Heading:      s.Heading,
+				HeadingLevel: s.HeadingLevel,
+				Content:      content,
+				DocNumber:    doc.GetDocNumber(),
+				DocType:      doc.GetDocType(),
+				ModifiedTime: doc.GetModifiedTime(),
+				Owners:       doc.GetOwners(),
+				Product:      doc.GetProduct(),
+				Status:       doc.GetStatus(),
+				Title:        doc.GetTitle(),
+			})
+		}
+	}
+	return objs
+}
+
+// splitContent splits content s into chunks of at most max bytes, at line
+// breaks where possible. It always returns at least one (possibly empty)
+// chunk.
+func splitContent(s string, max int) []string {
+	var (
+		chunks []string
+		cur    strings.Builder
+	)
+	flush := func() {
+		if cur.Len() > 0 {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, line := range strings.Split(s, "\n") {
+		// Split lines that are too long on their own.
+		for len(line) > max {
+			flush()
+			chunk := trimContent(line, max)
+			chunks = append(chunks, chunk)
+			line = line[len(chunk):]
+		}
+
+		if cur.Len() > 0 && cur.Len()+1+len(line) > max {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteByte('\n')
+		}
+		cur.WriteString(line)
+	}
+	flush()
+
+	if len(chunks) == 0 {
+		chunks = []string{""}
+	}
+	return chunks
+}
+
+// trimContent trims content s to at most max bytes without splitting a UTF-8
+// encoded character.
+func trimContent(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
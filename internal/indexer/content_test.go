@@ -0,0 +1,86 @@
+package indexer
+
+import (
+	"strings"
+	"testing"
+
+	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitContent(t *testing.T) {
+	cases := map[string]struct {
+		s    string
+		max  int
+		want []string
+	}{
+		"empty": {
+			s:    "",
+			max:  10,
+			want: []string{""},
+		},
+		"fits": {
+			s:    "one\ntwo",
+			max:  10,
+			want: []string{"one\ntwo"},
+		},
+		"split at line breaks": {
+			s:    "one\ntwo\nthree\nfour",
+			max:  9,
+			want: []string{"one\ntwo", "three", "four"},
+		},
+		"split long line": {
+			s:    "abcdefghij\nk",
+			max:  4,
+			want: []string{"abcd", "efgh", "ij\nk"},
+		},
+		"don't split characters": {
+			s:    "aéé",
+			max:  4,
+			want: []string{"aé", "é"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, splitContent(c.s, c.max))
+		})
+	}
+}
+
+func TestNewSectionObjects(t *testing.T) {
+	assert := assert.New(t)
+
+	doc := &hcd.RFC{}
+	doc.ObjectID = "doc1"
+	doc.DocType = "RFC"
+	doc.Title = "Retries"
+	long := strings.Repeat("a", maxSectionContentSize) + "\nb"
+
+	objs := newSectionObjects(doc, []gw.DocSection{
+		{
+			HeadingLevel: -1,
+			Content:      []gw.DocSectionContent{{Paragraph: "Intro"}},
+		},
+		{
+			Heading:      "Design",
+			HeadingLevel: 1,
+			Content:      []gw.DocSectionContent{{Paragraph: long}},
+		},
+	})
+	if assert.Len(objs, 3) {
+		assert.Equal("doc1_0", objs[0].ObjectID)
+		assert.Equal("Intro", objs[0].Content)
+		assert.Equal("", objs[0].Heading)
+
+		for i, o := range objs[1:] {
+			assert.Equal("Design", o.Heading)
+			assert.Equal(i+1, o.Position)
+			assert.Equal("doc1", o.DocID)
+			assert.Equal("RFC", o.DocType)
+			assert.Equal("Retries", o.Title)
+		}
+		assert.Equal("b", objs[2].Content)
+	}
+}
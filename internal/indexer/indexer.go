@@ -3,17 +3,18 @@ package indexer
 import (
 	"errors"
 	"fmt"
-	"os"
 	"sync"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
-	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"github.com/hashicorp-forge/hermes/internal/notify"
 	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
 	"github.com/hashicorp-forge/hermes/pkg/links"
 	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"github.com/hashicorp/go-hclog"
+	"google.golang.org/api/drive/v3"
 	"gorm.io/gorm"
 )
 
@@ -29,9 +30,6 @@ const (
 
 // Indexer contains the indexer configuration.
 type Indexer struct {
-	// AlgoliaClient is the Algolia client.
-	AlgoliaClient *algolia.Client
-
 	// BaseURL is the base URL for the application.
 	BaseURL string
 
@@ -46,8 +44,18 @@ type Indexer struct {
 	// documents to index.
 	DraftsFolderID string
 
-	// GoogleWorkspaceService is the Google Workspace service.
-	GoogleWorkspaceService *gw.Service
+	// EmailNotifier is the notification channel used to send emails to
+	// subscribers of commented documents. Comment notifications are disabled if
+	// nil.
+	EmailNotifier notify.Channel
+
+	// LeaseDuration is the duration of the leader lease. If set, only the
+	// replica holding the lease runs the indexer, and another replica takes over
+	// within the lease duration if the leader stops renewing it.
+	LeaseDuration time.Duration
+
+	// LeaseHolder is the ID of the replica used for leader election.
+	LeaseHolder string
 
 	// Logger is the logger to use.
 	Logger hclog.Logger
@@ -56,11 +64,42 @@ type Indexer struct {
 	// simultaneously indexed.
 	MaxParallelDocuments int
 
+	// NotificationToken is the token that Drive push notifications must
+	// include.
+	NotificationToken string
+
+	// NotificationURL is the public HTTPS URL of the indexer's notification
+	// handler. Drive push notifications are used if set.
+	NotificationURL string
+
+	// PollInterval is the time between indexer runs when no push notifications
+	// are received.
+	PollInterval time.Duration
+
+	// SearchProvider is the search provider used to store document objects.
+	SearchProvider search.Provider
+
+	// StorageProvider is the storage provider containing the documents to index.
+	StorageProvider storage.Provider
+
 	// UpdateDocumentHeaders updates published document headers, if true.
 	UpdateDocumentHeaders bool
 
 	// UpdateDraftHeaders updates draft document headers, if true.
 	UpdateDraftHeaders bool
+
+	// UseDriveChanges uses the Drive Changes API to find updated documents, if
+	// true.
+	UseDriveChanges bool
+
+	// changesChannel is the current push notification channel.
+	changesChannel *drive.Channel
+
+	// changesNotifications receives a value when changes are notified.
+	changesNotifications chan struct{}
+
+	// leader is the leader election state.
+	leader *leaderState
 }
 
 type IndexerOption func(*Indexer)
@@ -78,6 +117,9 @@ func NewIndexer(opts ...IndexerOption) (*Indexer, error) {
 		Logger: hclog.New(&hclog.LoggerOptions{
 			Name: loggerName,
 		}),
+		PollInterval:         time.Minute,
+		changesNotifications: make(chan struct{}, 1),
+		leader:               &leaderState{},
 	}
 
 	// Apply functional options.
@@ -96,22 +138,24 @@ func NewIndexer(opts ...IndexerOption) (*Indexer, error) {
 // validate validates the indexer configuration.
 func (idx *Indexer) validate() error {
 	return validation.ValidateStruct(idx,
-		validation.Field(&idx.AlgoliaClient, validation.Required),
 		validation.Field(&idx.BaseURL, validation.Required),
 		validation.Field(&idx.Database, validation.Required),
 		validation.Field(&idx.DocumentsFolderID, validation.Required),
 		validation.Field(&idx.DraftsFolderID, validation.Required),
-		validation.Field(&idx.GoogleWorkspaceService, validation.Required),
+		validation.Field(&idx.LeaseHolder,
+			validation.When(idx.LeaseDuration != 0, validation.Required)),
+		validation.Field(&idx.NotificationToken,
+			validation.When(idx.NotificationURL != "", validation.Required)),
+		validation.Field(&idx.NotificationURL,
+			validation.When(!idx.UseDriveChanges,
+				validation.Empty.Error("requires using the Drive Changes API"))),
+		validation.Field(&idx.PollInterval, validation.Required),
+		validation.Field(&idx.SearchProvider, validation.Required),
+		validation.Field(&idx.StorageProvider,
+			validation.Required, validation.By(idx.validateChangeWatcher)),
 	)
 }
 
-// WithAlgoliaClient sets the Algolia client.
-func WithAlgoliaClient(a *algolia.Client) IndexerOption {
-	return func(i *Indexer) {
-		i.AlgoliaClient = a
-	}
-}
-
 // WithBaseURL sets the base URL.
 func WithBaseURL(b string) IndexerOption {
 	return func(i *Indexer) {
@@ -140,10 +184,12 @@ func WithDraftsFolderID(d string) IndexerOption {
 	}
 }
 
-// WithGoogleWorkspaceService sets the Google Workspace service.
-func WithGoogleWorkspaceService(g *gw.Service) IndexerOption {
+// WithLeaderElection enables leader election with replica ID holder and lease
+// duration d.
+func WithLeaderElection(holder string, d time.Duration) IndexerOption {
 	return func(i *Indexer) {
-		i.GoogleWorkspaceService = g
+		i.LeaseHolder = holder
+		i.LeaseDuration = d
 	}
 }
 
@@ -162,6 +208,44 @@ func WithMaxParallelDocuments(m int) IndexerOption {
 	}
 }
 
+// WithDriveNotifications enables Drive push notifications, which are sent to
+// public HTTPS URL u of the indexer's notification handler with token tok.
+func WithDriveNotifications(u, tok string) IndexerOption {
+	return func(i *Indexer) {
+		i.NotificationURL = u
+		i.NotificationToken = tok
+	}
+}
+
+// WithPollInterval sets the time between indexer runs.
+func WithPollInterval(d time.Duration) IndexerOption {
+	return func(i *Indexer) {
+		i.PollInterval = d
+	}
+}
+
+// WithSearchProvider sets the search provider.
+func WithSearchProvider(sp search.Provider) IndexerOption {
+	return func(i *Indexer) {
+		i.SearchProvider = sp
+	}
+}
+
+// WithStorageProvider sets the storage provider.
+func WithStorageProvider(st storage.Provider) IndexerOption {
+	return func(i *Indexer) {
+		i.StorageProvider = st
+	}
+}
+
+// WithSubscriberEmails sets the notification channel used to email
+// subscribers about new comments on documents in review.
+func WithSubscriberEmails(n notify.Channel) IndexerOption {
+	return func(i *Indexer) {
+		i.EmailNotifier = n
+	}
+}
+
 // WithUpdateDocumentHeaders sets the boolean to update draft document headers.
 func WithUpdateDocumentHeaders(u bool) IndexerOption {
 	return func(i *Indexer) {
@@ -176,16 +260,32 @@ func WithUpdateDraftHeaders(u bool) IndexerOption {
 	}
 }
 
-// Run runs the indexer.
-// TODO: improve error handling.
+// WithUseDriveChanges sets the boolean to use the Drive Changes API to find
+// updated documents.
+func WithUseDriveChanges(u bool) IndexerOption {
+	return func(i *Indexer) {
+		i.UseDriveChanges = u
+	}
+}
+
+// Run runs the indexer. Documents that fail to index are recorded as indexer
+// failures and retried with exponential backoff in later runs. Errors that stop
+// a run are logged and counted, and the indexer tries again in the next run. If
+// leader election is enabled, the indexer only runs while it is the leader.
 func (idx *Indexer) Run() error {
-	algo := idx.AlgoliaClient
 	db := idx.Database
-	gwSvc := idx.GoogleWorkspaceService
+	st := idx.StorageProvider
 	log := idx.Logger
 
+	if idx.LeaseDuration != 0 {
+		go idx.renewLeases()
+	}
+
 	for {
+		idx.waitForLeadership()
+
 		runStartedAt := time.Now().UTC()
+		var runIndexed, runFailed int
 
 		// Get indexer metadata.
 		md := models.IndexerMetadata{}
@@ -198,7 +298,8 @@ func (idx *Indexer) Run() error {
 				log.Error("error getting indexer metadata",
 					"error", err,
 				)
-				os.Exit(1)
+				idx.failRun(runStartedAt, metadataStage)
+				continue
 			}
 		}
 
@@ -221,7 +322,8 @@ func (idx *Indexer) Run() error {
 				log.Error("error getting drafts headers folder indexer data",
 					"error", err,
 				)
-				os.Exit(1)
+				idx.failRun(runStartedAt, folderStage)
+				continue
 			}
 
 			// If the last indexed timestamp doesn't exist, set it to the Unix epoch.
@@ -246,7 +348,8 @@ func (idx *Indexer) Run() error {
 				log.Error("error refreshing draft document headers",
 					"error", err,
 				)
-				os.Exit(1)
+				idx.failRun(runStartedAt, headersStage)
+				continue
 			}
 
 			// Save last indexed time for the drafts folder (headers).
@@ -281,7 +384,8 @@ func (idx *Indexer) Run() error {
 				log.Error("error getting documents headers folder indexer data",
 					"error", err,
 				)
-				os.Exit(1)
+				idx.failRun(runStartedAt, folderStage)
+				continue
 			}
 
 			// If the last indexed timestamp doesn't exist, set it to the Unix epoch.
@@ -306,7 +410,8 @@ func (idx *Indexer) Run() error {
 				log.Error("error refreshing published document headers",
 					"error", err,
 				)
-				os.Exit(1)
+				idx.failRun(runStartedAt, headersStage)
+				continue
 			}
 
 			// Save last indexed time for the documents folder (headers).
@@ -331,7 +436,8 @@ func (idx *Indexer) Run() error {
 			log.Error("error getting documents folder indexer data",
 				"error", err,
 			)
-			os.Exit(1)
+			idx.failRun(runStartedAt, folderStage)
+			continue
 		}
 
 		// If the last indexed timestamp doesn't exist, set it to the Unix epoch.
@@ -349,9 +455,19 @@ func (idx *Indexer) Run() error {
 		currentTimeStr := currentTime.UTC().Format(time.RFC3339Nano)
 
 		// Get documents that have been updated in the folder since it was last
-		// indexed.
-		docFiles, err := gwSvc.GetUpdatedDocsBetween(
-			idx.DocumentsFolderID, lastIndexedAtStr, currentTimeStr)
+		// indexed, using the Drive Changes API if configured.
+		var (
+			docFiles []*drive.File
+			err      error
+		)
+		cw, useChanges := idx.changeWatcher()
+		if useChanges {
+			docFiles, err = idx.getChangedDocs(
+				cw, &docsFolderData, lastIndexedAtStr, currentTimeStr)
+		} else {
+			docFiles, err = st.GetUpdatedDocsBetween(
+				idx.DocumentsFolderID, lastIndexedAtStr, currentTimeStr)
+		}
 		if err != nil {
 			log.Error("error getting updated document files",
 				"error", err,
@@ -359,7 +475,8 @@ func (idx *Indexer) Run() error {
 				"after_time", lastIndexedAtStr,
 				"before_time", currentTimeStr,
 			)
-			os.Exit(1)
+			idx.failRun(runStartedAt, updatedDocsStage)
+			continue
 		}
 		if len(docFiles) == 0 {
 			log.Info("no new document updates since the last indexed time",
@@ -368,14 +485,10 @@ func (idx *Indexer) Run() error {
 			)
 		}
 
+		leader := true
 		for _, file := range docFiles {
-			logError := func(errMsg string, err error) {
-				log.Error(errMsg,
-					"error", err,
-					"google_file_id", file.Id,
-					"folder_id", idx.DocumentsFolderID,
-					"last_indexed_at", lastIndexedAtStr,
-				)
+			if leader = idx.checkLeadership(); !leader {
+				break
 			}
 
 			log.Info("indexing document",
@@ -384,74 +497,14 @@ func (idx *Indexer) Run() error {
 				"last_indexed_at", lastIndexedAtStr,
 			)
 
-			// Get document from database.
-			dbDoc := models.Document{
-				GoogleFileID: file.Id,
-			}
-			if err := dbDoc.Get(db); err != nil {
-				logError("error getting document from the database", err)
-				os.Exit(1)
-			}
-
-			// Parse document modified time.
-			modifiedTime, err := time.Parse(time.RFC3339Nano, file.ModifiedTime)
+			modifiedTime, err := idx.indexDocument(file)
 			if err != nil {
-				logError("error parsing document modified time", err)
-				os.Exit(1)
-			}
-
-			// Set new modified for document record and update in database.
-			dbDoc.DocumentModifiedAt = modifiedTime
-
-			// Update document in database.
-			if err := dbDoc.Upsert(db); err != nil {
-				logError("error upserting document", err)
-				os.Exit(1)
-			}
-
-			// Create new document object of the proper document type.
-			docObj, err := hcd.NewEmptyDoc(dbDoc.DocumentType.Name)
-			if err != nil {
-				logError("error creating new empty document", err)
-				os.Exit(1)
-			}
-
-			// Get document object from Algolia.
-			if err := algo.Docs.GetObject(file.Id, &docObj); err != nil {
-				logError("error retrieving document object from Algolia", err)
-				os.Exit(1)
-			}
-
-			// Uncomment following lines if you wish to share all of your documents contents to algolia
-			// for indexing
-
-			// Get document content.
-			//exp, err := gwSvc.Drive.Files.Export(file.Id, "text/plain").Download()
-			//if err != nil {
-			//	logError("error exporting document", err)
-			//	os.Exit(1)
-			//}
-			//
-			//content, err := io.ReadAll(exp.Body)
-			//
-			//if err != nil {
-			//	logError("error reading exported document", err)
-			//	os.Exit(1)
-			//}
-			//// Trim doc content if it is larger than the maximum size.
-			//if len(content) > maxContentSize {
-			//	content = content[:maxContentSize]
-			//}
-
-			// Update document object with content and latest modified time.
-			//docObj.SetContent(string(content))
-			docObj.SetContent("NA")
-			docObj.SetModifiedTime(modifiedTime.Unix())
-
-			// Save the document in Algolia.
-			if err := saveDocInAlgolia(docObj, idx.AlgoliaClient); err != nil {
-				return fmt.Errorf("error saving document in Algolia: %w", err)
+				runFailed++
+				idx.recordFailure(file.Id, idx.DocumentsFolderID, err)
+				continue
 			}
+			runIndexed++
+			idx.resolveFailure(file.Id)
 
 			// Update last indexed time for folder if document modified time is later.
 			if modifiedTime.After(docsFolderData.LastIndexedAt) {
@@ -464,7 +517,26 @@ func (idx *Indexer) Run() error {
 			)
 		}
 
-		// Save last indexed time for the documents folder.
+		// Don't save the results of the run if another replica may have taken
+		// over.
+		if !leader {
+			continue
+		}
+
+		// Retry documents that previously failed to index.
+		indexed, failed := idx.retryFailures()
+		runIndexed += indexed
+		runFailed += failed
+
+		// Notify subscribers of new comments on documents in review.
+		if err := idx.notifyCommentSubscribers(currentTime); err != nil {
+			log.Error("error notifying comment subscribers",
+				"error", err,
+			)
+		}
+
+		// Save last indexed time and changes page token for the documents
+		// folder.
 		if err := docsFolderData.Upsert(db); err != nil {
 			log.Error("error upserting last indexed time for the folder",
 				"folder_id", idx.DocumentsFolderID,
@@ -472,38 +544,212 @@ func (idx *Indexer) Run() error {
 			)
 		}
 
+		// Subscribe to (or renew) push notifications of changes.
+		if useChanges {
+			idx.watchChanges(cw, docsFolderData.ChangesPageToken)
+		}
+
 		// Update the last full index time.
 		md.LastFullIndexAt = runStartedAt.UTC()
 		if err := md.Upsert(db); err != nil {
-			log.Error("error upserting metadata with last full index time: %w", err)
-			os.Exit(1)
+			log.Error("error upserting metadata with last full index time",
+				"error", err,
+			)
+			idx.failRun(runStartedAt, metadataStage)
+			continue
 		}
 
-		log.Info("sleeping for a minute before the next indexing run...")
-		// TODO: make sleep time configurable.
-		time.Sleep(1 * time.Minute)
+		// Record metrics for the run.
+		lastRunDocuments.WithLabelValues(indexedResult).Set(float64(runIndexed))
+		lastRunDocuments.WithLabelValues(failedResult).Set(float64(runFailed))
+		lastRunTimestamp.Set(float64(time.Now().Unix()))
+		runDuration.Observe(time.Since(runStartedAt).Seconds())
+		idx.setLastRunAt(time.Now())
+
+		log.Info("waiting for the next indexing run...",
+			"poll_interval", idx.PollInterval,
+		)
+		idx.waitForNextRun(runStartedAt)
 	}
 }
 
-// saveDoc saves a document struct and its redirect details in Algolia.
-func saveDocInAlgolia(
-	doc hcd.Doc,
-	algo *algolia.Client,
-) error {
-	// Save document object.
-	res, err := algo.Docs.SaveObject(doc)
+// failRun records that the indexer run started at runStartedAt failed at stage
+// stage, and waits for the next run.
+func (idx *Indexer) failRun(runStartedAt time.Time, stage string) {
+	runFailuresTotal.WithLabelValues(stage).Inc()
+	idx.Logger.Info("waiting for the next indexing run after failure...",
+		"poll_interval", idx.PollInterval,
+		"stage", stage,
+	)
+	idx.waitForNextRun(runStartedAt)
+}
+
+// indexDocument indexes document file in the search index, and returns its
+// modified time.
+func (idx *Indexer) indexDocument(file *drive.File) (time.Time, error) {
+	db := idx.Database
+	sp := idx.SearchProvider
+
+	// Get document from database.
+	dbDoc := models.Document{
+		GoogleFileID: file.Id,
+	}
+	if err := dbDoc.Get(db); err != nil {
+		return time.Time{}, fmt.Errorf(
+			"error getting document from the database: %w", err)
+	}
+
+	// Parse document modified time.
+	modifiedTime, err := time.Parse(time.RFC3339Nano, file.ModifiedTime)
 	if err != nil {
-		return fmt.Errorf("error saving document: %w", err)
+		return time.Time{}, fmt.Errorf(
+			"error parsing document modified time: %w", err)
 	}
-	err = res.Wait()
+
+	// Set new modified for document record and update in database.
+	dbDoc.DocumentModifiedAt = modifiedTime
+
+	// Update document in database.
+	if err := dbDoc.Upsert(db); err != nil {
+		return time.Time{}, fmt.Errorf("error upserting document: %w", err)
+	}
+
+	// Create new document object of the proper document type.
+	docObj, err := hcd.NewEmptyDoc(dbDoc.DocumentType.Name)
 	if err != nil {
+		return time.Time{}, fmt.Errorf(
+			"error creating new empty document: %w", err)
+	}
+
+	// Get document object from the search index.
+	if err := sp.Docs().GetObject(file.Id, &docObj); err != nil {
+		return time.Time{}, fmt.Errorf(
+			"error retrieving document object from search index: %w", err)
+	}
+
+	// Update document object with latest modified time and content, if content
+	// indexing is enabled for the document type.
+	docObj.SetModifiedTime(modifiedTime.Unix())
+	if dbDoc.DocumentType.IndexContent {
+		content, err := idx.indexContent(docObj)
+		if err != nil {
+			return time.Time{}, fmt.Errorf(
+				"error indexing document content: %w", err)
+		}
+		docObj.SetContent(content)
+	} else {
+		docObj.SetContent("NA")
+	}
+
+	// Save the document in the search index.
+	if err := saveDoc(docObj, sp); err != nil {
+		return time.Time{}, fmt.Errorf(
+			"error saving document in search index: %w", err)
+	}
+
+	documentsTotal.WithLabelValues(indexedResult).Inc()
+	return modifiedTime, nil
+}
+
+// retryFailures retries indexing documents that previously failed to index and
+// are due to be retried, and returns the number of documents indexed and
+// failed.
+func (idx *Indexer) retryFailures() (indexed, failed int) {
+	log := idx.Logger
+
+	var fs models.IndexerFailures
+	if err := fs.FindDue(idx.Database, time.Now().UTC()); err != nil {
+		log.Error("error finding indexer failures to retry",
+			"error", err,
+		)
+		return 0, 0
+	}
+
+	for _, f := range fs {
+		log.Info("retrying document that failed to index",
+			"google_file_id", f.GoogleFileID,
+			"attempts", f.Attempts,
+		)
+
+		file, err := idx.StorageProvider.GetFile(f.GoogleFileID)
+		if err != nil {
+			failed++
+			idx.recordFailure(f.GoogleFileID, f.FolderID,
+				fmt.Errorf("error getting file: %w", err))
+			continue
+		}
+		if _, err := idx.indexDocument(file); err != nil {
+			failed++
+			idx.recordFailure(f.GoogleFileID, f.FolderID, err)
+			continue
+		}
+
+		indexed++
+		idx.resolveFailure(f.GoogleFileID)
+		log.Info("indexed document that previously failed to index",
+			"google_file_id", f.GoogleFileID,
+		)
+	}
+
+	return indexed, failed
+}
+
+// recordFailure logs and records a failure with error err to index document
+// fileID from folder folderID, so that it is retried later.
+func (idx *Indexer) recordFailure(fileID, folderID string, err error) {
+	log := idx.Logger
+
+	documentsTotal.WithLabelValues(failedResult).Inc()
+	log.Error("error indexing document",
+		"error", err,
+		"google_file_id", fileID,
+		"folder_id", folderID,
+	)
+
+	f := models.IndexerFailure{
+		GoogleFileID: fileID,
+		FolderID:     folderID,
+	}
+	if err := f.RecordAttempt(idx.Database, err, time.Now().UTC()); err != nil {
+		log.Error("error recording indexer failure",
+			"error", err,
+			"google_file_id", fileID,
+		)
+		return
+	}
+	log.Info("document will be retried",
+		"google_file_id", fileID,
+		"attempts", f.Attempts,
+		"next_attempt_at", f.NextAttemptAt,
+	)
+}
+
+// resolveFailure removes any recorded failure to index document fileID.
+func (idx *Indexer) resolveFailure(fileID string) {
+	f := models.IndexerFailure{GoogleFileID: fileID}
+	if err := f.Resolve(idx.Database); err != nil {
+		idx.Logger.Error("error resolving indexer failure",
+			"error", err,
+			"google_file_id", fileID,
+		)
+	}
+}
+
+// saveDoc saves a document struct and its redirect details in the search
+// index.
+func saveDoc(
+	doc hcd.Doc,
+	sp search.Provider,
+) error {
+	// Save document object.
+	if err := sp.Docs().SaveObject(doc); err != nil {
 		return fmt.Errorf("error saving document: %w", err)
 	}
 
 	// Save document redirect details.
 	if doc.GetDocNumber() != "" {
-		err = links.SaveDocumentRedirectDetails(
-			algo, doc.GetObjectID(), doc.GetDocType(), doc.GetDocNumber())
+		err := links.SaveDocumentRedirectDetails(
+			sp, doc.GetObjectID(), doc.GetDocType(), doc.GetDocNumber())
 		if err != nil {
 			return err
 		}
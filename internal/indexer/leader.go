@@ -0,0 +1,210 @@
+package indexer
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+)
+
+const (
+	// HealthPath is the path that the indexer's health is served on.
+	HealthPath = "/health"
+
+	// leaseName is the name of the lease held by the leader indexer replica.
+	leaseName = "indexer"
+)
+
+// leaderState is the leader election state of an indexer replica.
+type leaderState struct {
+	// expiresAt is the time that the replica's lease expires unless it is
+	// renewed. The replica is the leader until then.
+	expiresAt time.Time
+
+	// lastRunAt is the time that the replica last completed an indexer run.
+	lastRunAt time.Time
+
+	// released is true if the replica released its lease and shouldn't acquire
+	// it again.
+	released bool
+
+	sync.RWMutex
+}
+
+// isLeader returns true if the indexer is the leader. It is always true if
+// leader election isn't used.
+func (idx *Indexer) isLeader() bool {
+	if idx.LeaseDuration == 0 {
+		return true
+	}
+
+	idx.leader.RLock()
+	defer idx.leader.RUnlock()
+	return time.Now().Before(idx.leader.expiresAt)
+}
+
+// acquireLease tries to acquire or renew the leader lease, and returns true if
+// the indexer is the leader.
+func (idx *Indexer) acquireLease() bool {
+	log := idx.Logger
+
+	idx.leader.RLock()
+	released := idx.leader.released
+	idx.leader.RUnlock()
+	if released {
+		return false
+	}
+
+	wasLeader := idx.isLeader()
+
+	// Record the time before acquiring the lease so that the lease is
+	// considered expired locally no later than in the database.
+	now := time.Now().UTC()
+	l := models.IndexerLease{Name: leaseName}
+	ok, err := l.Acquire(idx.Database, idx.LeaseHolder, now, idx.LeaseDuration)
+	if err != nil {
+		log.Error("error acquiring indexer leader lease",
+			"error", err,
+		)
+		return idx.isLeader()
+	}
+
+	idx.leader.Lock()
+	if ok && !idx.leader.released {
+		idx.leader.expiresAt = now.Add(idx.LeaseDuration)
+	} else {
+		idx.leader.expiresAt = time.Time{}
+	}
+	idx.leader.Unlock()
+
+	switch {
+	case ok && !wasLeader:
+		log.Info("became indexer leader",
+			"holder", idx.LeaseHolder,
+		)
+		leaderStatus.Set(1)
+	case !ok && wasLeader:
+		log.Warn("lost indexer leadership",
+			"holder", idx.LeaseHolder,
+			"leader", l.Holder,
+		)
+		leaderStatus.Set(0)
+	}
+	return ok
+}
+
+// renewLeases tries to acquire or renew the leader lease every third of the
+// lease duration, so that a replica takes over within the lease duration if
+// the leader stops renewing it.
+func (idx *Indexer) renewLeases() {
+	for {
+		idx.acquireLease()
+		time.Sleep(idx.LeaseDuration / 3)
+	}
+}
+
+// checkLeadership returns true if the indexer is still the leader, and logs a
+// warning if it isn't.
+func (idx *Indexer) checkLeadership() bool {
+	if idx.isLeader() {
+		return true
+	}
+	idx.Logger.Warn("no longer indexer leader, stopping run",
+		"holder", idx.LeaseHolder,
+	)
+	return false
+}
+
+// waitForLeadership waits until the indexer is the leader.
+func (idx *Indexer) waitForLeadership() {
+	if idx.isLeader() {
+		return
+	}
+
+	idx.Logger.Info("waiting to become indexer leader",
+		"holder", idx.LeaseHolder,
+	)
+	for !idx.isLeader() {
+		time.Sleep(idx.LeaseDuration / 3)
+	}
+}
+
+// ReleaseLeadership releases the leader lease, if held, so that another replica
+// can take over without waiting for it to expire.
+func (idx *Indexer) ReleaseLeadership() {
+	if idx.LeaseDuration == 0 || !idx.isLeader() {
+		return
+	}
+
+	idx.leader.Lock()
+	idx.leader.expiresAt = time.Time{}
+	idx.leader.released = true
+	idx.leader.Unlock()
+	leaderStatus.Set(0)
+
+	l := models.IndexerLease{Name: leaseName}
+	if err := l.Release(idx.Database, idx.LeaseHolder); err != nil {
+		idx.Logger.Error("error releasing indexer leader lease",
+			"error", err,
+		)
+		return
+	}
+	idx.Logger.Info("released indexer leadership",
+		"holder", idx.LeaseHolder,
+	)
+}
+
+// setLastRunAt records the time that the indexer last completed a run.
+func (idx *Indexer) setLastRunAt(t time.Time) {
+	idx.leader.Lock()
+	defer idx.leader.Unlock()
+	idx.leader.lastRunAt = t
+}
+
+// healthResponse is the response of the indexer health endpoint.
+type healthResponse struct {
+	Status         string     `json:"status"`
+	LeaderElection bool       `json:"leaderElection"`
+	Leader         bool       `json:"leader"`
+	Holder         string     `json:"holder,omitempty"`
+	LeaseExpiresAt *time.Time `json:"leaseExpiresAt,omitempty"`
+	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
+}
+
+// HealthHandler returns an HTTP handler that reports the health and leader
+// status of the indexer.
+func (idx *Indexer) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		resp := healthResponse{
+			Status:         "ok",
+			LeaderElection: idx.LeaseDuration != 0,
+			Leader:         idx.isLeader(),
+			Holder:         idx.LeaseHolder,
+		}
+		idx.leader.RLock()
+		if resp.LeaderElection && resp.Leader {
+			t := idx.leader.expiresAt.UTC()
+			resp.LeaseExpiresAt = &t
+		}
+		if !idx.leader.lastRunAt.IsZero() {
+			t := idx.leader.lastRunAt.UTC()
+			resp.LastRunAt = &t
+		}
+		idx.leader.RUnlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		if err := enc.Encode(resp); err != nil {
+			idx.Logger.Error("error encoding health response",
+				"error", err,
+			)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package indexer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler(t *testing.T) {
+	lastRunAt := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		leaseDuration time.Duration
+		expiresAt     time.Time
+		wantLeader    bool
+	}{
+		"without leader election": {
+			wantLeader: true,
+		},
+		"leader": {
+			leaseDuration: 30 * time.Second,
+			expiresAt:     time.Now().Add(time.Minute),
+			wantLeader:    true,
+		},
+		"expired lease": {
+			leaseDuration: 30 * time.Second,
+			expiresAt:     time.Now().Add(-time.Second),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			idx := &Indexer{
+				LeaseDuration: c.leaseDuration,
+				LeaseHolder:   "replica1",
+				Logger:        hclog.NewNullLogger(),
+				leader: &leaderState{
+					expiresAt: c.expiresAt,
+					lastRunAt: lastRunAt,
+				},
+			}
+
+			req := httptest.NewRequest("GET", HealthPath, nil)
+			rr := httptest.NewRecorder()
+			idx.HealthHandler().ServeHTTP(rr, req)
+			require.Equal(http.StatusOK, rr.Code)
+
+			var resp healthResponse
+			require.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+			assert.Equal("ok", resp.Status)
+			assert.Equal(c.leaseDuration != 0, resp.LeaderElection)
+			assert.Equal(c.wantLeader, resp.Leader)
+			assert.Equal(c.wantLeader && c.leaseDuration != 0,
+				resp.LeaseExpiresAt != nil)
+			require.NotNil(resp.LastRunAt)
+			assert.Equal(lastRunAt, *resp.LastRunAt)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package indexer
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// indexedResult is the metric label value for indexed documents.
+	indexedResult = "indexed"
+
+	// failedResult is the metric label value for documents that failed to be
+	// indexed.
+	failedResult = "failed"
+
+	// The following are metric label values for the stages of an indexer run
+	// that can fail.
+	folderStage      = "folder"
+	headersStage     = "headers"
+	metadataStage    = "metadata"
+	updatedDocsStage = "updated_docs"
+)
+
+var (
+	// documentsTotal is the number of documents processed by the indexer, by
+	// result.
+	documentsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hermes_indexer_documents_total",
+			Help: "Documents processed by the indexer, by result (indexed or failed).",
+		},
+		[]string{"result"},
+	)
+
+	// lastRunDocuments is the number of documents processed in the last indexer
+	// run, by result.
+	lastRunDocuments = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hermes_indexer_last_run_documents",
+			Help: "Documents processed in the last indexer run, by result (indexed or failed).",
+		},
+		[]string{"result"},
+	)
+
+	// lastRunTimestamp is the time the last indexer run completed.
+	lastRunTimestamp = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hermes_indexer_last_run_timestamp_seconds",
+			Help: "Unix time the last indexer run completed.",
+		},
+	)
+
+	// leaderStatus is 1 if the indexer replica is the leader, and 0 otherwise.
+	leaderStatus = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "hermes_indexer_leader",
+			Help: "Whether the indexer replica is the leader (1) or not (0).",
+		},
+	)
+
+	// runFailuresTotal is the number of indexer runs that failed before
+	// completing, by stage.
+	runFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hermes_indexer_run_failures_total",
+			Help: "Indexer runs that failed before completing, by stage.",
+		},
+		[]string{"stage"},
+	)
+
+	// runDuration is the duration of indexer runs.
+	runDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "hermes_indexer_run_duration_seconds",
+			Help:    "Duration of indexer runs.",
+			Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800},
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		documentsTotal,
+		lastRunDocuments,
+		lastRunTimestamp,
+		leaderStatus,
+		runDuration,
+		runFailuresTotal,
+	)
+}
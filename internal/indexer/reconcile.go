@@ -0,0 +1,474 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/links"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+)
+
+// ReconcileIssueKind is the kind of inconsistency found between the database,
+// search indexes, and storage provider.
+type ReconcileIssueKind string
+
+const (
+	// MissingFileIssue is a published document in the database without a file
+	// in storage.
+	MissingFileIssue ReconcileIssueKind = "missing-file"
+
+	// OrphanFileIssue is a file in the documents or drafts folder without a
+	// document in the database.
+	OrphanFileIssue ReconcileIssueKind = "orphan-file"
+
+	// MissingObjectIssue is a document in the database without an object in the
+	// search index for its status.
+	MissingObjectIssue ReconcileIssueKind = "missing-object"
+
+	// OrphanObjectIssue is an object in a search index without a document in
+	// the database or storage, or in the wrong index for its status.
+	OrphanObjectIssue ReconcileIssueKind = "orphan-object"
+
+	// FieldMismatchIssue is a search index object with a field that doesn't
+	// match the database.
+	FieldMismatchIssue ReconcileIssueKind = "field-mismatch"
+
+	// MissingLinkIssue is a published document without a matching redirect
+	// record in the links index.
+	MissingLinkIssue ReconcileIssueKind = "missing-link"
+)
+
+// ReconcileIssue is an inconsistency found for a document.
+type ReconcileIssue struct {
+	// Kind is the kind of issue.
+	Kind ReconcileIssueKind
+
+	// GoogleFileID is the Google Drive file ID of the document.
+	GoogleFileID string
+
+	// Store is the store that is inconsistent (e.g., "docs", "drafts",
+	// "links", "storage").
+	Store string
+
+	// Field is the mismatched field for field mismatch issues.
+	Field string
+
+	// Want is the value of the field in the database.
+	Want string
+
+	// Got is the value of the field in the search index.
+	Got string
+
+	// Fixed is true if the issue was fixed.
+	Fixed bool
+
+	// FixError is the error fixing the issue, if any.
+	FixError error
+
+	// fix fixes the issue. It is nil if the issue can't be fixed
+	// automatically.
+	fix func() error
+}
+
+// Fixable returns true if the issue can be fixed automatically.
+func (i ReconcileIssue) Fixable() bool {
+	return i.fix != nil
+}
+
+// String returns a description of the issue.
+func (i ReconcileIssue) String() string {
+	s := fmt.Sprintf("%s %s in %s", i.Kind, i.GoogleFileID, i.Store)
+	if i.Field != "" {
+		s += fmt.Sprintf(": %s is %q, want %q", i.Field, i.Got, i.Want)
+	}
+	return s
+}
+
+// reconcileState is the state of documents in the database, search indexes,
+// and storage provider.
+type reconcileState struct {
+	// docs are the documents in the database.
+	docs models.Documents
+
+	// files are the IDs of files in the documents and drafts folders.
+	files map[string]bool
+
+	// docObjs and draftObjs are the objects in the docs and drafts indexes,
+	// keyed by object ID.
+	docObjs, draftObjs map[string]map[string]interface{}
+
+	// links are the redirect records of objects in the docs index with a
+	// document number, keyed by object ID. The value is nil if there isn't a
+	// redirect record.
+	links map[string]*links.LinkData
+}
+
+// Reconcile compares documents in the database, search indexes, and storage
+// provider, and returns the inconsistencies found. If fix is true, fixable
+// issues are repaired in the search indexes, unless dryRun is also true.
+func (idx *Indexer) Reconcile(fix, dryRun bool) ([]ReconcileIssue, error) {
+	log := idx.Logger
+
+	st, err := idx.loadReconcileState()
+	if err != nil {
+		return nil, err
+	}
+	issues := idx.findIssues(st)
+
+	if !fix {
+		return issues, nil
+	}
+	for i := range issues {
+		is := &issues[i]
+		if !is.Fixable() {
+			continue
+		}
+		if dryRun {
+			log.Info("would fix issue", "issue", is.String())
+			continue
+		}
+		if err := is.fix(); err != nil {
+			is.FixError = err
+			log.Error("error fixing issue",
+				"error", err,
+				"issue", is.String(),
+			)
+			continue
+		}
+		is.Fixed = true
+		log.Info("fixed issue", "issue", is.String())
+	}
+
+	return issues, nil
+}
+
+// loadReconcileState loads the state of documents from all stores.
+func (idx *Indexer) loadReconcileState() (*reconcileState, error) {
+	sp := idx.SearchProvider
+	st := &reconcileState{
+		files:     make(map[string]bool),
+		docObjs:   make(map[string]map[string]interface{}),
+		draftObjs: make(map[string]map[string]interface{}),
+		links:     make(map[string]*links.LinkData),
+	}
+
+	if err := st.docs.Find(idx.Database,
+		"status <> ?", models.UnspecifiedDocumentStatus); err != nil {
+		return nil, fmt.Errorf("error finding documents in the database: %w", err)
+	}
+
+	for _, folderID := range []string{idx.DocumentsFolderID, idx.DraftsFolderID} {
+		files, err := idx.StorageProvider.GetDocs(folderID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting documents in folder %q: %w",
+				folderID, err)
+		}
+		for _, f := range files {
+			st.files[f.Id] = true
+		}
+	}
+
+	for _, ix := range []struct {
+		name string
+		idx  search.Index
+		objs map[string]map[string]interface{}
+	}{
+		{"docs", sp.Docs(), st.docObjs},
+		{"drafts", sp.Drafts(), st.draftObjs},
+	} {
+		if err := ix.idx.Browse(func(obj map[string]interface{}) error {
+			if id := stringField(obj, "objectID"); id != "" {
+				ix.objs[id] = obj
+			}
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("error browsing %s index: %w", ix.name, err)
+		}
+	}
+
+	for id, obj := range st.docObjs {
+		docType, docNum := stringField(obj, "docType"), stringField(obj, "docNumber")
+		if docType == "" || docNum == "" {
+			continue
+		}
+		ld, err := links.GetDocumentRedirectDetails(sp, docType, docNum)
+		if err != nil && !errors.Is(err, search.ErrNotFound) {
+			return nil, err
+		}
+		st.links[id] = ld
+	}
+
+	return st, nil
+}
+
+// findIssues returns the inconsistencies in state st.
+func (idx *Indexer) findIssues(st *reconcileState) []ReconcileIssue {
+	sp := idx.SearchProvider
+
+	var issues []ReconcileIssue
+	dbDocs := make(map[string]bool, len(st.docs))
+
+	for _, d := range st.docs {
+		id := d.GoogleFileID
+		dbDocs[id] = true
+
+		isDraft := d.Status == models.DraftDocumentStatus
+		wantIdx, wantName := sp.Docs, "docs"
+		wrongObjs, wrongIdx, wrongName := st.draftObjs, sp.Drafts, "drafts"
+		objs := st.docObjs
+		if isDraft {
+			wantIdx, wantName = sp.Drafts, "drafts"
+			wrongObjs, wrongIdx, wrongName = st.docObjs, sp.Docs, "docs"
+			objs = st.draftObjs
+		}
+
+		// An object in the index for the other status is stale.
+		if _, ok := wrongObjs[id]; ok {
+			issues = append(issues, ReconcileIssue{
+				Kind:         OrphanObjectIssue,
+				GoogleFileID: id,
+				Store:        wrongName,
+				fix:          func() error { return wrongIdx().DeleteObject(id) },
+			})
+		}
+
+		obj, hasObj := objs[id]
+		if !st.files[id] {
+			if isDraft {
+				// Deleting a draft removes its file and object but keeps the
+				// database record, so only the object is stale.
+				if hasObj {
+					issues = append(issues, ReconcileIssue{
+						Kind:         OrphanObjectIssue,
+						GoogleFileID: id,
+						Store:        wantName,
+						fix:          func() error { return wantIdx().DeleteObject(id) },
+					})
+				}
+			} else {
+				issues = append(issues, ReconcileIssue{
+					Kind:         MissingFileIssue,
+					GoogleFileID: id,
+					Store:        "storage",
+				})
+			}
+			continue
+		}
+
+		if !hasObj {
+			doc := newBaseDoc(d)
+			issues = append(issues, ReconcileIssue{
+				Kind:         MissingObjectIssue,
+				GoogleFileID: id,
+				Store:        wantName,
+				fix:          func() error { return wantIdx().SaveObject(doc) },
+			})
+			continue
+		}
+
+		issues = append(issues, fieldIssues(d, obj, wantName, wantIdx)...)
+
+		if !isDraft {
+			if is, ok := idx.linkIssue(id, obj, st.links); ok {
+				issues = append(issues, is)
+			}
+		}
+	}
+
+	// Objects and files without a database record.
+	for _, ix := range []struct {
+		name string
+		idx  func() search.Index
+		objs map[string]map[string]interface{}
+	}{
+		{"docs", sp.Docs, st.docObjs},
+		{"drafts", sp.Drafts, st.draftObjs},
+	} {
+		for _, id := range sortedKeys(ix.objs) {
+			if dbDocs[id] {
+				continue
+			}
+			id, getIdx := id, ix.idx
+			issues = append(issues, ReconcileIssue{
+				Kind:         OrphanObjectIssue,
+				GoogleFileID: id,
+				Store:        ix.name,
+				fix:          func() error { return getIdx().DeleteObject(id) },
+			})
+		}
+	}
+	for _, id := range sortedKeys(st.files) {
+		if !dbDocs[id] {
+			issues = append(issues, ReconcileIssue{
+				Kind:         OrphanFileIssue,
+				GoogleFileID: id,
+				Store:        "storage",
+			})
+		}
+	}
+
+	return issues
+}
+
+// fieldIssues returns the fields of search index object obj that don't match
+// database document d. Fixing an issue updates the field in index idx.
+func fieldIssues(
+	d models.Document,
+	obj map[string]interface{},
+	idxName string,
+	idx func() search.Index,
+) []ReconcileIssue {
+	var reviewers []string
+	for _, u := range d.Reviewers {
+		reviewers = append(reviewers, u.EmailAddress)
+	}
+
+	fields := []struct {
+		field string
+		value interface{}
+		got   string
+		want  string
+	}{
+		{
+			field: "status",
+			value: d.Status.String(),
+			got:   stringField(obj, "status"),
+			want:  d.Status.String(),
+		},
+		{
+			field: "product",
+			value: d.Product.Name,
+			got:   stringField(obj, "product"),
+			want:  d.Product.Name,
+		},
+		{
+			field: "reviewers",
+			value: reviewers,
+			got:   strings.Join(sortedStrings(stringsField(obj, "reviewers")), ","),
+			want:  strings.Join(sortedStrings(reviewers), ","),
+		},
+	}
+
+	var issues []ReconcileIssue
+	for _, f := range fields {
+		if f.got == f.want {
+			continue
+		}
+		field, value := f.field, f.value
+		issues = append(issues, ReconcileIssue{
+			Kind:         FieldMismatchIssue,
+			GoogleFileID: d.GoogleFileID,
+			Store:        idxName,
+			Field:        field,
+			Want:         f.want,
+			Got:          f.got,
+			fix: func() error {
+				obj[field] = value
+				return idx().SaveObject(obj)
+			},
+		})
+	}
+	return issues
+}
+
+// linkIssue returns an issue if published document object obj with ID id has a
+// document number but no matching redirect record in lds.
+func (idx *Indexer) linkIssue(
+	id string,
+	obj map[string]interface{},
+	lds map[string]*links.LinkData,
+) (ReconcileIssue, bool) {
+	ld, ok := lds[id]
+	if !ok || (ld != nil && ld.DocumentID == id) {
+		return ReconcileIssue{}, false
+	}
+
+	docType, docNum := stringField(obj, "docType"), stringField(obj, "docNumber")
+	is := ReconcileIssue{
+		Kind:         MissingLinkIssue,
+		GoogleFileID: id,
+		Store:        "links",
+		fix: func() error {
+			return links.SaveDocumentRedirectDetails(
+				idx.SearchProvider, id, docType, docNum)
+		},
+	}
+	if ld != nil {
+		is.Field, is.Want, is.Got = "documentID", id, ld.DocumentID
+	}
+	return is, true
+}
+
+// newBaseDoc returns a search index object for database document d.
+func newBaseDoc(d models.Document) *hcd.BaseDoc {
+	doc := &hcd.BaseDoc{
+		ObjectID:     d.GoogleFileID,
+		Title:        d.Title,
+		DocType:      d.DocumentType.Name,
+		AppCreated:   !d.Imported,
+		Locked:       d.Locked,
+		ModifiedTime: d.DocumentModifiedAt.Unix(),
+		Product:      d.Product.Name,
+		Team:         d.Team.Name,
+		Project:      d.Project.Name,
+		Status:       d.Status.String(),
+		Summary:      d.Summary,
+	}
+	if d.Owner != nil {
+		doc.Owners = []string{d.Owner.EmailAddress}
+	}
+	for _, u := range d.Reviewers {
+		doc.Reviewers = append(doc.Reviewers, u.EmailAddress)
+	}
+	for _, u := range d.ReviewedBy {
+		doc.ReviewedBy = append(doc.ReviewedBy, u.EmailAddress)
+	}
+	for _, u := range d.Contributors {
+		doc.Contributors = append(doc.Contributors, u.EmailAddress)
+	}
+	if !d.DocumentCreatedAt.IsZero() {
+		doc.Created = d.DocumentCreatedAt.UTC().Format("Jan 2, 2006")
+		doc.CreatedTime = d.DocumentCreatedAt.Unix()
+	}
+	return doc
+}
+
+// stringField returns the string value of field key of object obj, or an empty
+// string if it isn't a string.
+func stringField(obj map[string]interface{}, key string) string {
+	s, _ := obj[key].(string)
+	return s
+}
+
+// stringsField returns the string values of array field key of object obj.
+func stringsField(obj map[string]interface{}, key string) []string {
+	vals, _ := obj[key].([]interface{})
+	var ss []string
+	for _, v := range vals {
+		if s, ok := v.(string); ok {
+			ss = append(ss, s)
+		}
+	}
+	return ss
+}
+
+// sortedStrings returns a sorted copy of ss.
+func sortedStrings(ss []string) []string {
+	s := append([]string(nil), ss...)
+	sort.Strings(s)
+	return s
+}
+
+// sortedKeys returns the sorted keys of map m.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
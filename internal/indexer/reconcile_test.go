@@ -0,0 +1,127 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/pkg/links"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindIssues(t *testing.T) {
+	idx := &Indexer{SearchProvider: search.NewPostgresProvider(nil)}
+
+	doc := func(id string, status models.DocumentStatus, reviewers ...string) models.Document {
+		d := models.Document{
+			GoogleFileID: id,
+			Product:      models.Product{Name: "Payments"},
+			Status:       status,
+		}
+		for _, r := range reviewers {
+			d.Reviewers = append(d.Reviewers, &models.User{EmailAddress: r})
+		}
+		return d
+	}
+	obj := func(id, status string, reviewers ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"objectID":  id,
+			"docType":   "RFC",
+			"docNumber": "PAY-" + id,
+			"product":   "Payments",
+			"reviewers": reviewers,
+			"status":    status,
+		}
+	}
+
+	st := &reconcileState{
+		docs: models.Documents{
+			// In sync.
+			doc("ok", models.InReviewDocumentStatus, "b@x.com", "a@x.com"),
+			// Mismatched status and reviewers.
+			doc("mismatch", models.ReviewedDocumentStatus, "a@x.com"),
+			// Published document without a file.
+			doc("nofile", models.InReviewDocumentStatus),
+			// Deleted draft that is still indexed.
+			doc("deleted", models.DraftDocumentStatus),
+			// Deleted draft that isn't indexed.
+			doc("gone", models.DraftDocumentStatus),
+			// Draft without an object.
+			doc("noobj", models.DraftDocumentStatus),
+			// Published document still in the drafts index.
+			doc("moved", models.InReviewDocumentStatus),
+		},
+		files: map[string]bool{
+			"ok":       true,
+			"mismatch": true,
+			"noobj":    true,
+			"moved":    true,
+			"extra":    true,
+		},
+		docObjs: map[string]map[string]interface{}{
+			"ok":       obj("ok", "In-Review", "a@x.com", "b@x.com"),
+			"mismatch": obj("mismatch", "In-Review"),
+			"nofile":   obj("nofile", "In-Review"),
+			"moved":    obj("moved", "In-Review"),
+			"orphan":   obj("orphan", "In-Review"),
+		},
+		draftObjs: map[string]map[string]interface{}{
+			"deleted": obj("deleted", "Draft"),
+			"moved":   obj("moved", "Draft"),
+		},
+		links: map[string]*links.LinkData{
+			"ok":       {DocumentID: "ok"},
+			"mismatch": {DocumentID: "other"},
+			"nofile":   {DocumentID: "nofile"},
+			"moved":    nil,
+			"orphan":   {DocumentID: "orphan"},
+		},
+	}
+
+	type issue struct {
+		kind    ReconcileIssueKind
+		id      string
+		store   string
+		field   string
+		fixable bool
+	}
+	var got []issue
+	for _, is := range idx.findIssues(st) {
+		got = append(got, issue{is.Kind, is.GoogleFileID, is.Store, is.Field,
+			is.Fixable()})
+	}
+
+	assert.Equal(t, []issue{
+		{FieldMismatchIssue, "mismatch", "docs", "status", true},
+		{FieldMismatchIssue, "mismatch", "docs", "reviewers", true},
+		{MissingLinkIssue, "mismatch", "links", "documentID", true},
+		{MissingFileIssue, "nofile", "storage", "", false},
+		{OrphanObjectIssue, "deleted", "drafts", "", true},
+		{MissingObjectIssue, "noobj", "drafts", "", true},
+		{OrphanObjectIssue, "moved", "drafts", "", true},
+		{MissingLinkIssue, "moved", "links", "", true},
+		{OrphanObjectIssue, "orphan", "docs", "", true},
+		{OrphanFileIssue, "extra", "storage", "", false},
+	}, got)
+}
+
+func TestNewBaseDoc(t *testing.T) {
+	d := models.Document{
+		GoogleFileID: "doc1",
+		Title:        "Title",
+		DocumentType: models.DocumentType{Name: "RFC"},
+		Owner:        &models.User{EmailAddress: "owner@x.com"},
+		Product:      models.Product{Name: "Payments"},
+		Reviewers:    []*models.User{{EmailAddress: "a@x.com"}},
+		Status:       models.DraftDocumentStatus,
+	}
+
+	doc := newBaseDoc(d)
+	assert.Equal(t, "doc1", doc.ObjectID)
+	assert.Equal(t, "RFC", doc.DocType)
+	assert.True(t, doc.AppCreated)
+	assert.Equal(t, []string{"owner@x.com"}, doc.Owners)
+	assert.Equal(t, []string{"a@x.com"}, doc.Reviewers)
+	assert.Equal(t, "Draft", doc.Status)
+	assert.Zero(t, doc.CreatedTime)
+}
@@ -6,14 +6,14 @@ import (
 	"sync"
 	"time"
 
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
 	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
 	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
 	"google.golang.org/api/drive/v3"
 )
 
 // folderType is a temporary hack until we only fetch document data from the
-// database. It is needed so we know which Algolia index to fetch document data
+// database. It is needed so we know which search index to fetch document data
 // from.
 type folderType int
 
@@ -48,7 +48,7 @@ func refreshDocumentHeaders(
 	untilTimeStr := currentTime.Add(time.Duration(-30) * time.Minute).UTC().
 		Format(time.RFC3339Nano)
 
-	docs, err := idx.GoogleWorkspaceService.GetUpdatedDocsBetween(
+	docs, err := idx.StorageProvider.GetUpdatedDocsBetween(
 		folderID,
 		fromTimeStr,
 		untilTimeStr,
@@ -70,7 +70,7 @@ func refreshDocumentHeaders(
 	}
 	var lockedDocIDs []string
 	for _, d := range lockedDocs {
-		f, err := idx.GoogleWorkspaceService.GetFile(d.GoogleFileID)
+		f, err := idx.StorageProvider.GetFile(d.GoogleFileID)
 		if err != nil {
 			return fmt.Errorf("error getting file (%s): %w", d.GoogleFileID, err)
 		}
@@ -155,12 +155,12 @@ func refreshDocumentHeader(
 	ft folderType,
 	lastIndexedAt *safeTime,
 ) {
-	algo := idx.AlgoliaClient
+	sp := idx.SearchProvider
 	log := idx.Logger
 
 	// Check if document is locked.
 	locked, err := hcd.IsLocked(
-		file.Id, idx.Database, idx.GoogleWorkspaceService, log)
+		file.Id, idx.Database, idx.StorageProvider, log)
 	if err != nil {
 		log.Error("error checking document locked status",
 			"error", err,
@@ -173,11 +173,11 @@ func refreshDocumentHeader(
 		return
 	}
 
-	// Get base document object from Algolia so we can determine the document
-	// type.
+	// Get base document object from the search index so we can determine the
+	// document type.
 	var baseDocObj hcd.BaseDoc
-	if err := getAlgoliaDocObject(algo, file.Id, ft, &baseDocObj); err != nil {
-		log.Error("error getting document object from Algolia",
+	if err := getDocObject(sp, file.Id, ft, &baseDocObj); err != nil {
+		log.Error("error getting document object from search index",
 			"error", err,
 			"google_file_id", file.Id,
 		)
@@ -194,9 +194,9 @@ func refreshDocumentHeader(
 		os.Exit(1)
 	}
 
-	// Get document object from Algolia.
-	if err := getAlgoliaDocObject(algo, file.Id, ft, &docObj); err != nil {
-		log.Error("error getting document object from Algolia",
+	// Get document object from the search index.
+	if err := getDocObject(sp, file.Id, ft, &docObj); err != nil {
+		log.Error("error getting document object from search index",
 			"error", err,
 			"google_file_id", file.Id,
 		)
@@ -205,7 +205,7 @@ func refreshDocumentHeader(
 
 	// Replace document header.
 	if err := docObj.ReplaceHeader(
-		file.Id, idx.BaseURL, true, idx.GoogleWorkspaceService); err != nil {
+		file.Id, idx.BaseURL, true, idx.StorageProvider); err != nil {
 		log.Error("error replacing document header",
 			"error", err,
 			"google_file_id", file.Id,
@@ -214,7 +214,7 @@ func refreshDocumentHeader(
 	}
 
 	// Get the file again because we just modified it.
-	file, err = idx.GoogleWorkspaceService.GetFile(file.Id)
+	file, err = idx.StorageProvider.GetFile(file.Id)
 	if err != nil {
 		log.Error("error getting the file after replacing the header",
 			"error", err,
@@ -245,17 +245,17 @@ func refreshDocumentHeader(
 	)
 }
 
-func getAlgoliaDocObject(
-	algo *algolia.Client,
+func getDocObject(
+	sp search.Provider,
 	objectID string,
 	ft folderType,
 	target interface{},
 ) error {
 	switch ft {
 	case draftsFolderType:
-		return algo.Drafts.GetObject(objectID, &target)
+		return sp.Drafts().GetObject(objectID, &target)
 	case documentsFolderType:
-		return algo.Docs.GetObject(objectID, &target)
+		return sp.Docs().GetObject(objectID, &target)
 	default:
 		return fmt.Errorf("bad folder type: %v", ft)
 	}
@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"errors"
+
+	"github.com/hashicorp-forge/hermes/internal/config"
+	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+)
+
+// NewFromConfig creates a notifier with the channels enabled in configuration
+// cfg. Notifications are sent by email, if enabled, and as Slack direct
+// messages, if the Slack bot token is set. Google Workspace service s is used
+// to send emails with the "gmail" provider, and may be nil otherwise.
+func NewFromConfig(
+	cfg *config.Config, s *gw.Service, opts ...NotifierOption,
+) (*Notifier, error) {
+	if cfg.Email != nil && cfg.Email.Enabled {
+		switch cfg.Email.Provider {
+		case "gmail":
+			if s == nil {
+				return nil, errors.New(
+					"the Google storage provider is required to send email with Gmail")
+			}
+			opts = append(opts, WithChannel(
+				models.EmailNotificationChannel,
+				NewGmailChannel(s, cfg.Email.FromAddress)))
+		case "smtp":
+			opts = append(opts, WithChannel(
+				models.EmailNotificationChannel,
+				NewSMTPChannel(
+					cfg.Email.SMTP.Host,
+					cfg.Email.SMTP.Port,
+					cfg.Email.SMTP.Username,
+					cfg.Email.SMTP.Password,
+					cfg.Email.FromAddress,
+				)))
+		}
+	}
+	if cfg.Slack != nil && cfg.Slack.BotToken != "" {
+		opts = append(opts, WithChannel(
+			models.SlackNotificationChannel,
+			NewSlackChannel(cfg.Slack.BotToken)))
+	}
+
+	return NewNotifier(opts...)
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"fmt"
+
+	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+)
+
+// GmailChannel sends messages as emails using the Gmail API.
+type GmailChannel struct {
+	from string
+	s    *gw.Service
+}
+
+// NewGmailChannel returns a channel that sends emails from address from using
+// Google Workspace service s.
+func NewGmailChannel(s *gw.Service, from string) *GmailChannel {
+	return &GmailChannel{
+		from: from,
+		s:    s,
+	}
+}
+
+// Send sends message m as an email to each recipient. Messages without an HTML
+// body are skipped.
+func (c *GmailChannel) Send(m Message) error {
+	if m.HTMLBody == "" {
+		return nil
+	}
+
+	for _, to := range m.To {
+		if _, err := c.s.SendEmail(
+			[]string{to}, c.from, m.Subject, m.HTMLBody); err != nil {
+			return fmt.Errorf("error sending email to %q: %w", to, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,431 @@
+// Package notify sends notifications to users through notification channels,
+// such as email (using Gmail or SMTP) and Slack direct messages.
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
+	"github.com/slack-go/slack"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// loggerName is the name of the logger.
+	loggerName = "notify"
+
+	// defaultInterval is the default time between checks for deferred
+	// notifications that are due.
+	defaultInterval = time.Minute
+
+	// leaseDuration is how long due deferred notifications are claimed by a
+	// notifier while they are sent. Notifications that aren't deleted or
+	// updated by then, e.g., because the notifier exited, are sent again.
+	leaseDuration = 15 * time.Minute
+
+	// maxDeferredAttempts is the number of attempts to send a deferred
+	// notification before giving up.
+	maxDeferredAttempts = 5
+
+	// retryDelay is the delay before sending a deferred notification again
+	// after a failed attempt.
+	retryDelay = 10 * time.Minute
+)
+
+// Message is a notification to send to users. Each channel sends the
+// representation of the message that it supports, and skips messages without
+// one.
+type Message struct {
+	// To are the email addresses of the users to notify. Each user is sent a
+	// separate message.
+	To []string
+
+	// Event is the type of event that the message notifies users of. The
+	// notification preferences of each user decide how they are sent messages
+	// with an event. Messages without an event are sent through all channels
+	// immediately.
+	Event models.NotificationEventType
+
+	// Subject is the subject of emails, and the notification text of Slack
+	// messages.
+	Subject string
+
+	// HTMLBody is the HTML body of emails.
+	HTMLBody string
+
+	// SlackBlocks returns the Block Kit blocks of the Slack direct message to
+	// the user with Slack username username.
+	SlackBlocks func(username string) ([]slack.Block, error)
+}
+
+// Channel sends notifications through a medium, such as email or Slack.
+type Channel interface {
+	// Send sends message m to each of its recipients.
+	Send(m Message) error
+}
+
+// Renderer is implemented by channels that look up details of the recipient
+// when sending a message, such as their Slack username.
+type Renderer interface {
+	// Render returns message m, which has a single recipient, with those
+	// details filled in, so that it can be stored and sent later.
+	Render(m Message) (Message, error)
+}
+
+// namedChannel is a channel with the name that users choose it by in their
+// notification preferences.
+type namedChannel struct {
+	name models.NotificationChannel
+	Channel
+}
+
+// Notifier is a channel that sends messages through all of its channels,
+// according to the notification preferences of each recipient.
+type Notifier struct {
+	// channels are the channels to send messages through.
+	channels []namedChannel
+
+	// Database is the database connection used to get notification
+	// preferences and store deferred notifications. Preferences are ignored if
+	// nil.
+	Database *gorm.DB
+
+	// Interval is the time between checks for deferred notifications that are
+	// due.
+	Interval time.Duration
+
+	// Logger is the logger to use.
+	Logger hclog.Logger
+}
+
+type NotifierOption func(*Notifier)
+
+// NewNotifier creates a new notifier. A notifier without channels doesn't send
+// messages.
+func NewNotifier(opts ...NotifierOption) (*Notifier, error) {
+	// Initialize a new notifier with defaults.
+	n := &Notifier{
+		Interval: defaultInterval,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name: loggerName,
+		}),
+	}
+
+	// Apply functional options.
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	// Validate notifier configuration.
+	if err := n.validate(); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// validate validates the notifier configuration.
+func (n *Notifier) validate() error {
+	for _, c := range n.channels {
+		if err := c.name.Validate(); err != nil {
+			return err
+		}
+	}
+	return validation.ValidateStruct(n,
+		validation.Field(&n.Interval, validation.Required),
+	)
+}
+
+// WithChannel adds channel c, which users choose by name in their
+// notification preferences.
+func WithChannel(name models.NotificationChannel, c Channel) NotifierOption {
+	return func(n *Notifier) {
+		n.channels = append(n.channels, namedChannel{name: name, Channel: c})
+	}
+}
+
+// WithDatabase sets the database.
+func WithDatabase(db *gorm.DB) NotifierOption {
+	return func(n *Notifier) {
+		n.Database = db
+	}
+}
+
+// WithInterval sets the time between checks for deferred notifications that
+// are due.
+func WithInterval(i time.Duration) NotifierOption {
+	return func(n *Notifier) {
+		n.Interval = i
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(l hclog.Logger) NotifierOption {
+	return func(n *Notifier) {
+		n.Logger = l.Named(loggerName)
+	}
+}
+
+// Send sends message m through the channels of the notifier. If the message
+// has an event, each recipient is sent the message through the channels they
+// chose for the event, and messages during their quiet hours are deferred
+// until the quiet hours end. Sending continues if it fails for a channel or
+// recipient, and the errors are returned together.
+func (n *Notifier) Send(m Message) error {
+	if len(m.To) == 0 {
+		return nil
+	}
+	if m.Event == "" || n.Database == nil {
+		return n.sendThrough(m, models.NotificationChannels)
+	}
+
+	var result *multierror.Error
+	now := time.Now()
+	for _, to := range m.To {
+		rm := m
+		rm.To = []string{to}
+		if err := n.sendToRecipient(rm, now); err != nil {
+			result = multierror.Append(result,
+				fmt.Errorf("error notifying %q: %w", to, err))
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// sendToRecipient sends message m, which has a single recipient, according to
+// the recipient's notification preferences as of time now.
+func (n *Notifier) sendToRecipient(m Message, now time.Time) error {
+	// Users without preferences are sent messages through all channels.
+	p := models.NotificationPreference{
+		User: models.User{EmailAddress: m.To[0]},
+	}
+	if err := p.Get(n.Database); err != nil &&
+		!errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("error getting notification preference: %w", err)
+	}
+
+	chs := p.ChannelsFor(m.Event)
+	if len(chs) == 0 {
+		return nil
+	}
+
+	if deliverAt := p.QuietHoursEndAfter(now); !deliverAt.IsZero() {
+		return n.deferMessage(m, chs, deliverAt)
+	}
+	return n.sendThrough(m, chs)
+}
+
+// sendThrough sends message m through the notifier's channels with names chs.
+func (n *Notifier) sendThrough(
+	m Message, chs []models.NotificationChannel) error {
+	var result *multierror.Error
+	for _, c := range n.channels {
+		if !containsChannel(chs, c.name) {
+			continue
+		}
+		if err := c.Send(m); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// deferMessage stores message m, which has a single recipient, to be sent
+// through channels chs at time deliverAt. Channels that fail to render the
+// message are skipped.
+func (n *Notifier) deferMessage(
+	m Message, chs []models.NotificationChannel, deliverAt time.Time) error {
+	var result *multierror.Error
+	var rendered []models.NotificationChannel
+	for _, name := range chs {
+		for _, c := range n.channels {
+			if c.name != name {
+				continue
+			}
+			if r, ok := c.Channel.(Renderer); ok {
+				rm, err := r.Render(m)
+				if err != nil {
+					result = multierror.Append(result, err)
+					break
+				}
+				m = rm
+			}
+			rendered = append(rendered, name)
+			break
+		}
+	}
+	if len(rendered) == 0 {
+		return result.ErrorOrNil()
+	}
+
+	dn, err := newDeferredNotification(m, rendered, deliverAt)
+	if err == nil {
+		err = dn.Create(n.Database)
+	}
+	if err != nil {
+		result = multierror.Append(result,
+			fmt.Errorf("error deferring notification: %w", err))
+	}
+	return result.ErrorOrNil()
+}
+
+// Run sends deferred notifications as they become due, until the process
+// exits.
+func (n *Notifier) Run() {
+	for {
+		if err := n.SendDeferred(time.Now()); err != nil {
+			n.Logger.Error("error sending deferred notifications", "error", err)
+		}
+		time.Sleep(n.Interval)
+	}
+}
+
+// SendDeferred sends the deferred notifications that are due as of time now.
+// Notifications are deleted once sent through all of their channels. Channels
+// that fail are retried later, up to maxDeferredAttempts times.
+func (n *Notifier) SendDeferred(now time.Time) error {
+	if n.Database == nil {
+		return nil
+	}
+
+	ns, err := models.ClaimDueDeferredNotifications(
+		n.Database, now, leaseDuration)
+	if err != nil {
+		return fmt.Errorf("error claiming deferred notifications: %w", err)
+	}
+
+	for _, dn := range ns {
+		log := n.Logger.With(
+			"event", dn.Event,
+			"user", dn.User.EmailAddress,
+		)
+
+		m, err := messageFromDeferredNotification(dn)
+		if err != nil {
+			// The stored message can't be sent on a later attempt either.
+			log.Error("error decoding deferred notification", "error", err)
+			if err := dn.Delete(n.Database); err != nil {
+				log.Error("error deleting deferred notification", "error", err)
+			}
+			continue
+		}
+
+		// Send through each channel separately, so that only channels that
+		// fail are retried.
+		var failed []models.NotificationChannel
+		for _, ch := range dn.Channels.Data {
+			if err := n.sendThrough(
+				m, []models.NotificationChannel{ch}); err != nil {
+				log.Error("error sending deferred notification",
+					"error", err,
+					"channel", ch,
+				)
+				failed = append(failed, ch)
+			}
+		}
+
+		if len(failed) == 0 {
+			if err := dn.Delete(n.Database); err != nil {
+				log.Error("error deleting sent deferred notification",
+					"error", err)
+				continue
+			}
+			log.Info("sent deferred notification")
+			continue
+		}
+
+		dn.Attempts++
+		if dn.Attempts >= maxDeferredAttempts {
+			log.Error("giving up sending deferred notification",
+				"attempts", dn.Attempts,
+				"channels", failed,
+			)
+			if err := dn.Delete(n.Database); err != nil {
+				log.Error("error deleting deferred notification", "error", err)
+			}
+			continue
+		}
+		dn.Channels.Data = failed
+		dn.DeliverAt = now.Add(retryDelay)
+		if err := dn.Update(n.Database); err != nil {
+			log.Error("error updating deferred notification", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// newDeferredNotification returns the deferred notification that stores
+// message m, which has a single recipient, to be sent through channels chs at
+// time deliverAt. Slack blocks are rendered without a username, so channels
+// that need one must render the message first.
+func newDeferredNotification(
+	m Message,
+	chs []models.NotificationChannel,
+	deliverAt time.Time,
+) (*models.DeferredNotification, error) {
+	dn := &models.DeferredNotification{
+		User:      models.User{EmailAddress: m.To[0]},
+		Event:     m.Event,
+		Channels:  datatypes.JSONType[[]models.NotificationChannel]{Data: chs},
+		Subject:   m.Subject,
+		HTMLBody:  m.HTMLBody,
+		DeliverAt: deliverAt,
+	}
+
+	if m.SlackBlocks != nil {
+		blocks, err := m.SlackBlocks("")
+		if err != nil {
+			return nil, fmt.Errorf("error creating Slack blocks: %w", err)
+		}
+		b, err := json.Marshal(slack.Blocks{BlockSet: blocks})
+		if err != nil {
+			return nil, fmt.Errorf("error encoding Slack blocks: %w", err)
+		}
+		dn.SlackBlocks = b
+	}
+
+	return dn, nil
+}
+
+// messageFromDeferredNotification returns the message stored by deferred
+// notification dn.
+func messageFromDeferredNotification(
+	dn models.DeferredNotification) (Message, error) {
+	m := Message{
+		To:       []string{dn.User.EmailAddress},
+		Event:    dn.Event,
+		Subject:  dn.Subject,
+		HTMLBody: dn.HTMLBody,
+	}
+
+	if len(dn.SlackBlocks) > 0 {
+		var blocks slack.Blocks
+		if err := json.Unmarshal(dn.SlackBlocks, &blocks); err != nil {
+			return Message{}, fmt.Errorf("error decoding Slack blocks: %w", err)
+		}
+		m.SlackBlocks = func(string) ([]slack.Block, error) {
+			return blocks.BlockSet, nil
+		}
+	}
+
+	return m, nil
+}
+
+// containsChannel returns true if chs contains channel c.
+func containsChannel(
+	chs []models.NotificationChannel, c models.NotificationChannel) bool {
+	for _, ch := range chs {
+		if ch == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package notify
+
+import (
+	"errors"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/internal/config"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingChannel is a channel that fails to send messages.
+type failingChannel struct{}
+
+func (failingChannel) Send(m Message) error {
+	return errors.New("failed")
+}
+
+func TestNotifier(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	r1, r2 := NewRecorder(), NewRecorder()
+	n, err := NewNotifier(
+		WithChannel(models.EmailNotificationChannel, r1),
+		WithChannel(models.EmailNotificationChannel, failingChannel{}),
+		WithChannel(models.SlackNotificationChannel, r2),
+	)
+	require.NoError(err)
+
+	m := Message{
+		To:       []string{"a@example.com"},
+		Subject:  "Subject",
+		HTMLBody: "<p>Body</p>",
+	}
+
+	// Sending continues after a channel fails.
+	err = n.Send(m)
+	require.Error(err)
+	assert.Contains(err.Error(), "failed")
+	assert.Equal([]Message{m}, r1.Messages())
+	assert.Equal([]Message{m}, r2.Messages())
+
+	// Messages without recipients aren't sent.
+	r1.Reset()
+	require.NoError(n.Send(Message{Subject: "Subject"}))
+	assert.Empty(r1.Messages())
+
+	// Notifiers without channels don't send messages.
+	n, err = NewNotifier()
+	require.NoError(err)
+	require.NoError(n.Send(m))
+
+	// Channels must have a known name.
+	_, err = NewNotifier(WithChannel("pager", r1))
+	assert.Error(err)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := &config.Config{
+		Email: &config.Email{
+			Enabled:     true,
+			FromAddress: "docvault@example.com",
+			Provider:    "smtp",
+			SMTP:        &config.SMTP{Host: "localhost", Port: 25},
+		},
+		Slack: &config.Slack{BotToken: "xoxb-token"},
+	}
+	n, err := NewFromConfig(cfg, nil)
+	require.NoError(err)
+	require.Len(n.channels, 2)
+	assert.Equal(models.EmailNotificationChannel, n.channels[0].name)
+	assert.IsType(&SMTPChannel{}, n.channels[0].Channel)
+	assert.Equal(models.SlackNotificationChannel, n.channels[1].name)
+
+	// Disabled channels aren't added.
+	cfg.Email.Enabled = false
+	cfg.Slack.BotToken = ""
+	n, err = NewFromConfig(cfg, nil)
+	require.NoError(err)
+	assert.Empty(n.channels)
+
+	// Gmail requires the Google Workspace service.
+	cfg.Email.Enabled = true
+	cfg.Email.Provider = "gmail"
+	_, err = NewFromConfig(cfg, nil)
+	assert.Error(err)
+}
+
+func TestDeferredNotification(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	deliverAt := time.Date(2026, 10, 19, 7, 0, 0, 0, time.UTC)
+	m := Message{
+		To:       []string{"a@example.com"},
+		Event:    models.ReviewRequestedNotificationEvent,
+		Subject:  "Subject",
+		HTMLBody: "<p>Body</p>",
+		SlackBlocks: func(username string) ([]slack.Block, error) {
+			return []slack.Block{
+				slack.NewSectionBlock(slack.NewTextBlockObject(
+					"mrkdwn", "Hi "+username, false, false), nil, nil),
+			}, nil
+		},
+	}
+
+	dn, err := newDeferredNotification(m,
+		[]models.NotificationChannel{models.SlackNotificationChannel}, deliverAt)
+	require.NoError(err)
+	assert.Equal("a@example.com", dn.User.EmailAddress)
+	assert.Equal(models.ReviewRequestedNotificationEvent, dn.Event)
+	assert.Equal([]models.NotificationChannel{models.SlackNotificationChannel},
+		dn.Channels.Data)
+	assert.Equal(deliverAt, dn.DeliverAt)
+
+	// The stored message has the same content, with Slack blocks as rendered
+	// when it was deferred.
+	got, err := messageFromDeferredNotification(*dn)
+	require.NoError(err)
+	assert.Equal(m.To, got.To)
+	assert.Equal(m.Event, got.Event)
+	assert.Equal(m.Subject, got.Subject)
+	assert.Equal(m.HTMLBody, got.HTMLBody)
+	require.NotNil(got.SlackBlocks)
+	blocks, err := got.SlackBlocks("ignored")
+	require.NoError(err)
+	require.Len(blocks, 1)
+	section, ok := blocks[0].(*slack.SectionBlock)
+	require.True(ok)
+	assert.Equal("Hi ", section.Text.Text)
+
+	// Messages without Slack blocks are stored without them.
+	m.SlackBlocks = nil
+	dn, err = newDeferredNotification(m,
+		[]models.NotificationChannel{models.EmailNotificationChannel}, deliverAt)
+	require.NoError(err)
+	assert.Empty(dn.SlackBlocks)
+	got, err = messageFromDeferredNotification(*dn)
+	require.NoError(err)
+	assert.Nil(got.SlackBlocks)
+}
+
+func TestSMTPChannel(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	type sent struct {
+		addr string
+		from string
+		to   []string
+		msg  string
+	}
+	var got []sent
+	c := NewSMTPChannel("localhost", 1025, "", "", "docvault@example.com")
+	c.sendMail = func(
+		addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Nil(a)
+		got = append(got, sent{addr, from, to, string(msg)})
+		return nil
+	}
+
+	// Messages without an HTML body are skipped.
+	require.NoError(c.Send(Message{
+		To:      []string{"a@example.com"},
+		Subject: "Slack only",
+	}))
+	assert.Empty(got)
+
+	// Each recipient is sent a separate email.
+	require.NoError(c.Send(Message{
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "Review requested",
+		HTMLBody: "<p>Body</p>",
+	}))
+	require.Len(got, 2)
+	assert.Equal("localhost:1025", got[0].addr)
+	assert.Equal("docvault@example.com", got[0].from)
+	assert.Equal([]string{"a@example.com"}, got[0].to)
+	assert.Contains(got[0].msg, "To: a@example.com\r\n")
+	assert.Contains(got[0].msg, "Subject: Review requested\r\n")
+	assert.Contains(got[0].msg, "Content-Type: text/html; charset=UTF-8\r\n")
+	assert.Equal([]string{"b@example.com"}, got[1].to)
+}
+
+func TestBuildSMTPMessage(t *testing.T) {
+	date := time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+	msg := buildSMTPMessage("docvault@example.com", "a@example.com",
+		"Approved: [PAYMT-042] Zahlungen", "<p>Body</p>", date)
+	assert.Equal(t, "From: docvault@example.com\r\n"+
+		"To: a@example.com\r\n"+
+		"Subject: Approved: [PAYMT-042] Zahlungen\r\n"+
+		"Date: Mon, 01 May 2023 09:00:00 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/html; charset=UTF-8\r\n"+
+		"\r\n"+
+		"<p>Body</p>\r\n", string(msg))
+
+	// Non-ASCII subjects are encoded.
+	msg = buildSMTPMessage("docvault@example.com", "a@example.com",
+		"Über", "<p>Body</p>", date)
+	assert.Contains(t, string(msg), "Subject: =?utf-8?q?=C3=9Cber?=\r\n")
+}
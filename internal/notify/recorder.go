@@ -0,0 +1,40 @@
+package notify
+
+import "sync"
+
+// Recorder is a channel that records messages in memory instead of sending
+// them, for use in tests.
+type Recorder struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewRecorder returns an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Send records message m.
+func (r *Recorder) Send(m Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = append(r.messages, m)
+	return nil
+}
+
+// Messages returns the recorded messages, in the order they were sent.
+func (r *Recorder) Messages() []Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Message(nil), r.messages...)
+}
+
+// Reset deletes the recorded messages.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages = nil
+}
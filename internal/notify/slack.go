@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"fmt"
+
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	"github.com/slack-go/slack"
+)
+
+// SlackChannel sends messages as Slack direct messages from the Slack app's
+// bot user.
+type SlackChannel struct {
+	api *slack.Client
+}
+
+// NewSlackChannel returns a channel that sends Slack direct messages using the
+// Slack app's bot token.
+func NewSlackChannel(botToken string) *SlackChannel {
+	return &SlackChannel{
+		api: slack.New(botToken),
+	}
+}
+
+// Send sends message m as a direct message to the Slack user of each recipient,
+// found by email address. Messages without Slack blocks are skipped.
+func (c *SlackChannel) Send(m Message) error {
+	if m.SlackBlocks == nil {
+		return nil
+	}
+
+	for _, to := range m.To {
+		userID, username, err := slackbot.GetUserIDByEmail(to, c.api)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve Slack user ID: %w", err)
+		}
+
+		blocks, err := m.SlackBlocks(username)
+		if err != nil {
+			return fmt.Errorf("failed to create the message blocks: %w", err)
+		}
+
+		if _, _, err := c.api.PostMessage(
+			userID,
+			slack.MsgOptionText(m.Subject, false),
+			slack.MsgOptionBlocks(blocks...),
+		); err != nil {
+			return fmt.Errorf("failed to send Slack direct message: %w", err)
+		}
+	}
+	return nil
+}
+
+// Render returns message m, which has a single recipient, with its Slack
+// blocks rendered for the recipient's Slack user, found by email address.
+func (c *SlackChannel) Render(m Message) (Message, error) {
+	if m.SlackBlocks == nil {
+		return m, nil
+	}
+
+	_, username, err := slackbot.GetUserIDByEmail(m.To[0], c.api)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to retrieve Slack user ID: %w", err)
+	}
+	blocks, err := m.SlackBlocks(username)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to create the message blocks: %w", err)
+	}
+
+	m.SlackBlocks = func(string) ([]slack.Block, error) {
+		return blocks, nil
+	}
+	return m, nil
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPChannel sends messages as emails using an SMTP server, such as a local
+// mail server for development.
+type SMTPChannel struct {
+	addr string
+	auth smtp.Auth
+	from string
+
+	// sendMail sends an email, and is replaced in tests.
+	sendMail func(
+		addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPChannel returns a channel that sends emails from address from using
+// the SMTP server at host and port. If username is set, the channel
+// authenticates using PLAIN authentication, which requires TLS unless the
+// server is on localhost.
+func NewSMTPChannel(
+	host string, port int, username, password, from string) *SMTPChannel {
+	c := &SMTPChannel{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		from:     from,
+		sendMail: smtp.SendMail,
+	}
+	if username != "" {
+		c.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return c
+}
+
+// Send sends message m as an email to each recipient. Messages without an HTML
+// body are skipped.
+func (c *SMTPChannel) Send(m Message) error {
+	if m.HTMLBody == "" {
+		return nil
+	}
+
+	for _, to := range m.To {
+		msg := buildSMTPMessage(c.from, to, m.Subject, m.HTMLBody, time.Now())
+		if err := c.sendMail(
+			c.addr, c.auth, c.from, []string{to}, msg); err != nil {
+			return fmt.Errorf("error sending email to %q: %w", to, err)
+		}
+	}
+	return nil
+}
+
+// buildSMTPMessage returns the RFC 5322 message of an HTML email from address
+// from to address to, sent at time date.
+func buildSMTPMessage(from, to, subject, body string, date time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(body)
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
@@ -1,2 +0,0 @@
-// Package doctypes manages document types.
-package doctypes
@@ -4,11 +4,11 @@ import (
 	"hash/fnv"
 
 	"github.com/hashicorp-forge/hermes/internal/config"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
+	"github.com/hashicorp-forge/hermes/pkg/search"
 	"github.com/hashicorp/go-hclog"
 )
 
-// FeatureFlagsObj is a record in Algolia
+// FeatureFlagsObj is a record in the internal index
 // with "featureFlags" as object ID and
 // a map of each feature flag with a
 // set of user emails that should have
@@ -31,7 +31,7 @@ type FeatureFlagsObj struct {
 // SetAndToggle sets and toggle feature flags.
 func SetAndToggle(
 	flags *config.FeatureFlags,
-	a *algolia.Client,
+	sp search.Provider,
 	h string,
 	email string,
 	log hclog.Logger) map[string]bool {
@@ -70,7 +70,7 @@ func SetAndToggle(
 			// users using email address
 			if !featureFlags[j.Name] {
 				featureFlags[j.Name] = toggleFlagEmail(
-					a,
+					sp,
 					j.Name,
 					email,
 					log,
@@ -95,17 +95,17 @@ func toggleFlagPercentage(s string, p int) bool {
 
 // toggleFlagEmail toggles a feature flag
 // using user email
-func toggleFlagEmail(a *algolia.Client, flag string, email string, log hclog.Logger) bool {
+func toggleFlagEmail(sp search.Provider, flag string, email string, log hclog.Logger) bool {
 	f := FeatureFlagsObj{}
-	err := a.Internal.GetObject("featureFlags", &f)
+	err := sp.Internal().GetObject("featureFlags", &f)
 	if err != nil {
-		log.Error("error getting featureFlags object from algolia", "error", err)
+		log.Error("error getting featureFlags object", "error", err)
 		return false
 	}
 
 	// Enable feature flag if the user email
 	// is found in the list of user emails
-	// for the feature flag in Algolia
+	// for the feature flag in the internal index
 	for _, k := range f.FeatureFlagUserEmails[flag] {
 		if email == k {
 			return true
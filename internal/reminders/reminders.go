@@ -0,0 +1,540 @@
+package reminders
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hashicorp-forge/hermes/internal/email"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp/go-hclog"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	// loggerName is the name of the logger.
+	loggerName = "reminders"
+
+	// defaultDaysBefore is the default number of days before a document's due
+	// date to start reminding reviewers.
+	defaultDaysBefore = 2
+
+	// defaultInterval is the default time between runs of the scheduler.
+	defaultInterval = time.Hour
+)
+
+// Scheduler sends reminders to reviewers of in-review documents that have a due
+// date.
+type Scheduler struct {
+	// BaseURL is the base URL for the application.
+	BaseURL string
+
+	// Database is the database connection.
+	Database *gorm.DB
+
+	// DaysBefore is the number of days before a document's due date to start
+	// reminding reviewers.
+	DaysBefore int
+
+	// Interval is the time between runs of the scheduler.
+	Interval time.Duration
+
+	// Logger is the logger to use.
+	Logger hclog.Logger
+
+	// Notifier is the notification channel used to send reminders as emails and
+	// Slack direct messages.
+	Notifier notify.Channel
+
+	// SearchProvider is the search provider used to get document objects.
+	SearchProvider search.Provider
+
+	// SendEmails sends reminders as emails, if true.
+	SendEmails bool
+
+	// SendSlackMessages sends reminders as Slack direct messages, if true.
+	SendSlackMessages bool
+}
+
+type SchedulerOption func(*Scheduler)
+
+// NewScheduler creates a new reminder scheduler.
+func NewScheduler(opts ...SchedulerOption) (*Scheduler, error) {
+	// Initialize a new scheduler with defaults.
+	s := &Scheduler{
+		DaysBefore: defaultDaysBefore,
+		Interval:   defaultInterval,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name: loggerName,
+		}),
+	}
+
+	// Apply functional options.
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	// Validate scheduler configuration.
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// validate validates the scheduler configuration.
+func (s *Scheduler) validate() error {
+	return validation.ValidateStruct(s,
+		validation.Field(&s.BaseURL, validation.Required),
+		validation.Field(&s.Database, validation.Required),
+		validation.Field(&s.DaysBefore, validation.Min(0)),
+		validation.Field(&s.Interval, validation.Required),
+		validation.Field(&s.SearchProvider, validation.Required),
+		validation.Field(&s.Notifier,
+			validation.When(s.SendEmails || s.SendSlackMessages,
+				validation.Required)),
+	)
+}
+
+// WithBaseURL sets the base URL.
+func WithBaseURL(b string) SchedulerOption {
+	return func(s *Scheduler) {
+		s.BaseURL = b
+	}
+}
+
+// WithDatabase sets the database.
+func WithDatabase(db *gorm.DB) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Database = db
+	}
+}
+
+// WithDaysBefore sets the number of days before a document's due date to start
+// reminding reviewers.
+func WithDaysBefore(d int) SchedulerOption {
+	return func(s *Scheduler) {
+		s.DaysBefore = d
+	}
+}
+
+// WithInterval sets the time between runs of the scheduler.
+func WithInterval(i time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Interval = i
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(l hclog.Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Logger = l.Named(loggerName)
+	}
+}
+
+// WithNotifier sets the notification channel used to send reminders.
+func WithNotifier(n notify.Channel) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Notifier = n
+	}
+}
+
+// WithSearchProvider sets the search provider.
+func WithSearchProvider(sp search.Provider) SchedulerOption {
+	return func(s *Scheduler) {
+		s.SearchProvider = sp
+	}
+}
+
+// WithSendEmails sets the boolean to send reminders as emails.
+func WithSendEmails(b bool) SchedulerOption {
+	return func(s *Scheduler) {
+		s.SendEmails = b
+	}
+}
+
+// WithSendSlackMessages sets the boolean to send reminders as Slack direct
+// messages.
+func WithSendSlackMessages(b bool) SchedulerOption {
+	return func(s *Scheduler) {
+		s.SendSlackMessages = b
+	}
+}
+
+// Run runs the scheduler until the process exits.
+func (s *Scheduler) Run() {
+	for {
+		if err := s.RunOnce(time.Now()); err != nil {
+			s.Logger.Error("error sending review reminders", "error", err)
+		}
+		time.Sleep(s.Interval)
+	}
+}
+
+// RunOnce sends any due reminders as of time now. Reminders that have already
+// been sent (as recorded in the database) are not sent again. If Slack
+// messages are enabled, review requests snoozed from Slack are also sent again
+// once due.
+func (s *Scheduler) RunOnce(now time.Time) error {
+	var docs models.Documents
+	if err := docs.Find(s.Database,
+		"status = ? AND due_date IS NOT NULL", models.InReviewDocumentStatus,
+	); err != nil {
+		return fmt.Errorf("error finding in-review documents: %w", err)
+	}
+
+	today := hcd.NewDate(now).Time
+	for _, d := range docs {
+		rt := reminderType(today, *d.DueDate, s.DaysBefore)
+		if rt == models.UnspecifiedReviewReminderType {
+			continue
+		}
+
+		if err := s.remindReviewers(d, rt, now); err != nil {
+			s.Logger.Error("error reminding reviewers",
+				"error", err,
+				"doc_id", d.GoogleFileID,
+			)
+		}
+	}
+
+	if s.SendSlackMessages {
+		if err := s.resendSnoozedReviewRequests(now); err != nil {
+			return fmt.Errorf("error resending snoozed review requests: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// remindReviewers sends reminders of type rt to the reviewers of document d
+// who haven't approved it yet, through the channels they chose for due soon
+// notifications. Reviewers in their quiet hours as of time now are reminded by
+// the first run after their quiet hours end.
+func (s *Scheduler) remindReviewers(
+	d models.Document, rt models.ReviewReminderType, now time.Time) error {
+	// Get document object from search provider, which has the current set of
+	// reviewers and approvals.
+	docObj, err := hcd.NewEmptyDoc(d.DocumentType.Name)
+	if err != nil {
+		return fmt.Errorf("error getting empty document: %w", err)
+	}
+	if err := s.SearchProvider.Docs().GetObject(
+		d.GoogleFileID, &docObj); err != nil {
+		return fmt.Errorf("error getting document object: %w", err)
+	}
+	if len(docObj.GetOwners()) == 0 {
+		return errors.New("document has no owners")
+	}
+
+	docURL, err := getDocumentURL(s.BaseURL, d.GoogleFileID)
+	if err != nil {
+		return fmt.Errorf("error getting document URL: %w", err)
+	}
+
+	dueDate := hcd.NewDate(*d.DueDate)
+	for _, reviewer := range pendingReviewers(docObj) {
+		p := models.NotificationPreference{
+			User: models.User{EmailAddress: reviewer},
+		}
+		if err := p.Get(s.Database); err != nil &&
+			!errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("error getting notification preference: %w", err)
+		}
+
+		for _, ch := range reminderChannels(s.channels(), p, now) {
+			r := models.ReviewReminder{
+				DocumentID:           d.ID,
+				ReviewerEmailAddress: reviewer,
+				DueDate:              dueDate.Time,
+				Type:                 rt,
+				Channel:              ch,
+			}
+
+			// Skip reminders that have already been sent.
+			if err := r.Get(s.Database); err == nil {
+				continue
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("error getting review reminder: %w", err)
+			}
+
+			if err := s.send(ch, docObj, docURL, dueDate.String(),
+				rt == models.OverdueReviewReminderType, reviewer); err != nil {
+				s.Logger.Error("error sending review reminder",
+					"error", err,
+					"doc_id", d.GoogleFileID,
+					"reviewer", reviewer,
+					"channel", ch,
+				)
+				continue
+			}
+
+			if err := r.Create(s.Database); err != nil {
+				return fmt.Errorf("error recording review reminder: %w", err)
+			}
+			s.Logger.Info("sent review reminder",
+				"doc_id", d.GoogleFileID,
+				"reviewer", reviewer,
+				"channel", ch,
+				"overdue", rt == models.OverdueReviewReminderType,
+			)
+		}
+	}
+
+	return nil
+}
+
+// resendSnoozedReviewRequests sends review requests that reviewers snoozed from
+// Slack again as Slack direct messages, once due as of time now, if the
+// reviewer still hasn't approved the document.
+func (s *Scheduler) resendSnoozedReviewRequests(now time.Time) error {
+	var snoozes models.ReviewSnoozes
+	if err := snoozes.FindDue(s.Database, now); err != nil {
+		return fmt.Errorf("error finding due review snoozes: %w", err)
+	}
+
+	for _, rs := range snoozes {
+		if err := s.resendReviewRequest(rs); err != nil {
+			s.Logger.Error("error resending snoozed review request",
+				"error", err,
+				"doc_id", rs.Document.GoogleFileID,
+				"reviewer", rs.ReviewerEmailAddress,
+			)
+			continue
+		}
+		if err := rs.Delete(s.Database); err != nil {
+			return fmt.Errorf("error deleting review snooze: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// resendReviewRequest sends the review request snoozed by review snooze rs
+// again, if its document is still in review and the reviewer hasn't approved
+// it.
+func (s *Scheduler) resendReviewRequest(rs models.ReviewSnooze) error {
+	d := rs.Document
+	if d.Status != models.InReviewDocumentStatus {
+		return nil
+	}
+
+	docObj, err := hcd.NewEmptyDoc(d.DocumentType.Name)
+	if err != nil {
+		return fmt.Errorf("error getting empty document: %w", err)
+	}
+	if err := s.SearchProvider.Docs().GetObject(
+		d.GoogleFileID, &docObj); err != nil {
+		return fmt.Errorf("error getting document object: %w", err)
+	}
+	if len(docObj.GetOwners()) == 0 {
+		return errors.New("document has no owners")
+	}
+
+	pending := false
+	for _, r := range pendingReviewers(docObj) {
+		if strings.EqualFold(r, rs.ReviewerEmailAddress) {
+			pending = true
+			break
+		}
+	}
+	if !pending {
+		return nil
+	}
+
+	docURL, err := getDocumentURL(s.BaseURL, d.GoogleFileID)
+	if err != nil {
+		return fmt.Errorf("error getting document URL: %w", err)
+	}
+	sd := slackbot.ReviewerRequestedSlackData{
+		BaseURL:            s.BaseURL,
+		DocumentID:         d.GoogleFileID,
+		DocumentOwner:      docObj.GetOwners()[0],
+		DocumentOwnerEmail: docObj.GetOwners()[0],
+		DocumentType:       docObj.GetDocType(),
+		DocumentShortName:  docObj.GetDocNumber(),
+		DocumentTitle:      docObj.GetTitle(),
+		DocumentURL:        docURL,
+		DocumentProd:       docObj.GetProduct(),
+		DocumentTeam:       docObj.GetTeam(),
+		Interactive:        true,
+		Snoozable:          true,
+	}
+	// The message has no event because the reviewer chose to be sent it again
+	// when snoozing it, and only has Slack blocks so it's only sent through
+	// Slack.
+	if err := s.Notifier.Send(notify.Message{
+		To:      []string{rs.ReviewerEmailAddress},
+		Subject: "DocVault: Review Requested",
+		SlackBlocks: func(username string) ([]slack.Block, error) {
+			msg, err := slackbot.GenerateUIRichBlocks_Reviewer(sd, username)
+			if err != nil {
+				return nil, err
+			}
+			return msg.Blocks.BlockSet, nil
+		},
+	}); err != nil {
+		return fmt.Errorf("error sending review request: %w", err)
+	}
+	s.Logger.Info("resent snoozed review request",
+		"doc_id", d.GoogleFileID,
+		"reviewer", rs.ReviewerEmailAddress,
+	)
+
+	return nil
+}
+
+// channels returns the enabled reminder channels.
+func (s *Scheduler) channels() []models.ReviewReminderChannel {
+	var chs []models.ReviewReminderChannel
+	if s.SendEmails {
+		chs = append(chs, models.EmailReviewReminderChannel)
+	}
+	if s.SendSlackMessages {
+		chs = append(chs, models.SlackReviewReminderChannel)
+	}
+	return chs
+}
+
+// reminderChannels returns the channels of enabled channels that a reviewer
+// with notification preference p wants due soon reminders through as of time
+// now, which are none during the reviewer's quiet hours.
+func reminderChannels(
+	enabled []models.ReviewReminderChannel,
+	p models.NotificationPreference,
+	now time.Time,
+) []models.ReviewReminderChannel {
+	if !p.QuietHoursEndAfter(now).IsZero() {
+		return nil
+	}
+
+	var chs []models.ReviewReminderChannel
+	for _, ch := range enabled {
+		for _, pch := range p.ChannelsFor(models.DueSoonNotificationEvent) {
+			if string(ch) == string(pch) {
+				chs = append(chs, ch)
+				break
+			}
+		}
+	}
+	return chs
+}
+
+// send sends a reminder for document docObj to reviewer using channel ch.
+func (s *Scheduler) send(
+	ch models.ReviewReminderChannel,
+	docObj hcd.Doc,
+	docURL string,
+	dueDate string,
+	overdue bool,
+	reviewer string,
+) error {
+	switch ch {
+	case models.EmailReviewReminderChannel:
+		e, err := email.NewReviewReminderEmail(
+			email.ReviewReminderEmailData{
+				BaseURL:            s.BaseURL,
+				DocumentOwner:      docObj.GetOwners()[0],
+				DocumentOwnerEmail: docObj.GetOwners()[0],
+				DocumentTitle:      docObj.GetTitle(),
+				DocumentURL:        docURL,
+				DocumentProd:       docObj.GetProduct(),
+				DocumentTeam:       docObj.GetTeam(),
+				DueDate:            dueDate,
+				Overdue:            overdue,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		// The message has no event because the reminder channels were already
+		// chosen using the reviewer's notification preferences.
+		return s.Notifier.Send(notify.Message{
+			To:       []string{reviewer},
+			Subject:  e.Subject,
+			HTMLBody: e.Body,
+		})
+	case models.SlackReviewReminderChannel:
+		sd := slackbot.ReviewReminderSlackData{
+			BaseURL:            s.BaseURL,
+			DocumentOwner:      docObj.GetOwners()[0],
+			DocumentOwnerEmail: docObj.GetOwners()[0],
+			DocumentType:       docObj.GetDocType(),
+			DocumentTitle:      docObj.GetTitle(),
+			DocumentURL:        docURL,
+			DueDate:            dueDate,
+			Overdue:            overdue,
+		}
+		return s.Notifier.Send(notify.Message{
+			To:      []string{reviewer},
+			Subject: "DocVault: Review Reminder",
+			SlackBlocks: func(username string) ([]slack.Block, error) {
+				msg, err := slackbot.GenerateUIRichBlocks_ReviewReminder(sd, username)
+				if err != nil {
+					return nil, err
+				}
+				return msg.Blocks.BlockSet, nil
+			},
+		})
+	default:
+		return fmt.Errorf("unknown reminder channel: %q", ch)
+	}
+}
+
+// reminderType returns the type of reminder to send on date today for a
+// document due on dueDate, or UnspecifiedReviewReminderType if no reminder
+// should be sent.
+func reminderType(
+	today, dueDate time.Time, daysBefore int) models.ReviewReminderType {
+	today = hcd.NewDate(today).Time
+	dueDate = hcd.NewDate(dueDate).Time
+
+	switch {
+	case today.After(dueDate):
+		return models.OverdueReviewReminderType
+	case !today.Before(dueDate.AddDate(0, 0, -daysBefore)):
+		return models.UpcomingReviewReminderType
+	default:
+		return models.UnspecifiedReviewReminderType
+	}
+}
+
+// pendingReviewers returns the reviewers of a document who haven't approved
+// it.
+func pendingReviewers(docObj hcd.Doc) []string {
+	reviewed := make(map[string]struct{}, len(docObj.GetReviewedBy()))
+	for _, r := range docObj.GetReviewedBy() {
+		reviewed[strings.ToLower(r)] = struct{}{}
+	}
+
+	var pending []string
+	for _, r := range docObj.GetReviewers() {
+		if _, ok := reviewed[strings.ToLower(r)]; !ok {
+			pending = append(pending, r)
+		}
+	}
+	return pending
+}
+
+// getDocumentURL returns a Hermes document URL.
+func getDocumentURL(baseURL, docID string) (string, error) {
+	docURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing base URL: %w", err)
+	}
+
+	docURL.Path = path.Join(docURL.Path, "document", docID)
+	docURLString := docURL.String()
+	docURLString = strings.TrimRight(docURLString, "/")
+
+	return docURLString, nil
+}
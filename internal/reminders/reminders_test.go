@@ -0,0 +1,127 @@
+package reminders
+
+import (
+	"testing"
+	"time"
+
+	hcd "github.com/hashicorp-forge/hermes/pkg/hashicorpdocs"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+)
+
+func TestReminderType(t *testing.T) {
+	dueDate := time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		today      time.Time
+		daysBefore int
+		want       models.ReviewReminderType
+	}{
+		"well before due date": {
+			today:      time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC),
+			daysBefore: 2,
+			want:       models.UnspecifiedReviewReminderType,
+		},
+		"day before reminder window": {
+			today:      time.Date(2023, 5, 7, 23, 59, 0, 0, time.UTC),
+			daysBefore: 2,
+			want:       models.UnspecifiedReviewReminderType,
+		},
+		"start of reminder window": {
+			today:      time.Date(2023, 5, 8, 0, 0, 0, 0, time.UTC),
+			daysBefore: 2,
+			want:       models.UpcomingReviewReminderType,
+		},
+		"on due date": {
+			today:      time.Date(2023, 5, 10, 18, 0, 0, 0, time.UTC),
+			daysBefore: 2,
+			want:       models.UpcomingReviewReminderType,
+		},
+		"on due date with no days before": {
+			today:      time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC),
+			daysBefore: 0,
+			want:       models.UpcomingReviewReminderType,
+		},
+		"after due date": {
+			today:      time.Date(2023, 5, 11, 0, 0, 0, 0, time.UTC),
+			daysBefore: 2,
+			want:       models.OverdueReviewReminderType,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, reminderType(c.today, dueDate, c.daysBefore))
+		})
+	}
+}
+
+func TestPendingReviewers(t *testing.T) {
+	docObj := &hcd.COMMONTEMPLATE{
+		BaseDoc: hcd.BaseDoc{
+			Reviewers:  []string{"a@example.com", "b@example.com", "c@example.com"},
+			ReviewedBy: []string{"B@example.com"},
+		},
+	}
+	assert.Equal(t,
+		[]string{"a@example.com", "c@example.com"}, pendingReviewers(docObj))
+}
+
+func TestReminderChannels(t *testing.T) {
+	enabled := []models.ReviewReminderChannel{
+		models.EmailReviewReminderChannel,
+		models.SlackReviewReminderChannel,
+	}
+	now := time.Date(2023, 5, 10, 23, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		pref models.NotificationPreference
+		want []models.ReviewReminderChannel
+	}{
+		"no preference": {
+			want: enabled,
+		},
+		"slack only": {
+			pref: models.NotificationPreference{
+				Channels: datatypes.JSONType[map[models.NotificationEventType][]models.NotificationChannel]{
+					Data: map[models.NotificationEventType][]models.NotificationChannel{
+						models.DueSoonNotificationEvent: {models.SlackNotificationChannel},
+					},
+				},
+			},
+			want: []models.ReviewReminderChannel{
+				models.SlackReviewReminderChannel,
+			},
+		},
+		"opted out": {
+			pref: models.NotificationPreference{
+				Channels: datatypes.JSONType[map[models.NotificationEventType][]models.NotificationChannel]{
+					Data: map[models.NotificationEventType][]models.NotificationChannel{
+						models.DueSoonNotificationEvent: {},
+					},
+				},
+			},
+		},
+		"quiet hours": {
+			pref: models.NotificationPreference{
+				QuietHoursStart: "22:00",
+				QuietHoursEnd:   "07:00",
+			},
+		},
+		"outside quiet hours": {
+			pref: models.NotificationPreference{
+				QuietHoursStart: "22:00",
+				QuietHoursEnd:   "07:00",
+				TimeZone:        "America/Los_Angeles",
+			},
+			want: enabled,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, reminderChannels(enabled, c.pref, now))
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package savedsearches
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hashicorp-forge/hermes/internal/email"
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	slackbot "github.com/hashicorp-forge/hermes/internal/slack-bot"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/search"
+	"github.com/hashicorp/go-hclog"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	// loggerName is the name of the logger.
+	loggerName = "saved-searches"
+
+	// defaultInterval is the default time between runs of the notifier.
+	defaultInterval = time.Hour
+
+	// hitsPerCheck is the number of most recently modified matching documents
+	// considered in each check of a saved search.
+	hitsPerCheck = 100
+)
+
+// Notifier notifies users of new documents matching their saved searches.
+type Notifier struct {
+	// BaseURL is the base URL for the application.
+	BaseURL string
+
+	// Database is the database connection.
+	Database *gorm.DB
+
+	// Interval is the time between runs of the notifier.
+	Interval time.Duration
+
+	// Logger is the logger to use.
+	Logger hclog.Logger
+
+	// Notifier is the notification channel used to send notifications as
+	// emails and Slack direct messages.
+	Notifier notify.Channel
+
+	// SearchProvider is the search provider used to run saved searches.
+	SearchProvider search.Provider
+
+	// SendEmails sends notifications as emails, if true.
+	SendEmails bool
+
+	// SendSlackMessages sends notifications as Slack direct messages, if true.
+	SendSlackMessages bool
+}
+
+type NotifierOption func(*Notifier)
+
+// NewNotifier creates a new saved search notifier.
+func NewNotifier(opts ...NotifierOption) (*Notifier, error) {
+	// Initialize a new notifier with defaults.
+	n := &Notifier{
+		Interval: defaultInterval,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name: loggerName,
+		}),
+	}
+
+	// Apply functional options.
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	// Validate notifier configuration.
+	if err := n.validate(); err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
+
+// validate validates the notifier configuration.
+func (n *Notifier) validate() error {
+	return validation.ValidateStruct(n,
+		validation.Field(&n.BaseURL, validation.Required),
+		validation.Field(&n.Database, validation.Required),
+		validation.Field(&n.Interval, validation.Required),
+		validation.Field(&n.SearchProvider, validation.Required),
+		validation.Field(&n.Notifier,
+			validation.When(n.SendEmails || n.SendSlackMessages,
+				validation.Required)),
+	)
+}
+
+// WithBaseURL sets the base URL.
+func WithBaseURL(b string) NotifierOption {
+	return func(n *Notifier) {
+		n.BaseURL = b
+	}
+}
+
+// WithDatabase sets the database.
+func WithDatabase(db *gorm.DB) NotifierOption {
+	return func(n *Notifier) {
+		n.Database = db
+	}
+}
+
+// WithInterval sets the time between runs of the notifier.
+func WithInterval(i time.Duration) NotifierOption {
+	return func(n *Notifier) {
+		n.Interval = i
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(l hclog.Logger) NotifierOption {
+	return func(n *Notifier) {
+		n.Logger = l.Named(loggerName)
+	}
+}
+
+// WithNotifier sets the notification channel used to send notifications.
+func WithNotifier(c notify.Channel) NotifierOption {
+	return func(n *Notifier) {
+		n.Notifier = c
+	}
+}
+
+// WithSearchProvider sets the search provider.
+func WithSearchProvider(sp search.Provider) NotifierOption {
+	return func(n *Notifier) {
+		n.SearchProvider = sp
+	}
+}
+
+// WithSendEmails sets the boolean to send notifications as emails.
+func WithSendEmails(b bool) NotifierOption {
+	return func(n *Notifier) {
+		n.SendEmails = b
+	}
+}
+
+// WithSendSlackMessages sets the boolean to send notifications as Slack direct
+// messages.
+func WithSendSlackMessages(b bool) NotifierOption {
+	return func(n *Notifier) {
+		n.SendSlackMessages = b
+	}
+}
+
+// Run runs the notifier until the process exits.
+func (n *Notifier) Run() {
+	for {
+		if err := n.RunOnce(time.Now()); err != nil {
+			n.Logger.Error("error checking saved searches", "error", err)
+		}
+		time.Sleep(n.Interval)
+	}
+}
+
+// RunOnce checks all saved searches with notifications enabled for new
+// matching documents as of time now, and notifies their users.
+func (n *Notifier) RunOnce(now time.Time) error {
+	var ss models.SavedSearches
+	if err := ss.FindNotifiable(n.Database); err != nil {
+		return fmt.Errorf("error finding saved searches: %w", err)
+	}
+
+	for _, s := range ss {
+		if err := n.check(s, now); err != nil {
+			n.Logger.Error("error checking saved search",
+				"error", err,
+				"saved_search_id", s.ID,
+				"user", s.User.EmailAddress,
+			)
+		}
+	}
+
+	return nil
+}
+
+// check checks saved search s for new matching documents and notifies its
+// user of them.
+func (n *Notifier) check(s models.SavedSearch, now time.Time) error {
+	// Get the most recently modified matching documents.
+	res, err := n.SearchProvider.Docs().Search(search.Query{
+		Text:         s.Query,
+		FacetFilters: s.FacetFilters(),
+		HitsPerPage:  hitsPerCheck,
+		SortBy:       "modifiedTime",
+		SortDesc:     true,
+	})
+	if err != nil {
+		return fmt.Errorf("error searching documents: %w", err)
+	}
+	docs := matchedDocuments(res.Hits)
+
+	ids := make([]string, len(docs))
+	for i, d := range docs {
+		ids[i] = d.ID
+	}
+	newIDs, err := s.NewMatches(n.Database, ids)
+	if err != nil {
+		return fmt.Errorf("error getting new matches: %w", err)
+	}
+	if len(newIDs) == 0 {
+		return s.RecordMatches(n.Database, nil, now)
+	}
+
+	// Matches found by the first check of a search are recorded without
+	// notifying the user.
+	if s.LastCheckedAt == nil {
+		return s.RecordMatches(n.Database, newIDs, now)
+	}
+
+	matches := notifiableDocuments(docs, newIDs, s.User.EmailAddress)
+	for i := range matches {
+		if matches[i].URL, err = getDocumentURL(n.BaseURL, matches[i].ID); err != nil {
+			return fmt.Errorf("error getting document URL: %w", err)
+		}
+	}
+	if len(matches) > 0 {
+		if err := n.notify(s, matches); err != nil {
+			return err
+		}
+		n.Logger.Info("notified user of new saved search matches",
+			"saved_search_id", s.ID,
+			"user", s.User.EmailAddress,
+			"matches", len(matches),
+		)
+	}
+
+	return s.RecordMatches(n.Database, newIDs, now)
+}
+
+// notify notifies the user of saved search s of new matching documents docs
+// using every enabled channel. It returns an error only if all channels fail,
+// so the matches are retried on the next check.
+func (n *Notifier) notify(s models.SavedSearch, docs []matchedDocument) error {
+	var sent int
+	var lastErr error
+
+	if s.NotifyEmail && n.SendEmails {
+		var emailDocs []email.SavedSearchMatchDocument
+		for _, d := range docs {
+			emailDocs = append(emailDocs, email.SavedSearchMatchDocument{
+				DocumentOwner: d.owner(),
+				DocumentProd:  d.Product,
+				DocumentTitle: d.Title,
+				DocumentType:  d.DocType,
+				DocumentURL:   d.URL,
+			})
+		}
+		e, err := email.NewSavedSearchMatchesEmail(
+			email.SavedSearchMatchesEmailData{
+				BaseURL:         n.BaseURL,
+				Documents:       emailDocs,
+				SavedSearchName: s.Name,
+			},
+		)
+		if err == nil {
+			err = n.Notifier.Send(notify.Message{
+				To:       []string{s.User.EmailAddress},
+				Event:    models.SavedSearchMatchedNotificationEvent,
+				Subject:  e.Subject,
+				HTMLBody: e.Body,
+			})
+		}
+		if err != nil {
+			n.Logger.Error("error sending saved search email",
+				"error", err,
+				"saved_search_id", s.ID,
+				"user", s.User.EmailAddress,
+			)
+			lastErr = err
+		} else {
+			sent++
+		}
+	}
+
+	if s.NotifySlack && n.SendSlackMessages {
+		var slackDocs []slackbot.SavedSearchMatchDocument
+		for _, d := range docs {
+			slackDocs = append(slackDocs, slackbot.SavedSearchMatchDocument{
+				DocumentOwner: d.owner(),
+				DocumentTitle: d.Title,
+				DocumentType:  d.DocType,
+				DocumentURL:   d.URL,
+			})
+		}
+		sd := slackbot.SavedSearchMatchesSlackData{
+			BaseURL:         n.BaseURL,
+			Documents:       slackDocs,
+			SavedSearchName: s.Name,
+		}
+		if err := n.Notifier.Send(notify.Message{
+			To:      []string{s.User.EmailAddress},
+			Event:   models.SavedSearchMatchedNotificationEvent,
+			Subject: "DocVault: New Saved Search Matches",
+			SlackBlocks: func(username string) ([]slack.Block, error) {
+				msg, err := slackbot.GenerateUIRichBlocks_SavedSearchMatches(
+					sd, username)
+				if err != nil {
+					return nil, err
+				}
+				return msg.Blocks.BlockSet, nil
+			},
+		}); err != nil {
+			n.Logger.Error("error sending saved search Slack message",
+				"error", err,
+				"saved_search_id", s.ID,
+				"user", s.User.EmailAddress,
+			)
+			lastErr = err
+		} else {
+			sent++
+		}
+	}
+
+	if sent == 0 && lastErr != nil {
+		return fmt.Errorf("error notifying user: %w", lastErr)
+	}
+	return nil
+}
+
+// matchedDocument is a document matching a saved search.
+type matchedDocument struct {
+	ID      string
+	DocType string
+	Owners  []string
+	Product string
+	Title   string
+	URL     string
+}
+
+// matchedDocuments returns the documents of search hits. URLs aren't set.
+func matchedDocuments(hits []map[string]interface{}) []matchedDocument {
+	var docs []matchedDocument
+	for _, h := range hits {
+		id, _ := h["objectID"].(string)
+		if id == "" {
+			continue
+		}
+		d := matchedDocument{
+			ID:      id,
+			DocType: stringField(h, "docType"),
+			Product: stringField(h, "product"),
+			Title:   stringField(h, "title"),
+		}
+		if owners, ok := h["owners"].([]interface{}); ok {
+			for _, o := range owners {
+				if s, ok := o.(string); ok {
+					d.Owners = append(d.Owners, s)
+				}
+			}
+		}
+		docs = append(docs, d)
+	}
+	return docs
+}
+
+// notifiableDocuments returns the documents in docs with IDs in newIDs that
+// user should be notified of, which excludes documents the user owns.
+func notifiableDocuments(
+	docs []matchedDocument, newIDs []string, user string) []matchedDocument {
+	isNew := make(map[string]bool, len(newIDs))
+	for _, id := range newIDs {
+		isNew[id] = true
+	}
+
+	var res []matchedDocument
+	for _, d := range docs {
+		if !isNew[d.ID] || ownedBy(d, user) {
+			continue
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+// owner returns the first owner of document d, or an empty string if it has
+// no owners.
+func (d matchedDocument) owner() string {
+	if len(d.Owners) == 0 {
+		return ""
+	}
+	return d.Owners[0]
+}
+
+// ownedBy returns true if user is an owner of document d.
+func ownedBy(d matchedDocument, user string) bool {
+	for _, o := range d.Owners {
+		if strings.EqualFold(o, user) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringField returns the string value of key in search hit h, or an empty
+// string if it isn't a string.
+func stringField(h map[string]interface{}, key string) string {
+	s, _ := h[key].(string)
+	return s
+}
+
+// getDocumentURL returns a Hermes document URL.
+func getDocumentURL(baseURL, docID string) (string, error) {
+	docURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("error parsing base URL: %w", err)
+	}
+
+	docURL.Path = path.Join(docURL.Path, "document", docID)
+	docURLString := docURL.String()
+	docURLString = strings.TrimRight(docURLString, "/")
+
+	return docURLString, nil
+}
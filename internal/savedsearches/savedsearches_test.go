@@ -0,0 +1,116 @@
+package savedsearches
+
+import (
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/internal/notify"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchedDocuments(t *testing.T) {
+	hits := []map[string]interface{}{
+		{
+			"objectID": "doc1",
+			"docType":  "RFC",
+			"owners":   []interface{}{"a@example.com", "b@example.com"},
+			"product":  "Payments",
+			"title":    "Retries",
+		},
+		{
+			"title": "No object ID",
+		},
+		{
+			"objectID": "doc2",
+			"title":    "No owners",
+		},
+	}
+
+	assert.Equal(t, []matchedDocument{
+		{
+			ID:      "doc1",
+			DocType: "RFC",
+			Owners:  []string{"a@example.com", "b@example.com"},
+			Product: "Payments",
+			Title:   "Retries",
+		},
+		{
+			ID:    "doc2",
+			Title: "No owners",
+		},
+	}, matchedDocuments(hits))
+}
+
+func TestNotifiableDocuments(t *testing.T) {
+	docs := []matchedDocument{
+		{ID: "doc1", Owners: []string{"b@example.com"}},
+		{ID: "doc2", Owners: []string{"A@example.com"}},
+		{ID: "doc3", Owners: []string{"c@example.com"}},
+		{ID: "doc4"},
+	}
+
+	got := notifiableDocuments(docs,
+		[]string{"doc2", "doc3", "doc4"}, "a@example.com")
+
+	var ids []string
+	for _, d := range got {
+		ids = append(ids, d.ID)
+	}
+	assert.Equal(t, []string{"doc3", "doc4"}, ids)
+}
+
+func TestNotify(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	rec := notify.NewRecorder()
+	n := &Notifier{
+		BaseURL:           "https://docvault.example.com",
+		Logger:            hclog.NewNullLogger(),
+		Notifier:          rec,
+		SendEmails:        true,
+		SendSlackMessages: true,
+	}
+	s := models.SavedSearch{
+		Name:        "Payments RFCs",
+		User:        models.User{EmailAddress: "user@example.com"},
+		NotifyEmail: true,
+		NotifySlack: true,
+	}
+	docs := []matchedDocument{
+		{
+			ID:      "doc1",
+			DocType: "RFC",
+			Owners:  []string{"owner@example.com"},
+			Product: "Payments",
+			Title:   "Retries",
+			URL:     "https://docvault.example.com/document/doc1",
+		},
+	}
+	require.NoError(n.notify(s, docs))
+
+	// Emails and Slack messages are sent as separate messages through the
+	// notifier, so each channel only sends the one it supports.
+	msgs := rec.Messages()
+	require.Len(msgs, 2)
+	assert.Equal([]string{"user@example.com"}, msgs[0].To)
+	assert.Equal(models.SavedSearchMatchedNotificationEvent, msgs[0].Event)
+	assert.NotEmpty(msgs[0].HTMLBody)
+	assert.Nil(msgs[0].SlackBlocks)
+	assert.Equal([]string{"user@example.com"}, msgs[1].To)
+	assert.Equal(models.SavedSearchMatchedNotificationEvent, msgs[1].Event)
+	assert.Empty(msgs[1].HTMLBody)
+	require.NotNil(msgs[1].SlackBlocks)
+	blocks, err := msgs[1].SlackBlocks("user")
+	require.NoError(err)
+	assert.NotEmpty(blocks)
+
+	// Disabled channels aren't used.
+	rec.Reset()
+	n.SendSlackMessages = false
+	require.NoError(n.notify(s, docs))
+	msgs = rec.Messages()
+	require.Len(msgs, 1)
+	assert.NotEmpty(msgs[0].HTMLBody)
+}
@@ -14,3 +14,16 @@ func GetUserIDByEmail(email string, api *slack.Client) (string, string, error) {
 	}
 	return user.ID, user.Profile.DisplayName, nil
 }
+
+// GetUserEmailByID retrieves the email address of the Slack user with ID
+// userID.
+func GetUserEmailByID(userID string, api *slack.Client) (string, error) {
+	user, err := api.GetUserInfo(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve Slack user: %w", err)
+	}
+	if user.Profile.Email == "" {
+		return "", fmt.Errorf("Slack user %q has no email address", userID)
+	}
+	return user.Profile.Email, nil
+}
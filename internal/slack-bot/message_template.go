@@ -2,10 +2,28 @@ package slackbot
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/slack-go/slack"
 )
 
+const (
+	// ReviewActionsBlockID is the block ID of the action buttons in review
+	// request messages.
+	ReviewActionsBlockID = "review_actions"
+
+	// ApproveActionID is the action ID of the button to approve a document.
+	ApproveActionID = "approve_document"
+
+	// RequestChangesActionID is the action ID of the button to request changes
+	// of a document.
+	RequestChangesActionID = "request_changes"
+
+	// SnoozeActionID is the action ID of the button to snooze a review
+	// request.
+	SnoozeActionID = "snooze_review"
+)
+
 func GenerateUIRichBlocks_Reviewer(data ReviewerRequestedSlackData, username string) (*slack.Message, error) {
 	// header
 	headerText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s* *|* *Document Review Request* from *%s [%s] *", data.DocumentTitle, data.DocumentOwner, data.DocumentOwnerEmail), false, false)
@@ -60,8 +78,7 @@ func GenerateUIRichBlocks_Reviewer(data ReviewerRequestedSlackData, username str
 	// Divider Section
 	dividerSection2 := slack.NewDividerBlock()
 
-	// Build Message with blocks created above
-	msg := slack.NewBlockMessage(
+	blocks := []slack.Block{
 		headerSection,
 		dividerSection1,
 		invitationSection,
@@ -69,14 +86,71 @@ func GenerateUIRichBlocks_Reviewer(data ReviewerRequestedSlackData, username str
 		// documentOwnerSection,
 		// feedbackRequestSection,
 		// thankYouSection,
+	}
+
+	// Review Actions Section
+	if data.Interactive {
+		approveButton := slack.NewButtonBlockElement(ApproveActionID,
+			data.DocumentID,
+			slack.NewTextBlockObject("plain_text", "Approve", false, false))
+		approveButton.Style = slack.StylePrimary
+
+		requestChangesButton := slack.NewButtonBlockElement(
+			RequestChangesActionID, data.DocumentID,
+			slack.NewTextBlockObject("plain_text", "Request changes", false, false))
+		requestChangesButton.Style = slack.StyleDanger
+		requestChangesButton.Confirm = slack.NewConfirmationBlockObject(
+			slack.NewTextBlockObject("plain_text", "Request changes?", false, false),
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Request changes of *%s*? Please also leave comments in the document.", data.DocumentTitle), false, false),
+			slack.NewTextBlockObject("plain_text", "Request changes", false, false),
+			slack.NewTextBlockObject("plain_text", "Cancel", false, false),
+		)
+
+		buttons := []slack.BlockElement{approveButton, requestChangesButton}
+		if data.Snoozable {
+			buttons = append(buttons, slack.NewButtonBlockElement(SnoozeActionID,
+				data.DocumentID,
+				slack.NewTextBlockObject("plain_text", "Snooze for a day", false, false)))
+		}
+
+		blocks = append(blocks, slack.NewActionBlock(ReviewActionsBlockID,
+			buttons...))
+	}
+
+	// Build Message with blocks created above
+	blocks = append(blocks,
 		webAppLinkButtonSection,
 		signatureSection,
 		dividerSection2,
 	)
+	msg := slack.NewBlockMessage(blocks...)
 
 	return &msg, nil
 }
 
+// GenerateUIRichBlocks_ReviewerResult returns the blocks of review request
+// message original with its action buttons replaced by result.
+func GenerateUIRichBlocks_ReviewerResult(original slack.Message, result string) []slack.Block {
+	resultSection := slack.NewContextBlock("",
+		slack.NewTextBlockObject("mrkdwn", result, false, false))
+
+	var blocks []slack.Block
+	replaced := false
+	for _, b := range original.Blocks.BlockSet {
+		if ab, ok := b.(*slack.ActionBlock); ok && ab.BlockID == ReviewActionsBlockID {
+			blocks = append(blocks, resultSection)
+			replaced = true
+			continue
+		}
+		blocks = append(blocks, b)
+	}
+	if !replaced {
+		blocks = append(blocks, resultSection)
+	}
+
+	return blocks
+}
+
 func GenerateUIRichBlocks_Contributor(data ContributorInvitationSlackData, username string) (*slack.Message, error) {
 	// header
 	headerText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s* *|* *Document Contribution Invitation* from *%s [%s]*", data.DocumentTitle, data.DocumentOwner, data.DocumentOwnerEmail), false, false)
@@ -147,3 +221,265 @@ func GenerateUIRichBlocks_Contributor(data ContributorInvitationSlackData, usern
 
 	return &msg, nil
 }
+
+func GenerateUIRichBlocks_ReviewReminder(data ReviewReminderSlackData, username string) (*slack.Message, error) {
+	// header
+	headerText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s* *|* *Document Review Reminder* from *%s [%s]*", data.DocumentTitle, data.DocumentOwner, data.DocumentOwnerEmail), false, false)
+	headerSection := slack.NewSectionBlock(headerText, nil, nil)
+
+	// Reminder Text Section
+	reminder := fmt.Sprintf("The review of this document is due on *%s*.", data.DueDate)
+	if data.Overdue {
+		reminder = fmt.Sprintf("The review of this document was due on *%s* and is now overdue.", data.DueDate)
+	}
+	reminderText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Hi %s,* \n%s Please review it in DocVault.", username, reminder), false, false)
+	reminderSection := slack.NewSectionBlock(reminderText, nil, nil)
+
+	// Divider Section
+	dividerSection1 := slack.NewDividerBlock()
+
+	// Document Details Section
+	documentDetailsText := slack.NewTextBlockObject("mrkdwn", "*Access the Document:*", false, false)
+	documentDetailsSection := slack.NewSectionBlock(documentDetailsText, nil, nil)
+
+	// Document Details Link Button
+	documentDetailsButton := slack.NewButtonBlockElement("", "", nil)
+	documentDetailsLinkText := slack.NewTextBlockObject("plain_text", fmt.Sprintf("[%s] %s", data.DocumentType, data.DocumentTitle), false, false)
+	documentDetailsButton.Text = documentDetailsLinkText
+	documentDetailsButton.URL = data.DocumentURL
+	documentDetailsButton.Style = slack.StylePrimary
+
+	documentDetailsSection.Accessory = slack.NewAccessory(documentDetailsButton)
+
+	// Web App Link Section
+	webAppLinkButton := slack.NewButtonBlockElement("", "", nil)
+	webAppLinkText := slack.NewTextBlockObject("plain_text", "Access the DocVault Web Application", false, false)
+	webAppLinkButton.Text = webAppLinkText
+	webAppLinkButton.URL = data.BaseURL
+	webAppLinkButtonSection := slack.NewSectionBlock(slack.NewTextBlockObject("plain_text", " ", false, false), nil, nil)
+	webAppLinkButtonSection.Accessory = slack.NewAccessory(webAppLinkButton)
+	webAppLinkButton.Style = slack.StylePrimary
+
+	// Signature Section
+	signatureText := slack.NewTextBlockObject("mrkdwn", "*Best Regards,*\n*DocVault Team*", false, false)
+	signatureSection := slack.NewSectionBlock(signatureText, nil, nil)
+
+	// Divider Section
+	dividerSection2 := slack.NewDividerBlock()
+
+	// Build Message with blocks created above
+	msg := slack.NewBlockMessage(
+		headerSection,
+		dividerSection1,
+		reminderSection,
+		documentDetailsSection,
+		webAppLinkButtonSection,
+		signatureSection,
+		dividerSection2,
+	)
+
+	return &msg, nil
+}
+
+func GenerateUIRichBlocks_SavedSearchMatches(data SavedSearchMatchesSlackData, username string) (*slack.Message, error) {
+	// header
+	headerText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s* *|* *New Saved Search Matches*", data.SavedSearchName), false, false)
+	headerSection := slack.NewSectionBlock(headerText, nil, nil)
+
+	// Notification Text Section
+	notificationText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Hi %s,* \nThe following documents now match your saved search *%s*.", username, data.SavedSearchName), false, false)
+	notificationSection := slack.NewSectionBlock(notificationText, nil, nil)
+
+	// Divider Section
+	dividerSection1 := slack.NewDividerBlock()
+
+	blocks := []slack.Block{
+		headerSection,
+		dividerSection1,
+		notificationSection,
+	}
+
+	// Document Link Sections
+	for _, doc := range data.Documents {
+		documentText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("by %s", doc.DocumentOwner), false, false)
+		documentSection := slack.NewSectionBlock(documentText, nil, nil)
+
+		documentButton := slack.NewButtonBlockElement("", "", nil)
+		documentLinkText := slack.NewTextBlockObject("plain_text", fmt.Sprintf("[%s] %s", doc.DocumentType, doc.DocumentTitle), false, false)
+		documentButton.Text = documentLinkText
+		documentButton.URL = doc.DocumentURL
+		documentButton.Style = slack.StylePrimary
+
+		documentSection.Accessory = slack.NewAccessory(documentButton)
+		blocks = append(blocks, documentSection)
+	}
+
+	// Web App Link Section
+	webAppLinkButton := slack.NewButtonBlockElement("", "", nil)
+	webAppLinkText := slack.NewTextBlockObject("plain_text", "Access the DocVault Web Application", false, false)
+	webAppLinkButton.Text = webAppLinkText
+	webAppLinkButton.URL = data.BaseURL
+	webAppLinkButtonSection := slack.NewSectionBlock(slack.NewTextBlockObject("plain_text", " ", false, false), nil, nil)
+	webAppLinkButtonSection.Accessory = slack.NewAccessory(webAppLinkButton)
+	webAppLinkButton.Style = slack.StylePrimary
+
+	// Signature Section
+	signatureText := slack.NewTextBlockObject("mrkdwn", "*Best Regards,*\n*DocVault Team*", false, false)
+	signatureSection := slack.NewSectionBlock(signatureText, nil, nil)
+
+	// Divider Section
+	dividerSection2 := slack.NewDividerBlock()
+
+	// Build Message with blocks created above
+	blocks = append(blocks,
+		webAppLinkButtonSection,
+		signatureSection,
+		dividerSection2,
+	)
+	msg := slack.NewBlockMessage(blocks...)
+
+	return &msg, nil
+}
+
+func GenerateUIRichBlocks_DocumentInReview(data DocumentChannelSlackData) (*slack.Message, error) {
+	// header
+	event := "Document In Review"
+	if data.Published {
+		event = "Document Published"
+	}
+	title := data.DocumentTitle
+	if data.DocumentShortName != "" {
+		title = fmt.Sprintf("[%s] %s", data.DocumentShortName, data.DocumentTitle)
+	}
+	headerText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s* *|* *%s* by *%s*", title, event, data.DocumentOwner), false, false)
+	headerSection := slack.NewSectionBlock(headerText, nil, nil)
+
+	// Divider Section
+	dividerSection1 := slack.NewDividerBlock()
+
+	// Document Summary Section
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Type:*\n%s", data.DocumentType), false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Product:*\n%s", data.DocumentProd), false, false),
+	}
+	if data.DocumentTeam != "" {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Team:*\n%s", data.DocumentTeam), false, false))
+	}
+	if data.DocumentProject != "" {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Project:*\n%s", data.DocumentProject), false, false))
+	}
+	summarySection := slack.NewSectionBlock(nil, fields, nil)
+
+	// Reviewers Section
+	reviewers := "_No reviewers requested._"
+	if len(data.Reviewers) > 0 {
+		reviewers = strings.Join(data.Reviewers, ", ")
+	}
+	reviewersText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Reviewers:* %s", reviewers), false, false)
+	reviewersSection := slack.NewSectionBlock(reviewersText, nil, nil)
+
+	// Document Details Link Button
+	documentDetailsText := slack.NewTextBlockObject("mrkdwn", "*Access the Document:*", false, false)
+	documentDetailsSection := slack.NewSectionBlock(documentDetailsText, nil, nil)
+	documentDetailsButton := slack.NewButtonBlockElement("", "", nil)
+	documentDetailsLinkText := slack.NewTextBlockObject("plain_text", fmt.Sprintf("[%s] %s", data.DocumentType, data.DocumentTitle), false, false)
+	documentDetailsButton.Text = documentDetailsLinkText
+	documentDetailsButton.URL = data.DocumentURL
+	documentDetailsButton.Style = slack.StylePrimary
+	documentDetailsSection.Accessory = slack.NewAccessory(documentDetailsButton)
+
+	// Approvals Context Section
+	approvalsText := slack.NewTextBlockObject("mrkdwn", "Approvals will be posted in this thread.", false, false)
+	approvalsSection := slack.NewContextBlock("", approvalsText)
+
+	// Build Message with blocks created above
+	msg := slack.NewBlockMessage(
+		headerSection,
+		dividerSection1,
+		summarySection,
+		reviewersSection,
+		documentDetailsSection,
+		approvalsSection,
+	)
+
+	return &msg, nil
+}
+
+func GenerateUIRichBlocks_DocumentApproved(data DocumentApprovalSlackData) []slack.Block {
+	text := fmt.Sprintf(":white_check_mark: *%s* approved this document", data.Approver)
+	if data.ReviewerCount > 0 {
+		text = fmt.Sprintf("%s (%d/%d approvals).", text, data.ApprovalCount, data.ReviewerCount)
+	} else {
+		text += "."
+	}
+	if data.FullyApproved {
+		text += fmt.Sprintf("\n:tada: <%s|%s> is now *Approved*.", data.DocumentURL, data.DocumentTitle)
+	}
+
+	approvalText := slack.NewTextBlockObject("mrkdwn", text, false, false)
+	return []slack.Block{slack.NewSectionBlock(approvalText, nil, nil)}
+}
+
+func GenerateUIRichBlocks_ApprovalReceived(data ApprovalReceivedSlackData, username string) (*slack.Message, error) {
+	// header
+	headerText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*%s* *|* *Document Approval* from *%s*", data.DocumentTitle, data.Approver), false, false)
+	headerSection := slack.NewSectionBlock(headerText, nil, nil)
+
+	// Approval Text Section
+	approval := fmt.Sprintf("*%s* approved your document", data.Approver)
+	if data.ReviewerCount > 0 {
+		approval = fmt.Sprintf("%s (%d/%d approvals).", approval, data.ApprovalCount, data.ReviewerCount)
+	} else {
+		approval += "."
+	}
+	if data.FullyApproved {
+		approval += " :tada: Your document is now *Approved*."
+	}
+	approvalText := slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Hi %s,* \n%s", username, approval), false, false)
+	approvalSection := slack.NewSectionBlock(approvalText, nil, nil)
+
+	// Divider Section
+	dividerSection1 := slack.NewDividerBlock()
+
+	// Document Details Section
+	documentDetailsText := slack.NewTextBlockObject("mrkdwn", "*Access the Document:*", false, false)
+	documentDetailsSection := slack.NewSectionBlock(documentDetailsText, nil, nil)
+
+	// Document Details Link Button
+	documentDetailsButton := slack.NewButtonBlockElement("", "", nil)
+	documentDetailsLinkText := slack.NewTextBlockObject("plain_text", fmt.Sprintf("[%s] %s", data.DocumentType, data.DocumentTitle), false, false)
+	documentDetailsButton.Text = documentDetailsLinkText
+	documentDetailsButton.URL = data.DocumentURL
+	documentDetailsButton.Style = slack.StylePrimary
+
+	documentDetailsSection.Accessory = slack.NewAccessory(documentDetailsButton)
+
+	// Web App Link Section
+	webAppLinkButton := slack.NewButtonBlockElement("", "", nil)
+	webAppLinkText := slack.NewTextBlockObject("plain_text", "Access the DocVault Web Application", false, false)
+	webAppLinkButton.Text = webAppLinkText
+	webAppLinkButton.URL = data.BaseURL
+	webAppLinkButtonSection := slack.NewSectionBlock(slack.NewTextBlockObject("plain_text", " ", false, false), nil, nil)
+	webAppLinkButtonSection.Accessory = slack.NewAccessory(webAppLinkButton)
+	webAppLinkButton.Style = slack.StylePrimary
+
+	// Signature Section
+	signatureText := slack.NewTextBlockObject("mrkdwn", "*Best Regards,*\n*DocVault Team*", false, false)
+	signatureSection := slack.NewSectionBlock(signatureText, nil, nil)
+
+	// Divider Section
+	dividerSection2 := slack.NewDividerBlock()
+
+	// Build Message with blocks created above
+	msg := slack.NewBlockMessage(
+		headerSection,
+		dividerSection1,
+		approvalSection,
+		documentDetailsSection,
+		webAppLinkButtonSection,
+		signatureSection,
+		dividerSection2,
+	)
+
+	return &msg, nil
+}
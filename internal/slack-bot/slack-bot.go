@@ -2,15 +2,16 @@ package slackbot
 
 import (
 	"fmt"
-	"os"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hashicorp/go-multierror"
 	"github.com/slack-go/slack"
 )
 
 type ReviewerRequestedSlackData struct {
 	BaseURL            string
 	CurrentYear        int
+	DocumentID         string
 	DocumentOwner      string
 	DocumentOwnerEmail string
 	DocumentType       string
@@ -19,6 +20,16 @@ type ReviewerRequestedSlackData struct {
 	DocumentURL        string
 	DocumentProd       string
 	DocumentTeam       string
+
+	// Interactive adds Approve and Request changes buttons for document
+	// DocumentID to the message, which are handled by the Slack interactivity
+	// endpoint.
+	Interactive bool
+
+	// Snoozable also adds a Snooze button to interactive messages. Snoozed
+	// review requests are sent again by the reminders scheduler, so this should
+	// only be set if it sends Slack messages.
+	Snoozable bool
 }
 
 type ContributorInvitationSlackData struct {
@@ -34,21 +45,21 @@ type ContributorInvitationSlackData struct {
 	DocumentTeam       string
 }
 
-func SendSlackMessage_Reviewer(d ReviewerRequestedSlackData, Reviewers []string) error {
+func SendSlackMessage_Reviewer(
+	d ReviewerRequestedSlackData, Reviewers []string, api *slack.Client) error {
 	// Validate data.
 	if err := validation.ValidateStruct(&d,
 		validation.Field(&d.BaseURL, validation.Required),
 		validation.Field(&d.DocumentOwner, validation.Required),
 		validation.Field(&d.DocumentTitle, validation.Required),
 		validation.Field(&d.DocumentURL, validation.Required),
+		validation.Field(&d.DocumentID,
+			validation.When(d.Interactive, validation.Required)),
 		// validation.Field(&d.Product, validation.Required),
 	); err != nil {
 		return fmt.Errorf("error validating email data: %w", err)
 	}
 
-	// Create a Slack API client
-	api := slack.New(os.Getenv("SLACK_BOT_ACCESS_TOKEN"))
-
 	// Iterate over the list of user emails and get user IDs
 	for _, email := range Reviewers {
 		userID, username, err := GetUserIDByEmail(email, api)
@@ -79,7 +90,8 @@ func SendSlackMessage_Reviewer(d ReviewerRequestedSlackData, Reviewers []string)
 
 // SendSlackMessage_Contributor sends the slack messagae to the
 // dedicated channel and tags all the contributor
-func SendSlackMessage_Contributor(d ContributorInvitationSlackData, Contributors []string) error {
+func SendSlackMessage_Contributor(
+	d ContributorInvitationSlackData, Contributors []string, api *slack.Client) error {
 	// Validate data.
 	if err := validation.ValidateStruct(&d,
 		validation.Field(&d.BaseURL, validation.Required),
@@ -91,9 +103,6 @@ func SendSlackMessage_Contributor(d ContributorInvitationSlackData, Contributors
 		return fmt.Errorf("error validating email data: %w", err)
 	}
 
-	// Create a Slack API client
-	api := slack.New(os.Getenv("SLACK_BOT_ACCESS_TOKEN"))
-
 	// Iterate over the list of user emails and get user IDs
 	for _, email := range Contributors {
 		userID, username, err := GetUserIDByEmail(email, api)
@@ -120,3 +129,177 @@ func SendSlackMessage_Contributor(d ContributorInvitationSlackData, Contributors
 
 	return nil
 }
+
+type ReviewReminderSlackData struct {
+	BaseURL            string
+	DocumentOwner      string
+	DocumentOwnerEmail string
+	DocumentType       string
+	DocumentTitle      string
+	DocumentURL        string
+	DueDate            string
+	Overdue            bool
+}
+
+type SavedSearchMatchesSlackData struct {
+	BaseURL         string
+	Documents       []SavedSearchMatchDocument
+	SavedSearchName string
+}
+
+// SavedSearchMatchDocument is a document that newly matched a saved search.
+type SavedSearchMatchDocument struct {
+	DocumentOwner string
+	DocumentTitle string
+	DocumentType  string
+	DocumentURL   string
+}
+
+// UpdateSlackMessage_ReviewerResult replaces a review request message, using
+// the response URL of an interaction with it, with the original message
+// without its action buttons and with result appended.
+func UpdateSlackMessage_ReviewerResult(
+	responseURL string, original slack.Message, result string) error {
+	blocks := GenerateUIRichBlocks_ReviewerResult(original, result)
+	if err := slack.PostWebhook(responseURL, &slack.WebhookMessage{
+		Text:            result,
+		Blocks:          &slack.Blocks{BlockSet: blocks},
+		ReplaceOriginal: true,
+	}); err != nil {
+		return fmt.Errorf("failed to update Slack message: %w", err)
+	}
+	return nil
+}
+
+// DocumentChannelSlackData is the data of a document event posted to the Slack
+// channels mapped to the document's product, team, or project.
+type DocumentChannelSlackData struct {
+	BaseURL           string
+	DocumentOwner     string
+	DocumentType      string
+	DocumentShortName string
+	DocumentTitle     string
+	DocumentURL       string
+	DocumentProd      string
+	DocumentTeam      string
+	DocumentProject   string
+	Reviewers         []string
+
+	// Published is true if the document was published from a draft, instead of
+	// being moved back into review.
+	Published bool
+}
+
+// PostSlackChannelMessage_DocumentInReview posts a summary of a document
+// entering review to each of the Slack channels channelIDs. It returns the
+// timestamps of the posted messages by channel ID, so later events of the
+// document can be posted as thread replies. Posting continues if it fails for
+// a channel, and the errors are returned together.
+func PostSlackChannelMessage_DocumentInReview(
+	d DocumentChannelSlackData, channelIDs []string, api *slack.Client,
+) (map[string]string, error) {
+	// Validate data.
+	if err := validation.ValidateStruct(&d,
+		validation.Field(&d.BaseURL, validation.Required),
+		validation.Field(&d.DocumentOwner, validation.Required),
+		validation.Field(&d.DocumentTitle, validation.Required),
+		validation.Field(&d.DocumentURL, validation.Required),
+	); err != nil {
+		return nil, fmt.Errorf("error validating slack data: %w", err)
+	}
+
+	// Generate the block message
+	msg, err := GenerateUIRichBlocks_DocumentInReview(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the message block using slack-go-blockkit: %w", err)
+	}
+
+	var result *multierror.Error
+	timestamps := make(map[string]string, len(channelIDs))
+	for _, channelID := range channelIDs {
+		_, ts, err := api.PostMessage(
+			channelID,
+			slack.MsgOptionText(fmt.Sprintf(
+				"DocVault: %s is in review", d.DocumentTitle), false),
+			slack.MsgOptionBlocks(msg.Msg.Blocks.BlockSet...))
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf(
+				"failed to post Slack message to channel %q: %w", channelID, err))
+			continue
+		}
+		timestamps[channelID] = ts
+	}
+
+	return timestamps, result.ErrorOrNil()
+}
+
+// DocumentApprovalSlackData is the data of a document approval posted as a
+// thread reply to the in-review messages of the document in Slack channels.
+type DocumentApprovalSlackData struct {
+	Approver      string
+	ApprovalCount int
+	DocumentTitle string
+	DocumentURL   string
+	ReviewerCount int
+
+	// FullyApproved is true if the approval moved the document to the
+	// "Approved" status.
+	FullyApproved bool
+}
+
+// PostSlackThreadReply_DocumentApproved posts an approval of a document as a
+// reply to the threads of the Slack channel messages threads, which maps
+// channel IDs to message timestamps. Posting continues if it fails for a
+// channel, and the errors are returned together.
+func PostSlackThreadReply_DocumentApproved(
+	d DocumentApprovalSlackData, threads map[string]string, api *slack.Client,
+) error {
+	// Validate data.
+	if err := validation.ValidateStruct(&d,
+		validation.Field(&d.Approver, validation.Required),
+		validation.Field(&d.DocumentTitle, validation.Required),
+		validation.Field(&d.DocumentURL, validation.Required),
+	); err != nil {
+		return fmt.Errorf("error validating slack data: %w", err)
+	}
+
+	blocks := GenerateUIRichBlocks_DocumentApproved(d)
+
+	var result *multierror.Error
+	for channelID, ts := range threads {
+		opts := []slack.MsgOption{
+			slack.MsgOptionText(fmt.Sprintf(
+				"DocVault: %s approved %s", d.Approver, d.DocumentTitle), false),
+			slack.MsgOptionBlocks(blocks...),
+			slack.MsgOptionTS(ts),
+		}
+		// Also broadcast the reply to the channel once the document is
+		// approved.
+		if d.FullyApproved {
+			opts = append(opts, slack.MsgOptionBroadcast())
+		}
+		if _, _, err := api.PostMessage(channelID, opts...); err != nil {
+			result = multierror.Append(result, fmt.Errorf(
+				"failed to post Slack thread reply to channel %q: %w",
+				channelID, err))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// ApprovalReceivedSlackData is the data of a direct message to a document
+// owner about a reviewer approving their document.
+type ApprovalReceivedSlackData struct {
+	BaseURL       string
+	Approver      string
+	ApprovalCount int
+	DocumentType  string
+	DocumentTitle string
+	DocumentURL   string
+	ReviewerCount int
+
+	// FullyApproved is true if the approval moved the document to the
+	// "Approved" status.
+	FullyApproved bool
+}
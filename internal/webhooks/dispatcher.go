@@ -0,0 +1,290 @@
+package webhooks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
+	"gorm.io/gorm"
+)
+
+const (
+	// loggerName is the name of the logger.
+	loggerName = "webhooks"
+
+	// defaultBatchSize is the default maximum number of deliveries attempted in
+	// each run.
+	defaultBatchSize = 20
+
+	// defaultInterval is the default time between runs of the dispatcher.
+	defaultInterval = 10 * time.Second
+
+	// defaultMaxAttempts is the default number of attempts to deliver an event
+	// before giving up.
+	defaultMaxAttempts = 10
+
+	// defaultTimeout is the default timeout of delivery requests.
+	defaultTimeout = 10 * time.Second
+
+	// leaseDuration is how long due deliveries are claimed by a dispatcher
+	// while they are attempted. Deliveries that aren't updated by then, e.g.,
+	// because the dispatcher exited, are attempted again.
+	leaseDuration = 15 * time.Minute
+
+	// minBackoff is the delay before the first retry of a failed delivery. The
+	// delay doubles with each failed attempt, up to maxBackoff.
+	minBackoff = 30 * time.Second
+
+	// maxBackoff is the maximum delay between delivery attempts.
+	maxBackoff = 6 * time.Hour
+)
+
+// Dispatcher delivers pending webhook deliveries from the database queue.
+type Dispatcher struct {
+	// BatchSize is the maximum number of deliveries attempted in each run.
+	BatchSize int
+
+	// Database is the database connection.
+	Database *gorm.DB
+
+	// HTTPClient is the HTTP client used to deliver events.
+	HTTPClient *http.Client
+
+	// Interval is the time between runs of the dispatcher.
+	Interval time.Duration
+
+	// Logger is the logger to use.
+	Logger hclog.Logger
+
+	// MaxAttempts is the number of attempts to deliver an event before giving
+	// up.
+	MaxAttempts int
+}
+
+type DispatcherOption func(*Dispatcher)
+
+// NewDispatcher creates a new webhook dispatcher.
+func NewDispatcher(opts ...DispatcherOption) (*Dispatcher, error) {
+	// Initialize a new dispatcher with defaults.
+	d := &Dispatcher{
+		BatchSize:   defaultBatchSize,
+		HTTPClient:  &http.Client{Timeout: defaultTimeout},
+		Interval:    defaultInterval,
+		MaxAttempts: defaultMaxAttempts,
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name: loggerName,
+		}),
+	}
+
+	// Apply functional options.
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	// Validate dispatcher configuration.
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// validate validates the dispatcher configuration.
+func (d *Dispatcher) validate() error {
+	return validation.ValidateStruct(d,
+		validation.Field(&d.BatchSize, validation.Required, validation.Min(1)),
+		validation.Field(&d.Database, validation.Required),
+		validation.Field(&d.HTTPClient, validation.Required),
+		validation.Field(&d.Interval, validation.Required),
+		validation.Field(&d.MaxAttempts, validation.Required, validation.Min(1)),
+	)
+}
+
+// WithBatchSize sets the maximum number of deliveries attempted in each run.
+func WithBatchSize(b int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.BatchSize = b
+	}
+}
+
+// WithDatabase sets the database.
+func WithDatabase(db *gorm.DB) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.Database = db
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to deliver events.
+func WithHTTPClient(c *http.Client) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.HTTPClient = c
+	}
+}
+
+// WithInterval sets the time between runs of the dispatcher.
+func WithInterval(i time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.Interval = i
+	}
+}
+
+// WithLogger sets the logger.
+func WithLogger(l hclog.Logger) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.Logger = l.Named(loggerName)
+	}
+}
+
+// WithMaxAttempts sets the number of attempts to deliver an event before
+// giving up.
+func WithMaxAttempts(m int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.MaxAttempts = m
+	}
+}
+
+// Run runs the dispatcher until the process exits.
+func (d *Dispatcher) Run() {
+	for {
+		n, err := d.DeliverDue(time.Now())
+		if err != nil {
+			d.Logger.Error("error delivering webhook events", "error", err)
+		}
+		// Keep going without waiting if there may be more due deliveries.
+		if err != nil || n < d.BatchSize {
+			time.Sleep(d.Interval)
+		}
+	}
+}
+
+// DeliverDue attempts the pending deliveries that are due at time now, and
+// returns the number of attempted deliveries. Deliveries are claimed before
+// they are attempted so multiple dispatchers can safely share the queue, and
+// are sent outside of a database transaction. Attempts continue if updating a
+// delivery fails, and the errors are returned together.
+func (d *Dispatcher) DeliverDue(now time.Time) (int, error) {
+	dels, err := models.ClaimDueWebhookDeliveries(
+		d.Database, now, d.BatchSize, leaseDuration)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming due webhook deliveries: %w", err)
+	}
+
+	var result *multierror.Error
+	for i := range dels {
+		if err := d.attempt(&dels[i], now); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	return len(dels), result.ErrorOrNil()
+}
+
+// attempt attempts delivery del and updates its status in the database.
+// Deliveries that fail to update are attempted again once their lease
+// expires.
+func (d *Dispatcher) attempt(del *models.WebhookDelivery, now time.Time) error {
+	log := d.Logger.With(
+		"delivery_id", del.ID,
+		"webhook_id", del.WebhookID,
+		"event", del.Event,
+	)
+
+	wh := models.Webhook{}
+	wh.ID = del.WebhookID
+	err := wh.Get(d.Database)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		del.Status = models.FailedWebhookDeliveryStatus
+		del.LastError = "webhook was deleted"
+	case err != nil:
+		return fmt.Errorf("error getting webhook: %w", err)
+	case wh.Disabled:
+		del.Status = models.FailedWebhookDeliveryStatus
+		del.LastError = "webhook is disabled"
+	default:
+		del.Attempts++
+		code, err := d.send(wh, *del, now)
+		del.LastResponseCode = code
+		if err == nil {
+			del.Status = models.SucceededWebhookDeliveryStatus
+			del.LastError = ""
+			del.DeliveredAt = &now
+			log.Info("delivered webhook event", "attempts", del.Attempts)
+		} else {
+			del.LastError = err.Error()
+			if del.Attempts >= d.MaxAttempts {
+				del.Status = models.FailedWebhookDeliveryStatus
+				log.Error("giving up delivering webhook event",
+					"error", err,
+					"attempts", del.Attempts,
+				)
+			} else {
+				del.NextAttemptAt = now.Add(backoff(del.Attempts))
+				log.Warn("error delivering webhook event, will retry",
+					"error", err,
+					"attempts", del.Attempts,
+					"next_attempt_at", del.NextAttemptAt,
+				)
+			}
+		}
+	}
+
+	if err := del.Update(d.Database); err != nil {
+		return fmt.Errorf("error updating webhook delivery %d: %w", del.ID, err)
+	}
+	return nil
+}
+
+// send POSTs the signed payload of delivery del to webhook wh, and returns the
+// HTTP response status code. An error is returned if the request fails or the
+// response status isn't 2xx.
+func (d *Dispatcher) send(
+	wh models.Webhook, del models.WebhookDelivery, now time.Time,
+) (int, error) {
+	req, err := http.NewRequest(
+		http.MethodPost, wh.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Hermes-Webhooks")
+	req.Header.Set(EventHeader, string(del.Event))
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(del.ID), 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(wh.Secret, now, del.Payload))
+
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf(
+			"unexpected response status: %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next delivery attempt after the
+// provided number of failed attempts.
+func backoff(attempts int) time.Duration {
+	b := minBackoff
+	for i := 1; i < attempts; i++ {
+		b *= 2
+		if b >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return b
+}
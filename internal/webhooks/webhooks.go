@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// SignatureHeader is the HTTP header containing the HMAC-SHA256 signature of
+	// the timestamp and payload, in the format "sha256=<hex digest>".
+	SignatureHeader = "X-Hermes-Signature"
+
+	// TimestampHeader is the HTTP header containing the Unix time the payload
+	// was signed at.
+	TimestampHeader = "X-Hermes-Timestamp"
+
+	// EventHeader is the HTTP header containing the event type.
+	EventHeader = "X-Hermes-Event"
+
+	// DeliveryHeader is the HTTP header containing the delivery ID, which is the
+	// same for all attempts to deliver an event to a webhook.
+	DeliveryHeader = "X-Hermes-Delivery"
+)
+
+// Event is the payload of a document event delivered to webhooks.
+type Event struct {
+	// Type is the type of event.
+	Type models.WebhookEventType `json:"type"`
+
+	// Timestamp is the time the event occurred.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Actor is the email address of the user who caused the event.
+	Actor string `json:"actor"`
+
+	// Document is the document the event occurred on.
+	Document Document `json:"document"`
+}
+
+// Document is a document in an event payload.
+type Document struct {
+	ID        string   `json:"id"`
+	DocNumber string   `json:"docNumber,omitempty"`
+	DocType   string   `json:"docType"`
+	Owners    []string `json:"owners"`
+	Product   string   `json:"product"`
+	Reviewers []string `json:"reviewers"`
+	Status    string   `json:"status"`
+	Team      string   `json:"team,omitempty"`
+	Title     string   `json:"title"`
+	URL       string   `json:"url"`
+}
+
+// Enqueue adds a pending delivery of event e to database db for every webhook
+// subscribed to it. Call Enqueue in the same transaction as the change that
+// caused the event so the event is only delivered if the change is committed.
+func Enqueue(db *gorm.DB, e Event) error {
+	var whs models.Webhooks
+	if err := whs.Find(db); err != nil {
+		return fmt.Errorf("error finding webhooks: %w", err)
+	}
+
+	var payload []byte
+	for _, wh := range whs {
+		if !wh.Matches(
+			e.Type, e.Document.Product, e.Document.Team, e.Document.DocType) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(e); err != nil {
+				return fmt.Errorf("error encoding event payload: %w", err)
+			}
+		}
+
+		d := models.WebhookDelivery{
+			WebhookID:     wh.ID,
+			Event:         e.Type,
+			Payload:       datatypes.JSON(payload),
+			Status:        models.PendingWebhookDeliveryStatus,
+			NextAttemptAt: e.Timestamp,
+		}
+		if err := d.Create(db); err != nil {
+			return fmt.Errorf("error creating webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Sign returns the signature of payload signed at time ts with secret, as sent
+// in the SignatureHeader. The signed message is the Unix timestamp (as sent in
+// the TimestampHeader), a period, and the payload.
+func Sign(secret string, ts time.Time, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(ts.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a new random webhook secret.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,147 @@
+package webhooks
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1683000000, 0)
+	payload := []byte(`{"type":"document.approved"}`)
+
+	sig := Sign("secret", ts, payload)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, sig)
+
+	// Signatures are deterministic and depend on the secret, timestamp, and
+	// payload.
+	assert.Equal(t, sig, Sign("secret", ts, payload))
+	assert.NotEqual(t, sig, Sign("other", ts, payload))
+	assert.NotEqual(t, sig, Sign("secret", ts.Add(time.Second), payload))
+	assert.NotEqual(t, sig, Sign("secret", ts, []byte(`{}`)))
+}
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		5:  8 * time.Minute,
+		10: 256 * time.Minute,
+		11: maxBackoff,
+		50: maxBackoff,
+	}
+
+	for attempts, want := range cases {
+		t.Run(strconv.Itoa(attempts), func(t *testing.T) {
+			assert.Equal(t, want, backoff(attempts))
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	now := time.Unix(1683000000, 0)
+	payload := []byte(`{"type":"document.approved"}`)
+
+	var gotReq *http.Request
+	var gotBody []byte
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			gotReq = r
+			gotBody, _ = ioutil.ReadAll(r.Body)
+			w.WriteHeader(status)
+		}))
+	defer srv.Close()
+
+	d := &Dispatcher{HTTPClient: srv.Client()}
+	wh := models.Webhook{URL: srv.URL, Secret: "secret"}
+	del := models.WebhookDelivery{
+		Event:   models.DocumentApprovedWebhookEvent,
+		Payload: datatypes.JSON(payload),
+	}
+	del.ID = 42
+
+	t.Run("success", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+
+		code, err := d.send(wh, del, now)
+		require.NoError(err)
+		assert.Equal(http.StatusOK, code)
+
+		assert.Equal(http.MethodPost, gotReq.Method)
+		assert.Equal(payload, gotBody)
+		assert.Equal("document.approved", gotReq.Header.Get(EventHeader))
+		assert.Equal("42", gotReq.Header.Get(DeliveryHeader))
+		assert.Equal("1683000000", gotReq.Header.Get(TimestampHeader))
+		assert.Equal(Sign("secret", now, payload),
+			gotReq.Header.Get(SignatureHeader))
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		assert := assert.New(t)
+		status = http.StatusServiceUnavailable
+
+		code, err := d.send(wh, del, now)
+		assert.Error(err)
+		assert.Equal(http.StatusServiceUnavailable, code)
+	})
+}
+
+func TestWebhookMatches(t *testing.T) {
+	cases := map[string]struct {
+		webhook models.Webhook
+		want    bool
+	}{
+		"no filters": {
+			want: true,
+		},
+		"disabled": {
+			webhook: models.Webhook{Disabled: true},
+			want:    false,
+		},
+		"matching event and filters": {
+			webhook: models.Webhook{
+				Events: datatypes.JSONType[[]models.WebhookEventType]{
+					Data: []models.WebhookEventType{
+						models.DocumentApprovedWebhookEvent,
+					},
+				},
+				Products:      datatypes.JSONType[[]string]{Data: []string{"Labs"}},
+				DocumentTypes: datatypes.JSONType[[]string]{Data: []string{"rfc"}},
+			},
+			want: true,
+		},
+		"other event": {
+			webhook: models.Webhook{
+				Events: datatypes.JSONType[[]models.WebhookEventType]{
+					Data: []models.WebhookEventType{
+						models.DocumentPublishedWebhookEvent,
+					},
+				},
+			},
+			want: false,
+		},
+		"other team": {
+			webhook: models.Webhook{
+				Teams: datatypes.JSONType[[]string]{Data: []string{"Platform"}},
+			},
+			want: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, c.webhook.Matches(
+				models.DocumentApprovedWebhookEvent, "Labs", "Infra", "RFC"))
+		})
+	}
+}
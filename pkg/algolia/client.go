@@ -57,6 +57,10 @@ type Client struct {
 	// MissingFields is an Algolia index for storing missing fields from indexed
 	// documents.
 	MissingFields *search.Index
+
+	// Sections is an Algolia index for searching sections of published
+	// document content.
+	Sections *search.Index
 }
 
 // Config is the configuration for interacting with the Algolia API.
@@ -87,6 +91,10 @@ type Config struct {
 	// fields from indexed documents.
 	MissingFieldsIndexName string `hcl:"missing_fields_index_name,optional"`
 
+	// SectionsIndexName is the name of the Algolia index for storing sections
+	// of document content. Defaults to DocsIndexName with a "_sections" suffix.
+	SectionsIndexName string `hcl:"sections_index_name,optional"`
+
 	// SearchAPIKey is the Algolia API Key for searching Hermes indices.
 	SearchAPIKey string `hcl:"search_api_key,optional"`
 
@@ -109,6 +117,7 @@ func New(cfg *Config) (*Client, error) {
 		APIKey:       cfg.WriteAPIKey,
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
+		Requester:    newInstrumentedRequester(),
 	})
 
 	c.Docs = a.InitIndex(cfg.DocsIndexName)
@@ -117,6 +126,7 @@ func New(cfg *Config) (*Client, error) {
 	c.Template = a.InitIndex(cfg.TemplateIndexName)
 	c.Links = a.InitIndex(cfg.LinksIndexName)
 	c.MissingFields = a.InitIndex(cfg.MissingFieldsIndexName)
+	c.Sections = a.InitIndex(sectionsIndexName(cfg))
 
 	// Configure the docs index.
 	err := configureMainIndex(cfg.DocsIndexName, c.Docs, search.Settings{
@@ -171,6 +181,34 @@ func New(cfg *Config) (*Client, error) {
 		return nil, err
 	}
 
+	// Configure the sections index.
+	err = configureMainIndex(sectionsIndexName(cfg), c.Sections, search.Settings{
+		// Attributes
+		AttributesForFaceting: opt.AttributesForFaceting(
+			"docID",
+			"docType",
+			"owners",
+			"product",
+			"status",
+		),
+		SearchableAttributes: opt.SearchableAttributes(
+			"title",
+			"heading",
+			"content",
+		),
+
+		// Highlighting/snippeting
+		AttributesToSnippet: opt.AttributesToSnippet(
+			"content:20",
+		),
+		HighlightPostTag:    opt.HighlightPostTag("</mark>"),
+		HighlightPreTag:     opt.HighlightPreTag(`<mark class="hds-surface-warning hds-foreground-warning-on-surface">`),
+		SnippetEllipsisText: opt.SnippetEllipsisText("..."),
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	// Configure the drafts index.
 	err = configureMainIndex(cfg.DraftsIndexName, c.Drafts, search.Settings{
 		// Attributes
@@ -401,7 +439,11 @@ func NewSearchClient(cfg *Config) (*Client, error) {
 	c := &Client{}
 
 	// TODO: make ReadTimeout configurable.
-	a := search.NewClient(cfg.ApplicationID, cfg.SearchAPIKey)
+	a := search.NewClientWithConfig(search.Configuration{
+		AppID:     cfg.ApplicationID,
+		APIKey:    cfg.SearchAPIKey,
+		Requester: newInstrumentedRequester(),
+	})
 
 	c.Docs = a.InitIndex(cfg.DocsIndexName)
 	c.DocsCreatedTimeAsc = a.InitIndex(cfg.DocsIndexName + "_createdTime_asc")
@@ -415,10 +457,19 @@ func NewSearchClient(cfg *Config) (*Client, error) {
 	c.Template = a.InitIndex(cfg.TemplateIndexName)
 	c.Internal = a.InitIndex(cfg.InternalIndexName)
 	c.Links = a.InitIndex(cfg.LinksIndexName)
+	c.Sections = a.InitIndex(sectionsIndexName(cfg))
 
 	return c, nil
 }
 
+// sectionsIndexName returns the name of the sections index.
+func sectionsIndexName(cfg *Config) string {
+	if cfg.SectionsIndexName != "" {
+		return cfg.SectionsIndexName
+	}
+	return cfg.DocsIndexName + "_sections"
+}
+
 // validate validates the Algolia configuration.
 func validate(c *Config) error {
 	return validation.ValidateStruct(c,
@@ -0,0 +1,60 @@
+package algolia
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/transport"
+	"github.com/hashicorp-forge/hermes/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// requestDuration is the duration of Algolia API requests, by index, method,
+// and response status code.
+var requestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "hermes_algolia_request_duration_seconds",
+		Help:    "Duration of Algolia API requests, by index, method, and response status code.",
+		Buckets: metrics.DefaultBuckets,
+	},
+	[]string{"index", "method", "code"},
+)
+
+func init() {
+	prometheus.MustRegister(requestDuration)
+}
+
+// instrumentedRequester is an Algolia requester that records metrics for all
+// requests.
+type instrumentedRequester struct {
+	client *http.Client
+}
+
+func newInstrumentedRequester() *instrumentedRequester {
+	return &instrumentedRequester{
+		client: transport.DefaultHTTPClient(),
+	}
+}
+
+// Request implements transport.Requester.
+func (r *instrumentedRequester) Request(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := r.client.Do(req)
+
+	requestDuration.WithLabelValues(
+		indexName(req.URL.Path), req.Method, metrics.StatusCodeLabel(resp, err),
+	).Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
+
+// indexName returns the name of the index requested with URL path p (e.g.,
+// "/1/indexes/docs/query"), or "none" if the request isn't for an index.
+func indexName(p string) string {
+	parts := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	if len(parts) > 2 && parts[0] == "1" && parts[1] == "indexes" {
+		return parts[2]
+	}
+	return "none"
+}
@@ -17,8 +17,10 @@ func defaultBackoff() *backoff.ExponentialBackOff {
 }
 
 // backoffNotify is an exponential backoff notify function that logs the error
-// and wait duration as a warning.
+// and wait duration as a warning, and counts the retry.
 func backoffNotify(err error, d time.Duration) {
+	backoffRetries.Inc()
+
 	// TODO: enable passing in a logger.
 	l := hclog.Default()
 	l.Warn("backoff error (retrying)",
@@ -0,0 +1,123 @@
+package googleworkspace
+
+import (
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+// DocSection is a section of a Google Doc, which starts at a heading (or the
+// beginning of the document) and ends at the next heading.
+type DocSection struct {
+	// Heading is the text of the heading that starts the section. It is empty
+	// for content before the first heading.
+	Heading string
+
+	// HeadingLevel is the level of the heading that starts the section (1-6),
+	// 0 for the document title, or -1 for content before the first heading.
+	HeadingLevel int
+
+	// Content is the non-empty body paragraphs and tables of the section, in
+	// document order.
+	Content []DocSectionContent
+}
+
+// DocSectionContent is a body paragraph or table of a DocSection.
+type DocSectionContent struct {
+	// Paragraph is the text of a body paragraph. It is empty for tables.
+	Paragraph string
+
+	// Table is the non-empty paragraphs of a table, or nil for paragraphs.
+	Table []string
+}
+
+// headingLevels are the heading levels of named paragraph styles.
+var headingLevels = map[string]int{
+	"TITLE":     0,
+	"HEADING_1": 1,
+	"HEADING_2": 2,
+	"HEADING_3": 3,
+	"HEADING_4": 4,
+	"HEADING_5": 5,
+	"HEADING_6": 6,
+}
+
+// GetSections returns the sections of a Google Doc Body, split at headings.
+// Sections without a heading or content are omitted.
+func GetSections(b *docs.Body) []DocSection {
+	var (
+		sections []DocSection
+		cur      = DocSection{HeadingLevel: -1}
+	)
+	flush := func() {
+		if cur.Heading != "" || len(cur.Content) > 0 {
+			sections = append(sections, cur)
+		}
+	}
+
+	for _, e := range b.Content {
+		switch {
+		case e.Paragraph != nil:
+			text := ParagraphText(e.Paragraph)
+			if text == "" {
+				continue
+			}
+			if level, ok := paragraphHeadingLevel(e.Paragraph); ok {
+				flush()
+				cur = DocSection{Heading: text, HeadingLevel: level}
+				continue
+			}
+			cur.Content = append(cur.Content, DocSectionContent{Paragraph: text})
+
+		case e.Table != nil:
+			var table []string
+			VisitAllTableParagraphs(e.Table, func(p *docs.Paragraph) {
+				if text := ParagraphText(p); text != "" {
+					table = append(table, text)
+				}
+			})
+			if len(table) > 0 {
+				cur.Content = append(cur.Content, DocSectionContent{Table: table})
+			}
+		}
+	}
+	flush()
+
+	return sections
+}
+
+// ParagraphText returns the text of a Google Doc paragraph, with surrounding
+// whitespace removed.
+func ParagraphText(p *docs.Paragraph) string {
+	var sb strings.Builder
+	for _, e := range p.Elements {
+		if tr := e.TextRun; tr != nil {
+			sb.WriteString(tr.Content)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// Text returns the text of the section's paragraphs and tables in document
+// order, one paragraph per line.
+func (s DocSection) Text() string {
+	var lines []string
+	for _, c := range s.Content {
+		if c.Table != nil {
+			lines = append(lines, c.Table...)
+		} else {
+			lines = append(lines, c.Paragraph)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paragraphHeadingLevel returns the heading level of a paragraph, if it is a
+// heading.
+func paragraphHeadingLevel(p *docs.Paragraph) (int, bool) {
+	if p.ParagraphStyle == nil {
+		return 0, false
+	}
+	level, ok := headingLevels[p.ParagraphStyle.NamedStyleType]
+	return level, ok
+}
@@ -0,0 +1,108 @@
+package googleworkspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/docs/v1"
+)
+
+func TestGetSections(t *testing.T) {
+	para := func(style, text string) *docs.StructuralElement {
+		return &docs.StructuralElement{
+			Paragraph: &docs.Paragraph{
+				Elements: []*docs.ParagraphElement{
+					{TextRun: &docs.TextRun{Content: text + "\n"}},
+				},
+				ParagraphStyle: &docs.ParagraphStyle{NamedStyleType: style},
+			},
+		}
+	}
+	table := func(cells ...string) *docs.StructuralElement {
+		row := &docs.TableRow{}
+		for _, c := range cells {
+			row.TableCells = append(row.TableCells, &docs.TableCell{
+				Content: []*docs.StructuralElement{para("NORMAL_TEXT", c)},
+			})
+		}
+		return &docs.StructuralElement{
+			Table: &docs.Table{TableRows: []*docs.TableRow{row}},
+		}
+	}
+
+	cases := map[string]struct {
+		content []*docs.StructuralElement
+		want    []DocSection
+	}{
+		"empty": {},
+
+		"content before first heading": {
+			content: []*docs.StructuralElement{
+				para("NORMAL_TEXT", "Intro"),
+				para("NORMAL_TEXT", ""),
+				table("Owner", "a@example.com"),
+				para("HEADING_1", "Background"),
+				para("NORMAL_TEXT", "Some history."),
+			},
+			want: []DocSection{
+				{
+					HeadingLevel: -1,
+					Content: []DocSectionContent{
+						{Paragraph: "Intro"},
+						{Table: []string{"Owner", "a@example.com"}},
+					},
+				},
+				{
+					Heading:      "Background",
+					HeadingLevel: 1,
+					Content: []DocSectionContent{
+						{Paragraph: "Some history."},
+					},
+				},
+			},
+		},
+
+		"nested headings": {
+			content: []*docs.StructuralElement{
+				para("TITLE", "RFC-001: Retries"),
+				para("HEADING_1", "Proposal"),
+				para("HEADING_2", "Backoff"),
+				para("NORMAL_TEXT", "Exponential."),
+				table("Attempt", "Delay", ""),
+				para("NORMAL_TEXT", "With jitter."),
+			},
+			want: []DocSection{
+				{Heading: "RFC-001: Retries", HeadingLevel: 0},
+				{Heading: "Proposal", HeadingLevel: 1},
+				{
+					Heading:      "Backoff",
+					HeadingLevel: 2,
+					Content: []DocSectionContent{
+						{Paragraph: "Exponential."},
+						{Table: []string{"Attempt", "Delay"}},
+						{Paragraph: "With jitter."},
+					},
+				},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := GetSections(&docs.Body{Content: c.content})
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestDocSectionText(t *testing.T) {
+	s := DocSection{
+		Heading: "Backoff",
+		Content: []DocSectionContent{
+			{Paragraph: "Exponential."},
+			{Table: []string{"Attempt", "Delay"}},
+			{Paragraph: "With jitter."},
+		},
+	}
+	assert.Equal(t, "Exponential.\nAttempt\nDelay\nWith jitter.", s.Text())
+}
@@ -0,0 +1,112 @@
+package googleworkspace
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// changeFields are the fields returned for changes.
+var changeFields = fmt.Sprintf(
+	"changes(fileId, removed, file(%s, mimeType, trashed)), "+
+		"nextPageToken, newStartPageToken", fileFields)
+
+// GetChangesStartPageToken returns the page token for listing future changes
+// to files in Google Drive.
+func (s *Service) GetChangesStartPageToken() (string, error) {
+	var tok string
+
+	op := func() error {
+		resp, err := s.Drive.Changes.GetStartPageToken().
+			SupportsAllDrives(true).
+			Do()
+		if err != nil {
+			return fmt.Errorf("error getting changes start page token: %w", err)
+		}
+		tok = resp.StartPageToken
+
+		return nil
+	}
+
+	boErr := backoff.RetryNotify(op, defaultBackoff(), backoffNotify)
+	if boErr != nil {
+		return "", boErr
+	}
+
+	return tok, nil
+}
+
+// ListChanges lists all changes to files in Google Drive since page token
+// pageToken, and returns them with the page token for listing later changes.
+func (s *Service) ListChanges(pageToken string) ([]*drive.Change, string, error) {
+	var (
+		changes           []*drive.Change
+		newStartPageToken string
+	)
+
+	for pageToken != "" {
+		op := func() error {
+			resp, err := s.Drive.Changes.List(pageToken).
+				Fields(googleapi.Field(changeFields)).
+				IncludeItemsFromAllDrives(true).
+				IncludeRemoved(true).
+				PageSize(100).
+				SupportsAllDrives(true).
+				Do()
+			if err != nil {
+				return fmt.Errorf("error listing changes: %w", err)
+			}
+			changes = append(changes, resp.Changes...)
+			pageToken = resp.NextPageToken
+			if resp.NewStartPageToken != "" {
+				newStartPageToken = resp.NewStartPageToken
+			}
+
+			return nil
+		}
+
+		boErr := backoff.RetryNotify(op, defaultBackoff(), backoffNotify)
+		if boErr != nil {
+			return nil, "", boErr
+		}
+	}
+
+	return changes, newStartPageToken, nil
+}
+
+// WatchChanges subscribes to push notifications for changes to files in Google
+// Drive since page token pageToken. Notifications are sent to HTTPS URL address
+// with channel ID channelID and token token, until time expiration.
+func (s *Service) WatchChanges(
+	pageToken, channelID, address, token string,
+	expiration time.Time,
+) (*drive.Channel, error) {
+	resp, err := s.Drive.Changes.Watch(pageToken, &drive.Channel{
+		Address:    address,
+		Expiration: expiration.UnixMilli(),
+		Id:         channelID,
+		Token:      token,
+		Type:       "web_hook",
+	}).
+		IncludeItemsFromAllDrives(true).
+		SupportsAllDrives(true).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("error watching changes: %w", err)
+	}
+	return resp, nil
+}
+
+// StopChannel stops push notifications for a channel.
+func (s *Service) StopChannel(ch *drive.Channel) error {
+	if err := s.Drive.Channels.Stop(&drive.Channel{
+		Id:         ch.Id,
+		ResourceId: ch.ResourceId,
+	}).Do(); err != nil {
+		return fmt.Errorf("error stopping channel: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package googleworkspace
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// commentFields are the comment fields returned by Google Drive API requests.
+const commentFields = "comments(id, createdTime, author(displayName, emailAddress)), nextPageToken"
+
+// ListComments lists the comments on a Google Drive file created after
+// afterTime (an RFC 3339 timestamp).
+func (s *Service) ListComments(
+	fileID, afterTime string) ([]*drive.Comment, error) {
+	after, err := time.Parse(time.RFC3339Nano, afterTime)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing after time: %w", err)
+	}
+
+	var (
+		comments      []*drive.Comment
+		nextPageToken string
+	)
+	for {
+		op := func() error {
+			// Comments modified after the time include older comments with new
+			// replies, which are filtered out below.
+			call := s.Drive.Comments.List(fileID).
+				Fields(googleapi.Field(commentFields)).
+				PageSize(100).
+				StartModifiedTime(afterTime)
+			if nextPageToken != "" {
+				call = call.PageToken(nextPageToken)
+			}
+			resp, err := call.Do()
+			if err != nil {
+				return fmt.Errorf("error listing comments: %w", err)
+			}
+			for _, c := range resp.Comments {
+				created, err := time.Parse(time.RFC3339Nano, c.CreatedTime)
+				if err != nil {
+					return backoff.Permanent(
+						fmt.Errorf("error parsing comment created time: %w", err))
+				}
+				if created.After(after) {
+					comments = append(comments, c)
+				}
+			}
+			nextPageToken = resp.NextPageToken
+
+			return nil
+		}
+
+		boErr := backoff.RetryNotify(op, defaultBackoff(), backoffNotify)
+		if boErr != nil {
+			return nil, boErr
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+	}
+
+	return comments, nil
+}
@@ -0,0 +1,89 @@
+package googleworkspace
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// apiRequests is the number of Google Workspace API requests, by API and
+	// response status code.
+	apiRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hermes_google_api_requests_total",
+			Help: "Google Workspace API requests, by API and response status code.",
+		},
+		[]string{"api", "code"},
+	)
+
+	// apiRequestDuration is the duration of Google Workspace API requests, by
+	// API.
+	apiRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hermes_google_api_request_duration_seconds",
+			Help:    "Duration of Google Workspace API requests, by API.",
+			Buckets: metrics.DefaultBuckets,
+		},
+		[]string{"api"},
+	)
+
+	// backoffRetries is the number of Google Workspace API operations that were
+	// retried with exponential backoff.
+	backoffRetries = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "hermes_google_api_backoff_retries_total",
+			Help: "Google Workspace API operations retried with exponential backoff.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(apiRequests, apiRequestDuration, backoffRetries)
+}
+
+// instrumentClient wraps the transport of HTTP client c to record metrics for
+// all requests, and returns c.
+func instrumentClient(c *http.Client) *http.Client {
+	next := c.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	c.Transport = metrics.RoundTripperFunc(
+		func(r *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(r)
+
+			api := apiName(r.URL)
+			apiRequestDuration.WithLabelValues(api).Observe(
+				time.Since(start).Seconds())
+			apiRequests.WithLabelValues(
+				api, metrics.StatusCodeLabel(resp, err)).Inc()
+
+			return resp, err
+		})
+	return c
+}
+
+// apiName returns the name of the Google API (e.g., "drive") requested with
+// URL u.
+func apiName(u *url.URL) string {
+	host := u.Hostname()
+	if host == "www.googleapis.com" {
+		// Paths are like "/drive/v3/files" or "/upload/drive/v3/files".
+		parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+		if len(parts) > 1 && parts[0] == "upload" {
+			return parts[1]
+		}
+		return parts[0]
+	}
+	if name := strings.TrimSuffix(host, ".googleapis.com"); name != host {
+		return name
+	}
+	return "other"
+}
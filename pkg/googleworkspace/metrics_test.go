@@ -0,0 +1,25 @@
+package googleworkspace
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIName(t *testing.T) {
+	cases := map[string]string{
+		"https://www.googleapis.com/drive/v3/files/abc":        "drive",
+		"https://www.googleapis.com/upload/drive/v3/files":     "drive",
+		"https://docs.googleapis.com/v1/documents/abc":         "docs",
+		"https://people.googleapis.com/v1/people:searchDir":    "people",
+		"https://admin.googleapis.com/admin/directory/v1/user": "admin",
+		"https://example.com/other":                            "other",
+	}
+
+	for u, want := range cases {
+		parsed, err := url.Parse(u)
+		assert.NoError(t, err)
+		assert.Equal(t, want, apiName(parsed), u)
+	}
+}
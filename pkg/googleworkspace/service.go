@@ -62,7 +62,7 @@ func NewFromConfig(cfg *Config) *Service {
 
 	fmt.Println(string("\033[32m"), "Service Account Detected, Using it.......", string("\033[0m"))
 
-	client := conf.Client(context.TODO())
+	client := instrumentClient(conf.Client(context.TODO()))
 
 	adminSrv, err := admin.NewService(context.TODO(), option.WithHTTPClient(client))
 	if err != nil {
@@ -123,7 +123,7 @@ func New() *Service {
 	if err != nil {
 		log.Fatalf("Unable to parse client secret file to config: %v", err)
 	}
-	client := getClient(gc)
+	client := instrumentClient(getClient(gc))
 
 	adminSrv, err := admin.NewService(context.TODO(), option.WithHTTPClient(client))
 	if err != nil {
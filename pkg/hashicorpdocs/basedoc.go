@@ -1,5 +1,11 @@
 package hashicorpdocs
 
+import (
+	"time"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+)
+
 // BaseDoc contains common document metadata fields used by Hermes.
 type BaseDoc struct {
 	// ObjectID is the Google Drive file ID for the document.
@@ -29,8 +35,8 @@ type BaseDoc struct {
 	// are requested for the document.
 	Reviewers []string `json:"reviewers,omitempty"`
 
-	// last date to review a doc for a reviewer
-	DueDate string `json:"dueDate,omitempty"`
+	// DueDate is the date by which reviewers should review the document.
+	DueDate *ObjectDate `json:"dueDate,omitempty"`
 
 	// ChangesRequestedBy is a slice of email address strings for users that have
 	// requested changes for the document.
@@ -62,6 +68,10 @@ type BaseDoc struct {
 	// Locked is true if the document is locked for editing.
 	Locked bool `json:"locked,omitempty"`
 
+	// ApprovalStatus is the status of the approval policy of the document type,
+	// if it has one. This value isn't stored in the search index.
+	ApprovalStatus *models.ApprovalPolicyStatus `json:"approvalStatus,omitempty"`
+
 	// MetaTags contains metadata tags that can be used for filtering in Algolia.
 	MetaTags []string `json:"_tags,omitempty"`
 
@@ -114,8 +124,8 @@ func (d BaseDoc) GetReviewers() []string {
 	return d.Reviewers
 }
 
-func (d BaseDoc) GetDueDate() string {
-	return d.DueDate
+func (d BaseDoc) GetDueDate() *time.Time {
+	return d.DueDate.TimePtr()
 }
 
 func (d BaseDoc) GetChangesRequestedBy() []string {
@@ -204,6 +214,10 @@ func (d *BaseDoc) SetFileRevision(revisionID, revisionName string) {
 	}
 }
 
+func (d *BaseDoc) SetApprovalStatus(s *models.ApprovalPolicyStatus) {
+	d.ApprovalStatus = s
+}
+
 func (d *BaseDoc) SetLocked(l bool) {
 	d.Locked = l
 }
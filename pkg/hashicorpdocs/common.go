@@ -2,8 +2,11 @@ package hashicorpdocs
 
 import (
 	"fmt"
+	"time"
 
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"google.golang.org/api/drive/v3"
 )
 
@@ -27,7 +30,7 @@ type Doc interface {
 	GetDocType() string
 	GetMetaTags() []string
 	GetModifiedTime() int64
-	GetDueDate() string
+	GetDueDate() *time.Time
 	GetObjectID() string
 	GetOwners() []string
 	GetProduct() string
@@ -38,9 +41,10 @@ type Doc interface {
 	GetTitle() string
 
 	MissingFields() []string
-	ReplaceHeader(fileID, baseURL string, isDraft bool, s *gw.Service) error
+	ReplaceHeader(fileID, baseURL string, isDraft bool, s storage.Provider) error
 
 	// Setters for fields common to all document types.
+	SetApprovalStatus(*models.ApprovalPolicyStatus)
 	SetReviewedBy([]string)
 	SetChangesRequestedBy([]string)
 	SetContent(s string)
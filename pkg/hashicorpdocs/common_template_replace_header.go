@@ -2,11 +2,12 @@ package hashicorpdocs
 
 import (
 	"fmt"
-	// "net/url"
-	// "path"
+	"net/url"
+	"path"
 	"strings"
 
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"google.golang.org/api/docs/v1"
 )
 
@@ -684,6 +685,63 @@ func (doc *COMMONTEMPLATE) oldReplaceHeader(fileID, baseURL string, isDraft bool
 	return nil
 }
 
-func (doc *COMMONTEMPLATE) ReplaceHeader(fileID, baseURL string, isDraft bool, s *gw.Service) error {
+// ReplaceHeader replaces the document header for storage providers that write
+// headers themselves (e.g., the local provider). Headers of Google Docs are
+// currently left untouched.
+func (doc *COMMONTEMPLATE) ReplaceHeader(fileID, baseURL string, isDraft bool, s storage.Provider) error {
+	hr, ok := s.(storage.HeaderReplacer)
+	if !ok {
+		return nil
+	}
+
+	docURL, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("error parsing base URL: %w", err)
+	}
+	docURL.Path = path.Join(docURL.Path, "document", fileID)
+	docURLString := docURL.String()
+	if isDraft {
+		docURLString += "?draft=true"
+	}
+
+	// Build reviewers slice with a check next to reviewers who have reviewed.
+	var reviewers []string
+	for _, reviewer := range doc.Reviewers {
+		if contains(doc.ReviewedBy, reviewer) {
+			reviewers = append(reviewers, "✅ "+reviewer)
+		} else if contains(doc.ChangesRequestedBy, reviewer) {
+			reviewers = append(reviewers, "❌ "+reviewer)
+		} else {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+
+	// Owners are listed first in the authors field.
+	authors := doc.Contributors
+	if len(doc.Owners) > 0 && !contains(authors, doc.Owners[0]) {
+		authors = append([]string{doc.Owners[0]}, authors...)
+	}
+
+	if err := hr.ReplaceHeader(fileID, []storage.HeaderField{
+		{Name: "Title", Value: doc.Title},
+		{Name: "Doc Number", Value: doc.DocNumber},
+		{Name: "Summary", Value: doc.Summary},
+		{Name: "Created", Value: doc.Created},
+		{Name: "Status", Value: doc.Status},
+		{Name: "BU", Value: doc.Product},
+		{Name: "Author/s", Value: strings.Join(authors, ", ")},
+		{Name: "Reviewers", Value: strings.Join(reviewers, ", ")},
+		{Name: "Due Date", Value: doc.DueDate.String()},
+		{Name: "RFC", Value: doc.RFC},
+		{Name: "Hermes", Value: docURLString},
+	}); err != nil {
+		return fmt.Errorf("error replacing header: %w", err)
+	}
+
+	// Rename file with new title.
+	if err := s.RenameFile(fileID, doc.Title); err != nil {
+		return fmt.Errorf("error renaming file with new title: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,45 @@
+package hashicorpdocs
+
+import (
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCOMMONTEMPLATEReplaceHeaderLocal(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	st, err := storage.NewLocalProvider(t.TempDir())
+	require.NoError(err)
+	f, err := st.CreateFile("Untitled", "drafts", []byte("Body\n"))
+	require.NoError(err)
+
+	doc := &COMMONTEMPLATE{
+		BaseDoc: BaseDoc{
+			ObjectID:   f.Id,
+			Title:      "My Doc",
+			Status:     "WIP",
+			Owners:     []string{"owner@example.com"},
+			Reviewers:  []string{"a@example.com", "b@example.com"},
+			ReviewedBy: []string{"a@example.com"},
+		},
+	}
+	require.NoError(doc.ReplaceHeader(f.Id, "https://hermes.example.com", true, st))
+
+	content, err := st.GetContent(f.Id)
+	require.NoError(err)
+	assert.Contains(string(content), "| **Title** | My Doc |")
+	assert.Contains(string(content), "| **Author/s** | owner@example.com |")
+	assert.Contains(string(content),
+		"| **Reviewers** | ✅ a@example.com, b@example.com |")
+	assert.Contains(string(content),
+		"| **Hermes** | https://hermes.example.com/document/"+f.Id+"?draft=true |")
+	assert.Contains(string(content), "Body\n")
+
+	// The file is renamed to the document title.
+	f, err = st.GetFile(f.Id)
+	require.NoError(err)
+	assert.Equal("My Doc", f.Name)
+}
@@ -0,0 +1,127 @@
+package hashicorpdocs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// DateLayout is the layout of dates in document objects.
+const DateLayout = "2006-01-02"
+
+// Date is a calendar date (without a time of day) that is encoded in JSON as a
+// "YYYY-MM-DD" string.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the date of t.
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses a date in the "YYYY-MM-DD" format. RFC 3339 timestamps are
+// also accepted, in which case the time of day is dropped.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		ts, tsErr := time.Parse(time.RFC3339, s)
+		if tsErr != nil {
+			return Date{}, fmt.Errorf(
+				"invalid date %q: must be in the YYYY-MM-DD format", s)
+		}
+		t = ts
+	}
+	return NewDate(t), nil
+}
+
+// String returns the date in the "YYYY-MM-DD" format, or an empty string if d
+// is nil or zero.
+func (d *Date) String() string {
+	if d == nil || d.IsZero() {
+		return ""
+	}
+	return d.Format(DateLayout)
+}
+
+// MarshalJSON implements json.Marshaler. The zero date is encoded as an empty
+// string.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return json.Marshal("")
+	}
+	return json.Marshal(d.Format(DateLayout))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// ObjectDate is a Date in a document object. Unlike Date, invalid dates are
+// decoded as the zero date instead of returning an error, because objects
+// indexed before dates were validated may contain free-form text.
+type ObjectDate struct {
+	Date
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *ObjectDate) UnmarshalJSON(b []byte) error {
+	if err := d.Date.UnmarshalJSON(b); err != nil {
+		hclog.Default().Warn("ignoring invalid date in document object",
+			"error", err,
+			"date", string(b),
+		)
+		*d = ObjectDate{}
+	}
+	return nil
+}
+
+// String returns the date in the "YYYY-MM-DD" format, or an empty string if d
+// is nil or zero.
+func (d *ObjectDate) String() string {
+	if d == nil {
+		return ""
+	}
+	return d.Date.String()
+}
+
+// TimePtr returns the date as a time pointer, or nil if d is nil or zero.
+func (d *ObjectDate) TimePtr() *time.Time {
+	if d == nil {
+		return nil
+	}
+	return d.Date.TimePtr()
+}
+
+// ObjectDatePtr returns the date as an object date pointer, or nil if d is nil.
+func (d *Date) ObjectDatePtr() *ObjectDate {
+	if d == nil {
+		return nil
+	}
+	return &ObjectDate{*d}
+}
+
+// TimePtr returns the date as a time pointer, or nil if d is nil or zero.
+func (d *Date) TimePtr() *time.Time {
+	if d == nil || d.IsZero() {
+		return nil
+	}
+	t := d.Time
+	return &t
+}
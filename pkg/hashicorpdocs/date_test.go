@@ -0,0 +1,111 @@
+package hashicorpdocs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateJSON(t *testing.T) {
+	cases := map[string]struct {
+		json    string
+		want    string
+		wantErr bool
+	}{
+		"date": {
+			json: `"2023-05-10"`,
+			want: "2023-05-10",
+		},
+		"timestamp": {
+			json: `"2023-05-10T18:30:00Z"`,
+			want: "2023-05-10",
+		},
+		"empty": {
+			json: `""`,
+			want: "",
+		},
+		"invalid": {
+			json:    `"next week"`,
+			wantErr: true,
+		},
+		"not a string": {
+			json:    `20230510`,
+			wantErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var d Date
+			err := json.Unmarshal([]byte(c.json), &d)
+			if c.wantErr {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(c.want, d.String())
+
+			b, err := json.Marshal(d)
+			assert.NoError(err)
+			assert.JSONEq(`"`+c.want+`"`, string(b))
+		})
+	}
+}
+
+func TestDateTimePtr(t *testing.T) {
+	assert := assert.New(t)
+
+	var nilDate *Date
+	assert.Nil(nilDate.TimePtr())
+	assert.Nil((&Date{}).TimePtr())
+
+	d := NewDate(time.Date(2023, 5, 10, 18, 30, 0, 0, time.UTC))
+	assert.Equal(time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC), *d.TimePtr())
+}
+
+func TestObjectDateJSON(t *testing.T) {
+	cases := map[string]struct {
+		json string
+		want string
+	}{
+		"date": {
+			json: `{"objectID":"doc","dueDate":"2023-05-10"}`,
+			want: "2023-05-10",
+		},
+		"no date": {
+			json: `{"objectID":"doc"}`,
+		},
+		"legacy free-form date": {
+			json: `{"objectID":"doc","dueDate":"next week"}`,
+		},
+		"legacy non-string date": {
+			json: `{"objectID":"doc","dueDate":20230510}`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			var doc BaseDoc
+			assert.NoError(json.Unmarshal([]byte(c.json), &doc))
+			assert.Equal("doc", doc.ObjectID)
+			assert.Equal(c.want, doc.DueDate.String())
+			if c.want == "" {
+				assert.Nil(doc.GetDueDate())
+			} else {
+				assert.NotNil(doc.GetDueDate())
+			}
+		})
+	}
+
+	// Dates in document objects are encoded like dates.
+	d := NewDate(time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC))
+	b, err := json.Marshal(BaseDoc{DueDate: d.ObjectDatePtr()})
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"dueDate":"2023-05-10"`)
+}
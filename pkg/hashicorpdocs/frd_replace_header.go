@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"google.golang.org/api/docs/v1"
 )
 
@@ -40,7 +41,12 @@ import (
 //   |-----------------------------------------------------------------------------------|
 //
 
-func (doc *FRD) ReplaceHeader(fileID, baseURL string, isDraft bool, s *gw.Service) error {
+func (doc *FRD) ReplaceHeader(fileID, baseURL string, isDraft bool, sp storage.Provider) error {
+	s, ok := sp.(*gw.Service)
+	if !ok {
+		return fmt.Errorf("FRD header replacement requires Google Workspace storage")
+	}
+
 	const (
 		tableRows = 11 // Number of rows in the header table.
 	)
@@ -3,8 +3,8 @@ package hashicorpdocs
 import (
 	"fmt"
 
-	"github.com/hashicorp-forge/hermes/pkg/googleworkspace"
 	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"github.com/hashicorp/go-hclog"
 	"google.golang.org/api/docs/v1"
 	"gorm.io/gorm"
@@ -15,7 +15,7 @@ import (
 func IsLocked(
 	fileID string,
 	db *gorm.DB,
-	goog *googleworkspace.Service,
+	s storage.Provider,
 	log hclog.Logger,
 ) (bool, error) {
 
@@ -30,9 +30,9 @@ func IsLocked(
 	// Find out if the document header contains a suggestion. Deleting text which
 	// contains a suggestion currently causes a Google internal API error so we
 	// need to lock the document.
-	gDoc, err := goog.GetDoc(fileID)
+	gDoc, err := s.GetDoc(fileID)
 	if err != nil {
-		return false, fmt.Errorf("error getting Google Doc: %w", err)
+		return false, fmt.Errorf("error getting doc: %w", err)
 	}
 
 	hasSuggestion := containsSuggestionInHeader(gDoc)
@@ -8,6 +8,7 @@ import (
 	"unicode/utf8"
 
 	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"github.com/hashicorp-forge/hermes/pkg/storage"
 	"google.golang.org/api/docs/v1"
 )
 
@@ -47,7 +48,12 @@ const (
 	tableRows = 12 // Number of rows in the header table.
 )
 
-func (doc *RFC) ReplaceHeader(fileID, baseURL string, isDraft bool, s *gw.Service) error {
+func (doc *RFC) ReplaceHeader(fileID, baseURL string, isDraft bool, sp storage.Provider) error {
+	s, ok := sp.(*gw.Service)
+	if !ok {
+		return fmt.Errorf("RFC header replacement requires Google Workspace storage")
+	}
+
 	// Get doc.
 	d, err := s.GetDoc(fileID)
 	if err != nil {
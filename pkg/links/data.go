@@ -4,21 +4,17 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
+	"github.com/hashicorp-forge/hermes/pkg/search"
 )
 
-// DeleteDocumentRedirectDetails deletes document redirect details from Algolia.
+// DeleteDocumentRedirectDetails deletes document redirect details from the
+// links index.
 func DeleteDocumentRedirectDetails(
-	algo *algolia.Client, id string, docType string, docNumString string) error {
+	sp search.Provider, id string, docType string, docNumString string) error {
 
 	if docNumString != "" && docType != "" {
 		objectID := getObjectID(docType, docNumString)
-		res, err := algo.Links.DeleteObject(objectID)
-		if err != nil {
-			return fmt.Errorf("error deleting redirect link details: %w", err)
-		}
-		err = res.Wait()
-		if err != nil {
+		if err := sp.Links().DeleteObject(objectID); err != nil {
 			return fmt.Errorf("error deleting redirect link details: %w", err)
 		}
 	}
@@ -26,10 +22,22 @@ func DeleteDocumentRedirectDetails(
 	return nil
 }
 
+// GetDocumentRedirectDetails gets the redirect details of the document with
+// type docType and number docNumString from the links index.
+func GetDocumentRedirectDetails(
+	sp search.Provider, docType string, docNumString string) (*LinkData, error) {
+	var ld LinkData
+	if err := sp.Links().GetObject(
+		getObjectID(docType, docNumString), &ld); err != nil {
+		return nil, fmt.Errorf("error getting redirect link details: %w", err)
+	}
+	return &ld, nil
+}
+
 // SaveDocumentRedirectDetails saves the short path of the document as the key
-// and the document ID as the value in Algolia.
+// and the document ID as the value in the links index.
 func SaveDocumentRedirectDetails(
-	algo *algolia.Client, id string, docType string, docNumString string) error {
+	sp search.Provider, id string, docType string, docNumString string) error {
 
 	var ld LinkData
 
@@ -39,12 +47,7 @@ func SaveDocumentRedirectDetails(
 		ld.ObjectID = getObjectID(docType, docNumString)
 		// Save id of the document
 		ld.DocumentID = id
-		res, err := algo.Links.SaveObject(&ld)
-		if err != nil {
-			return fmt.Errorf("error saving redirect link details: %w", err)
-		}
-		err = res.Wait()
-		if err != nil {
+		if err := sp.Links().SaveObject(&ld); err != nil {
 			return fmt.Errorf("error saving redirect link details: %w", err)
 		}
 	}
@@ -52,7 +55,7 @@ func SaveDocumentRedirectDetails(
 	return nil
 }
 
-// getObjectID builds the ID for a document redirect details object in Algolia.
+// getObjectID builds the ID for a document redirect details object.
 // Object ID's format is: /doctype/{product_abbreviation-docnumber}
 // (e.g., "/rfc/lab-001").
 func getObjectID(docType, docNumString string) string {
@@ -5,7 +5,7 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
+	"github.com/hashicorp-forge/hermes/pkg/search"
 	"github.com/hashicorp/go-hclog"
 )
 
@@ -23,7 +23,7 @@ type LinkData struct {
 }
 
 // RedirectHandler handles redirects from Hashilinks
-func RedirectHandler(algo *algolia.Client, algoCfg *algolia.Config, log hclog.Logger) http.Handler {
+func RedirectHandler(sp search.Provider, log hclog.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Only allow GET requests.
 		if r.Method != http.MethodGet {
@@ -49,14 +49,15 @@ func RedirectHandler(algo *algolia.Client, algoCfg *algolia.Config, log hclog.Lo
 			return
 		}
 
-		// Get document associated with the short link path from Algolia
+		// Get document associated with the short link path from the search
+		// provider.
 		ld := LinkData{
 			ObjectID: p,
 		}
 
-		err = algo.Links.GetObject(p, &ld)
+		err = sp.Links().GetObject(p, &ld)
 		if err != nil {
-			log.Error("error getting redirect link from algolia", "error", err, "id", p)
+			log.Error("error getting redirect link", "error", err, "id", p)
 			http.Error(w, "Error getting redirect link", http.StatusInternalServerError)
 			return
 		}
@@ -77,7 +78,7 @@ func RedirectHandler(algo *algolia.Client, algoCfg *algolia.Config, log hclog.Lo
 // parseAndValidatePath parses the short URL that is requested on "/l"
 // route that has the format /l/doctype/product-docnumber and validates
 // that the path has only two fields and removes the "/l" prefix to help
-// get a valid short URL key to perform a look up in Algolia
+// get a valid short URL key to perform a look up in the links index
 func parseAndValidatePath(p string) (string, error) {
 	// Remove redirect url path "/l"
 	p = strings.TrimPrefix(p, "/l")
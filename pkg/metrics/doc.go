@@ -0,0 +1,6 @@
+// Package metrics provides helpers for recording Prometheus metrics of HTTP
+// requests served and made by Hermes.
+//
+// Metrics are defined with the Prometheus client library and registered with
+// its default registry, which is served by promhttp.Handler.
+package metrics
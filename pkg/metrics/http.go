@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultBuckets are the default histogram buckets, in seconds.
+var DefaultBuckets = []float64{
+	.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60,
+}
+
+// httpRequestDuration is the duration of HTTP requests served, by route.
+var httpRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "hermes_http_request_duration_seconds",
+		Help:    "Duration of HTTP requests served, by route.",
+		Buckets: DefaultBuckets,
+	},
+	[]string{"route", "method", "code"},
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestDuration)
+}
+
+// InstrumentHandler is middleware that records the duration and response status
+// code of requests to next, labeled with route (e.g., the pattern the handler
+// is registered for).
+func InstrumentHandler(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(sr, r)
+
+		httpRequestDuration.WithLabelValues(
+			route, r.Method, strconv.Itoa(sr.status),
+		).Observe(time.Since(start).Seconds())
+	})
+}
+
+// statusRecorder is a http.ResponseWriter that records the response status
+// code.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if !sr.wroteHeader {
+		sr.status = code
+		sr.wroteHeader = true
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
+
+func (sr *statusRecorder) Write(b []byte) (int, error) {
+	sr.wroteHeader = true
+	return sr.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher if the underlying response writer does.
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// RoundTripperFunc is a function that implements http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// StatusCodeLabel returns the label value for the response status code of an
+// outgoing request that returned resp and err.
+func StatusCodeLabel(resp *http.Response, err error) string {
+	if err != nil || resp == nil {
+		return "error"
+	}
+	return strconv.Itoa(resp.StatusCode)
+}
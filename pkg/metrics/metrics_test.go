@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumentHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	h := InstrumentHandler("/test/", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Not found", http.StatusNotFound)
+		}))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/test/1", nil))
+	assert.Equal(http.StatusNotFound, rr.Code)
+
+	rr = httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(http.StatusOK, rr.Code)
+	assert.Contains(rr.Body.String(),
+		`hermes_http_request_duration_seconds_count{code="404",method="GET",route="/test/"} 1`)
+}
+
+func TestStatusCodeLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("200", StatusCodeLabel(&http.Response{StatusCode: 200}, nil))
+	assert.Equal("error", StatusCodeLabel(nil, errors.New("timeout")))
+	assert.Equal("error", StatusCodeLabel(nil, nil))
+}
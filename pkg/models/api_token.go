@@ -0,0 +1,212 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/gorm"
+)
+
+// APIToken is a model for a personal API token used by scripts and
+// integrations to authenticate as a user. Only a hash of the token is stored.
+type APIToken struct {
+	gorm.Model
+
+	// User is the user that the token authenticates as.
+	User   User
+	UserID uint `gorm:"not null;index"`
+
+	// Name is a user-provided name to identify the token.
+	Name string `gorm:"default:null;not null"`
+
+	// Prefix is the beginning of the token, which is displayed to help users
+	// identify their tokens.
+	Prefix string `gorm:"default:null;not null"`
+
+	// TokenHash is the SHA-256 hash of the token.
+	TokenHash string `gorm:"default:null;not null;uniqueIndex"`
+
+	// Scope is the scope of access granted by the token.
+	Scope APITokenScope `gorm:"default:null;not null"`
+
+	// LastUsedAt is the time the token was last used to authenticate a request.
+	LastUsedAt *time.Time
+}
+
+// APITokens is a slice of API tokens.
+type APITokens []APIToken
+
+// APITokenScope is the scope of access granted by an API token.
+type APITokenScope string
+
+const (
+	// ReadOnlyAPITokenScope allows read-only (GET and HEAD) requests.
+	ReadOnlyAPITokenScope APITokenScope = "read-only"
+
+	// DraftsAPITokenScope allows read-only requests and managing drafts.
+	DraftsAPITokenScope APITokenScope = "drafts"
+
+	// AdminAPITokenScope allows all requests that the user is authorized to make,
+	// including admin actions.
+	AdminAPITokenScope APITokenScope = "admin"
+)
+
+// APITokenScopes are all API token scopes.
+var APITokenScopes = []APITokenScope{
+	ReadOnlyAPITokenScope,
+	DraftsAPITokenScope,
+	AdminAPITokenScope,
+}
+
+const (
+	// apiTokenPrefix is the prefix of all API tokens, which makes them easy to
+	// recognize (e.g., by secret scanners).
+	apiTokenPrefix = "hermes_"
+
+	// apiTokenDisplayPrefixLength is the length of the token prefix that is
+	// stored and displayed to identify a token.
+	apiTokenDisplayPrefixLength = len(apiTokenPrefix) + 6
+
+	// apiTokenLastUsedResolution is the resolution of LastUsedAt, which limits
+	// database writes for frequently used tokens.
+	apiTokenLastUsedResolution = time.Minute
+)
+
+// Generate generates a new API token and returns it. The token's prefix and
+// hash are assigned to the receiver; the token itself isn't stored and can only
+// be shown to the user once.
+func (t *APIToken) Generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+	tok := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	t.Prefix = tok[:apiTokenDisplayPrefixLength]
+	t.TokenHash = HashAPIToken(tok)
+
+	return tok, nil
+}
+
+// HashAPIToken returns the hash of API token tok as it is stored in the
+// database.
+func HashAPIToken(tok string) string {
+	h := sha256.Sum256([]byte(tok))
+	return hex.EncodeToString(h[:])
+}
+
+// IsAPIToken returns true if tok looks like an API token.
+func IsAPIToken(tok string) bool {
+	return strings.HasPrefix(tok, apiTokenPrefix)
+}
+
+// Create creates the API token in database db for the user with the receiver's
+// User.EmailAddress.
+func (t *APIToken) Create(db *gorm.DB) error {
+	if err := validation.ValidateStruct(t,
+		validation.Field(&t.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&t.Prefix, validation.Required),
+		validation.Field(&t.TokenHash, validation.Required),
+		validation.Field(&t.Scope, validation.Required),
+	); err != nil {
+		return err
+	}
+	if t.User.EmailAddress == "" {
+		return errors.New("user email address is required")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := t.User.FirstOrCreate(tx); err != nil {
+			return fmt.Errorf("error getting user: %w", err)
+		}
+		t.UserID = t.User.ID
+
+		return tx.
+			Omit("User").
+			Create(&t).
+			Error
+	})
+}
+
+// Delete soft-deletes the API token with the receiver's ID from database db, if
+// it belongs to the user with the receiver's User.EmailAddress. It returns
+// gorm.ErrRecordNotFound if no such token exists.
+func (t *APIToken) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(t,
+		validation.Field(&t.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	res := db.
+		Where("id = ? AND user_id = (?)", t.ID,
+			db.Model(&User{}).
+				Select("id").
+				Where("email_address = ?", t.User.EmailAddress)).
+		Delete(&APIToken{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetByToken gets the API token matching token tok (and its user) from
+// database db, and assigns it to the receiver.
+func (t *APIToken) GetByToken(db *gorm.DB, tok string) error {
+	return db.
+		Where(APIToken{TokenHash: HashAPIToken(tok)}).
+		Preload("User").
+		First(&t).
+		Error
+}
+
+// UpdateLastUsedAt records that the API token was used at time now, unless it
+// was already recorded as used recently.
+func (t *APIToken) UpdateLastUsedAt(db *gorm.DB, now time.Time) error {
+	if t.LastUsedAt != nil &&
+		now.Sub(*t.LastUsedAt) < apiTokenLastUsedResolution {
+		return nil
+	}
+
+	if err := db.
+		Model(&APIToken{}).
+		Where("id = ?", t.ID).
+		UpdateColumn("last_used_at", now).
+		Error; err != nil {
+		return err
+	}
+	t.LastUsedAt = &now
+
+	return nil
+}
+
+// FindByUser finds all API tokens of the user with email address email from
+// database db, and assigns them to the receiver.
+func (t *APITokens) FindByUser(db *gorm.DB, email string) error {
+	return db.
+		Joins("JOIN users ON users.id = api_tokens.user_id").
+		Where("users.email_address = ?", email).
+		Order("api_tokens.id").
+		Find(&t).
+		Error
+}
+
+// Validate validates that the API token scope is known.
+func (s APITokenScope) Validate() error {
+	for _, v := range APITokenScopes {
+		if s == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown API token scope %q", s)
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAPITokenGenerate(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	var t1, t2 APIToken
+	tok1, err := t1.Generate()
+	require.NoError(err)
+	tok2, err := t2.Generate()
+	require.NoError(err)
+
+	assert.NotEqual(tok1, tok2)
+	assert.True(IsAPIToken(tok1))
+	assert.Equal(tok1[:len(t1.Prefix)], t1.Prefix)
+	assert.Equal(HashAPIToken(tok1), t1.TokenHash)
+	assert.False(IsAPIToken("ya29.google-access-token"))
+}
+
+func TestAPITokenModel(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Create, use, and revoke", func(t *testing.T) {
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		t.Run("Create a token with an unknown scope", func(t *testing.T) {
+			require := require.New(t)
+			at := APIToken{
+				User:  User{EmailAddress: "a@a.com"},
+				Name:  "CI",
+				Scope: "everything",
+			}
+			_, err := at.Generate()
+			require.NoError(err)
+			err = at.Create(db)
+			require.Error(err)
+		})
+
+		var at APIToken
+		var tok string
+		t.Run("Create a token", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			at = APIToken{
+				User:  User{EmailAddress: "a@a.com"},
+				Name:  "CI",
+				Scope: ReadOnlyAPITokenScope,
+			}
+			var err error
+			tok, err = at.Generate()
+			require.NoError(err)
+			err = at.Create(db)
+			require.NoError(err)
+			assert.NotEmpty(at.ID)
+			assert.NotEmpty(at.UserID)
+		})
+
+		t.Run("Get the token", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var got APIToken
+			err := got.GetByToken(db, tok)
+			require.NoError(err)
+			assert.Equal(at.ID, got.ID)
+			assert.Equal("a@a.com", got.User.EmailAddress)
+			assert.Equal(ReadOnlyAPITokenScope, got.Scope)
+			assert.Nil(got.LastUsedAt)
+		})
+
+		t.Run("Get an unknown token", func(t *testing.T) {
+			var got APIToken
+			err := got.GetByToken(db, "hermes_unknown")
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		})
+
+		t.Run("Update last used time", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			now := time.Now()
+			err := at.UpdateLastUsedAt(db, now)
+			require.NoError(err)
+
+			var got APIToken
+			err = got.GetByToken(db, tok)
+			require.NoError(err)
+			require.NotNil(got.LastUsedAt)
+			assert.WithinDuration(now, *got.LastUsedAt, time.Second)
+		})
+
+		t.Run("Find tokens by user", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var ts APITokens
+			err := ts.FindByUser(db, "a@a.com")
+			require.NoError(err)
+			require.Len(ts, 1)
+			assert.Equal(at.ID, ts[0].ID)
+
+			err = ts.FindByUser(db, "b@b.com")
+			require.NoError(err)
+			assert.Len(ts, 0)
+		})
+
+		t.Run("Revoke another user's token", func(t *testing.T) {
+			other := APIToken{User: User{EmailAddress: "b@b.com"}}
+			other.ID = at.ID
+			err := other.Delete(db)
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		})
+
+		t.Run("Revoke the token", func(t *testing.T) {
+			require := require.New(t)
+			err := at.Delete(db)
+			require.NoError(err)
+
+			var got APIToken
+			err = got.GetByToken(db, tok)
+			require.ErrorIs(err, gorm.ErrRecordNotFound)
+		})
+	})
+}
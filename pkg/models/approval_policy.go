@@ -0,0 +1,210 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// ApprovalPolicy is a policy of approvals required for a document to be
+// approved. A policy consists of stages that are satisfied in order, which
+// allows policies like "2 of N reviewers", "all reviewers", or "tech lead, then
+// security". An empty policy doesn't require any particular approvals.
+type ApprovalPolicy struct {
+	// Stages are the stages of the policy, in the order they need to be
+	// satisfied.
+	Stages []ApprovalStage `json:"stages,omitempty"`
+}
+
+// ApprovalStage is a stage of an approval policy.
+type ApprovalStage struct {
+	// Name is the name of the stage.
+	// Example: "Security"
+	Name string `json:"name"`
+
+	// Approvers are the email addresses of the users who can approve the stage.
+	// If empty, the stage can be approved by the document's reviewers.
+	Approvers []string `json:"approvers,omitempty"`
+
+	// MinApprovals is the minimum number of approvals required to satisfy the
+	// stage. If zero, approvals are required from all approvers.
+	MinApprovals int `json:"minApprovals,omitempty"`
+}
+
+// ApprovalPolicyStatus is the status of an approval policy for a document.
+type ApprovalPolicyStatus struct {
+	// Satisfied is true if all stages of the policy are satisfied.
+	Satisfied bool `json:"satisfied"`
+
+	// CurrentStage is the name of the first stage that isn't satisfied.
+	CurrentStage string `json:"currentStage,omitempty"`
+
+	// Stages are the statuses of the policy stages.
+	Stages []ApprovalStageStatus `json:"stages"`
+}
+
+// ApprovalStageStatus is the status of an approval policy stage.
+type ApprovalStageStatus struct {
+	// Name is the name of the stage.
+	Name string `json:"name"`
+
+	// RequiredApprovals is the number of approvals required to satisfy the
+	// stage.
+	RequiredApprovals int `json:"requiredApprovals"`
+
+	// ApprovedBy are the approvers who have approved the document.
+	ApprovedBy []string `json:"approvedBy"`
+
+	// PendingApprovers are the approvers who haven't approved the document.
+	PendingApprovers []string `json:"pendingApprovers"`
+
+	// RemainingApprovals is the number of approvals still required to satisfy
+	// the stage.
+	RemainingApprovals int `json:"remainingApprovals"`
+
+	// Satisfied is true if the stage is satisfied.
+	Satisfied bool `json:"satisfied"`
+}
+
+// Validate validates the approval policy.
+func (p ApprovalPolicy) Validate() error {
+	names := map[string]bool{}
+	for _, s := range p.Stages {
+		if names[s.Name] {
+			return fmt.Errorf("duplicate approval stage name %q", s.Name)
+		}
+		names[s.Name] = true
+
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("invalid approval stage %q: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate validates the approval stage.
+func (s ApprovalStage) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(&s.Name, validation.Required),
+		validation.Field(&s.MinApprovals,
+			validation.Min(0),
+			validation.When(len(s.Approvers) > 0,
+				validation.Max(len(s.Approvers)).Error(
+					"must be no greater than the number of approvers")),
+		),
+	)
+}
+
+// IsEmpty returns true if the policy doesn't have any stages.
+func (p ApprovalPolicy) IsEmpty() bool {
+	return len(p.Stages) == 0
+}
+
+// Evaluate returns the status of the policy for a document with reviewers
+// reviewers that has been approved by approvedBy.
+func (p ApprovalPolicy) Evaluate(
+	reviewers, approvedBy []string) ApprovalPolicyStatus {
+	status := ApprovalPolicyStatus{
+		Satisfied: true,
+		Stages:    []ApprovalStageStatus{},
+	}
+
+	for _, s := range p.Stages {
+		approvers := s.Approvers
+		if len(approvers) == 0 {
+			approvers = reviewers
+		}
+
+		ss := ApprovalStageStatus{
+			Name:              s.Name,
+			RequiredApprovals: s.MinApprovals,
+			ApprovedBy:        []string{},
+			PendingApprovers:  []string{},
+		}
+		if ss.RequiredApprovals <= 0 {
+			ss.RequiredApprovals = len(approvers)
+		}
+		for _, a := range approvers {
+			if containsFold(approvedBy, a) {
+				ss.ApprovedBy = append(ss.ApprovedBy, a)
+			} else {
+				ss.PendingApprovers = append(ss.PendingApprovers, a)
+			}
+		}
+		if n := ss.RequiredApprovals - len(ss.ApprovedBy); n > 0 {
+			ss.RemainingApprovals = n
+		}
+		ss.Satisfied = ss.RemainingApprovals == 0
+
+		if !ss.Satisfied && status.Satisfied {
+			status.Satisfied = false
+			status.CurrentStage = s.Name
+		}
+		status.Stages = append(status.Stages, ss)
+	}
+
+	return status
+}
+
+// CanApprove returns an error if user email can't approve a document with
+// reviewers reviewers that has been approved by approvedBy yet, because their
+// approval is required for a later stage of the policy than the current one.
+func (p ApprovalPolicy) CanApprove(
+	reviewers, approvedBy []string, email string) error {
+	status := p.Evaluate(reviewers, approvedBy)
+
+	current := -1
+	for i, ss := range status.Stages {
+		if !ss.Satisfied {
+			current = i
+			break
+		}
+	}
+	if current < 0 {
+		return nil
+	}
+
+	// Users who can approve the current stage can always approve.
+	if isStageApprover(p.Stages[current], reviewers, email) {
+		return nil
+	}
+	for _, s := range p.Stages[current+1:] {
+		if isStageApprover(s, reviewers, email) {
+			return fmt.Errorf(
+				"approval stage %q must be satisfied first", status.CurrentStage)
+		}
+	}
+	return nil
+}
+
+// IsApprover returns true if user email is an explicit approver of a stage of
+// the policy. Explicit approvers can approve documents even if they aren't
+// reviewers.
+func (p ApprovalPolicy) IsApprover(email string) bool {
+	for _, s := range p.Stages {
+		if containsFold(s.Approvers, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStageApprover returns true if user email can approve stage s of a document
+// with reviewers reviewers.
+func isStageApprover(s ApprovalStage, reviewers []string, email string) bool {
+	if len(s.Approvers) == 0 {
+		return containsFold(reviewers, email)
+	}
+	return containsFold(s.Approvers, email)
+}
+
+// containsFold returns true if values contains s, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
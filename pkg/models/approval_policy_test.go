@@ -0,0 +1,212 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalPolicyEvaluate(t *testing.T) {
+	reviewers := []string{"a@example.com", "b@example.com", "c@example.com"}
+
+	quorum := ApprovalPolicy{
+		Stages: []ApprovalStage{
+			{Name: "Reviewers", MinApprovals: 2},
+		},
+	}
+	all := ApprovalPolicy{
+		Stages: []ApprovalStage{
+			{Name: "Reviewers"},
+		},
+	}
+	staged := ApprovalPolicy{
+		Stages: []ApprovalStage{
+			{Name: "Tech lead", Approvers: []string{"lead@example.com"}},
+			{
+				Name:         "Security",
+				Approvers:    []string{"sec1@example.com", "sec2@example.com"},
+				MinApprovals: 1,
+			},
+		},
+	}
+
+	cases := map[string]struct {
+		policy           ApprovalPolicy
+		approvedBy       []string
+		wantSatisfied    bool
+		wantCurrentStage string
+		wantRemaining    []int
+	}{
+		"empty policy": {
+			policy:        ApprovalPolicy{},
+			wantSatisfied: true,
+			wantRemaining: []int{},
+		},
+		"quorum not met": {
+			policy:           quorum,
+			approvedBy:       []string{"a@example.com"},
+			wantCurrentStage: "Reviewers",
+			wantRemaining:    []int{1},
+		},
+		"quorum met": {
+			policy:        quorum,
+			approvedBy:    []string{"a@example.com", "C@example.com"},
+			wantSatisfied: true,
+			wantRemaining: []int{0},
+		},
+		"all reviewers not met": {
+			policy:           all,
+			approvedBy:       []string{"a@example.com", "b@example.com"},
+			wantCurrentStage: "Reviewers",
+			wantRemaining:    []int{1},
+		},
+		"all reviewers met": {
+			policy: all,
+			approvedBy: []string{
+				"a@example.com", "b@example.com", "c@example.com"},
+			wantSatisfied: true,
+			wantRemaining: []int{0},
+		},
+		"stages with no approvals": {
+			policy:           staged,
+			wantCurrentStage: "Tech lead",
+			wantRemaining:    []int{1, 1},
+		},
+		"stages with first stage approved": {
+			policy:           staged,
+			approvedBy:       []string{"lead@example.com"},
+			wantCurrentStage: "Security",
+			wantRemaining:    []int{0, 1},
+		},
+		"stages approved": {
+			policy:        staged,
+			approvedBy:    []string{"lead@example.com", "sec2@example.com"},
+			wantSatisfied: true,
+			wantRemaining: []int{0, 0},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			got := c.policy.Evaluate(reviewers, c.approvedBy)
+			assert.Equal(c.wantSatisfied, got.Satisfied)
+			assert.Equal(c.wantCurrentStage, got.CurrentStage)
+
+			remaining := []int{}
+			for _, s := range got.Stages {
+				remaining = append(remaining, s.RemainingApprovals)
+				assert.Equal(s.RemainingApprovals == 0, s.Satisfied)
+			}
+			assert.Equal(c.wantRemaining, remaining)
+		})
+	}
+}
+
+func TestApprovalPolicyCanApprove(t *testing.T) {
+	reviewers := []string{"a@example.com", "lead@example.com", "sec@example.com"}
+	policy := ApprovalPolicy{
+		Stages: []ApprovalStage{
+			{Name: "Tech lead", Approvers: []string{"lead@example.com"}},
+			{Name: "Security", Approvers: []string{"sec@example.com"}},
+		},
+	}
+
+	cases := map[string]struct {
+		approvedBy []string
+		email      string
+		wantErr    bool
+	}{
+		"current stage approver": {
+			email: "lead@example.com",
+		},
+		"later stage approver": {
+			email:   "sec@example.com",
+			wantErr: true,
+		},
+		"later stage approver after earlier stage": {
+			approvedBy: []string{"lead@example.com"},
+			email:      "sec@example.com",
+		},
+		"reviewer not in any stage": {
+			email: "a@example.com",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := policy.CanApprove(reviewers, c.approvedBy, c.email)
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApprovalPolicyIsApprover(t *testing.T) {
+	policy := ApprovalPolicy{
+		Stages: []ApprovalStage{
+			{Name: "Reviewers"},
+			{Name: "Security", Approvers: []string{"sec@example.com"}},
+		},
+	}
+
+	assert.True(t, policy.IsApprover("sec@example.com"))
+	assert.True(t, policy.IsApprover("SEC@example.com"))
+	assert.False(t, policy.IsApprover("a@example.com"))
+	assert.False(t, ApprovalPolicy{}.IsApprover("sec@example.com"))
+}
+
+func TestApprovalPolicyValidate(t *testing.T) {
+	cases := map[string]struct {
+		policy  ApprovalPolicy
+		wantErr bool
+	}{
+		"valid": {
+			policy: ApprovalPolicy{
+				Stages: []ApprovalStage{
+					{Name: "Reviewers", MinApprovals: 2},
+					{Name: "Security", Approvers: []string{"sec@example.com"}},
+				},
+			},
+		},
+		"missing stage name": {
+			policy: ApprovalPolicy{
+				Stages: []ApprovalStage{{MinApprovals: 1}},
+			},
+			wantErr: true,
+		},
+		"duplicate stage names": {
+			policy: ApprovalPolicy{
+				Stages: []ApprovalStage{{Name: "A"}, {Name: "A"}},
+			},
+			wantErr: true,
+		},
+		"more required approvals than approvers": {
+			policy: ApprovalPolicy{
+				Stages: []ApprovalStage{
+					{
+						Name:         "Security",
+						Approvers:    []string{"sec@example.com"},
+						MinApprovals: 2,
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := c.policy.Validate()
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// AuditEvent is a model for a recorded action on a document, template, or
+// user. Audit events are never updated or deleted.
+type AuditEvent struct {
+	ID uint `gorm:"primaryKey"`
+
+	// CreatedAt is the time the action occurred.
+	CreatedAt time.Time `gorm:"index"`
+
+	// Actor is the email address of the user who performed the action.
+	Actor string `gorm:"type:citext;default:null;not null;index"`
+
+	// Action is the action that was performed.
+	Action AuditAction `gorm:"default:null;not null;index"`
+
+	// DocumentID is the ID (Google file ID) of the document the action was
+	// performed on, if any.
+	DocumentID string `gorm:"index"`
+
+	// Target identifies what the action was performed on for actions that aren't
+	// on a document (e.g., the email address of a user or the ID of a template).
+	Target string `gorm:"index"`
+
+	// Before is the state of the changed object before the action.
+	Before datatypes.JSON
+
+	// After is the state of the changed object after the action.
+	After datatypes.JSON
+}
+
+// AuditEvents is a slice of audit events.
+type AuditEvents []AuditEvent
+
+// AuditAction is an action recorded in the audit log.
+type AuditAction string
+
+const (
+	DraftCreatedAuditAction     AuditAction = "draft.created"
+	DraftUpdatedAuditAction     AuditAction = "draft.updated"
+	DraftDeletedAuditAction     AuditAction = "draft.deleted"
+	ReviewStartedAuditAction    AuditAction = "review.started"
+	DocumentUpdatedAuditAction  AuditAction = "document.updated"
+	DocumentApprovedAuditAction AuditAction = "document.approved"
+	ChangesRequestedAuditAction AuditAction = "document.changes_requested"
+	AdminGrantedAuditAction     AuditAction = "user.admin_granted"
+	AdminRevokedAuditAction     AuditAction = "user.admin_revoked"
+	RoleGrantedAuditAction      AuditAction = "user.role_granted"
+	RoleRevokedAuditAction      AuditAction = "user.role_revoked"
+
+	// Template actions were recorded for changes to the Algolia templates that
+	// document types used to be stored as.
+	TemplateCreatedAuditAction AuditAction = "template.created"
+	TemplateUpdatedAuditAction AuditAction = "template.updated"
+	TemplateDeletedAuditAction AuditAction = "template.deleted"
+
+	DocumentTypeCreatedAuditAction AuditAction = "document_type.created"
+	DocumentTypeUpdatedAuditAction AuditAction = "document_type.updated"
+	DocumentTypeDeletedAuditAction AuditAction = "document_type.deleted"
+)
+
+// defaultAuditEventsFindLimit is the default maximum number of audit events
+// found with AuditEvents.Find.
+const defaultAuditEventsFindLimit = 100
+
+// AuditEventFilter filters audit events found with AuditEvents.Find. Zero
+// values are ignored.
+type AuditEventFilter struct {
+	// Actor filters by the email address of the user who performed the action.
+	Actor string
+
+	// Action filters by action.
+	Action AuditAction
+
+	// DocumentID filters by document ID.
+	DocumentID string
+
+	// Since filters events that occurred at or after this time.
+	Since time.Time
+
+	// Until filters events that occurred before this time.
+	Until time.Time
+
+	// Limit is the maximum number of events to find. Defaults to 100.
+	Limit int
+
+	// Offset is the number of events to skip.
+	Offset int
+}
+
+// AuditChange is the change of a field between the before and after states of
+// an audit event.
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Create creates the audit event in database db.
+func (e *AuditEvent) Create(db *gorm.DB) error {
+	if err := validation.ValidateStruct(e,
+		validation.Field(&e.Actor, validation.Required),
+		validation.Field(&e.Action, validation.Required),
+		validation.Field(&e.DocumentID,
+			validation.When(e.Target == "",
+				validation.Required.Error("either DocumentID or Target is required"),
+			),
+		),
+	); err != nil {
+		return err
+	}
+
+	return db.Create(&e).Error
+}
+
+// Find finds audit events matching filter f from database db, most recent
+// first, and assigns them to the receiver.
+func (e *AuditEvents) Find(db *gorm.DB, f AuditEventFilter) error {
+	tx := db.Model(&AuditEvent{})
+	if f.Actor != "" {
+		tx = tx.Where("actor = ?", f.Actor)
+	}
+	if f.Action != "" {
+		tx = tx.Where("action = ?", f.Action)
+	}
+	if f.DocumentID != "" {
+		tx = tx.Where("document_id = ?", f.DocumentID)
+	}
+	if !f.Since.IsZero() {
+		tx = tx.Where("created_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		tx = tx.Where("created_at < ?", f.Until)
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultAuditEventsFindLimit
+	}
+
+	return tx.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(f.Offset).
+		Find(&e).
+		Error
+}
+
+// Diff returns the top-level fields that differ between the before and after
+// states of the audit event, keyed by field name.
+func (e AuditEvent) Diff() (map[string]AuditChange, error) {
+	before, err := jsonObjectFields(e.Before)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding before state: %w", err)
+	}
+	after, err := jsonObjectFields(e.After)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding after state: %w", err)
+	}
+
+	diff := make(map[string]AuditChange)
+	for k, b := range before {
+		a, ok := after[k]
+		if !ok || !jsonEqual(a, b) {
+			diff[k] = AuditChange{Before: b, After: a}
+		}
+	}
+	for k, a := range after {
+		if _, ok := before[k]; !ok {
+			diff[k] = AuditChange{After: a}
+		}
+	}
+
+	return diff, nil
+}
+
+// jsonObjectFields decodes the fields of a JSON object. Empty or null JSON
+// returns no fields.
+func jsonObjectFields(j datatypes.JSON) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(j) == 0 || string(j) == "null" {
+		return fields, nil
+	}
+	if err := json.Unmarshal(j, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// jsonEqual returns true if JSON values a and b are semantically equal.
+func jsonEqual(a, b json.RawMessage) bool {
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestAuditEventDiff(t *testing.T) {
+	cases := map[string]struct {
+		before string
+		after  string
+
+		want map[string]AuditChange
+	}{
+		"changed, added, and removed fields": {
+			before: `{"title":"A","status":"Draft","summary":"S","tags":["x"]}`,
+			after:  `{"title":"B","status":"Draft","dueDate":"2023-05-01","tags":["x"]}`,
+			want: map[string]AuditChange{
+				"title":   {Before: json.RawMessage(`"A"`), After: json.RawMessage(`"B"`)},
+				"summary": {Before: json.RawMessage(`"S"`)},
+				"dueDate": {After: json.RawMessage(`"2023-05-01"`)},
+			},
+		},
+		"no before state": {
+			after: `{"title":"A"}`,
+			want: map[string]AuditChange{
+				"title": {After: json.RawMessage(`"A"`)},
+			},
+		},
+		"null after state": {
+			before: `{"title":"A"}`,
+			after:  `null`,
+			want: map[string]AuditChange{
+				"title": {Before: json.RawMessage(`"A"`)},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := AuditEvent{
+				Before: datatypes.JSON(c.before),
+				After:  datatypes.JSON(c.after),
+			}
+			got, err := e.Diff()
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestAuditEventModel(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Create and Find", func(t *testing.T) {
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		t.Run("Create without a document or target", func(t *testing.T) {
+			require := require.New(t)
+			e := AuditEvent{
+				Actor:  "a@example.com",
+				Action: DraftCreatedAuditAction,
+			}
+			err := e.Create(db)
+			require.Error(err)
+		})
+
+		t.Run("Create events", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			events := []AuditEvent{
+				{
+					Actor:      "a@example.com",
+					Action:     DraftCreatedAuditAction,
+					DocumentID: "fileID1",
+					After:      datatypes.JSON(`{"title":"A"}`),
+				},
+				{
+					Actor:      "b@example.com",
+					Action:     DraftUpdatedAuditAction,
+					DocumentID: "fileID1",
+					Before:     datatypes.JSON(`{"title":"A"}`),
+					After:      datatypes.JSON(`{"title":"B"}`),
+				},
+				{
+					Actor:  "a@example.com",
+					Action: AdminGrantedAuditAction,
+					Target: "c@example.com",
+				},
+			}
+			for i := range events {
+				err := events[i].Create(db)
+				require.NoError(err)
+				assert.EqualValues(i+1, events[i].ID)
+			}
+		})
+
+		t.Run("Find events for a document", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var events AuditEvents
+			err := events.Find(db, AuditEventFilter{DocumentID: "fileID1"})
+			require.NoError(err)
+			require.Len(events, 2)
+			// Most recent first.
+			assert.Equal(DraftUpdatedAuditAction, events[0].Action)
+			assert.Equal(DraftCreatedAuditAction, events[1].Action)
+		})
+
+		t.Run("Find events by actor and action", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var events AuditEvents
+			err := events.Find(db, AuditEventFilter{
+				Actor:  "a@example.com",
+				Action: AdminGrantedAuditAction,
+			})
+			require.NoError(err)
+			require.Len(events, 1)
+			assert.Equal("c@example.com", events[0].Target)
+		})
+
+		t.Run("Find events with a time range and limit", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var events AuditEvents
+			err := events.Find(db, AuditEventFilter{
+				Since: time.Now().Add(-time.Hour),
+				Until: time.Now().Add(time.Hour),
+				Limit: 2,
+			})
+			require.NoError(err)
+			assert.Len(events, 2)
+
+			err = events.Find(db, AuditEventFilter{
+				Until: time.Now().Add(-time.Hour),
+			})
+			require.NoError(err)
+			assert.Len(events, 0)
+		})
+	})
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeferredNotification is a model for a notification to a user that is deferred
+// until the end of the user's quiet hours. The notification is stored as
+// rendered for each of its channels, and is deleted once sent.
+type DeferredNotification struct {
+	ID uint `gorm:"primaryKey"`
+
+	// CreatedAt is the time the notification was deferred.
+	CreatedAt time.Time
+
+	// User is the user to notify.
+	User   User
+	UserID uint `gorm:"not null;index"`
+
+	// Event is the type of event the user is notified of.
+	Event NotificationEventType `gorm:"default:null;not null"`
+
+	// Channels are the channels to send the notification through, which are
+	// only the ones that failed after a partially failed attempt.
+	Channels datatypes.JSONType[[]NotificationChannel] `gorm:"not null"`
+
+	// Subject is the subject of the email, and the notification text of the
+	// Slack message.
+	Subject string
+
+	// HTMLBody is the HTML body of the email.
+	HTMLBody string
+
+	// SlackBlocks are the Block Kit blocks of the Slack message, if any.
+	SlackBlocks datatypes.JSON
+
+	// DeliverAt is the time to send the notification. It is moved later while
+	// the notification is claimed by a sender, and after failed attempts.
+	DeliverAt time.Time `gorm:"not null;index"`
+
+	// Attempts is the number of failed attempts to send the notification.
+	Attempts int
+}
+
+// DeferredNotifications is a slice of deferred notifications.
+type DeferredNotifications []DeferredNotification
+
+// Create stores the deferred notification in database db for the user with the
+// receiver's User.EmailAddress.
+func (n *DeferredNotification) Create(db *gorm.DB) error {
+	if err := validation.ValidateStruct(n,
+		validation.Field(&n.Event, validation.Required),
+		validation.Field(&n.DeliverAt, validation.Required),
+	); err != nil {
+		return err
+	}
+	if n.User.EmailAddress == "" {
+		return errors.New("user email address is required")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := n.User.FirstOrCreate(tx); err != nil {
+			return fmt.Errorf("error getting user: %w", err)
+		}
+		n.UserID = n.User.ID
+
+		return tx.
+			Omit("User").
+			Create(&n).
+			Error
+	})
+}
+
+// Delete deletes the deferred notification in database db.
+func (n *DeferredNotification) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(n,
+		validation.Field(&n.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.Delete(&DeferredNotification{}, n.ID).Error
+}
+
+// Update updates the channels, delivery time, and attempts of the deferred
+// notification in database db.
+func (n *DeferredNotification) Update(db *gorm.DB) error {
+	if err := validation.ValidateStruct(n,
+		validation.Field(&n.ID, validation.Required),
+		validation.Field(&n.DeliverAt, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Model(&n).
+		Select("Channels", "DeliverAt", "Attempts").
+		Updates(*n).
+		Error
+}
+
+// ClaimDueDeferredNotifications returns the notifications in database db that
+// are due to be sent as of time now, with their users, and claims them by
+// moving their delivery time to now plus lease. Notifications claimed by one
+// caller are skipped by concurrent callers until the lease expires, so a
+// notification that isn't deleted or updated by then (e.g., because the caller
+// exited) is sent again.
+func ClaimDueDeferredNotifications(
+	db *gorm.DB, now time.Time, lease time.Duration,
+) (DeferredNotifications, error) {
+	var ns DeferredNotifications
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{
+				Strength: "UPDATE",
+				Options:  "SKIP LOCKED",
+			}).
+			Where("deliver_at <= ?", now).
+			Order("id").
+			Find(&ns).
+			Error; err != nil {
+			return err
+		}
+		if len(ns) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(ns))
+		userIDs := make([]uint, len(ns))
+		for i, n := range ns {
+			ids[i] = n.ID
+			userIDs[i] = n.UserID
+		}
+
+		var users []User
+		if err := tx.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return fmt.Errorf("error getting users: %w", err)
+		}
+		byID := make(map[uint]User, len(users))
+		for _, u := range users {
+			byID[u.ID] = u
+		}
+		for i := range ns {
+			ns[i].User = byID[ns[i].UserID]
+			ns[i].DeliverAt = now.Add(lease)
+		}
+
+		return tx.
+			Model(&DeferredNotification{}).
+			Where("id IN ?", ids).
+			Update("deliver_at", now.Add(lease)).
+			Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
@@ -23,8 +23,9 @@ type Document struct {
 	// document.
 	Reviewers []*User `gorm:"many2many:document_reviews;"`
 
-	// last date to review a doc for a reviewer
-	DueDate string
+	// DueDate is the date by which reviewers should review the document.
+	DueDate *time.Time `gorm:"type:date"`
+
 	// Contributors are users who have contributed to the document.
 	Contributors []*User `gorm:"many2many:document_contributors;"`
 
@@ -97,6 +98,46 @@ const (
 	ObsoleteDocumentStatus
 )
 
+// String returns the name of the document status as displayed in the
+// application.
+func (s DocumentStatus) String() string {
+	switch s {
+	case DraftDocumentStatus:
+		return "Draft"
+	case InReviewDocumentStatus:
+		return "In-Review"
+	case ReviewedDocumentStatus:
+		return "Reviewed"
+	case ObsoleteDocumentStatus:
+		return "Obsolete"
+	default:
+		return "Unspecified"
+	}
+}
+
+// CountDocumentsByStatus returns the number of documents in database db for
+// each document status.
+func CountDocumentsByStatus(db *gorm.DB) (map[DocumentStatus]int64, error) {
+	var rows []struct {
+		Status DocumentStatus
+		Count  int64
+	}
+	if err := db.
+		Model(&Document{}).
+		Select("status, count(*) AS count").
+		Group("status").
+		Scan(&rows).
+		Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[DocumentStatus]int64, len(rows))
+	for _, r := range rows {
+		counts[r.Status] = r.Count
+	}
+	return counts, nil
+}
+
 // BeforeSave is a hook used to find associations before saving.
 func (d *Document) BeforeSave(tx *gorm.DB) error {
 	if err := d.getAssociations(tx); err != nil {
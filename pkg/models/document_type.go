@@ -1,9 +1,13 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -17,12 +21,15 @@ type DocumentType struct {
 	// Example: "RFC"
 	Name string `gorm:"index;not null;unique"`
 
-
 	// Description is the description of the document type.
 	// Example: "Create a Request for Comments document to present a proposal to
 	// colleagues for their review and feedback."
 	Description string
 
+	// TemplateFileID is the Google file ID of the document template that drafts
+	// of this type are copied from.
+	TemplateFileID string
+
 	// MoreInfoLinkText is the text for a "more info" link.
 	// Example: "When should I create an RFC?"
 	MoreInfoLinkText string
@@ -37,11 +44,45 @@ type DocumentType struct {
 	// Checks are document type checks, which require acknowledging a check box in
 	// order to publish a document.
 	Checks datatypes.JSON
+
+	// ApprovalPolicy is the policy of approvals required for documents of this
+	// type to be approved.
+	ApprovalPolicy datatypes.JSONType[ApprovalPolicy] `gorm:"not null;default:'{}'"`
+
+	// IndexContent enables indexing the content of published documents of this
+	// type for full-text search.
+	IndexContent bool `gorm:"default:false;not null"`
 }
 
 // DocumentTypes is a slice of document types.
 type DocumentTypes []DocumentType
 
+// ErrDocumentTypeInUse is returned when deleting a document type, or a custom
+// field of one, that is used by documents.
+var ErrDocumentTypeInUse = errors.New("document type is used by documents")
+
+// DocumentTypeCheck is a document type check, which requires acknowledging a
+// check box in order to publish a document.
+type DocumentTypeCheck struct {
+	// Label is the label of the check box.
+	Label string `json:"label"`
+
+	// HelperText contains more details for the check.
+	HelperText string `json:"helperText,omitempty"`
+
+	// Links are links with more info for the check.
+	Links []DocumentTypeLink `json:"links,omitempty"`
+}
+
+// DocumentTypeLink is a link for a document type check.
+type DocumentTypeLink struct {
+	// Text is the displayed text of the link.
+	Text string `json:"text"`
+
+	// URL is the URL that the link links to.
+	URL string `json:"url"`
+}
+
 // FirstOrCreate finds the first document type by name or creates a new record
 // if it does not exist.
 func (d *DocumentType) FirstOrCreate(db *gorm.DB) error {
@@ -61,13 +102,414 @@ func (d *DocumentType) Get(db *gorm.DB) error {
 		Error
 }
 
-// GetAll gets all document types from database db, and assigns them to the
-// receiver.
+// GetAll gets all document types from database db, ordered by name, and
+// assigns them to the receiver.
 func (d *DocumentTypes) GetAll(db *gorm.DB) error {
-	return db.Find(&d).
+	return db.
+		Preload(clause.Associations).
+		Order("name").
+		Find(&d).
 		Error
 }
 
+// GetChecks returns the decoded checks of the document type.
+func (d DocumentType) GetChecks() ([]DocumentTypeCheck, error) {
+	if len(d.Checks) == 0 || string(d.Checks) == "null" {
+		return nil, nil
+	}
+
+	var checks []DocumentTypeCheck
+	if err := json.Unmarshal(d.Checks, &checks); err != nil {
+		return nil, fmt.Errorf("error decoding checks: %w", err)
+	}
+	return checks, nil
+}
+
+// SetChecks sets the checks of the document type.
+func (d *DocumentType) SetChecks(checks []DocumentTypeCheck) error {
+	if len(checks) == 0 {
+		d.Checks = nil
+		return nil
+	}
+
+	b, err := json.Marshal(checks)
+	if err != nil {
+		return fmt.Errorf("error encoding checks: %w", err)
+	}
+	d.Checks = b
+	return nil
+}
+
+// Create creates the document type, including its custom fields, in database
+// db. A previously deleted document type with the same name is restored with
+// the fields of the receiver.
+func (d *DocumentType) Create(db *gorm.DB) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var deleted DocumentType
+		err := tx.
+			Unscoped().
+			Where("name = ? AND deleted_at IS NOT NULL", d.Name).
+			First(&deleted).
+			Error
+		switch {
+		case err == nil:
+			if err := tx.
+				Unscoped().
+				Model(&deleted).
+				Update("deleted_at", nil).
+				Error; err != nil {
+				return fmt.Errorf("error restoring deleted document type: %w", err)
+			}
+			d.ID = deleted.ID
+			d.CreatedAt = deleted.CreatedAt
+			if err := d.update(tx); err != nil {
+				return err
+			}
+
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.
+				Omit(clause.Associations).
+				Create(d).
+				Error; err != nil {
+				return err
+			}
+			if err := d.replaceCustomFields(tx); err != nil {
+				return err
+			}
+
+		default:
+			return err
+		}
+
+		d.CustomFields = nil
+		return d.Get(tx)
+	})
+}
+
+// Update updates all fields of the document type, including its name and
+// custom fields, in database db. Custom fields that aren't in the receiver are
+// deleted, unless they are used by documents.
+func (d *DocumentType) Update(db *gorm.DB) error {
+	if err := validation.ValidateStruct(d,
+		validation.Field(&d.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+	if err := d.Validate(); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := d.update(tx); err != nil {
+			return err
+		}
+
+		d.CustomFields = nil
+		return d.Get(tx)
+	})
+}
+
+// update updates all fields of the document type in database db. Document
+// types can't be renamed while documents have the type.
+func (d *DocumentType) update(db *gorm.DB) error {
+	var current DocumentType
+	if err := db.
+		Unscoped().
+		Select("name").
+		First(&current, d.ID).
+		Error; err != nil {
+		return err
+	}
+	if current.Name != d.Name {
+		var count int64
+		if err := db.
+			Unscoped().
+			Model(&Document{}).
+			Where("document_type_id = ?", d.ID).
+			Count(&count).
+			Error; err != nil {
+			return fmt.Errorf("error counting documents: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("error renaming document type: %w",
+				ErrDocumentTypeInUse)
+		}
+	}
+
+	if err := db.
+		Model(d).
+		Select(
+			"name",
+			"description",
+			"template_file_id",
+			"more_info_link_text",
+			"more_info_link_url",
+			"checks",
+			"approval_policy",
+			"index_content",
+		).
+		Updates(d).
+		Error; err != nil {
+		return err
+	}
+
+	return d.replaceCustomFields(db)
+}
+
+// replaceCustomFields makes the custom fields of the document type in database
+// db match the custom fields of the receiver, by name.
+func (d *DocumentType) replaceCustomFields(db *gorm.DB) error {
+	var existing []DocumentTypeCustomField
+	if err := db.
+		Where("document_type_id = ?", d.ID).
+		Find(&existing).
+		Error; err != nil {
+		return fmt.Errorf("error finding custom fields: %w", err)
+	}
+
+	existingByName := make(map[string]DocumentTypeCustomField, len(existing))
+	for _, c := range existing {
+		existingByName[c.Name] = c
+	}
+
+	kept := make(map[string]bool, len(d.CustomFields))
+	for _, c := range d.CustomFields {
+		kept[c.Name] = true
+		if e, ok := existingByName[c.Name]; ok {
+			if err := db.
+				Model(&e).
+				Select("read_only", "type").
+				Updates(DocumentTypeCustomField{
+					ReadOnly: c.ReadOnly,
+					Type:     c.Type,
+				}).
+				Error; err != nil {
+				return fmt.Errorf("error updating custom field %q: %w", c.Name, err)
+			}
+			continue
+		}
+
+		c.ID = 0
+		c.DocumentTypeID = d.ID
+		if err := db.
+			Omit(clause.Associations).
+			Create(&c).
+			Error; err != nil {
+			return fmt.Errorf("error creating custom field %q: %w", c.Name, err)
+		}
+	}
+
+	for _, c := range existing {
+		if kept[c.Name] {
+			continue
+		}
+
+		var count int64
+		if err := db.
+			Model(&DocumentCustomField{}).
+			Where("document_type_custom_field_id = ?", c.ID).
+			Count(&count).
+			Error; err != nil {
+			return fmt.Errorf("error counting custom field values: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("error deleting custom field %q: %w",
+				c.Name, ErrDocumentTypeInUse)
+		}
+
+		if err := db.
+			Unscoped().
+			Delete(&c).
+			Error; err != nil {
+			return fmt.Errorf("error deleting custom field %q: %w", c.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Delete soft-deletes the document type, and deletes its custom fields, in
+// database db. ErrDocumentTypeInUse is returned if documents have the type.
+func (d *DocumentType) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(d,
+		validation.Field(&d.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.
+			Unscoped().
+			Model(&Document{}).
+			Where("document_type_id = ?", d.ID).
+			Count(&count).
+			Error; err != nil {
+			return fmt.Errorf("error counting documents: %w", err)
+		}
+		if count > 0 {
+			return ErrDocumentTypeInUse
+		}
+
+		if err := tx.
+			Unscoped().
+			Where("document_type_id = ?", d.ID).
+			Delete(&DocumentTypeCustomField{}).
+			Error; err != nil {
+			return fmt.Errorf("error deleting custom fields: %w", err)
+		}
+
+		return tx.Delete(d).Error
+	})
+}
+
+// Seed creates the document type in database db if no document type with its
+// name exists, including deleted ones, so that seeding doesn't override changes
+// made since. Existing document types without a template file ID are given the
+// one of the receiver. Seed returns true if the document type was created.
+func (d *DocumentType) Seed(db *gorm.DB) (bool, error) {
+	var created bool
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing DocumentType
+		err := tx.
+			Unscoped().
+			Where(DocumentType{Name: d.Name}).
+			First(&existing).
+			Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			created = true
+			return d.Create(tx)
+
+		case err != nil:
+			return err
+
+		case existing.DeletedAt.Valid ||
+			existing.TemplateFileID != "" ||
+			d.TemplateFileID == "":
+			return nil
+
+		default:
+			return tx.
+				Model(&existing).
+				Update("template_file_id", d.TemplateFileID).
+				Error
+		}
+	})
+	return created, err
+}
+
+// Validate validates the document type.
+func (d *DocumentType) Validate() error {
+	if err := validation.ValidateStruct(d,
+		validation.Field(&d.Name,
+			validation.Required,
+			validation.By(func(interface{}) error {
+				if strings.Contains(d.Name, "/") {
+					return errors.New("must not contain \"/\"")
+				}
+				return nil
+			}),
+		),
+		validation.Field(&d.TemplateFileID, validation.Required),
+		validation.Field(&d.MoreInfoLinkURL, is.URL),
+	); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(d.CustomFields))
+	for _, c := range d.CustomFields {
+		if err := validation.ValidateStruct(&c,
+			validation.Field(&c.Name, validation.Required),
+			validation.Field(&c.Type, validation.Required, validation.In(
+				StringDocumentTypeCustomFieldType,
+				PersonDocumentTypeCustomFieldType,
+				PeopleDocumentTypeCustomFieldType,
+			).Error("must be a valid custom field type")),
+		); err != nil {
+			return fmt.Errorf("invalid custom field: %w", err)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("duplicate custom field %q", c.Name)
+		}
+		names[c.Name] = true
+	}
+
+	checks, err := d.GetChecks()
+	if err != nil {
+		return err
+	}
+	for _, c := range checks {
+		if c.Label == "" {
+			return errors.New("check label is required")
+		}
+		for _, l := range c.Links {
+			if err := validation.ValidateStruct(&l,
+				validation.Field(&l.Text, validation.Required),
+				validation.Field(&l.URL, validation.Required, is.URL),
+			); err != nil {
+				return fmt.Errorf("invalid check link: %w", err)
+			}
+		}
+	}
+
+	return d.ApprovalPolicy.Data.Validate()
+}
+
+// UpdateApprovalPolicy updates the approval policy of the document type in
+// database db, creating the document type if it doesn't exist.
+func (d *DocumentType) UpdateApprovalPolicy(db *gorm.DB) error {
+	if err := validation.ValidateStruct(d,
+		validation.Field(&d.Name, validation.Required),
+	); err != nil {
+		return err
+	}
+	if err := d.ApprovalPolicy.Data.Validate(); err != nil {
+		return err
+	}
+
+	policy := d.ApprovalPolicy
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := d.FirstOrCreate(tx); err != nil {
+			return err
+		}
+
+		d.ApprovalPolicy = policy
+		return tx.
+			Model(&d).
+			Update("approval_policy", policy).
+			Error
+	})
+}
+
+// UpdateIndexContent updates whether the content of documents of the document
+// type is indexed in database db, creating the document type if it doesn't
+// exist.
+func (d *DocumentType) UpdateIndexContent(db *gorm.DB) error {
+	if err := validation.ValidateStruct(d,
+		validation.Field(&d.Name, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	indexContent := d.IndexContent
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := d.FirstOrCreate(tx); err != nil {
+			return err
+		}
+
+		d.IndexContent = indexContent
+		return tx.
+			Model(&d).
+			Update("index_content", indexContent).
+			Error
+	})
+}
+
 // Upsert updates or inserts the receiver into database db.
 func (d *DocumentType) Upsert(db *gorm.DB) error {
 	if err := validation.ValidateStruct(d,
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"gorm.io/gorm"
@@ -27,6 +28,37 @@ const (
 	PeopleDocumentTypeCustomFieldType
 )
 
+// ParseDocumentTypeCustomFieldType returns the custom field type with name s
+// ("string", "person", or "people"), ignoring case.
+func ParseDocumentTypeCustomFieldType(
+	s string) (DocumentTypeCustomFieldType, error) {
+	switch strings.ToLower(s) {
+	case "string":
+		return StringDocumentTypeCustomFieldType, nil
+	case "person":
+		return PersonDocumentTypeCustomFieldType, nil
+	case "people":
+		return PeopleDocumentTypeCustomFieldType, nil
+	default:
+		return UnspecifiedDocumentTypeCustomFieldType,
+			fmt.Errorf("invalid custom field type %q", s)
+	}
+}
+
+// String returns the name of the custom field type.
+func (t DocumentTypeCustomFieldType) String() string {
+	switch t {
+	case StringDocumentTypeCustomFieldType:
+		return "string"
+	case PersonDocumentTypeCustomFieldType:
+		return "person"
+	case PeopleDocumentTypeCustomFieldType:
+		return "people"
+	default:
+		return "unspecified"
+	}
+}
+
 // Get gets a document type custom field from database db by name and document
 // type name, and assigns it to the receiver.
 func (d *DocumentTypeCustomField) Get(db *gorm.DB) error {
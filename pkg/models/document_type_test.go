@@ -129,4 +129,268 @@ func TestDocumentType(t *testing.T) {
 			assert.Equal(PeopleDocumentTypeCustomFieldType, d.CustomFields[2].Type)
 		})
 	})
+
+	t.Run("UpdateApprovalPolicy", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		// Create a document type without an approval policy.
+		d := DocumentType{
+			Name: "DT1",
+		}
+		err := d.FirstOrCreate(db)
+		require.NoError(err)
+		assert.True(d.ApprovalPolicy.Data.IsEmpty())
+
+		// Update the approval policy.
+		d = DocumentType{
+			Name: "DT1",
+		}
+		d.ApprovalPolicy.Data = ApprovalPolicy{
+			Stages: []ApprovalStage{
+				{Name: "Reviewers", MinApprovals: 2},
+			},
+		}
+		err = d.UpdateApprovalPolicy(db)
+		require.NoError(err)
+		assert.EqualValues(1, d.ID)
+
+		// Get the document type.
+		d = DocumentType{
+			Name: "DT1",
+		}
+		err = d.Get(db)
+		require.NoError(err)
+		require.Len(d.ApprovalPolicy.Data.Stages, 1)
+		assert.Equal("Reviewers", d.ApprovalPolicy.Data.Stages[0].Name)
+		assert.Equal(2, d.ApprovalPolicy.Data.Stages[0].MinApprovals)
+
+		// Update the approval policy of a new document type.
+		d = DocumentType{
+			Name: "DT2",
+		}
+		d.ApprovalPolicy.Data = ApprovalPolicy{
+			Stages: []ApprovalStage{
+				{Name: "Security", Approvers: []string{"a@example.com"}},
+			},
+		}
+		err = d.UpdateApprovalPolicy(db)
+		require.NoError(err)
+		assert.EqualValues(2, d.ID)
+
+		// Remove the approval policy.
+		d = DocumentType{
+			Name: "DT2",
+		}
+		err = d.UpdateApprovalPolicy(db)
+		require.NoError(err)
+		err = d.Get(db)
+		require.NoError(err)
+		assert.True(d.ApprovalPolicy.Data.IsEmpty())
+	})
+
+	t.Run("Create, Update, Delete, and Seed", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		// Create a document type.
+		d := DocumentType{
+			Name:             "DT1",
+			Description:      "Description 1",
+			TemplateFileID:   "template1",
+			MoreInfoLinkText: "More info",
+			MoreInfoLinkURL:  "https://example.com/dt1",
+			CustomFields: []DocumentTypeCustomField{
+				{Name: "Stakeholders", Type: PeopleDocumentTypeCustomFieldType},
+				{Name: "Target Version", Type: StringDocumentTypeCustomFieldType},
+			},
+		}
+		require.NoError(d.SetChecks([]DocumentTypeCheck{{Label: "Check 1"}}))
+		require.NoError(d.Create(db))
+		assert.NotZero(d.ID)
+		require.Len(d.CustomFields, 2)
+
+		// Update the document type, replacing a custom field.
+		d.Description = ""
+		d.TemplateFileID = "template2"
+		d.CustomFields = []DocumentTypeCustomField{
+			{Name: "Stakeholders", Type: PersonDocumentTypeCustomFieldType},
+			{Name: "PRD", Type: StringDocumentTypeCustomFieldType},
+		}
+		require.NoError(d.SetChecks(nil))
+		require.NoError(d.Update(db))
+
+		got := DocumentType{Name: "DT1"}
+		require.NoError(got.Get(db))
+		assert.Equal(d.ID, got.ID)
+		assert.Empty(got.Description)
+		assert.Equal("template2", got.TemplateFileID)
+		checks, err := got.GetChecks()
+		require.NoError(err)
+		assert.Empty(checks)
+		names := map[string]DocumentTypeCustomFieldType{}
+		for _, c := range got.CustomFields {
+			names[c.Name] = c.Type
+		}
+		assert.Equal(map[string]DocumentTypeCustomFieldType{
+			"Stakeholders": PersonDocumentTypeCustomFieldType,
+			"PRD":          StringDocumentTypeCustomFieldType,
+		}, names)
+
+		// Document types used by documents can't be renamed or deleted.
+		p := Product{Name: "Product1"}
+		require.NoError(p.FirstOrCreate(db))
+		doc := Document{
+			GoogleFileID: "fileID1",
+			DocumentType: DocumentType{Name: "DT1"},
+			Product:      Product{Name: "Product1"},
+		}
+		require.NoError(doc.Create(db))
+		d = got
+		d.Name = "DT2"
+		assert.ErrorIs(d.Update(db), ErrDocumentTypeInUse)
+		assert.ErrorIs(got.Delete(db), ErrDocumentTypeInUse)
+
+		// Delete a document type that isn't used.
+		unused := DocumentType{Name: "DT3", TemplateFileID: "template3"}
+		require.NoError(unused.Create(db))
+		require.NoError(unused.Delete(db))
+		assert.ErrorIs(
+			(&DocumentType{Name: "DT3"}).Get(db), gorm.ErrRecordNotFound)
+
+		// Seeding doesn't change existing or deleted document types.
+		seed := DocumentType{Name: "DT1", TemplateFileID: "template4"}
+		created, err := seed.Seed(db)
+		require.NoError(err)
+		assert.False(created)
+		seed = DocumentType{Name: "DT3", TemplateFileID: "template4"}
+		created, err = seed.Seed(db)
+		require.NoError(err)
+		assert.False(created)
+		assert.ErrorIs(
+			(&DocumentType{Name: "DT3"}).Get(db), gorm.ErrRecordNotFound)
+
+		// Seeding creates new document types.
+		seed = DocumentType{Name: "DT4", TemplateFileID: "template4"}
+		created, err = seed.Seed(db)
+		require.NoError(err)
+		assert.True(created)
+		got = DocumentType{Name: "DT4"}
+		require.NoError(got.Get(db))
+		assert.Equal("template4", got.TemplateFileID)
+
+		// Deleted document types are restored when created again.
+		restored := DocumentType{Name: "DT3", TemplateFileID: "template5"}
+		require.NoError(restored.Create(db))
+		assert.Equal(unused.ID, restored.ID)
+		assert.Equal("template5", restored.TemplateFileID)
+	})
+}
+
+func TestDocumentTypeValidate(t *testing.T) {
+	cases := map[string]struct {
+		d         DocumentType
+		shouldErr bool
+	}{
+		"valid": {
+			d: DocumentType{
+				Name:            "RFC",
+				TemplateFileID:  "template",
+				MoreInfoLinkURL: "https://example.com/rfc",
+				CustomFields: []DocumentTypeCustomField{
+					{Name: "Stakeholders", Type: PeopleDocumentTypeCustomFieldType},
+				},
+				Checks: []byte(`[{"label":"Check",` +
+					`"links":[{"text":"Link","url":"https://example.com"}]}]`),
+			},
+		},
+		"no name": {
+			d:         DocumentType{TemplateFileID: "template"},
+			shouldErr: true,
+		},
+		"name with slash": {
+			d:         DocumentType{Name: "RFC/PRD", TemplateFileID: "template"},
+			shouldErr: true,
+		},
+		"no template file ID": {
+			d:         DocumentType{Name: "RFC"},
+			shouldErr: true,
+		},
+		"invalid more info link URL": {
+			d: DocumentType{
+				Name:            "RFC",
+				TemplateFileID:  "template",
+				MoreInfoLinkURL: "not a URL",
+			},
+			shouldErr: true,
+		},
+		"custom field without type": {
+			d: DocumentType{
+				Name:           "RFC",
+				TemplateFileID: "template",
+				CustomFields: []DocumentTypeCustomField{
+					{Name: "Stakeholders"},
+				},
+			},
+			shouldErr: true,
+		},
+		"duplicate custom field": {
+			d: DocumentType{
+				Name:           "RFC",
+				TemplateFileID: "template",
+				CustomFields: []DocumentTypeCustomField{
+					{Name: "PRD", Type: StringDocumentTypeCustomFieldType},
+					{Name: "PRD", Type: StringDocumentTypeCustomFieldType},
+				},
+			},
+			shouldErr: true,
+		},
+		"check without label": {
+			d: DocumentType{
+				Name:           "RFC",
+				TemplateFileID: "template",
+				Checks:         []byte(`[{"helperText":"Help"}]`),
+			},
+			shouldErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := c.d.Validate()
+			if c.shouldErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDocumentTypeChecks(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	var d DocumentType
+	checks, err := d.GetChecks()
+	require.NoError(err)
+	assert.Nil(checks)
+
+	want := []DocumentTypeCheck{
+		{
+			Label:      "I have updated the status",
+			HelperText: "Set the status in the document header.",
+			Links: []DocumentTypeLink{
+				{Text: "Statuses", URL: "https://example.com/statuses"},
+			},
+		},
+	}
+	require.NoError(d.SetChecks(want))
+	checks, err = d.GetChecks()
+	require.NoError(err)
+	assert.Equal(want, checks)
+
+	require.NoError(d.SetChecks(nil))
+	assert.Nil(d.Checks)
 }
@@ -2,18 +2,36 @@ package models
 
 func ModelsToAutoMigrate() []interface{} {
 	return []interface{}{
+		&APIToken{},
 		&DocumentType{},
 		&Document{},
 		&DocumentCustomField{},
 		&DocumentReview{},
+		&AuditEvent{},
+		&DeferredNotification{},
 		&DocumentTypeCustomField{},
+		&IndexerFailure{},
 		&IndexerFolder{},
+		&IndexerLease{},
 		&IndexerMetadata{},
+		&NotificationPreference{},
 		&Product{},
 		&ProductLatestDocumentNumber{},
+		&QueuedNotification{},
+		&ReviewReminder{},
+		&ReviewSnooze{},
+		&RoleAssignment{},
+		&SavedSearch{},
+		&SavedSearchMatch{},
+		&Subscription{},
 		&User{},
 		&Team{},
 		&Project{},
 		&TeamProject{},
+		&SearchObject{},
+		&SlackChannel{},
+		&SlackChannelMessage{},
+		&Webhook{},
+		&WebhookDelivery{},
 	}
 }
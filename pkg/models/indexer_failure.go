@@ -0,0 +1,201 @@
+package models
+
+import (
+	"log"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	// indexerFailureInitialBackoff is the time to wait before retrying a document
+	// after its first failure.
+	indexerFailureInitialBackoff = time.Minute
+
+	// indexerFailureMaxBackoff is the maximum time to wait before retrying a
+	// document.
+	indexerFailureMaxBackoff = 24 * time.Hour
+)
+
+// IndexerFailure is a model for a document that the indexer failed to index.
+// Failed documents are retried with exponential backoff until they are indexed
+// successfully or ignored.
+type IndexerFailure struct {
+	gorm.Model
+
+	// GoogleFileID is the Google Drive file ID of the document.
+	GoogleFileID string `gorm:"default:null;not null;uniqueIndex"`
+
+	// FolderID is the Google Drive ID of the folder that the document was
+	// indexed from.
+	FolderID string
+
+	// Attempts is the number of failed attempts to index the document.
+	Attempts int `gorm:"default:0;not null"`
+
+	// LastError is the error of the last failed attempt.
+	LastError string
+
+	// LastAttemptAt is the time of the last failed attempt.
+	LastAttemptAt time.Time
+
+	// NextAttemptAt is the earliest time to retry indexing the document.
+	NextAttemptAt time.Time `gorm:"index"`
+
+	// Ignored is true if the document should no longer be retried.
+	Ignored bool `gorm:"default:false;not null"`
+}
+
+// IndexerFailures is a slice of indexer failures.
+type IndexerFailures []IndexerFailure
+
+// IndexerFailureBackoff returns the time to wait before retrying a document
+// that has failed to index attempts times.
+func IndexerFailureBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+
+	d := indexerFailureInitialBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= indexerFailureMaxBackoff {
+			return indexerFailureMaxBackoff
+		}
+	}
+	return d
+}
+
+// Get gets the indexer failure by the receiver's GoogleFileID from database db,
+// and assigns it to the receiver.
+func (f *IndexerFailure) Get(db *gorm.DB) error {
+	if err := validation.ValidateStruct(f,
+		validation.Field(&f.GoogleFileID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	// Don't log "record not found" errors (will still return the error).
+	tx := db.Session(&gorm.Session{Logger: logger.New(
+		log.Default(),
+		logger.Config{IgnoreRecordNotFoundError: true},
+	)})
+	return tx.
+		Where(IndexerFailure{GoogleFileID: f.GoogleFileID}).
+		First(&f).
+		Error
+}
+
+// RecordAttempt records a failed attempt at time now (with error attemptErr)
+// to index the document with the receiver's GoogleFileID in database db, and
+// schedules the next attempt.
+func (f *IndexerFailure) RecordAttempt(
+	db *gorm.DB, attemptErr error, now time.Time) error {
+	if err := validation.ValidateStruct(f,
+		validation.Field(&f.GoogleFileID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		folderID := f.FolderID
+		if err := tx.
+			Where(IndexerFailure{GoogleFileID: f.GoogleFileID}).
+			FirstOrInit(&f).
+			Error; err != nil {
+			return err
+		}
+
+		if folderID != "" {
+			f.FolderID = folderID
+		}
+		f.Attempts++
+		f.LastError = attemptErr.Error()
+		f.LastAttemptAt = now
+		f.NextAttemptAt = now.Add(IndexerFailureBackoff(f.Attempts))
+
+		return tx.Save(&f).Error
+	})
+}
+
+// Ignore stops retrying the document with the receiver's GoogleFileID.
+func (f *IndexerFailure) Ignore(db *gorm.DB) error {
+	return f.update(db, map[string]interface{}{
+		"ignored": true,
+	})
+}
+
+// Retry schedules the document with the receiver's GoogleFileID to be retried
+// at the next indexer run (after time now), even if it was ignored.
+func (f *IndexerFailure) Retry(db *gorm.DB, now time.Time) error {
+	return f.update(db, map[string]interface{}{
+		"ignored":         false,
+		"next_attempt_at": now,
+	})
+}
+
+// Resolve deletes the indexer failure with the receiver's GoogleFileID from
+// database db, after the document has been indexed successfully. It isn't an
+// error if no such failure exists.
+func (f *IndexerFailure) Resolve(db *gorm.DB) error {
+	if err := validation.ValidateStruct(f,
+		validation.Field(&f.GoogleFileID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	// Permanently delete the failure so that it can be recreated for the same
+	// document.
+	return db.
+		Unscoped().
+		Where(IndexerFailure{GoogleFileID: f.GoogleFileID}).
+		Delete(&IndexerFailure{}).
+		Error
+}
+
+// Find finds all indexer failures from database db, including ignored failures
+// if includeIgnored is true, and assigns them to the receiver.
+func (fs *IndexerFailures) Find(db *gorm.DB, includeIgnored bool) error {
+	tx := db.Order("next_attempt_at")
+	if !includeIgnored {
+		tx = tx.Where("ignored = ?", false)
+	}
+	return tx.Find(&fs).Error
+}
+
+// FindDue finds all indexer failures from database db that are due to be
+// retried at time now, and assigns them to the receiver.
+func (fs *IndexerFailures) FindDue(db *gorm.DB, now time.Time) error {
+	return db.
+		Where("ignored = ? AND next_attempt_at <= ?", false, now).
+		Order("next_attempt_at").
+		Find(&fs).
+		Error
+}
+
+// update updates columns of the indexer failure with the receiver's
+// GoogleFileID in database db, and gets the updated failure. It returns
+// gorm.ErrRecordNotFound if no such failure exists.
+func (f *IndexerFailure) update(
+	db *gorm.DB, columns map[string]interface{}) error {
+	if err := validation.ValidateStruct(f,
+		validation.Field(&f.GoogleFileID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	res := db.
+		Model(&IndexerFailure{}).
+		Where(IndexerFailure{GoogleFileID: f.GoogleFileID}).
+		Updates(columns)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return f.Get(db)
+}
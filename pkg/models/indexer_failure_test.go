@@ -0,0 +1,112 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestIndexerFailureBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:   0,
+		1:   time.Minute,
+		2:   2 * time.Minute,
+		3:   4 * time.Minute,
+		10:  512 * time.Minute,
+		11:  1024 * time.Minute,
+		12:  24 * time.Hour,
+		100: 24 * time.Hour,
+	}
+
+	for attempts, want := range cases {
+		assert.Equal(t, want, IndexerFailureBackoff(attempts), attempts)
+	}
+}
+
+func TestIndexerFailure(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("RecordAttempt, Retry, Ignore, and Resolve", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		time1 := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		// Get failure, which won't exist yet (should error).
+		f := IndexerFailure{GoogleFileID: "fileID1"}
+		err := f.Get(db)
+		require.ErrorIs(err, gorm.ErrRecordNotFound)
+
+		// Record the first attempt.
+		f = IndexerFailure{
+			GoogleFileID: "fileID1",
+			FolderID:     "folderID1",
+		}
+		err = f.RecordAttempt(db, errors.New("error1"), time1)
+		require.NoError(err)
+		assert.Equal(1, f.Attempts)
+		assert.Equal("error1", f.LastError)
+		assert.Equal("folderID1", f.FolderID)
+		assert.Equal(time1.Add(time.Minute), f.NextAttemptAt)
+
+		// Record the second attempt.
+		f = IndexerFailure{GoogleFileID: "fileID1"}
+		err = f.RecordAttempt(db, errors.New("error2"), time1)
+		require.NoError(err)
+		assert.Equal(2, f.Attempts)
+		assert.Equal("error2", f.LastError)
+		assert.Equal("folderID1", f.FolderID)
+		assert.Equal(time1.Add(2*time.Minute), f.NextAttemptAt.UTC())
+
+		// The failure isn't due yet.
+		var fs IndexerFailures
+		require.NoError(fs.FindDue(db, time1))
+		assert.Len(fs, 0)
+		require.NoError(fs.FindDue(db, time1.Add(2*time.Minute)))
+		assert.Len(fs, 1)
+
+		// Ignore the failure.
+		f = IndexerFailure{GoogleFileID: "fileID1"}
+		require.NoError(f.Ignore(db))
+		assert.True(f.Ignored)
+		fs = nil
+		require.NoError(fs.FindDue(db, time1.Add(time.Hour)))
+		assert.Len(fs, 0)
+		require.NoError(fs.Find(db, false))
+		assert.Len(fs, 0)
+		require.NoError(fs.Find(db, true))
+		assert.Len(fs, 1)
+
+		// Retry the failure.
+		f = IndexerFailure{GoogleFileID: "fileID1"}
+		require.NoError(f.Retry(db, time1))
+		assert.False(f.Ignored)
+		fs = nil
+		require.NoError(fs.FindDue(db, time1))
+		assert.Len(fs, 1)
+
+		// Retry a failure that doesn't exist.
+		f = IndexerFailure{GoogleFileID: "fileID2"}
+		assert.ErrorIs(f.Retry(db, time1), gorm.ErrRecordNotFound)
+
+		// Resolve the failure.
+		f = IndexerFailure{GoogleFileID: "fileID1"}
+		require.NoError(f.Resolve(db))
+		err = f.Get(db)
+		require.ErrorIs(err, gorm.ErrRecordNotFound)
+
+		// A new failure can be recorded for the same document.
+		f = IndexerFailure{GoogleFileID: "fileID1"}
+		require.NoError(f.RecordAttempt(db, errors.New("error3"), time1))
+		assert.Equal(1, f.Attempts)
+	})
+}
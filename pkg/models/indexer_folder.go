@@ -19,6 +19,11 @@ type IndexerFolder struct {
 
 	// LastIndexedAt is the time that the folder was last indexed.
 	LastIndexedAt time.Time
+
+	// ChangesPageToken is the Google Drive Changes API page token for listing
+	// changes since the folder was last indexed, if the indexer uses the Changes
+	// API.
+	ChangesPageToken string
 }
 
 // Get gets the indexer folder and assigns it to the receiver.
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"log"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// IndexerLease is a model for a lease held by an indexer replica, which is used
+// to elect a single replica as the leader.
+type IndexerLease struct {
+	// Name is the name of the lease.
+	Name string `gorm:"primaryKey"`
+
+	// Holder is the ID of the replica holding the lease.
+	Holder string `gorm:"default:null;not null"`
+
+	// ExpiresAt is the time that the lease expires unless it is renewed.
+	ExpiresAt time.Time `gorm:"not null"`
+
+	// UpdatedAt is the time that the lease was last acquired or renewed.
+	UpdatedAt time.Time
+}
+
+// Acquire acquires or renews the lease by the receiver's Name for holder
+// holder until time now plus duration ttl, if it is unheld, expired, or already
+// held by holder. It returns true if the lease was acquired, and assigns the
+// current lease to the receiver.
+func (l *IndexerLease) Acquire(
+	db *gorm.DB, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	if err := validation.ValidateStruct(l,
+		validation.Field(&l.Name, validation.Required),
+	); err != nil {
+		return false, err
+	}
+
+	lease := IndexerLease{
+		Name:      l.Name,
+		Holder:    holder,
+		ExpiresAt: now.Add(ttl),
+		UpdatedAt: now,
+	}
+
+	// Insert the lease, or take it over if it is expired or already held by
+	// holder, in a single statement so that only one replica can acquire it.
+	tx := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns(
+			[]string{"holder", "expires_at", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Or(
+				clause.Eq{
+					Column: clause.Column{Table: "indexer_leases", Name: "holder"},
+					Value:  holder,
+				},
+				clause.Lt{
+					Column: clause.Column{Table: "indexer_leases", Name: "expires_at"},
+					Value:  now,
+				},
+			),
+		}},
+	}).Create(&lease)
+	if err := tx.Error; err != nil {
+		return false, err
+	}
+	acquired := tx.RowsAffected == 1
+
+	if err := l.Get(db); err != nil {
+		return false, err
+	}
+	return acquired && l.Holder == holder, nil
+}
+
+// Get gets the lease by the receiver's Name from database db, and assigns it to
+// the receiver.
+func (l *IndexerLease) Get(db *gorm.DB) error {
+	if err := validation.ValidateStruct(l,
+		validation.Field(&l.Name, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	// Don't log "record not found" errors (will still return the error).
+	tx := db.Session(&gorm.Session{Logger: logger.New(
+		log.Default(),
+		logger.Config{IgnoreRecordNotFoundError: true},
+	)})
+	return tx.
+		Where(IndexerLease{Name: l.Name}).
+		First(&l).
+		Error
+}
+
+// Release releases the lease by the receiver's Name if it is held by holder, so
+// that another replica can acquire it without waiting for it to expire.
+func (l *IndexerLease) Release(db *gorm.DB, holder string) error {
+	if err := validation.ValidateStruct(l,
+		validation.Field(&l.Name, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Model(&IndexerLease{}).
+		Where("name = ? AND holder = ?", l.Name, holder).
+		Update("expires_at", time.Unix(0, 0).UTC()).
+		Error
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestIndexerLease(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Acquire, renew, take over, and Release", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		time1 := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		ttl := 30 * time.Second
+
+		// Get lease, which won't exist yet (should error).
+		l := IndexerLease{Name: "indexer"}
+		require.ErrorIs(l.Get(db), gorm.ErrRecordNotFound)
+
+		// Acquire the lease.
+		ok, err := l.Acquire(db, "replica1", time1, ttl)
+		require.NoError(err)
+		assert.True(ok)
+		assert.Equal("replica1", l.Holder)
+		assert.Equal(time1.Add(ttl), l.ExpiresAt.UTC())
+
+		// Another replica can't acquire the lease before it expires.
+		l = IndexerLease{Name: "indexer"}
+		ok, err = l.Acquire(db, "replica2", time1.Add(10*time.Second), ttl)
+		require.NoError(err)
+		assert.False(ok)
+		assert.Equal("replica1", l.Holder)
+
+		// The holder can renew the lease.
+		l = IndexerLease{Name: "indexer"}
+		ok, err = l.Acquire(db, "replica1", time1.Add(20*time.Second), ttl)
+		require.NoError(err)
+		assert.True(ok)
+		assert.Equal(time1.Add(50*time.Second), l.ExpiresAt.UTC())
+
+		// Another replica can take over the lease after it expires.
+		l = IndexerLease{Name: "indexer"}
+		ok, err = l.Acquire(db, "replica2", time1.Add(51*time.Second), ttl)
+		require.NoError(err)
+		assert.True(ok)
+		assert.Equal("replica2", l.Holder)
+
+		// Releasing a lease held by another replica has no effect.
+		l = IndexerLease{Name: "indexer"}
+		require.NoError(l.Release(db, "replica1"))
+		ok, err = l.Acquire(db, "replica1", time1.Add(52*time.Second), ttl)
+		require.NoError(err)
+		assert.False(ok)
+
+		// Released leases can be acquired by another replica.
+		l = IndexerLease{Name: "indexer"}
+		require.NoError(l.Release(db, "replica2"))
+		ok, err = l.Acquire(db, "replica1", time1.Add(53*time.Second), ttl)
+		require.NoError(err)
+		assert.True(ok)
+		assert.Equal("replica1", l.Holder)
+	})
+}
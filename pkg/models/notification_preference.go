@@ -0,0 +1,279 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationPreference is a model for how a user wants to be notified of
+// events that concern them directly, such as review requests.
+type NotificationPreference struct {
+	ID uint `gorm:"primaryKey"`
+
+	// CreatedAt is the time the preference was created.
+	CreatedAt time.Time
+
+	// UpdatedAt is the time the preference was last updated.
+	UpdatedAt time.Time
+
+	// User is the user that the preference belongs to.
+	User   User
+	UserID uint `gorm:"not null;uniqueIndex"`
+
+	// Channels are the channels to notify the user through, keyed by event
+	// type. Events without an entry are sent through all channels, and events
+	// with an empty list aren't sent.
+	Channels datatypes.JSONType[map[NotificationEventType][]NotificationChannel] `gorm:"not null"`
+
+	// QuietHoursStart is the time of day ("HH:MM") that the user's quiet hours
+	// start. Notifications during quiet hours are delivered when they end.
+	// Quiet hours are disabled if empty.
+	QuietHoursStart string
+
+	// QuietHoursEnd is the time of day ("HH:MM") that the user's quiet hours
+	// end.
+	QuietHoursEnd string
+
+	// TimeZone is the IANA time zone (e.g., "Asia/Kolkata") of the quiet hours.
+	// UTC is used if empty.
+	TimeZone string
+}
+
+// NotificationEventType is the type of an event that a user can be notified
+// of.
+type NotificationEventType string
+
+const (
+	// ReviewRequestedNotificationEvent is the user being requested to review a
+	// document.
+	ReviewRequestedNotificationEvent NotificationEventType = "reviewRequested"
+
+	// ContributorInvitedNotificationEvent is the user being invited to
+	// contribute to a draft.
+	ContributorInvitedNotificationEvent NotificationEventType = "contributorInvited"
+
+	// DocumentPublishedNotificationEvent is a document that the user is
+	// subscribed to being published.
+	DocumentPublishedNotificationEvent NotificationEventType = "documentPublished"
+
+	// ApprovalReceivedNotificationEvent is a reviewer approving a document that
+	// the user owns.
+	ApprovalReceivedNotificationEvent NotificationEventType = "approvalReceived"
+
+	// DueSoonNotificationEvent is a review assigned to the user being due soon
+	// or overdue.
+	DueSoonNotificationEvent NotificationEventType = "dueSoon"
+
+	// DigestNotificationEvent is a digest of the document events that the user
+	// is subscribed to being sent.
+	DigestNotificationEvent NotificationEventType = "digest"
+
+	// SavedSearchMatchedNotificationEvent is documents newly matching one of
+	// the user's saved searches.
+	SavedSearchMatchedNotificationEvent NotificationEventType = "savedSearchMatched"
+)
+
+// NotificationEventTypes are all notification event types.
+var NotificationEventTypes = []NotificationEventType{
+	ReviewRequestedNotificationEvent,
+	ContributorInvitedNotificationEvent,
+	DocumentPublishedNotificationEvent,
+	ApprovalReceivedNotificationEvent,
+	DueSoonNotificationEvent,
+	DigestNotificationEvent,
+	SavedSearchMatchedNotificationEvent,
+}
+
+// Validate returns an error if the notification event type is unknown.
+func (e NotificationEventType) Validate() error {
+	for _, v := range NotificationEventTypes {
+		if e == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid notification event type %q", e)
+}
+
+// NotificationChannel is a medium that users can be notified through.
+type NotificationChannel string
+
+const (
+	// EmailNotificationChannel notifies users by email.
+	EmailNotificationChannel NotificationChannel = "email"
+
+	// SlackNotificationChannel notifies users by Slack direct message.
+	SlackNotificationChannel NotificationChannel = "slack"
+)
+
+// NotificationChannels are all notification channels.
+var NotificationChannels = []NotificationChannel{
+	EmailNotificationChannel,
+	SlackNotificationChannel,
+}
+
+// Validate returns an error if the notification channel is unknown.
+func (c NotificationChannel) Validate() error {
+	for _, v := range NotificationChannels {
+		if c == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid notification channel %q", c)
+}
+
+// timeOfDayRE matches a time of day in 24-hour "HH:MM" format.
+var timeOfDayRE = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+// Validate validates the notification preference.
+func (p NotificationPreference) Validate() error {
+	for e, chs := range p.Channels.Data {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+		for _, ch := range chs {
+			if err := ch.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.QuietHoursStart,
+			validation.Match(timeOfDayRE).Error("must be a time in HH:MM format"),
+			validation.When(p.QuietHoursEnd != "", validation.Required),
+			validation.When(p.QuietHoursEnd != "",
+				validation.NotIn(p.QuietHoursEnd).Error(
+					"must be different from the end of quiet hours")),
+		),
+		validation.Field(&p.QuietHoursEnd,
+			validation.Match(timeOfDayRE).Error("must be a time in HH:MM format"),
+			validation.When(p.QuietHoursStart != "", validation.Required),
+		),
+		validation.Field(&p.TimeZone, validation.By(func(interface{}) error {
+			_, err := time.LoadLocation(p.TimeZone)
+			return err
+		})),
+	)
+}
+
+// Get gets the notification preference of the user with the receiver's
+// User.EmailAddress from database db, and assigns it to the receiver. It
+// returns gorm.ErrRecordNotFound if the user hasn't set any preferences.
+func (p *NotificationPreference) Get(db *gorm.DB) error {
+	if p.User.EmailAddress == "" {
+		return errors.New("user email address is required")
+	}
+
+	return db.
+		Joins("JOIN users ON users.id = notification_preferences.user_id").
+		Where("users.email_address = ?", p.User.EmailAddress).
+		First(&p).
+		Error
+}
+
+// Upsert creates or replaces the notification preference of the user with the
+// receiver's User.EmailAddress in database db, creating the user if it doesn't
+// exist.
+func (p *NotificationPreference) Upsert(db *gorm.DB) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.User.EmailAddress == "" {
+		return errors.New("user email address is required")
+	}
+	if p.Channels.Data == nil {
+		p.Channels.Data = map[NotificationEventType][]NotificationChannel{}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := p.User.FirstOrCreate(tx); err != nil {
+			return fmt.Errorf("error getting user: %w", err)
+		}
+		p.UserID = p.User.ID
+
+		return tx.
+			Omit("User").
+			Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "user_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"channels",
+					"quiet_hours_start",
+					"quiet_hours_end",
+					"time_zone",
+					"updated_at",
+				}),
+			}).
+			Create(&p).
+			Error
+	})
+}
+
+// ChannelsFor returns the channels to notify the user through for events of
+// type e.
+func (p NotificationPreference) ChannelsFor(
+	e NotificationEventType) []NotificationChannel {
+	if chs, ok := p.Channels.Data[e]; ok {
+		return chs
+	}
+	return NotificationChannels
+}
+
+// QuietHoursEndAfter returns the time that the user's quiet hours end, if time
+// now is during quiet hours, or the zero time otherwise.
+func (p NotificationPreference) QuietHoursEndAfter(now time.Time) time.Time {
+	if p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return time.Time{}
+	}
+	start, err := minuteOfDay(p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}
+	}
+	end, err := minuteOfDay(p.QuietHoursEnd)
+	if err != nil {
+		return time.Time{}
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.Time{}
+	}
+
+	now = now.In(loc)
+	m := now.Hour()*60 + now.Minute()
+
+	var quiet bool
+	if start < end {
+		quiet = m >= start && m < end
+	} else {
+		// Quiet hours span midnight.
+		quiet = m >= start || m < end
+	}
+	if !quiet {
+		return time.Time{}
+	}
+
+	endTime := time.Date(now.Year(), now.Month(), now.Day(),
+		end/60, end%60, 0, 0, loc)
+	if m >= end {
+		endTime = endTime.AddDate(0, 0, 1)
+	}
+	return endTime
+}
+
+// minuteOfDay returns the minute of the day of time of day s in "HH:MM"
+// format.
+func minuteOfDay(s string) (int, error) {
+	if !timeOfDayRE.MatchString(s) {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	h, _ := strconv.Atoi(s[:2])
+	m, _ := strconv.Atoi(s[3:])
+	return h*60 + m, nil
+}
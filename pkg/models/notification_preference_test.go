@@ -0,0 +1,273 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestNotificationPreferenceQuietHoursEndAfter(t *testing.T) {
+	cases := map[string]struct {
+		start string
+		end   string
+		now   time.Time
+		want  time.Time
+	}{
+		"disabled": {
+			now: time.Date(2023, 5, 10, 23, 0, 0, 0, time.UTC),
+		},
+		"before quiet hours": {
+			start: "09:00",
+			end:   "17:00",
+			now:   time.Date(2023, 5, 10, 8, 59, 0, 0, time.UTC),
+		},
+		"during quiet hours": {
+			start: "09:00",
+			end:   "17:00",
+			now:   time.Date(2023, 5, 10, 9, 0, 0, 0, time.UTC),
+			want:  time.Date(2023, 5, 10, 17, 0, 0, 0, time.UTC),
+		},
+		"end of quiet hours": {
+			start: "09:00",
+			end:   "17:00",
+			now:   time.Date(2023, 5, 10, 17, 0, 0, 0, time.UTC),
+		},
+		"spanning midnight, before midnight": {
+			start: "22:00",
+			end:   "07:00",
+			now:   time.Date(2023, 5, 10, 23, 30, 0, 0, time.UTC),
+			want:  time.Date(2023, 5, 11, 7, 0, 0, 0, time.UTC),
+		},
+		"spanning midnight, after midnight": {
+			start: "22:00",
+			end:   "07:00",
+			now:   time.Date(2023, 5, 11, 6, 0, 0, 0, time.UTC),
+			want:  time.Date(2023, 5, 11, 7, 0, 0, 0, time.UTC),
+		},
+		"spanning midnight, outside quiet hours": {
+			start: "22:00",
+			end:   "07:00",
+			now:   time.Date(2023, 5, 11, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := NotificationPreference{
+				QuietHoursStart: c.start,
+				QuietHoursEnd:   c.end,
+			}
+			got := p.QuietHoursEndAfter(c.now)
+			if c.want.IsZero() {
+				assert.True(t, got.IsZero(), "got %v", got)
+			} else {
+				assert.True(t, c.want.Equal(got), "want %v, got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestNotificationPreferenceValidate(t *testing.T) {
+	cases := map[string]struct {
+		p         NotificationPreference
+		shouldErr bool
+	}{
+		"empty": {},
+		"valid": {
+			p: NotificationPreference{
+				Channels: datatypes.JSONType[map[NotificationEventType][]NotificationChannel]{
+					Data: map[NotificationEventType][]NotificationChannel{
+						ReviewRequestedNotificationEvent: {SlackNotificationChannel},
+						DueSoonNotificationEvent:         {},
+					},
+				},
+				QuietHoursStart: "22:00",
+				QuietHoursEnd:   "07:00",
+				TimeZone:        "UTC",
+			},
+		},
+		"unknown event": {
+			p: NotificationPreference{
+				Channels: datatypes.JSONType[map[NotificationEventType][]NotificationChannel]{
+					Data: map[NotificationEventType][]NotificationChannel{
+						"commented": {EmailNotificationChannel},
+					},
+				},
+			},
+			shouldErr: true,
+		},
+		"unknown channel": {
+			p: NotificationPreference{
+				Channels: datatypes.JSONType[map[NotificationEventType][]NotificationChannel]{
+					Data: map[NotificationEventType][]NotificationChannel{
+						ReviewRequestedNotificationEvent: {"pager"},
+					},
+				},
+			},
+			shouldErr: true,
+		},
+		"start without end": {
+			p: NotificationPreference{
+				QuietHoursStart: "22:00",
+			},
+			shouldErr: true,
+		},
+		"invalid time of day": {
+			p: NotificationPreference{
+				QuietHoursStart: "10pm",
+				QuietHoursEnd:   "07:00",
+			},
+			shouldErr: true,
+		},
+		"same start and end": {
+			p: NotificationPreference{
+				QuietHoursStart: "07:00",
+				QuietHoursEnd:   "07:00",
+			},
+			shouldErr: true,
+		},
+		"unknown time zone": {
+			p: NotificationPreference{
+				QuietHoursStart: "22:00",
+				QuietHoursEnd:   "07:00",
+				TimeZone:        "Mars/Olympus_Mons",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := c.p.Validate()
+			if c.shouldErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNotificationPreferenceModel(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Upsert and Get", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		// Users without preferences aren't found.
+		p := NotificationPreference{
+			User: User{EmailAddress: "a@example.com"},
+		}
+		assert.True(errors.Is(p.Get(db), gorm.ErrRecordNotFound))
+		assert.Equal(NotificationChannels,
+			p.ChannelsFor(ReviewRequestedNotificationEvent))
+
+		p.Channels.Data = map[NotificationEventType][]NotificationChannel{
+			ReviewRequestedNotificationEvent: {SlackNotificationChannel},
+			DueSoonNotificationEvent:         {},
+		}
+		p.QuietHoursStart = "22:00"
+		p.QuietHoursEnd = "07:00"
+		p.TimeZone = "UTC"
+		require.NoError(p.Upsert(db))
+		assert.NotZero(p.ID)
+
+		got := NotificationPreference{
+			User: User{EmailAddress: "a@example.com"},
+		}
+		require.NoError(got.Get(db))
+		assert.Equal(p.ID, got.ID)
+		assert.Equal([]NotificationChannel{SlackNotificationChannel},
+			got.ChannelsFor(ReviewRequestedNotificationEvent))
+		assert.Empty(got.ChannelsFor(DueSoonNotificationEvent))
+		assert.Equal(NotificationChannels,
+			got.ChannelsFor(ApprovalReceivedNotificationEvent))
+		assert.Equal("22:00", got.QuietHoursStart)
+
+		// Upserting again replaces the preference.
+		p2 := NotificationPreference{
+			User: User{EmailAddress: "a@example.com"},
+		}
+		require.NoError(p2.Upsert(db))
+		got = NotificationPreference{
+			User: User{EmailAddress: "a@example.com"},
+		}
+		require.NoError(got.Get(db))
+		assert.Equal(p.ID, got.ID)
+		assert.Equal(NotificationChannels,
+			got.ChannelsFor(ReviewRequestedNotificationEvent))
+		assert.Empty(got.QuietHoursStart)
+	})
+
+	t.Run("Create and claim deferred notifications", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		now := time.Date(2023, 5, 10, 23, 0, 0, 0, time.UTC)
+		due := DeferredNotification{
+			User:      User{EmailAddress: "a@example.com"},
+			Event:     ReviewRequestedNotificationEvent,
+			Subject:   "Due",
+			DeliverAt: now.Add(-time.Minute),
+		}
+		due.Channels.Data = []NotificationChannel{EmailNotificationChannel}
+		require.NoError(due.Create(db))
+		later := DeferredNotification{
+			User:      User{EmailAddress: "b@example.com"},
+			Event:     DueSoonNotificationEvent,
+			Subject:   "Later",
+			DeliverAt: now.Add(time.Hour),
+		}
+		later.Channels.Data = []NotificationChannel{SlackNotificationChannel}
+		require.NoError(later.Create(db))
+
+		lease := 15 * time.Minute
+		ns, err := ClaimDueDeferredNotifications(db, now, lease)
+		require.NoError(err)
+		require.Len(ns, 1)
+		assert.Equal("Due", ns[0].Subject)
+		assert.Equal("a@example.com", ns[0].User.EmailAddress)
+		assert.Equal([]NotificationChannel{EmailNotificationChannel},
+			ns[0].Channels.Data)
+		claimed := ns[0]
+
+		// Claimed notifications are skipped until the lease expires.
+		ns, err = ClaimDueDeferredNotifications(db, now, lease)
+		require.NoError(err)
+		assert.Empty(ns)
+
+		ns, err = ClaimDueDeferredNotifications(db, now.Add(lease), lease)
+		require.NoError(err)
+		require.Len(ns, 1)
+		assert.Equal("Due", ns[0].Subject)
+
+		// Updated notifications are claimed again at their new delivery time.
+		claimed.Attempts = 1
+		claimed.DeliverAt = now.Add(30 * time.Minute)
+		require.NoError(claimed.Update(db))
+		ns, err = ClaimDueDeferredNotifications(
+			db, now.Add(30*time.Minute), lease)
+		require.NoError(err)
+		require.Len(ns, 1)
+		assert.Equal(1, ns[0].Attempts)
+
+		// Deleted notifications aren't claimed again.
+		require.NoError(claimed.Delete(db))
+		ns, err = ClaimDueDeferredNotifications(db, now.Add(time.Hour), lease)
+		require.NoError(err)
+		require.Len(ns, 1)
+		assert.Equal("Later", ns[0].Subject)
+	})
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// QueuedNotification is a model for a notification of a document event that is
+// queued for a user's next digest email. Document fields are stored as of the
+// time of the event.
+type QueuedNotification struct {
+	ID uint `gorm:"primaryKey"`
+
+	// CreatedAt is the time the notification was queued.
+	CreatedAt time.Time
+
+	// User is the user to notify.
+	User   User
+	UserID uint `gorm:"not null;index"`
+
+	// Event is the type of document event.
+	Event SubscriptionEventType `gorm:"default:null;not null"`
+
+	// GoogleFileID is the Google Drive file ID of the document.
+	GoogleFileID string `gorm:"default:null;not null"`
+
+	// DocumentNumber is the document number (e.g., "PAYMT-042").
+	DocumentNumber string
+
+	// DocumentTitle is the title of the document.
+	DocumentTitle string
+
+	// DocumentType is the document type.
+	DocumentType string
+
+	// DocumentURL is the URL of the document in the application.
+	DocumentURL string
+
+	// Owner is the email address of the document owner.
+	Owner string
+
+	// Product is the product (business unit) of the document.
+	Product string
+
+	// Team is the team of the document.
+	Team string
+
+	// Commenters are the names of the commenters, for commented events.
+	Commenters datatypes.JSONType[[]string] `gorm:"not null"`
+}
+
+// QueuedNotifications is a slice of queued notifications.
+type QueuedNotifications []QueuedNotification
+
+// Create queues the notification in database db for the user with the
+// receiver's User.EmailAddress.
+func (n *QueuedNotification) Create(db *gorm.DB) error {
+	if err := validation.ValidateStruct(n,
+		validation.Field(&n.Event, validation.Required),
+		validation.Field(&n.GoogleFileID, validation.Required),
+	); err != nil {
+		return err
+	}
+	if n.User.EmailAddress == "" {
+		return errors.New("user email address is required")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := n.User.FirstOrCreate(tx); err != nil {
+			return fmt.Errorf("error getting user: %w", err)
+		}
+		n.UserID = n.User.ID
+
+		return tx.
+			Omit("User").
+			Create(&n).
+			Error
+	})
+}
+
+// FindByUserID finds all notifications queued for the user with ID userID in
+// database db, in the order they were queued, and assigns them to the
+// receiver.
+func (n *QueuedNotifications) FindByUserID(db *gorm.DB, userID uint) error {
+	return db.
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&n).
+		Error
+}
+
+// Delete deletes the notifications in the receiver from database db.
+func (n QueuedNotifications) Delete(db *gorm.DB) error {
+	if len(n) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(n))
+	for i, qn := range n {
+		ids[i] = qn.ID
+	}
+	return db.Delete(&QueuedNotification{}, ids).Error
+}
+
+// FindUsersWithQueuedNotifications returns all users in database db that have
+// queued notifications.
+func FindUsersWithQueuedNotifications(db *gorm.DB) ([]User, error) {
+	var users []User
+	if err := db.
+		Where("id IN (?)", db.Model(&QueuedNotification{}).Select("user_id")).
+		Order("id").
+		Find(&users).
+		Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// RecordDigestSent records that a digest email was sent to the user with ID
+// userID at time sentAt, and deletes the notifications it included, in
+// database db.
+func RecordDigestSent(
+	db *gorm.DB, userID uint, ns QueuedNotifications, sentAt time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := ns.Delete(tx); err != nil {
+			return fmt.Errorf("error deleting queued notifications: %w", err)
+		}
+		return tx.
+			Model(&User{}).
+			Where("id = ?", userID).
+			UpdateColumn("last_digest_sent_at", sentAt).
+			Error
+	})
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestFrequencyValidate(t *testing.T) {
+	for _, f := range DigestFrequencies {
+		assert.NoError(t, f.Validate())
+	}
+	assert.Error(t, DigestFrequency("hourly").Validate())
+	assert.Error(t, DigestFrequency("").Validate())
+
+	assert.False(t, ImmediateDigestFrequency.IsDigest())
+	assert.True(t, DailyDigestFrequency.IsDigest())
+	assert.True(t, WeeklyDigestFrequency.IsDigest())
+}
+
+func TestQueuedNotification(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("UpdateDigestFrequency, Create, Find, and RecordDigestSent",
+		func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			db, tearDownTest := setupTest(t, dsn)
+			defer tearDownTest(t)
+
+			// Users default to immediate emails.
+			freqs, err := GetDigestFrequencies(db,
+				[]string{"a@example.com", "B@example.com"})
+			require.NoError(err)
+			assert.Equal(map[string]DigestFrequency{
+				"a@example.com": ImmediateDigestFrequency,
+				"b@example.com": ImmediateDigestFrequency,
+			}, freqs)
+
+			// Invalid frequencies are rejected.
+			u := User{
+				EmailAddress:    "a@example.com",
+				DigestFrequency: "hourly",
+			}
+			require.Error(u.UpdateDigestFrequency(db))
+
+			u.DigestFrequency = DailyDigestFrequency
+			require.NoError(u.UpdateDigestFrequency(db))
+			freqs, err = GetDigestFrequencies(db, []string{"a@example.com"})
+			require.NoError(err)
+			assert.Equal(DailyDigestFrequency, freqs["a@example.com"])
+
+			// Queue notifications.
+			for _, e := range []SubscriptionEventType{
+				PublishedSubscriptionEvent, ApprovedSubscriptionEvent,
+			} {
+				n := QueuedNotification{
+					User:         User{EmailAddress: "a@example.com"},
+					Event:        e,
+					GoogleFileID: "fileID1",
+					Product:      "Payments",
+				}
+				require.NoError(n.Create(db))
+				assert.NotZero(n.ID)
+			}
+
+			// Notifications without an event are rejected.
+			n := QueuedNotification{
+				User:         User{EmailAddress: "a@example.com"},
+				GoogleFileID: "fileID1",
+			}
+			require.Error(n.Create(db))
+
+			users, err := FindUsersWithQueuedNotifications(db)
+			require.NoError(err)
+			require.Len(users, 1)
+			assert.Equal("a@example.com", users[0].EmailAddress)
+			assert.Equal(DailyDigestFrequency, users[0].DigestFrequency)
+			assert.Nil(users[0].LastDigestSentAt)
+
+			var ns QueuedNotifications
+			require.NoError(ns.FindByUserID(db, users[0].ID))
+			require.Len(ns, 2)
+			assert.Equal(PublishedSubscriptionEvent, ns[0].Event)
+			assert.Equal(ApprovedSubscriptionEvent, ns[1].Event)
+
+			// Record digest.
+			sentAt := time.Now().UTC().Truncate(time.Second)
+			require.NoError(RecordDigestSent(db, users[0].ID, ns, sentAt))
+
+			users, err = FindUsersWithQueuedNotifications(db)
+			require.NoError(err)
+			assert.Empty(users)
+
+			u = User{EmailAddress: "a@example.com"}
+			require.NoError(u.Get(db))
+			require.NotNil(u.LastDigestSentAt)
+			assert.WithinDuration(sentAt, *u.LastDigestSentAt, time.Second)
+		})
+}
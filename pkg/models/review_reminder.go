@@ -0,0 +1,108 @@
+package models
+
+import (
+	"log"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ReviewReminder is a model for a reminder sent to a reviewer of a document
+// that has a due date.
+type ReviewReminder struct {
+	gorm.Model
+
+	// Document is the document that the reminder was sent for.
+	Document   Document
+	DocumentID uint `gorm:"not null;uniqueIndex:idx_review_reminders_sent"`
+
+	// ReviewerEmailAddress is the email address of the reviewer that the
+	// reminder was sent to.
+	ReviewerEmailAddress string `gorm:"type:citext;default:null;not null;uniqueIndex:idx_review_reminders_sent"`
+
+	// DueDate is the due date of the document when the reminder was sent. A
+	// change of due date allows new reminders to be sent.
+	DueDate time.Time `gorm:"type:date;not null;uniqueIndex:idx_review_reminders_sent"`
+
+	// Type is the type of reminder (e.g., upcoming, overdue).
+	Type ReviewReminderType `gorm:"not null;uniqueIndex:idx_review_reminders_sent"`
+
+	// Channel is the channel the reminder was sent through (e.g., email,
+	// Slack).
+	Channel ReviewReminderChannel `gorm:"default:null;not null;uniqueIndex:idx_review_reminders_sent"`
+}
+
+// ReviewReminderType is the type of a review reminder.
+type ReviewReminderType int
+
+const (
+	UnspecifiedReviewReminderType ReviewReminderType = iota
+
+	// UpcomingReviewReminderType is a reminder sent before the due date.
+	UpcomingReviewReminderType
+
+	// OverdueReviewReminderType is a reminder sent after the due date.
+	OverdueReviewReminderType
+)
+
+// ReviewReminderChannel is the channel a review reminder is sent through.
+type ReviewReminderChannel string
+
+const (
+	EmailReviewReminderChannel ReviewReminderChannel = "email"
+	SlackReviewReminderChannel ReviewReminderChannel = "slack"
+)
+
+// Create creates the review reminder in database db.
+func (r *ReviewReminder) Create(db *gorm.DB) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	return db.
+		Omit("Document").
+		Create(&r).
+		Error
+}
+
+// Get gets the review reminder for the receiver's document, reviewer, due
+// date, type, and channel from database db, and assigns it to the receiver.
+func (r *ReviewReminder) Get(db *gorm.DB) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	// Don't log "record not found" errors (will still return the error).
+	tx := db.Session(&gorm.Session{Logger: logger.New(
+		log.Default(),
+		logger.Config{IgnoreRecordNotFoundError: true},
+	)})
+	return tx.
+		Where(ReviewReminder{
+			DocumentID:           r.DocumentID,
+			ReviewerEmailAddress: r.ReviewerEmailAddress,
+			DueDate:              r.DueDate,
+			Type:                 r.Type,
+			Channel:              r.Channel,
+		}).
+		First(&r).
+		Error
+}
+
+func (r *ReviewReminder) validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.DocumentID, validation.Required),
+		validation.Field(&r.ReviewerEmailAddress, validation.Required),
+		validation.Field(&r.DueDate, validation.Required),
+		validation.Field(&r.Type,
+			validation.Required,
+			validation.In(UpcomingReviewReminderType, OverdueReviewReminderType),
+		),
+		validation.Field(&r.Channel,
+			validation.Required,
+			validation.In(EmailReviewReminderChannel, SlackReviewReminderChannel),
+		),
+	)
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestReviewReminderModel(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Create and Get", func(t *testing.T) {
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		dueDate := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+		var d Document
+		t.Run("Create a document", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			d = Document{
+				GoogleFileID: "fileID1",
+				DueDate:      &dueDate,
+				DocumentType: DocumentType{
+					Name: "DT1",
+				},
+				Product: Product{
+					Name: "Product1",
+				},
+			}
+			err := d.Create(db)
+			require.NoError(err)
+			assert.EqualValues(1, d.ID)
+		})
+
+		t.Run("Get a reminder that hasn't been sent", func(t *testing.T) {
+			require := require.New(t)
+			r := ReviewReminder{
+				DocumentID:           d.ID,
+				ReviewerEmailAddress: "a@reviewer.com",
+				DueDate:              dueDate,
+				Type:                 UpcomingReviewReminderType,
+				Channel:              EmailReviewReminderChannel,
+			}
+			err := r.Get(db)
+			require.ErrorIs(err, gorm.ErrRecordNotFound)
+		})
+
+		t.Run("Create a reminder", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			r := ReviewReminder{
+				DocumentID:           d.ID,
+				ReviewerEmailAddress: "a@reviewer.com",
+				DueDate:              dueDate,
+				Type:                 UpcomingReviewReminderType,
+				Channel:              EmailReviewReminderChannel,
+			}
+			err := r.Create(db)
+			require.NoError(err)
+			assert.EqualValues(1, r.ID)
+		})
+
+		t.Run("Get the reminder", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			r := ReviewReminder{
+				DocumentID:           d.ID,
+				ReviewerEmailAddress: "a@reviewer.com",
+				DueDate:              dueDate,
+				Type:                 UpcomingReviewReminderType,
+				Channel:              EmailReviewReminderChannel,
+			}
+			err := r.Get(db)
+			require.NoError(err)
+			assert.EqualValues(1, r.ID)
+		})
+
+		t.Run("Creating a duplicate reminder errors", func(t *testing.T) {
+			require := require.New(t)
+			r := ReviewReminder{
+				DocumentID:           d.ID,
+				ReviewerEmailAddress: "a@reviewer.com",
+				DueDate:              dueDate,
+				Type:                 UpcomingReviewReminderType,
+				Channel:              EmailReviewReminderChannel,
+			}
+			err := r.Create(db)
+			require.Error(err)
+		})
+
+		t.Run("Get the overdue reminder that hasn't been sent", func(t *testing.T) {
+			require := require.New(t)
+			r := ReviewReminder{
+				DocumentID:           d.ID,
+				ReviewerEmailAddress: "a@reviewer.com",
+				DueDate:              dueDate,
+				Type:                 OverdueReviewReminderType,
+				Channel:              EmailReviewReminderChannel,
+			}
+			err := r.Get(db)
+			require.ErrorIs(err, gorm.ErrRecordNotFound)
+		})
+
+		t.Run("Create a reminder with an invalid channel", func(t *testing.T) {
+			require := require.New(t)
+			r := ReviewReminder{
+				DocumentID:           d.ID,
+				ReviewerEmailAddress: "a@reviewer.com",
+				DueDate:              dueDate,
+				Type:                 OverdueReviewReminderType,
+				Channel:              "pager",
+			}
+			err := r.Create(db)
+			require.Error(err)
+		})
+	})
+}
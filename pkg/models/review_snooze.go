@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReviewSnooze is a model for a reviewer snoozing a review request for a
+// document, to be sent the request again later.
+type ReviewSnooze struct {
+	ID uint `gorm:"primaryKey"`
+
+	// CreatedAt is the time the review request was first snoozed.
+	CreatedAt time.Time
+
+	// UpdatedAt is the time the review request was last snoozed.
+	UpdatedAt time.Time
+
+	// Document is the document that the review request is for.
+	Document   Document
+	DocumentID uint `gorm:"not null;uniqueIndex:idx_review_snoozes_reviewer"`
+
+	// ReviewerEmailAddress is the email address of the reviewer that snoozed
+	// the review request.
+	ReviewerEmailAddress string `gorm:"type:citext;default:null;not null;uniqueIndex:idx_review_snoozes_reviewer"`
+
+	// RemindAt is the time to send the review request again.
+	RemindAt time.Time `gorm:"not null;index"`
+}
+
+// ReviewSnoozes is a slice of review snoozes.
+type ReviewSnoozes []ReviewSnooze
+
+// Upsert creates the review snooze for the receiver's document and reviewer in
+// database db, or updates its RemindAt if the reviewer already snoozed the
+// review request.
+func (s *ReviewSnooze) Upsert(db *gorm.DB) error {
+	if err := validation.ValidateStruct(s,
+		validation.Field(&s.DocumentID, validation.Required),
+		validation.Field(&s.ReviewerEmailAddress, validation.Required),
+		validation.Field(&s.RemindAt, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Omit("Document").
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "document_id"},
+				{Name: "reviewer_email_address"},
+			},
+			DoUpdates: clause.AssignmentColumns(
+				[]string{"remind_at", "updated_at"}),
+		}).
+		Create(&s).
+		Error
+}
+
+// Delete deletes the review snooze with the receiver's ID from database db.
+func (s *ReviewSnooze) Delete(db *gorm.DB) error {
+	return db.Delete(&ReviewSnooze{}, s.ID).Error
+}
+
+// FindDue finds all review snoozes in database db that are due to be sent
+// again as of time now, with their documents, and assigns them to the
+// receiver.
+func (s *ReviewSnoozes) FindDue(db *gorm.DB, now time.Time) error {
+	return db.
+		Preload("Document.DocumentType").
+		Where("remind_at <= ?", now).
+		Order("id").
+		Find(&s).
+		Error
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewSnoozeModel(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Upsert, FindDue, and Delete", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		d := Document{
+			GoogleFileID: "fileID1",
+			DocumentType: DocumentType{
+				Name: "DT1",
+			},
+			Product: Product{
+				Name: "Product1",
+			},
+		}
+		require.NoError(d.Create(db))
+
+		now := time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+
+		// Snoozes without a reviewer are rejected.
+		rs := ReviewSnooze{
+			DocumentID: d.ID,
+			RemindAt:   now.Add(time.Hour),
+		}
+		require.Error(rs.Upsert(db))
+
+		// Snooze review request.
+		rs.ReviewerEmailAddress = "a@reviewer.com"
+		require.NoError(rs.Upsert(db))
+		assert.NotZero(rs.ID)
+
+		var snoozes ReviewSnoozes
+		require.NoError(snoozes.FindDue(db, now))
+		assert.Empty(snoozes)
+
+		// Snoozing again updates the time to remind the reviewer.
+		rs2 := ReviewSnooze{
+			DocumentID:           d.ID,
+			ReviewerEmailAddress: "A@reviewer.com",
+			RemindAt:             now.Add(-time.Hour),
+		}
+		require.NoError(rs2.Upsert(db))
+
+		require.NoError(snoozes.FindDue(db, now))
+		require.Len(snoozes, 1)
+		assert.Equal(rs.ID, snoozes[0].ID)
+		assert.Equal("fileID1", snoozes[0].Document.GoogleFileID)
+		assert.Equal("DT1", snoozes[0].Document.DocumentType.Name)
+
+		require.NoError(snoozes[0].Delete(db))
+		snoozes = nil
+		require.NoError(snoozes.FindDue(db, now))
+		assert.Empty(snoozes)
+	})
+}
@@ -0,0 +1,321 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RoleAssignment is a model for an admin role granted to a user, either
+// globally or scoped to a product (business unit) or team.
+//
+// Global admins also have User.Role set to Admin, which is kept in sync when
+// global admin roles are granted and revoked.
+type RoleAssignment struct {
+	ID uint `gorm:"primaryKey"`
+
+	// CreatedAt is the time the role was granted.
+	CreatedAt time.Time
+
+	// User is the user the role is granted to.
+	User   User
+	UserID uint `gorm:"not null;index"`
+
+	// Scope is the scope of the role.
+	Scope RoleScope `gorm:"default:null;not null"`
+
+	// Product is the product the role is scoped to, for product roles.
+	Product   *Product
+	ProductID *uuid.UUID `gorm:"type:uuid;index"`
+
+	// Team is the team the role is scoped to, for team roles.
+	Team   *Team
+	TeamID *uuid.UUID `gorm:"type:uuid;index"`
+
+	// GrantedBy is the email address of the user who granted the role, or
+	// "oidc" for global admin roles granted from OIDC group membership.
+	GrantedBy string `gorm:"type:citext"`
+}
+
+// RoleAssignments is a slice of role assignments.
+type RoleAssignments []RoleAssignment
+
+// RoleScope is the scope of an admin role.
+type RoleScope string
+
+const (
+	// GlobalRoleScope is the scope of admins of the whole application.
+	GlobalRoleScope RoleScope = "global"
+
+	// ProductRoleScope is the scope of admins of a product (business unit) and
+	// its teams.
+	ProductRoleScope RoleScope = "product"
+
+	// TeamRoleScope is the scope of admins of a team and its projects.
+	TeamRoleScope RoleScope = "team"
+)
+
+// Create grants the role to the user with the receiver's User.EmailAddress in
+// database db. The product or team is found by the receiver's Product.Name or
+// Team.Name. If the user already has the role, the existing role assignment is
+// assigned to the receiver.
+func (ra *RoleAssignment) Create(db *gorm.DB) error {
+	if err := ra.validate(); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := ra.User.FirstOrCreate(tx); err != nil {
+			return fmt.Errorf("error getting user: %w", err)
+		}
+		ra.UserID = ra.User.ID
+
+		switch ra.Scope {
+		case ProductRoleScope:
+			if err := ra.Product.Get(tx); err != nil {
+				return fmt.Errorf("error getting product: %w", err)
+			}
+			ra.ProductID = &ra.Product.ID
+		case TeamRoleScope:
+			if err := ra.Team.Get(tx); err != nil {
+				return fmt.Errorf("error getting team: %w", err)
+			}
+			ra.TeamID = &ra.Team.ID
+		}
+
+		// Return the existing role assignment if the user already has the role.
+		var existing RoleAssignment
+		err := ra.scopeQuery(tx).First(&existing).Error
+		if err == nil {
+			ra.ID = existing.ID
+			ra.CreatedAt = existing.CreatedAt
+			ra.GrantedBy = existing.GrantedBy
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("error finding existing role assignment: %w", err)
+		}
+
+		if err := tx.
+			Omit("User", "Product", "Team").
+			Create(&ra).
+			Error; err != nil {
+			return err
+		}
+
+		if ra.Scope == GlobalRoleScope {
+			if err := tx.
+				Model(&User{}).
+				Where("id = ?", ra.UserID).
+				Update("role", Admin).
+				Error; err != nil {
+				return fmt.Errorf("error updating user role: %w", err)
+			}
+			ra.User.Role = Admin
+		}
+
+		return nil
+	})
+}
+
+// Delete revokes the role assignment with the receiver's ID from database db.
+func (ra *RoleAssignment) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(ra,
+		validation.Field(&ra.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := ra.Get(tx); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&RoleAssignment{}, ra.ID).Error; err != nil {
+			return err
+		}
+
+		if ra.Scope == GlobalRoleScope {
+			if err := tx.
+				Model(&User{}).
+				Where("id = ?", ra.UserID).
+				Update("role", Basic).
+				Error; err != nil {
+				return fmt.Errorf("error updating user role: %w", err)
+			}
+			ra.User.Role = Basic
+		}
+
+		return nil
+	})
+}
+
+// Get gets the role assignment by ID from database db, and assigns it to the
+// receiver.
+func (ra *RoleAssignment) Get(db *gorm.DB) error {
+	if err := validation.ValidateStruct(ra,
+		validation.Field(&ra.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Preload("User").
+		Preload("Product").
+		Preload("Team.BU").
+		First(&ra, ra.ID).
+		Error
+}
+
+// Find finds all role assignments from database db, filtered to the user with
+// email address email if not empty, and assigns them to the receiver.
+func (ras *RoleAssignments) Find(db *gorm.DB, email string) error {
+	tx := db.
+		Preload("User").
+		Preload("Product").
+		Preload("Team.BU")
+	if email != "" {
+		tx = tx.
+			Joins("JOIN users ON users.id = role_assignments.user_id").
+			Where("users.email_address = ?", email)
+	}
+
+	return tx.
+		Order("role_assignments.id").
+		Find(&ras).
+		Error
+}
+
+// IsAdminFor returns true if the user with the receiver's EmailAddress is an
+// admin for the product or team with name name in database db. Global admins
+// are admins for everything, and product admins are also admins for the
+// product's teams. name is ignored for the global scope.
+func (u *User) IsAdminFor(
+	db *gorm.DB, scope RoleScope, name string) (bool, error) {
+	isAdmin, err := u.IsUserAdmin(db)
+	if err != nil || isAdmin {
+		return isAdmin, err
+	}
+
+	q := db.
+		Model(&RoleAssignment{}).
+		Joins("JOIN users ON users.id = role_assignments.user_id").
+		Where("users.email_address = ?", u.EmailAddress)
+
+	switch scope {
+	case GlobalRoleScope:
+		return false, nil
+
+	case ProductRoleScope:
+		q = q.
+			Joins("JOIN products ON products.id = role_assignments.product_id").
+			Where("role_assignments.scope = ? AND products.name = ?",
+				ProductRoleScope, name)
+
+	case TeamRoleScope:
+		t := Team{Name: name}
+		if err := t.Get(db); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, nil
+			}
+			return false, fmt.Errorf("error getting team: %w", err)
+		}
+		q = q.Where(
+			"(role_assignments.scope = ? AND role_assignments.team_id = ?) OR "+
+				"(role_assignments.scope = ? AND role_assignments.product_id = ?)",
+			TeamRoleScope, t.ID, ProductRoleScope, t.BUID)
+
+	default:
+		return false, fmt.Errorf("unknown role scope %q", scope)
+	}
+
+	var count int64
+	if err := q.Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// MigrateAdminRoles creates global role assignments for users in database db
+// that have the Admin role without one (e.g., admins from before scoped roles
+// existed).
+func MigrateAdminRoles(db *gorm.DB) error {
+	var users []User
+	if err := db.
+		Where("role = ?", Admin).
+		Where("NOT EXISTS (?)", db.
+			Model(&RoleAssignment{}).
+			Select("1").
+			Where("role_assignments.user_id = users.id AND scope = ?",
+				GlobalRoleScope)).
+		Find(&users).
+		Error; err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		ra := RoleAssignment{
+			User:  User{EmailAddress: u.EmailAddress},
+			Scope: GlobalRoleScope,
+		}
+		if err := ra.Create(db); err != nil {
+			return fmt.Errorf(
+				"error creating role assignment for user %q: %w", u.EmailAddress, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates that the role scope is known.
+func (s RoleScope) Validate() error {
+	switch s {
+	case GlobalRoleScope, ProductRoleScope, TeamRoleScope:
+		return nil
+	default:
+		return fmt.Errorf("unknown role scope %q", s)
+	}
+}
+
+// scopeQuery returns a query for role assignments of the receiver's user, scope,
+// product, and team.
+func (ra *RoleAssignment) scopeQuery(db *gorm.DB) *gorm.DB {
+	q := db.Where("user_id = ? AND scope = ?", ra.UserID, ra.Scope)
+	if ra.ProductID != nil {
+		q = q.Where("product_id = ?", *ra.ProductID)
+	}
+	if ra.TeamID != nil {
+		q = q.Where("team_id = ?", *ra.TeamID)
+	}
+	return q
+}
+
+func (ra *RoleAssignment) validate() error {
+	return validation.ValidateStruct(ra,
+		validation.Field(&ra.User, validation.By(func(interface{}) error {
+			return validation.Validate(ra.User.EmailAddress, validation.Required)
+		})),
+		validation.Field(&ra.Scope, validation.Required),
+		validation.Field(&ra.Product, validation.By(func(interface{}) error {
+			if ra.Scope != ProductRoleScope {
+				return validation.Validate(ra.Product, validation.Nil)
+			}
+			if ra.Product == nil || ra.Product.Name == "" {
+				return errors.New("product is required for product roles")
+			}
+			return nil
+		})),
+		validation.Field(&ra.Team, validation.By(func(interface{}) error {
+			if ra.Scope != TeamRoleScope {
+				return validation.Validate(ra.Team, validation.Nil)
+			}
+			if ra.Team == nil || ra.Team.Name == "" {
+				return errors.New("team is required for team roles")
+			}
+			return nil
+		})),
+	)
+}
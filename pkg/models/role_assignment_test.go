@@ -0,0 +1,236 @@
+package models
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleAssignmentValidate(t *testing.T) {
+	cases := map[string]struct {
+		ra      RoleAssignment
+		wantErr bool
+	}{
+		"global": {
+			ra: RoleAssignment{
+				User:  User{EmailAddress: "a@a.com"},
+				Scope: GlobalRoleScope,
+			},
+		},
+		"product": {
+			ra: RoleAssignment{
+				User:    User{EmailAddress: "a@a.com"},
+				Scope:   ProductRoleScope,
+				Product: &Product{Name: "Product1"},
+			},
+		},
+		"team": {
+			ra: RoleAssignment{
+				User:  User{EmailAddress: "a@a.com"},
+				Scope: TeamRoleScope,
+				Team:  &Team{Name: "Team1"},
+			},
+		},
+		"no user": {
+			ra: RoleAssignment{
+				Scope: GlobalRoleScope,
+			},
+			wantErr: true,
+		},
+		"unknown scope": {
+			ra: RoleAssignment{
+				User:  User{EmailAddress: "a@a.com"},
+				Scope: "everything",
+			},
+			wantErr: true,
+		},
+		"product without product": {
+			ra: RoleAssignment{
+				User:  User{EmailAddress: "a@a.com"},
+				Scope: ProductRoleScope,
+			},
+			wantErr: true,
+		},
+		"team with product": {
+			ra: RoleAssignment{
+				User:    User{EmailAddress: "a@a.com"},
+				Scope:   TeamRoleScope,
+				Product: &Product{Name: "Product1"},
+				Team:    &Team{Name: "Team1"},
+			},
+			wantErr: true,
+		},
+		"global with team": {
+			ra: RoleAssignment{
+				User:  User{EmailAddress: "a@a.com"},
+				Scope: GlobalRoleScope,
+				Team:  &Team{Name: "Team1"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := c.ra.validate()
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRoleAssignmentModel(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Grant, check, and revoke", func(t *testing.T) {
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		t.Run("Create products and teams", func(t *testing.T) {
+			require := require.New(t)
+			for _, p := range []string{"Product1", "Product2"} {
+				pm := Product{Name: p}
+				require.NoError(pm.Upsert(db))
+			}
+			t1 := Team{Name: "Team1"}
+			require.NoError(t1.Upsert(db, "Product1"))
+			t2 := Team{Name: "Team2"}
+			require.NoError(t2.Upsert(db, "Product2"))
+		})
+
+		t.Run("Grant a role for a product that doesn't exist", func(t *testing.T) {
+			ra := RoleAssignment{
+				User:    User{EmailAddress: "a@a.com"},
+				Scope:   ProductRoleScope,
+				Product: &Product{Name: "Unknown"},
+			}
+			assert.Error(t, ra.Create(db))
+		})
+
+		var productRole RoleAssignment
+		t.Run("Grant a product role", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			productRole = RoleAssignment{
+				User:      User{EmailAddress: "a@a.com"},
+				Scope:     ProductRoleScope,
+				Product:   &Product{Name: "Product1"},
+				GrantedBy: "admin@a.com",
+			}
+			require.NoError(productRole.Create(db))
+			assert.NotEmpty(productRole.ID)
+			assert.NotNil(productRole.ProductID)
+		})
+
+		t.Run("Grant the same product role again", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			ra := RoleAssignment{
+				User:    User{EmailAddress: "a@a.com"},
+				Scope:   ProductRoleScope,
+				Product: &Product{Name: "Product1"},
+			}
+			require.NoError(ra.Create(db))
+			assert.Equal(productRole.ID, ra.ID)
+		})
+
+		t.Run("Grant a team role", func(t *testing.T) {
+			ra := RoleAssignment{
+				User:  User{EmailAddress: "b@b.com"},
+				Scope: TeamRoleScope,
+				Team:  &Team{Name: "Team2"},
+			}
+			require.NoError(t, ra.Create(db))
+		})
+
+		t.Run("Check scoped admins", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			a := User{EmailAddress: "a@a.com"}
+			b := User{EmailAddress: "b@b.com"}
+
+			cases := []struct {
+				u     User
+				scope RoleScope
+				name  string
+				want  bool
+			}{
+				{a, GlobalRoleScope, "", false},
+				{a, ProductRoleScope, "Product1", true},
+				{a, ProductRoleScope, "Product2", false},
+				{a, TeamRoleScope, "Team1", true},
+				{a, TeamRoleScope, "Team2", false},
+				{b, ProductRoleScope, "Product2", false},
+				{b, TeamRoleScope, "Team2", true},
+				{b, TeamRoleScope, "Team1", false},
+				{b, TeamRoleScope, "Unknown", false},
+			}
+			for _, c := range cases {
+				got, err := c.u.IsAdminFor(db, c.scope, c.name)
+				require.NoError(err)
+				assert.Equal(c.want, got, "%s %s %s", c.u.EmailAddress, c.scope, c.name)
+			}
+		})
+
+		var globalRole RoleAssignment
+		t.Run("Grant a global role", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			globalRole = RoleAssignment{
+				User:  User{EmailAddress: "c@c.com"},
+				Scope: GlobalRoleScope,
+			}
+			require.NoError(globalRole.Create(db))
+
+			c := User{EmailAddress: "c@c.com"}
+			isAdmin, err := c.IsUserAdmin(db)
+			require.NoError(err)
+			assert.True(isAdmin)
+			isAdmin, err = c.IsAdminFor(db, TeamRoleScope, "Team2")
+			require.NoError(err)
+			assert.True(isAdmin)
+		})
+
+		t.Run("Find role assignments", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var ras RoleAssignments
+			require.NoError(ras.Find(db, ""))
+			assert.Len(ras, 3)
+
+			require.NoError(ras.Find(db, "a@a.com"))
+			require.Len(ras, 1)
+			require.NotNil(ras[0].Product)
+			assert.Equal("Product1", ras[0].Product.Name)
+		})
+
+		t.Run("Revoke the global role", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			ra := RoleAssignment{ID: globalRole.ID}
+			require.NoError(ra.Delete(db))
+
+			c := User{EmailAddress: "c@c.com"}
+			isAdmin, err := c.IsUserAdmin(db)
+			require.NoError(err)
+			assert.False(isAdmin)
+		})
+
+		t.Run("Migrate legacy admins", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			u := User{EmailAddress: "d@d.com"}
+			require.NoError(u.FirstOrCreate(db))
+			require.NoError(db.Model(&u).Update("role", Admin).Error)
+
+			require.NoError(MigrateAdminRoles(db))
+			require.NoError(MigrateAdminRoles(db))
+
+			var ras RoleAssignments
+			require.NoError(ras.Find(db, "d@d.com"))
+			require.Len(ras, 1)
+			assert.Equal(GlobalRoleScope, ras[0].Scope)
+		})
+	})
+}
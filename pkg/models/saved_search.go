@@ -0,0 +1,333 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SavedSearchFilterAttributes are the search index attributes that saved
+// searches can filter on.
+var SavedSearchFilterAttributes = []string{
+	"docType",
+	"owners",
+	"product",
+	"project",
+	"status",
+	"team",
+}
+
+// SavedSearch is a model for a user's saved search of published documents.
+// Users can be notified when new documents match a saved search.
+type SavedSearch struct {
+	gorm.Model
+
+	// User is the user that saved the search.
+	User   User
+	UserID uint `gorm:"not null;index"`
+
+	// Name is a user-provided name to identify the search.
+	Name string `gorm:"default:null;not null"`
+
+	// Query is the full-text query.
+	Query string
+
+	// Filters are the values of search index attributes to filter on, keyed by
+	// attribute (see SavedSearchFilterAttributes). Values for the same
+	// attribute are combined with OR, and attributes are combined with AND.
+	Filters datatypes.JSONType[map[string][]string] `gorm:"not null"`
+
+	// NotifyEmail notifies the user by email when new documents match.
+	NotifyEmail bool
+
+	// NotifySlack notifies the user by Slack direct message when new documents
+	// match.
+	NotifySlack bool
+
+	// LastCheckedAt is the time the search was last checked for new matches.
+	// Matches found by the first check after the search is created or changed
+	// are recorded without notifying the user.
+	LastCheckedAt *time.Time
+}
+
+// SavedSearches is a slice of saved searches.
+type SavedSearches []SavedSearch
+
+// SavedSearchMatch is a model for a document that has matched a saved search,
+// which is used to only notify users of new matches.
+type SavedSearchMatch struct {
+	ID uint `gorm:"primaryKey"`
+
+	// SavedSearchID is the ID of the saved search.
+	SavedSearchID uint `gorm:"not null;uniqueIndex:idx_saved_search_matches"`
+
+	// GoogleFileID is the Google Drive file ID of the matching document.
+	GoogleFileID string `gorm:"default:null;not null;uniqueIndex:idx_saved_search_matches"`
+
+	// CreatedAt is the time the document first matched.
+	CreatedAt time.Time
+}
+
+// Create creates the saved search in database db for the user with the
+// receiver's User.EmailAddress.
+func (s *SavedSearch) Create(db *gorm.DB) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	if s.User.EmailAddress == "" {
+		return errors.New("user email address is required")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := s.User.FirstOrCreate(tx); err != nil {
+			return fmt.Errorf("error getting user: %w", err)
+		}
+		s.UserID = s.User.ID
+
+		return tx.
+			Omit("User").
+			Create(&s).
+			Error
+	})
+}
+
+// Delete soft-deletes the saved search with the receiver's ID from database db,
+// if it belongs to the user with the receiver's User.EmailAddress. It returns
+// gorm.ErrRecordNotFound if no such saved search exists.
+func (s *SavedSearch) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(s,
+		validation.Field(&s.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Where("id = ? AND user_id = (?)", s.ID, userIDByEmail(tx, s.User.EmailAddress)).
+			Delete(&SavedSearch{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.
+			Where("saved_search_id = ?", s.ID).
+			Delete(&SavedSearchMatch{}).
+			Error
+	})
+}
+
+// Get gets the saved search with the receiver's ID from database db, if it
+// belongs to the user with the receiver's User.EmailAddress, and assigns it to
+// the receiver.
+func (s *SavedSearch) Get(db *gorm.DB) error {
+	if err := validation.ValidateStruct(s,
+		validation.Field(&s.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Where("id = ? AND user_id = (?)", s.ID, userIDByEmail(db, s.User.EmailAddress)).
+		Preload("User").
+		First(&s).
+		Error
+}
+
+// Update updates the name, query, filters, and notification settings of the
+// saved search with the receiver's ID in database db, if it belongs to the user
+// with the receiver's User.EmailAddress. Previously recorded matches are
+// cleared, so matches of the changed search are recorded again without
+// notifying the user.
+func (s *SavedSearch) Update(db *gorm.DB) error {
+	if err := validation.ValidateStruct(s,
+		validation.Field(&s.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Model(&SavedSearch{}).
+			Where("id = ? AND user_id = (?)", s.ID, userIDByEmail(tx, s.User.EmailAddress)).
+			Select("Name", "Query", "Filters", "NotifyEmail", "NotifySlack",
+				"LastCheckedAt").
+			Updates(SavedSearch{
+				Name:        s.Name,
+				Query:       s.Query,
+				Filters:     s.Filters,
+				NotifyEmail: s.NotifyEmail,
+				NotifySlack: s.NotifySlack,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.
+			Where("saved_search_id = ?", s.ID).
+			Delete(&SavedSearchMatch{}).
+			Error; err != nil {
+			return err
+		}
+
+		return tx.
+			Preload("User").
+			First(&s, s.ID).
+			Error
+	})
+}
+
+// FacetFilters returns the saved search's filters as search facet filters, in
+// order of attribute.
+func (s SavedSearch) FacetFilters() [][]string {
+	attrs := make([]string, 0, len(s.Filters.Data))
+	for attr := range s.Filters.Data {
+		attrs = append(attrs, attr)
+	}
+	sort.Strings(attrs)
+
+	var filters [][]string
+	for _, attr := range attrs {
+		var group []string
+		for _, v := range s.Filters.Data[attr] {
+			group = append(group, attr+":"+v)
+		}
+		if len(group) > 0 {
+			filters = append(filters, group)
+		}
+	}
+	return filters
+}
+
+// NewMatches returns the Google file IDs in ids of documents that haven't
+// matched the saved search before.
+func (s *SavedSearch) NewMatches(db *gorm.DB, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var matched []string
+	if err := db.
+		Model(&SavedSearchMatch{}).
+		Where("saved_search_id = ? AND google_file_id IN ?", s.ID, ids).
+		Pluck("google_file_id", &matched).
+		Error; err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(matched))
+	for _, id := range matched {
+		seen[id] = true
+	}
+	var newIDs []string
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			newIDs = append(newIDs, id)
+		}
+	}
+	return newIDs, nil
+}
+
+// RecordMatches records that documents with Google file IDs ids matched the
+// saved search, and that the search was checked at time now.
+func (s *SavedSearch) RecordMatches(
+	db *gorm.DB, ids []string, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(ids) > 0 {
+			matches := make([]SavedSearchMatch, len(ids))
+			for i, id := range ids {
+				matches[i] = SavedSearchMatch{
+					SavedSearchID: s.ID,
+					GoogleFileID:  id,
+				}
+			}
+			if err := tx.
+				Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&matches).
+				Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.
+			Model(&SavedSearch{}).
+			Where("id = ?", s.ID).
+			UpdateColumn("last_checked_at", now).
+			Error; err != nil {
+			return err
+		}
+		s.LastCheckedAt = &now
+
+		return nil
+	})
+}
+
+// FindByUser finds all saved searches of the user with email address email
+// from database db, and assigns them to the receiver.
+func (s *SavedSearches) FindByUser(db *gorm.DB, email string) error {
+	return db.
+		Where("user_id = (?)", userIDByEmail(db, email)).
+		Order("id").
+		Find(&s).
+		Error
+}
+
+// FindNotifiable finds all saved searches with notifications enabled from
+// database db, and assigns them to the receiver.
+func (s *SavedSearches) FindNotifiable(db *gorm.DB) error {
+	return db.
+		Where("notify_email OR notify_slack").
+		Preload("User").
+		Order("id").
+		Find(&s).
+		Error
+}
+
+func (s *SavedSearch) validate() error {
+	return validation.ValidateStruct(s,
+		validation.Field(&s.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&s.Filters, validation.By(func(interface{}) error {
+			for attr := range s.Filters.Data {
+				if !validSavedSearchFilterAttribute(attr) {
+					return fmt.Errorf("invalid filter attribute %q", attr)
+				}
+			}
+			return nil
+		})),
+	)
+}
+
+// validSavedSearchFilterAttribute returns true if saved searches can filter on
+// attribute attr.
+func validSavedSearchFilterAttribute(attr string) bool {
+	for _, a := range SavedSearchFilterAttributes {
+		if a == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// userIDByEmail returns a subquery of the ID of the user with email address
+// email.
+func userIDByEmail(db *gorm.DB, email string) *gorm.DB {
+	return db.
+		Model(&User{}).
+		Select("id").
+		Where("email_address = ?", email)
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestSavedSearchFacetFilters(t *testing.T) {
+	s := SavedSearch{
+		Filters: datatypes.JSONType[map[string][]string]{
+			Data: map[string][]string{
+				"status":  {"In-Review"},
+				"docType": {"RFC"},
+				"team":    {},
+				"product": {"Payments", "Platform"},
+			},
+		},
+	}
+
+	assert.Equal(t, [][]string{
+		{"docType:RFC"},
+		{"product:Payments", "product:Platform"},
+		{"status:In-Review"},
+	}, s.FacetFilters())
+}
+
+func TestSavedSearch(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Create, Get, Update, matches, and Delete", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		filters := datatypes.JSONType[map[string][]string]{
+			Data: map[string][]string{"docType": {"RFC"}},
+		}
+
+		// Invalid filter attributes are rejected.
+		s := SavedSearch{
+			User: User{EmailAddress: "a@example.com"},
+			Name: "RFCs",
+			Filters: datatypes.JSONType[map[string][]string]{
+				Data: map[string][]string{"contributors": {"b@example.com"}},
+			},
+		}
+		require.Error(s.Create(db))
+
+		// Create saved search.
+		s = SavedSearch{
+			User:        User{EmailAddress: "a@example.com"},
+			Name:        "RFCs",
+			Filters:     filters,
+			NotifyEmail: true,
+		}
+		require.NoError(s.Create(db))
+		assert.NotZero(s.ID)
+
+		// Other users can't get the saved search.
+		other := SavedSearch{User: User{EmailAddress: "b@example.com"}}
+		other.ID = s.ID
+		require.ErrorIs(other.Get(db), gorm.ErrRecordNotFound)
+
+		// Get saved search.
+		got := SavedSearch{User: User{EmailAddress: "a@example.com"}}
+		got.ID = s.ID
+		require.NoError(got.Get(db))
+		assert.Equal("RFCs", got.Name)
+		assert.Equal(filters.Data, got.Filters.Data)
+		assert.Nil(got.LastCheckedAt)
+
+		// Record matches.
+		now := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		ids, err := got.NewMatches(db, []string{"doc1", "doc2"})
+		require.NoError(err)
+		assert.Equal([]string{"doc1", "doc2"}, ids)
+		require.NoError(got.RecordMatches(db, ids, now))
+		ids, err = got.NewMatches(db, []string{"doc2", "doc3"})
+		require.NoError(err)
+		assert.Equal([]string{"doc3"}, ids)
+
+		// Find notifiable saved searches.
+		var ss SavedSearches
+		require.NoError(ss.FindNotifiable(db))
+		require.Len(ss, 1)
+		assert.Equal("a@example.com", ss[0].User.EmailAddress)
+		assert.Equal(now, ss[0].LastCheckedAt.UTC())
+
+		// Update saved search, which clears matches.
+		got.Name = "Payments RFCs"
+		got.NotifyEmail = false
+		require.NoError(got.Update(db))
+		assert.Equal("Payments RFCs", got.Name)
+		assert.Nil(got.LastCheckedAt)
+		ids, err = got.NewMatches(db, []string{"doc1"})
+		require.NoError(err)
+		assert.Equal([]string{"doc1"}, ids)
+		require.NoError(ss.FindNotifiable(db))
+		assert.Len(ss, 0)
+
+		// Find by user.
+		require.NoError(ss.FindByUser(db, "a@example.com"))
+		assert.Len(ss, 1)
+		require.NoError(ss.FindByUser(db, "b@example.com"))
+		assert.Len(ss, 0)
+
+		// Other users can't delete the saved search.
+		require.ErrorIs(other.Delete(db), gorm.ErrRecordNotFound)
+
+		// Delete saved search.
+		require.NoError(got.Delete(db))
+		require.ErrorIs(got.Get(db), gorm.ErrRecordNotFound)
+	})
+}
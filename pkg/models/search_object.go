@@ -0,0 +1,110 @@
+package models
+
+import (
+	"log"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// SearchObject is a model for an object stored in a search index when
+// PostgreSQL is used as the search provider.
+type SearchObject struct {
+	// IndexName is the name of the search index (e.g., "docs", "drafts").
+	IndexName string `gorm:"primaryKey"`
+
+	// ObjectID is the ID of the object in the search index.
+	ObjectID string `gorm:"primaryKey"`
+
+	// Data is the JSON-encoded object.
+	Data datatypes.JSON `gorm:"not null"`
+
+	// SearchVector is the weighted full-text search vector for the object. It
+	// is built by Upsert and is not loaded by Get.
+	SearchVector string `gorm:"type:tsvector;index:,type:gin"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Delete deletes the receiver search object from database db.
+func (o *SearchObject) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(o,
+		validation.Field(&o.IndexName, validation.Required),
+		validation.Field(&o.ObjectID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Where(SearchObject{IndexName: o.IndexName, ObjectID: o.ObjectID}).
+		Delete(&SearchObject{}).
+		Error
+}
+
+// Get gets the search object and assigns it to the receiver.
+func (o *SearchObject) Get(db *gorm.DB) error {
+	if err := validation.ValidateStruct(o,
+		validation.Field(&o.IndexName, validation.Required),
+		validation.Field(&o.ObjectID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	// Don't log "record not found" errors (will still return the error).
+	tx := db.Session(&gorm.Session{Logger: logger.New(
+		log.Default(),
+		logger.Config{IgnoreRecordNotFoundError: true},
+	)})
+	return tx.
+		Select("index_name", "object_id", "data", "created_at", "updated_at").
+		Where(SearchObject{IndexName: o.IndexName, ObjectID: o.ObjectID}).
+		First(&o).
+		Error
+}
+
+// Upsert updates or inserts the receiver search object into database db and
+// rebuilds its search vector. The title, summary, and content arguments are
+// weighted in that order when ranking search results.
+func (o *SearchObject) Upsert(
+	db *gorm.DB, title, summary, content string) error {
+	if err := validation.ValidateStruct(o,
+		validation.Field(&o.IndexName, validation.Required),
+		validation.Field(&o.ObjectID, validation.Required),
+		validation.Field(&o.Data, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.OnConflict{
+				Columns: []clause.Column{
+					{Name: "index_name"},
+					{Name: "object_id"},
+				},
+				DoUpdates: clause.AssignmentColumns(
+					[]string{"data", "updated_at"}),
+			}).
+			Omit("search_vector").
+			Create(&o).
+			Error; err != nil {
+			return err
+		}
+
+		return tx.
+			Model(&SearchObject{}).
+			Where(SearchObject{IndexName: o.IndexName, ObjectID: o.ObjectID}).
+			Update("search_vector", gorm.Expr(
+				"setweight(to_tsvector('simple', ?), 'A') || "+
+					"setweight(to_tsvector('english', ?), 'B') || "+
+					"setweight(to_tsvector('english', ?), 'C')",
+				title, summary, content,
+			)).
+			Error
+	})
+}
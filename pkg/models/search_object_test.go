@@ -0,0 +1,86 @@
+package models
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSearchObject(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Get, Upsert, and Delete", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		// Get object, which won't exist yet (should error).
+		o := SearchObject{
+			IndexName: "docs",
+			ObjectID:  "ID1",
+		}
+		err := o.Get(db)
+		require.Error(err)
+		require.ErrorIs(err, gorm.ErrRecordNotFound)
+
+		// Insert object using Upsert.
+		o = SearchObject{
+			IndexName: "docs",
+			ObjectID:  "ID1",
+			Data:      []byte(`{"objectID":"ID1","title":"Title 1"}`),
+		}
+		err = o.Upsert(db, "Title 1", "", "")
+		require.NoError(err)
+
+		// Get object.
+		o = SearchObject{
+			IndexName: "docs",
+			ObjectID:  "ID1",
+		}
+		err = o.Get(db)
+		require.NoError(err)
+		assert.JSONEq(`{"objectID":"ID1","title":"Title 1"}`, string(o.Data))
+
+		// Update object using Upsert.
+		o = SearchObject{
+			IndexName: "docs",
+			ObjectID:  "ID1",
+			Data:      []byte(`{"objectID":"ID1","title":"Title 2"}`),
+		}
+		err = o.Upsert(db, "Title 2", "", "")
+		require.NoError(err)
+
+		// Verify the search vector matches the new title.
+		var count int64
+		err = db.Model(&SearchObject{}).
+			Where("search_vector @@ plainto_tsquery('simple', ?)", "title 2").
+			Count(&count).
+			Error
+		require.NoError(err)
+		assert.EqualValues(1, count)
+
+		// An object with the same ID in another index is a separate object.
+		o = SearchObject{
+			IndexName: "drafts",
+			ObjectID:  "ID1",
+		}
+		err = o.Get(db)
+		require.ErrorIs(err, gorm.ErrRecordNotFound)
+
+		// Delete object.
+		o = SearchObject{
+			IndexName: "docs",
+			ObjectID:  "ID1",
+		}
+		err = o.Delete(db)
+		require.NoError(err)
+		err = o.Get(db)
+		require.ErrorIs(err, gorm.ErrRecordNotFound)
+	})
+}
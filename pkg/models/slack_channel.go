@@ -0,0 +1,205 @@
+package models
+
+import (
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SlackChannel is a model for a Slack channel mapped to a product, team, or
+// project, that document events of the target are posted to.
+type SlackChannel struct {
+	ID uint `gorm:"primaryKey"`
+
+	// CreatedAt is the time the channel was mapped.
+	CreatedAt time.Time
+
+	// ChannelID is the ID of the Slack channel (e.g., "C0123456789").
+	ChannelID string `gorm:"default:null;not null;uniqueIndex:idx_slack_channels_target"`
+
+	// TargetType is the type of the target the channel is mapped to.
+	TargetType SlackChannelTargetType `gorm:"default:null;not null;uniqueIndex:idx_slack_channels_target"`
+
+	// Target is the name of the product, team, or project.
+	Target string `gorm:"default:null;not null;uniqueIndex:idx_slack_channels_target"`
+}
+
+// SlackChannels is a slice of Slack channels.
+type SlackChannels []SlackChannel
+
+// SlackChannelTargetType is the type of target a Slack channel is mapped to.
+type SlackChannelTargetType string
+
+const (
+	ProductSlackChannelTarget SlackChannelTargetType = "product"
+	TeamSlackChannelTarget    SlackChannelTargetType = "team"
+	ProjectSlackChannelTarget SlackChannelTargetType = "project"
+)
+
+// SlackChannelTargetTypes are all Slack channel target types.
+var SlackChannelTargetTypes = []SlackChannelTargetType{
+	ProductSlackChannelTarget,
+	TeamSlackChannelTarget,
+	ProjectSlackChannelTarget,
+}
+
+// slackChannelIDRegexp matches Slack public and private channel IDs.
+var slackChannelIDRegexp = regexp.MustCompile(`^[CG][A-Z0-9]+$`)
+
+// SlackChannelMessage is a model for the message posted to a Slack channel
+// when a document entered review, that later events of the document are posted
+// as thread replies to.
+type SlackChannelMessage struct {
+	ID uint `gorm:"primaryKey"`
+
+	// CreatedAt is the time the message was first posted.
+	CreatedAt time.Time
+
+	// UpdatedAt is the time the message was last posted.
+	UpdatedAt time.Time
+
+	// ChannelID is the ID of the Slack channel.
+	ChannelID string `gorm:"default:null;not null;uniqueIndex:idx_slack_channel_messages_document"`
+
+	// GoogleFileID is the Google Drive file ID of the document.
+	GoogleFileID string `gorm:"default:null;not null;uniqueIndex:idx_slack_channel_messages_document"`
+
+	// Timestamp is the Slack timestamp ("ts") of the message.
+	Timestamp string `gorm:"default:null;not null"`
+}
+
+// SlackChannelMessages is a slice of Slack channel messages.
+type SlackChannelMessages []SlackChannelMessage
+
+// Create creates the Slack channel mapping in database db.
+func (c *SlackChannel) Create(db *gorm.DB) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	return db.Create(&c).Error
+}
+
+// Delete deletes the Slack channel mapping with the receiver's ID from
+// database db. It returns gorm.ErrRecordNotFound if no such mapping exists.
+func (c *SlackChannel) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(c,
+		validation.Field(&c.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	res := db.Delete(&SlackChannel{}, c.ID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Get gets the Slack channel mapping with the receiver's ID from database db,
+// and assigns it to the receiver.
+func (c *SlackChannel) Get(db *gorm.DB) error {
+	if err := validation.ValidateStruct(c,
+		validation.Field(&c.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.First(&c, c.ID).Error
+}
+
+// Find finds all Slack channel mappings in database db, and assigns them to the
+// receiver.
+func (c *SlackChannels) Find(db *gorm.DB) error {
+	return db.
+		Order("target_type, target, channel_id").
+		Find(&c).
+		Error
+}
+
+// FindSlackChannelIDs returns the unique IDs of Slack channels in database db
+// mapped to product product, team team, or project project. Empty targets are
+// ignored.
+func FindSlackChannelIDs(
+	db *gorm.DB, product, team, project string) ([]string, error) {
+	tx := db.Model(&SlackChannel{}).Where("1 = 0")
+	for _, t := range []struct {
+		targetType SlackChannelTargetType
+		target     string
+	}{
+		{ProductSlackChannelTarget, product},
+		{TeamSlackChannelTarget, team},
+		{ProjectSlackChannelTarget, project},
+	} {
+		if t.target != "" {
+			tx = tx.Or("target_type = ? AND target = ?", t.targetType, t.target)
+		}
+	}
+
+	var ids []string
+	if err := tx.
+		Distinct("channel_id").
+		Order("channel_id").
+		Pluck("channel_id", &ids).
+		Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (c *SlackChannel) validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.ChannelID,
+			validation.Required,
+			validation.Match(slackChannelIDRegexp).
+				Error("must be a Slack channel ID (e.g., \"C0123456789\")"),
+		),
+		validation.Field(&c.TargetType,
+			validation.Required,
+			validation.In(ProductSlackChannelTarget, TeamSlackChannelTarget,
+				ProjectSlackChannelTarget),
+		),
+		validation.Field(&c.Target, validation.Required),
+	)
+}
+
+// Upsert creates the Slack channel message in database db, or updates its
+// timestamp if a message was already posted to the channel for the document.
+func (m *SlackChannelMessage) Upsert(db *gorm.DB) error {
+	if err := validation.ValidateStruct(m,
+		validation.Field(&m.ChannelID, validation.Required),
+		validation.Field(&m.GoogleFileID, validation.Required),
+		validation.Field(&m.Timestamp, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{
+				{Name: "channel_id"}, {Name: "google_file_id"},
+			},
+			DoUpdates: clause.AssignmentColumns(
+				[]string{"timestamp", "updated_at"}),
+		}).
+		Create(&m).
+		Error
+}
+
+// FindByGoogleFileID finds the Slack channel messages posted for the document
+// with Google Drive file ID fileID in database db, and assigns them to the
+// receiver.
+func (m *SlackChannelMessages) FindByGoogleFileID(
+	db *gorm.DB, fileID string) error {
+	return db.
+		Where("google_file_id = ?", fileID).
+		Order("channel_id").
+		Find(&m).
+		Error
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSlackChannelModel(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Create, Find, FindSlackChannelIDs, and Delete", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		// Invalid channel IDs are rejected.
+		c := SlackChannel{
+			ChannelID:  "general",
+			TargetType: ProductSlackChannelTarget,
+			Target:     "Product1",
+		}
+		require.Error(c.Create(db))
+
+		c.ChannelID = "C0001"
+		require.NoError(c.Create(db))
+		assert.NotZero(c.ID)
+
+		// Mapping the same channel to the same target again fails.
+		dup := SlackChannel{
+			ChannelID:  "C0001",
+			TargetType: ProductSlackChannelTarget,
+			Target:     "Product1",
+		}
+		require.Error(dup.Create(db))
+
+		c2 := SlackChannel{
+			ChannelID:  "C0001",
+			TargetType: TeamSlackChannelTarget,
+			Target:     "Team1",
+		}
+		require.NoError(c2.Create(db))
+		c3 := SlackChannel{
+			ChannelID:  "G0002",
+			TargetType: ProjectSlackChannelTarget,
+			Target:     "Project1",
+		}
+		require.NoError(c3.Create(db))
+
+		var cs SlackChannels
+		require.NoError(cs.Find(db))
+		assert.Len(cs, 3)
+
+		ids, err := FindSlackChannelIDs(db, "Product1", "Team1", "Project1")
+		require.NoError(err)
+		assert.Equal([]string{"C0001", "G0002"}, ids)
+
+		ids, err = FindSlackChannelIDs(db, "Product2", "", "Project1")
+		require.NoError(err)
+		assert.Equal([]string{"G0002"}, ids)
+
+		ids, err = FindSlackChannelIDs(db, "", "", "")
+		require.NoError(err)
+		assert.Empty(ids)
+
+		require.NoError(c3.Delete(db))
+		assert.ErrorIs(c3.Delete(db), gorm.ErrRecordNotFound)
+		got := SlackChannel{ID: c3.ID}
+		assert.ErrorIs(got.Get(db), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Upsert and FindByGoogleFileID messages", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		m := SlackChannelMessage{
+			ChannelID:    "C0001",
+			GoogleFileID: "fileID1",
+			Timestamp:    "1.0",
+		}
+		require.NoError(m.Upsert(db))
+
+		// Posting again updates the timestamp.
+		m2 := SlackChannelMessage{
+			ChannelID:    "C0001",
+			GoogleFileID: "fileID1",
+			Timestamp:    "2.0",
+		}
+		require.NoError(m2.Upsert(db))
+
+		var ms SlackChannelMessages
+		require.NoError(ms.FindByGoogleFileID(db, "fileID1"))
+		require.Len(ms, 1)
+		assert.Equal("2.0", ms[0].Timestamp)
+	})
+}
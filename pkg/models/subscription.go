@@ -0,0 +1,319 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Subscription is a model for a user's subscription to events of documents of a
+// team, project, or document type, or of a single document. Whole-product
+// subscriptions are stored as User.ProductSubscriptions.
+type Subscription struct {
+	gorm.Model
+
+	// User is the subscribed user.
+	User   User
+	UserID uint `gorm:"not null;uniqueIndex:idx_subscriptions_target"`
+
+	// TargetType is the type of the subscription target.
+	TargetType SubscriptionTargetType `gorm:"default:null;not null;uniqueIndex:idx_subscriptions_target"`
+
+	// Target is the name of the team, project, or document type, or the Google
+	// file ID of the document, that the user is subscribed to.
+	Target string `gorm:"default:null;not null;uniqueIndex:idx_subscriptions_target"`
+
+	// Events are the types of events the user is notified of.
+	Events datatypes.JSONType[[]SubscriptionEventType] `gorm:"not null"`
+}
+
+// Subscriptions is a slice of subscriptions.
+type Subscriptions []Subscription
+
+// SubscriptionTargetType is the type of a subscription target.
+type SubscriptionTargetType string
+
+const (
+	// TeamSubscriptionTarget subscribes to documents of a team.
+	TeamSubscriptionTarget SubscriptionTargetType = "team"
+
+	// ProjectSubscriptionTarget subscribes to documents of a project.
+	ProjectSubscriptionTarget SubscriptionTargetType = "project"
+
+	// DocumentTypeSubscriptionTarget subscribes to documents of a document type.
+	DocumentTypeSubscriptionTarget SubscriptionTargetType = "documentType"
+
+	// DocumentSubscriptionTarget subscribes to a single document.
+	DocumentSubscriptionTarget SubscriptionTargetType = "document"
+)
+
+// SubscriptionTargetTypes are all subscription target types.
+var SubscriptionTargetTypes = []SubscriptionTargetType{
+	TeamSubscriptionTarget,
+	ProjectSubscriptionTarget,
+	DocumentTypeSubscriptionTarget,
+	DocumentSubscriptionTarget,
+}
+
+// SubscriptionEventType is the type of a document event that subscribers can
+// be notified of.
+type SubscriptionEventType string
+
+const (
+	// PublishedSubscriptionEvent is a draft being published.
+	PublishedSubscriptionEvent SubscriptionEventType = "published"
+
+	// ReviewStartedSubscriptionEvent is a document entering review, either when
+	// it is published or when its status changes back to In-Review.
+	ReviewStartedSubscriptionEvent SubscriptionEventType = "reviewStarted"
+
+	// ApprovedSubscriptionEvent is a document being approved.
+	ApprovedSubscriptionEvent SubscriptionEventType = "approved"
+
+	// CommentedSubscriptionEvent is a comment being added to a document in
+	// review.
+	CommentedSubscriptionEvent SubscriptionEventType = "commented"
+)
+
+// SubscriptionEventTypes are all subscription event types.
+var SubscriptionEventTypes = []SubscriptionEventType{
+	PublishedSubscriptionEvent,
+	ReviewStartedSubscriptionEvent,
+	ApprovedSubscriptionEvent,
+	CommentedSubscriptionEvent,
+}
+
+// SubscriptionDocument identifies the targets of a document that subscriptions
+// can match.
+type SubscriptionDocument struct {
+	DocumentType string
+	GoogleFileID string
+	Project      string
+	Team         string
+}
+
+// Delete deletes the subscription with the receiver's ID from database db, if
+// it belongs to the user with the receiver's User.EmailAddress. It returns
+// gorm.ErrRecordNotFound if no such subscription exists.
+func (s *Subscription) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(s,
+		validation.Field(&s.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	res := db.
+		Unscoped().
+		Where("id = ? AND user_id = (?)", s.ID, userIDByEmail(db, s.User.EmailAddress)).
+		Delete(&Subscription{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Get gets the subscription with the receiver's ID from database db, if it
+// belongs to the user with the receiver's User.EmailAddress, and assigns it to
+// the receiver.
+func (s *Subscription) Get(db *gorm.DB) error {
+	if err := validation.ValidateStruct(s,
+		validation.Field(&s.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Where("id = ? AND user_id = (?)", s.ID, userIDByEmail(db, s.User.EmailAddress)).
+		Preload("User").
+		First(&s).
+		Error
+}
+
+// Upsert creates the subscription in database db for the user with the
+// receiver's User.EmailAddress, or updates its events if the user is already
+// subscribed to the target. The result is saved back to the receiver.
+func (s *Subscription) Upsert(db *gorm.DB) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	if s.User.EmailAddress == "" {
+		return errors.New("user email address is required")
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := s.User.FirstOrCreate(tx); err != nil {
+			return fmt.Errorf("error getting user: %w", err)
+		}
+		s.UserID = s.User.ID
+
+		if err := tx.
+			Omit("User").
+			Clauses(clause.OnConflict{
+				Columns: []clause.Column{
+					{Name: "user_id"}, {Name: "target_type"}, {Name: "target"},
+				},
+				DoUpdates: clause.AssignmentColumns([]string{"events", "updated_at"}),
+			}).
+			Create(&s).
+			Error; err != nil {
+			return err
+		}
+
+		return tx.
+			Where(Subscription{
+				UserID:     s.UserID,
+				TargetType: s.TargetType,
+				Target:     s.Target,
+			}).
+			Preload("User").
+			First(&s).
+			Error
+	})
+}
+
+// UpdateEvents updates the events of the subscription with the receiver's ID in
+// database db, if it belongs to the user with the receiver's User.EmailAddress.
+func (s *Subscription) UpdateEvents(db *gorm.DB) error {
+	if err := validation.ValidateStruct(s,
+		validation.Field(&s.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+	if err := validateSubscriptionEvents(s.Events.Data); err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Model(&Subscription{}).
+			Where("id = ? AND user_id = (?)", s.ID, userIDByEmail(tx, s.User.EmailAddress)).
+			Update("events", s.Events)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.
+			Preload("User").
+			First(&s, s.ID).
+			Error
+	})
+}
+
+// HasEvent returns true if the subscription notifies the user of events of
+// type event.
+func (s Subscription) HasEvent(event SubscriptionEventType) bool {
+	for _, e := range s.Events.Data {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// FindByUser finds all subscriptions of the user with email address email from
+// database db, and assigns them to the receiver.
+func (s *Subscriptions) FindByUser(db *gorm.DB, email string) error {
+	return db.
+		Where("user_id = (?)", userIDByEmail(db, email)).
+		Order("id").
+		Find(&s).
+		Error
+}
+
+// FindSubscribers returns the email addresses of users in database db with a
+// subscription to a target of document doc that notifies them of any of the
+// events.
+func FindSubscribers(
+	db *gorm.DB, doc SubscriptionDocument, events ...SubscriptionEventType,
+) ([]string, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	for _, t := range []struct {
+		targetType SubscriptionTargetType
+		target     string
+	}{
+		{DocumentSubscriptionTarget, doc.GoogleFileID},
+		{DocumentTypeSubscriptionTarget, doc.DocumentType},
+		{ProjectSubscriptionTarget, doc.Project},
+		{TeamSubscriptionTarget, doc.Team},
+	} {
+		if t.target != "" {
+			conds = append(conds, "(target_type = ? AND target = ?)")
+			args = append(args, t.targetType, t.target)
+		}
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+
+	var subs Subscriptions
+	if err := db.
+		Where(strings.Join(conds, " OR "), args...).
+		Preload("User").
+		Order("id").
+		Find(&subs).
+		Error; err != nil {
+		return nil, err
+	}
+
+	return subs.subscribers(events...), nil
+}
+
+// subscribers returns the unique email addresses of users with a subscription
+// that notifies them of any of the events.
+func (s Subscriptions) subscribers(events ...SubscriptionEventType) []string {
+	var emails []string
+	for _, sub := range s {
+		if containsFold(emails, sub.User.EmailAddress) {
+			continue
+		}
+		for _, e := range events {
+			if sub.HasEvent(e) {
+				emails = append(emails, sub.User.EmailAddress)
+				break
+			}
+		}
+	}
+	return emails
+}
+
+func (s *Subscription) validate() error {
+	targetTypes := make([]interface{}, len(SubscriptionTargetTypes))
+	for i, t := range SubscriptionTargetTypes {
+		targetTypes[i] = t
+	}
+
+	return validation.ValidateStruct(s,
+		validation.Field(&s.TargetType,
+			validation.Required, validation.In(targetTypes...)),
+		validation.Field(&s.Target, validation.Required),
+		validation.Field(&s.Events, validation.By(func(interface{}) error {
+			return validateSubscriptionEvents(s.Events.Data)
+		})),
+	)
+}
+
+// validateSubscriptionEvents validates the events of a subscription.
+func validateSubscriptionEvents(events []SubscriptionEventType) error {
+	eventTypes := make([]interface{}, len(SubscriptionEventTypes))
+	for i, e := range SubscriptionEventTypes {
+		eventTypes[i] = e
+	}
+
+	return validation.Validate(events,
+		validation.Required.Error("at least one event is required"),
+		validation.Each(validation.In(eventTypes...)))
+}
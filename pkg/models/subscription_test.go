@@ -0,0 +1,131 @@
+package models
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestSubscriptionsSubscribers(t *testing.T) {
+	events := func(es ...SubscriptionEventType) datatypes.JSONType[[]SubscriptionEventType] {
+		return datatypes.JSONType[[]SubscriptionEventType]{Data: es}
+	}
+	subs := Subscriptions{
+		{
+			User:   User{EmailAddress: "a@example.com"},
+			Events: events(PublishedSubscriptionEvent),
+		},
+		{
+			User:   User{EmailAddress: "b@example.com"},
+			Events: events(ApprovedSubscriptionEvent, CommentedSubscriptionEvent),
+		},
+		{
+			User:   User{EmailAddress: "A@example.com"},
+			Events: events(PublishedSubscriptionEvent, ApprovedSubscriptionEvent),
+		},
+		{
+			User:   User{EmailAddress: "c@example.com"},
+			Events: events(ReviewStartedSubscriptionEvent),
+		},
+	}
+
+	assert.Equal(t, []string{"a@example.com"},
+		subs.subscribers(PublishedSubscriptionEvent))
+	assert.Equal(t, []string{"b@example.com", "A@example.com"},
+		subs.subscribers(ApprovedSubscriptionEvent))
+	assert.Equal(t, []string{"a@example.com", "c@example.com"},
+		subs.subscribers(
+			PublishedSubscriptionEvent, ReviewStartedSubscriptionEvent))
+	assert.Empty(t, Subscriptions{}.subscribers(CommentedSubscriptionEvent))
+}
+
+func TestSubscription(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Upsert, Get, UpdateEvents, FindSubscribers, and Delete",
+		func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			db, tearDownTest := setupTest(t, dsn)
+			defer tearDownTest(t)
+
+			// Subscriptions without events are rejected.
+			s := Subscription{
+				User:       User{EmailAddress: "a@example.com"},
+				TargetType: TeamSubscriptionTarget,
+				Target:     "Payments",
+			}
+			require.Error(s.Upsert(db))
+
+			// Create subscription.
+			s.Events = datatypes.JSONType[[]SubscriptionEventType]{
+				Data: []SubscriptionEventType{PublishedSubscriptionEvent},
+			}
+			require.NoError(s.Upsert(db))
+			assert.NotZero(s.ID)
+
+			// Subscribing to the same target again updates events.
+			s2 := Subscription{
+				User:       User{EmailAddress: "a@example.com"},
+				TargetType: TeamSubscriptionTarget,
+				Target:     "Payments",
+				Events: datatypes.JSONType[[]SubscriptionEventType]{
+					Data: []SubscriptionEventType{ApprovedSubscriptionEvent},
+				},
+			}
+			require.NoError(s2.Upsert(db))
+			assert.Equal(s.ID, s2.ID)
+			assert.Equal([]SubscriptionEventType{ApprovedSubscriptionEvent},
+				s2.Events.Data)
+
+			// Other users can't get the subscription.
+			other := Subscription{User: User{EmailAddress: "b@example.com"}}
+			other.ID = s.ID
+			require.ErrorIs(other.Get(db), gorm.ErrRecordNotFound)
+
+			// Update events.
+			s.Events = datatypes.JSONType[[]SubscriptionEventType]{
+				Data: []SubscriptionEventType{
+					PublishedSubscriptionEvent, CommentedSubscriptionEvent,
+				},
+			}
+			require.NoError(s.UpdateEvents(db))
+			require.ErrorIs(other.UpdateEvents(db), gorm.ErrRecordNotFound)
+
+			// Find subscribers.
+			doc := SubscriptionDocument{
+				DocumentType: "RFC",
+				GoogleFileID: "doc1",
+				Team:         "Payments",
+			}
+			subscribers, err := FindSubscribers(
+				db, doc, CommentedSubscriptionEvent)
+			require.NoError(err)
+			assert.Equal([]string{"a@example.com"}, subscribers)
+			subscribers, err = FindSubscribers(
+				db, doc, ApprovedSubscriptionEvent)
+			require.NoError(err)
+			assert.Empty(subscribers)
+			subscribers, err = FindSubscribers(db, SubscriptionDocument{
+				Team: "Platform",
+			}, CommentedSubscriptionEvent)
+			require.NoError(err)
+			assert.Empty(subscribers)
+
+			// Find by user.
+			var subs Subscriptions
+			require.NoError(subs.FindByUser(db, "a@example.com"))
+			assert.Len(subs, 1)
+
+			// Delete subscription.
+			require.ErrorIs(other.Delete(db), gorm.ErrRecordNotFound)
+			require.NoError(s.Delete(db))
+			require.ErrorIs(s.Get(db), gorm.ErrRecordNotFound)
+		})
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -29,6 +30,52 @@ type User struct {
 
 	// Role indicates whether the user is an admin or something else, done using an enum.
 	Role RoleType `gorm:"default:'Basic'"`
+
+	// DigestFrequency is how often the user is emailed about events of documents
+	// they are subscribed to.
+	DigestFrequency DigestFrequency `gorm:"default:'immediate';not null"`
+
+	// LastDigestSentAt is the time the last digest email was sent to the user.
+	LastDigestSentAt *time.Time
+}
+
+// DigestFrequency is how often a user is emailed about subscribed document
+// events.
+type DigestFrequency string
+
+const (
+	// ImmediateDigestFrequency emails the user about each event as it happens.
+	ImmediateDigestFrequency DigestFrequency = "immediate"
+
+	// DailyDigestFrequency queues events and emails the user a daily summary.
+	DailyDigestFrequency DigestFrequency = "daily"
+
+	// WeeklyDigestFrequency queues events and emails the user a weekly
+	// summary.
+	WeeklyDigestFrequency DigestFrequency = "weekly"
+)
+
+// DigestFrequencies are all digest frequencies.
+var DigestFrequencies = []DigestFrequency{
+	ImmediateDigestFrequency,
+	DailyDigestFrequency,
+	WeeklyDigestFrequency,
+}
+
+// Validate returns an error if the digest frequency is unknown.
+func (f DigestFrequency) Validate() error {
+	for _, v := range DigestFrequencies {
+		if f == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid digest frequency %q", f)
+}
+
+// IsDigest returns true if events are queued for a digest email instead of
+// emailed immediately.
+func (f DigestFrequency) IsDigest() bool {
+	return f == DailyDigestFrequency || f == WeeklyDigestFrequency
 }
 
 type RoleType string
@@ -171,6 +218,60 @@ func (u *User) getAssociations(tx *gorm.DB) error {
 	return nil
 }
 
+// UpdateDigestFrequency updates the digest frequency of the user with the
+// receiver's EmailAddress in database db, creating the user if it doesn't
+// exist.
+func (u *User) UpdateDigestFrequency(db *gorm.DB) error {
+	if err := u.DigestFrequency.Validate(); err != nil {
+		return err
+	}
+	freq := u.DigestFrequency
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := u.FirstOrCreate(tx); err != nil {
+			return err
+		}
+		if err := tx.
+			Model(&User{}).
+			Where("id = ?", u.ID).
+			UpdateColumn("digest_frequency", freq).
+			Error; err != nil {
+			return err
+		}
+		u.DigestFrequency = freq
+		return nil
+	})
+}
+
+// GetDigestFrequencies returns the digest frequencies of users with email
+// addresses emails in database db, keyed by lowercase email address. Users that
+// don't exist have the immediate digest frequency.
+func GetDigestFrequencies(
+	db *gorm.DB, emails []string) (map[string]DigestFrequency, error) {
+	freqs := make(map[string]DigestFrequency, len(emails))
+	for _, e := range emails {
+		freqs[strings.ToLower(e)] = ImmediateDigestFrequency
+	}
+	if len(emails) == 0 {
+		return freqs, nil
+	}
+
+	var users []User
+	if err := db.
+		Select("email_address", "digest_frequency").
+		Where("email_address IN ?", emails).
+		Find(&users).
+		Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		if u.DigestFrequency != "" {
+			freqs[strings.ToLower(u.EmailAddress)] = u.DigestFrequency
+		}
+	}
+	return freqs, nil
+}
+
 // FetchRole gets the value of column "IsAdmin"
 func (u *User) FetchRole(db *gorm.DB) (string, error) {
 	var user User
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Webhook is a model for an outbound webhook subscription to document events.
+type Webhook struct {
+	gorm.Model
+
+	// URL is the URL that event payloads are POSTed to.
+	URL string `gorm:"default:null;not null"`
+
+	// Secret is the secret used to sign event payloads.
+	Secret string `gorm:"default:null;not null"`
+
+	// Description is an optional description of the webhook.
+	Description string
+
+	// Disabled disables delivering events to the webhook.
+	Disabled bool
+
+	// Events are the event types that are delivered to the webhook. All events
+	// are delivered if empty.
+	Events datatypes.JSONType[[]WebhookEventType] `gorm:"not null"`
+
+	// Products filters events to documents with one of these products, if set.
+	Products datatypes.JSONType[[]string] `gorm:"not null"`
+
+	// Teams filters events to documents with one of these teams, if set.
+	Teams datatypes.JSONType[[]string] `gorm:"not null"`
+
+	// DocumentTypes filters events to documents with one of these document
+	// types, if set.
+	DocumentTypes datatypes.JSONType[[]string] `gorm:"not null"`
+}
+
+// Webhooks is a slice of webhooks.
+type Webhooks []Webhook
+
+// WebhookEventType is the type of a document event delivered to webhooks.
+type WebhookEventType string
+
+const (
+	// DocumentPublishedWebhookEvent is sent when a draft is published and enters
+	// review.
+	DocumentPublishedWebhookEvent WebhookEventType = "document.published"
+
+	// DocumentApprovedWebhookEvent is sent when a reviewer approves a document.
+	DocumentApprovedWebhookEvent WebhookEventType = "document.approved"
+
+	// DocumentChangesRequestedWebhookEvent is sent when a reviewer requests
+	// changes of a document.
+	DocumentChangesRequestedWebhookEvent WebhookEventType = "document.changes_requested"
+
+	// DocumentStatusChangedWebhookEvent is sent when the status of a published
+	// document changes.
+	DocumentStatusChangedWebhookEvent WebhookEventType = "document.status_changed"
+)
+
+// WebhookEventTypes are all webhook event types.
+var WebhookEventTypes = []WebhookEventType{
+	DocumentPublishedWebhookEvent,
+	DocumentApprovedWebhookEvent,
+	DocumentChangesRequestedWebhookEvent,
+	DocumentStatusChangedWebhookEvent,
+}
+
+// Create creates the webhook in database db.
+func (w *Webhook) Create(db *gorm.DB) error {
+	if err := w.validate(); err != nil {
+		return err
+	}
+
+	return db.Create(&w).Error
+}
+
+// Delete soft-deletes the webhook from database db.
+func (w *Webhook) Delete(db *gorm.DB) error {
+	if err := validation.ValidateStruct(w,
+		validation.Field(&w.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.Delete(&w).Error
+}
+
+// Get gets the webhook by ID from database db, and assigns it to the receiver.
+func (w *Webhook) Get(db *gorm.DB) error {
+	if err := validation.ValidateStruct(w,
+		validation.Field(&w.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.First(&w, w.ID).Error
+}
+
+// Update updates all fields of the webhook in database db.
+func (w *Webhook) Update(db *gorm.DB) error {
+	if err := validation.ValidateStruct(w,
+		validation.Field(&w.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+	if err := w.validate(); err != nil {
+		return err
+	}
+
+	return db.Save(&w).Error
+}
+
+// Find finds all webhooks in database db, and assigns them to the receiver.
+func (w *Webhooks) Find(db *gorm.DB) error {
+	return db.Order("id").Find(&w).Error
+}
+
+// Matches returns true if the webhook is enabled and subscribed to events of
+// type event for documents with the provided product, team, and document type.
+func (w Webhook) Matches(
+	event WebhookEventType, product, team, docType string) bool {
+	if w.Disabled {
+		return false
+	}
+	if len(w.Events.Data) > 0 {
+		found := false
+		for _, e := range w.Events.Data {
+			if e == event {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return matchesFilter(w.Products.Data, product) &&
+		matchesFilter(w.Teams.Data, team) &&
+		matchesFilter(w.DocumentTypes.Data, docType)
+}
+
+// matchesFilter returns true if filter is empty or contains value (ignoring
+// case).
+func matchesFilter(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if strings.EqualFold(f, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) validate() error {
+	eventTypes := make([]interface{}, len(WebhookEventTypes))
+	for i, e := range WebhookEventTypes {
+		eventTypes[i] = e
+	}
+
+	return validation.ValidateStruct(w,
+		validation.Field(&w.URL, validation.Required, is.URL),
+		validation.Field(&w.Secret, validation.Required),
+		validation.Field(&w.Events,
+			validation.By(func(interface{}) error {
+				return validation.Validate(w.Events.Data,
+					validation.Each(validation.In(eventTypes...)))
+			}),
+		),
+	)
+}
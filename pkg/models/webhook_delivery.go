@@ -0,0 +1,155 @@
+package models
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookDelivery is a model for the delivery of an event to a webhook. Pending
+// deliveries form a durable queue that is retried until the event is delivered
+// or the maximum number of attempts is reached.
+type WebhookDelivery struct {
+	gorm.Model
+
+	// Webhook is the webhook the event is delivered to.
+	Webhook   Webhook
+	WebhookID uint `gorm:"not null;index"`
+
+	// Event is the type of the delivered event.
+	Event WebhookEventType `gorm:"default:null;not null"`
+
+	// Payload is the JSON payload of the event.
+	Payload datatypes.JSON `gorm:"not null"`
+
+	// Status is the status of the delivery.
+	Status WebhookDeliveryStatus `gorm:"not null;index"`
+
+	// Attempts is the number of delivery attempts made.
+	Attempts int
+
+	// NextAttemptAt is the time of the next delivery attempt for pending
+	// deliveries.
+	NextAttemptAt time.Time `gorm:"index"`
+
+	// LastError is the error of the last failed delivery attempt.
+	LastError string
+
+	// LastResponseCode is the HTTP status code of the last delivery attempt.
+	LastResponseCode int
+
+	// DeliveredAt is the time the event was delivered.
+	DeliveredAt *time.Time
+}
+
+// WebhookDeliveries is a slice of webhook deliveries.
+type WebhookDeliveries []WebhookDelivery
+
+// WebhookDeliveryStatus is the status of a webhook delivery.
+type WebhookDeliveryStatus int
+
+const (
+	UnspecifiedWebhookDeliveryStatus WebhookDeliveryStatus = iota
+	PendingWebhookDeliveryStatus
+	SucceededWebhookDeliveryStatus
+	FailedWebhookDeliveryStatus
+)
+
+// Create creates the webhook delivery in database db.
+func (d *WebhookDelivery) Create(db *gorm.DB) error {
+	if err := validation.ValidateStruct(d,
+		validation.Field(&d.WebhookID, validation.Required),
+		validation.Field(&d.Event, validation.Required),
+		validation.Field(&d.Payload, validation.Required),
+		validation.Field(&d.Status, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Omit(clause.Associations).
+		Create(&d).
+		Error
+}
+
+// Update updates the delivery status fields of the webhook delivery in database
+// db.
+func (d *WebhookDelivery) Update(db *gorm.DB) error {
+	if err := validation.ValidateStruct(d,
+		validation.Field(&d.ID, validation.Required),
+	); err != nil {
+		return err
+	}
+
+	return db.
+		Model(&d).
+		Select("Status", "Attempts", "NextAttemptAt", "LastError",
+			"LastResponseCode", "DeliveredAt").
+		Updates(*d).
+		Error
+}
+
+// FindDue finds up to limit pending webhook deliveries that are due to be
+// attempted at time now, and assigns them to the receiver. When called in a
+// transaction, the found deliveries are locked so that concurrent callers
+// (e.g., other server instances) skip them.
+func (d *WebhookDeliveries) FindDue(db *gorm.DB, now time.Time, limit int) error {
+	return db.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?",
+			PendingWebhookDeliveryStatus, now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&d).
+		Error
+}
+
+// ClaimDueWebhookDeliveries finds up to limit pending webhook deliveries in
+// database db that are due to be attempted at time now, and claims them by
+// moving their next attempt to now plus lease. Deliveries claimed by one caller
+// are skipped by concurrent callers until the lease expires, so a delivery
+// that isn't updated by then (e.g., because the caller exited) is attempted
+// again.
+func ClaimDueWebhookDeliveries(
+	db *gorm.DB, now time.Time, limit int, lease time.Duration,
+) (WebhookDeliveries, error) {
+	var ds WebhookDeliveries
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ds.FindDue(tx, now, limit); err != nil {
+			return err
+		}
+		if len(ds) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(ds))
+		for i := range ds {
+			ids[i] = ds[i].ID
+			ds[i].NextAttemptAt = now.Add(lease)
+		}
+		return tx.
+			Model(&WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).
+			Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// FindByWebhook finds the most recent (up to limit) deliveries for webhook ID
+// webhookID, and assigns them to the receiver.
+func (d *WebhookDeliveries) FindByWebhook(
+	db *gorm.DB, webhookID uint, limit int) error {
+	return db.
+		Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&d).
+		Error
+}
@@ -0,0 +1,199 @@
+package models
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestWebhookModel(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	t.Run("Create, update, and delete", func(t *testing.T) {
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		t.Run("Create a webhook without a URL", func(t *testing.T) {
+			require := require.New(t)
+			w := Webhook{
+				Secret: "secret",
+			}
+			err := w.Create(db)
+			require.Error(err)
+		})
+
+		t.Run("Create a webhook with an unknown event", func(t *testing.T) {
+			require := require.New(t)
+			w := Webhook{
+				URL:    "https://example.com/hook",
+				Secret: "secret",
+				Events: datatypes.JSONType[[]WebhookEventType]{
+					Data: []WebhookEventType{"document.unknown"},
+				},
+			}
+			err := w.Create(db)
+			require.Error(err)
+		})
+
+		var w Webhook
+		t.Run("Create a webhook", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			w = Webhook{
+				URL:    "https://example.com/hook",
+				Secret: "secret",
+				Events: datatypes.JSONType[[]WebhookEventType]{
+					Data: []WebhookEventType{DocumentApprovedWebhookEvent},
+				},
+				Products: datatypes.JSONType[[]string]{
+					Data: []string{"Product1"},
+				},
+			}
+			err := w.Create(db)
+			require.NoError(err)
+			assert.EqualValues(1, w.ID)
+		})
+
+		t.Run("Get the webhook", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got := Webhook{}
+			got.ID = w.ID
+			err := got.Get(db)
+			require.NoError(err)
+			assert.Equal("https://example.com/hook", got.URL)
+			assert.Equal("secret", got.Secret)
+			assert.Equal([]WebhookEventType{DocumentApprovedWebhookEvent},
+				got.Events.Data)
+			assert.Equal([]string{"Product1"}, got.Products.Data)
+			assert.Empty(got.Teams.Data)
+		})
+
+		t.Run("Update the webhook", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			w.Disabled = true
+			err := w.Update(db)
+			require.NoError(err)
+
+			var ws Webhooks
+			err = ws.Find(db)
+			require.NoError(err)
+			require.Len(ws, 1)
+			assert.True(ws[0].Disabled)
+		})
+
+		t.Run("Delete the webhook", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := w.Delete(db)
+			require.NoError(err)
+
+			var ws Webhooks
+			err = ws.Find(db)
+			require.NoError(err)
+			assert.Empty(ws)
+
+			got := Webhook{}
+			got.ID = w.ID
+			err = got.Get(db)
+			require.ErrorIs(err, gorm.ErrRecordNotFound)
+		})
+	})
+
+	t.Run("Deliveries", func(t *testing.T) {
+		db, tearDownTest := setupTest(t, dsn)
+		defer tearDownTest(t)
+
+		now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		w := Webhook{
+			URL:    "https://example.com/hook",
+			Secret: "secret",
+		}
+		require.NoError(t, w.Create(db))
+
+		t.Run("Create deliveries", func(t *testing.T) {
+			require := require.New(t)
+			for i, next := range []time.Time{
+				now.Add(-time.Minute),
+				now,
+				now.Add(time.Minute),
+			} {
+				d := WebhookDelivery{
+					WebhookID:     w.ID,
+					Event:         DocumentPublishedWebhookEvent,
+					Payload:       datatypes.JSON(`{}`),
+					Status:        PendingWebhookDeliveryStatus,
+					NextAttemptAt: next,
+				}
+				err := d.Create(db)
+				require.NoError(err)
+				require.EqualValues(i+1, d.ID)
+			}
+		})
+
+		t.Run("Find due deliveries", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var ds WebhookDeliveries
+			err := ds.FindDue(db, now, 10)
+			require.NoError(err)
+			require.Len(ds, 2)
+			assert.EqualValues(1, ds[0].ID)
+			assert.EqualValues(2, ds[1].ID)
+		})
+
+		t.Run("Mark a delivery as succeeded", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			d := WebhookDelivery{}
+			d.ID = 1
+			d.Status = SucceededWebhookDeliveryStatus
+			d.Attempts = 1
+			d.LastResponseCode = 200
+			d.DeliveredAt = &now
+			err := d.Update(db)
+			require.NoError(err)
+
+			var ds WebhookDeliveries
+			err = ds.FindDue(db, now, 10)
+			require.NoError(err)
+			require.Len(ds, 1)
+			assert.EqualValues(2, ds[0].ID)
+		})
+
+		t.Run("Find deliveries by webhook", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var ds WebhookDeliveries
+			err := ds.FindByWebhook(db, w.ID, 2)
+			require.NoError(err)
+			require.Len(ds, 2)
+			assert.EqualValues(3, ds[0].ID)
+			assert.EqualValues(2, ds[1].ID)
+		})
+
+		t.Run("Claim due deliveries", func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			ds, err := ClaimDueWebhookDeliveries(db, now, 10, time.Hour)
+			require.NoError(err)
+			require.Len(ds, 1)
+			assert.EqualValues(2, ds[0].ID)
+			assert.Equal(now.Add(time.Hour), ds[0].NextAttemptAt.UTC())
+
+			// Claimed deliveries aren't due until the lease expires.
+			ds, err = ClaimDueWebhookDeliveries(db, now, 10, time.Hour)
+			require.NoError(err)
+			assert.Empty(ds)
+
+			ds, err = ClaimDueWebhookDeliveries(
+				db, now.Add(time.Hour), 10, time.Hour)
+			require.NoError(err)
+			require.Len(ds, 2)
+			assert.EqualValues(3, ds[0].ID)
+			assert.EqualValues(2, ds[1].ID)
+		})
+	})
+}
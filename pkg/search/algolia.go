@@ -0,0 +1,189 @@
+package search
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/errs"
+	"github.com/algolia/algoliasearch-client-go/v3/algolia/opt"
+	algoliasearch "github.com/algolia/algoliasearch-client-go/v3/algolia/search"
+	"github.com/hashicorp-forge/hermes/pkg/algolia"
+)
+
+// AlgoliaProvider is a search provider backed by Algolia.
+type AlgoliaProvider struct {
+	docs     *algoliaIndex
+	drafts   *algoliaIndex
+	internal *algoliaIndex
+	links    *algoliaIndex
+	sections *algoliaIndex
+}
+
+// NewAlgoliaProvider returns a new Algolia search provider. Reads use the
+// search client ar and writes use the write client aw.
+func NewAlgoliaProvider(ar, aw *algolia.Client) *AlgoliaProvider {
+	return &AlgoliaProvider{
+		docs: &algoliaIndex{
+			read:  ar.Docs,
+			write: aw.Docs,
+			replicas: map[string]*algoliasearch.Index{
+				"createdTime:asc":   ar.DocsCreatedTimeAsc,
+				"createdTime:desc":  ar.DocsCreatedTimeDesc,
+				"modifiedTime:desc": ar.DocsModifiedTimeDesc,
+				"dueDate:asc":       ar.DocsDueDateAsc,
+			},
+		},
+		drafts: &algoliaIndex{
+			read:  ar.Drafts,
+			write: aw.Drafts,
+			replicas: map[string]*algoliasearch.Index{
+				"createdTime:asc":   ar.DraftsCreatedTimeAsc,
+				"createdTime:desc":  ar.DraftsCreatedTimeDesc,
+				"modifiedTime:desc": ar.DraftsModifiedTimeDesc,
+			},
+		},
+		internal: &algoliaIndex{read: ar.Internal, write: aw.Internal},
+		links:    &algoliaIndex{read: ar.Links, write: aw.Links},
+		sections: &algoliaIndex{read: ar.Sections, write: aw.Sections},
+	}
+}
+
+func (p *AlgoliaProvider) Name() string    { return ProviderAlgolia }
+func (p *AlgoliaProvider) Docs() Index     { return p.docs }
+func (p *AlgoliaProvider) Drafts() Index   { return p.drafts }
+func (p *AlgoliaProvider) Internal() Index { return p.internal }
+func (p *AlgoliaProvider) Links() Index    { return p.links }
+func (p *AlgoliaProvider) Sections() Index { return p.sections }
+
+// algoliaIndex implements Index for an Algolia index and its sorted replicas.
+type algoliaIndex struct {
+	read  *algoliasearch.Index
+	write *algoliasearch.Index
+
+	// replicas are sorted replica indexes keyed by "attribute:asc" or
+	// "attribute:desc".
+	replicas map[string]*algoliasearch.Index
+}
+
+func (i *algoliaIndex) GetObject(objectID string, obj interface{}) error {
+	if err := i.read.GetObject(objectID, obj); err != nil {
+		if _, is404 := errs.IsAlgoliaErrWithCode(err, 404); is404 {
+			return fmt.Errorf("%w: %v", ErrNotFound, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (i *algoliaIndex) SaveObject(obj interface{}) error {
+	res, err := i.write.SaveObject(obj)
+	if err != nil {
+		return err
+	}
+	return res.Wait()
+}
+
+func (i *algoliaIndex) DeleteObject(objectID string) error {
+	res, err := i.write.DeleteObject(objectID)
+	if err != nil {
+		return err
+	}
+	return res.Wait()
+}
+
+func (i *algoliaIndex) DeleteBy(facetFilters [][]string) error {
+	filters := facetFilterGroups(facetFilters)
+	if len(filters) == 0 {
+		return fmt.Errorf("facet filters are required")
+	}
+
+	res, err := i.write.DeleteBy(opt.FacetFilterAnd(filters...))
+	if err != nil {
+		return err
+	}
+	return res.Wait()
+}
+
+func (i *algoliaIndex) Browse(fn func(obj map[string]interface{}) error) error {
+	// Browse with the write client, whose API key has the browse permission.
+	it, err := i.write.BrowseObjects()
+	if err != nil {
+		return err
+	}
+
+	for {
+		var obj map[string]interface{}
+		if _, err := it.Next(&obj); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := fn(obj); err != nil {
+			return err
+		}
+	}
+}
+
+func (i *algoliaIndex) Search(q Query) (*Result, error) {
+	idx := i.read
+	if q.SortBy != "" {
+		order := "asc"
+		if q.SortDesc {
+			order = "desc"
+		}
+		r, ok := i.replicas[q.SortBy+":"+order]
+		if !ok {
+			return nil, fmt.Errorf(
+				"sorting by %q in %s order is not supported", q.SortBy, order)
+		}
+		idx = r
+	}
+
+	var params []interface{}
+	if filters := facetFilterGroups(q.FacetFilters); len(filters) > 0 {
+		params = append(params, opt.FacetFilterAnd(filters...))
+	}
+	if len(q.Facets) > 0 {
+		params = append(params, opt.Facets(q.Facets...))
+	}
+	if q.MaxValuesPerFacet > 0 {
+		params = append(params, opt.MaxValuesPerFacet(q.MaxValuesPerFacet))
+	}
+	if q.HitsPerPage > 0 {
+		params = append(params, opt.HitsPerPage(q.HitsPerPage))
+	}
+	params = append(params, opt.Page(q.Page))
+
+	res, err := idx.Search(q.Text, params...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Facets:      res.Facets,
+		Hits:        res.Hits,
+		HitsPerPage: res.HitsPerPage,
+		NbHits:      res.NbHits,
+		NbPages:     res.NbPages,
+		Page:        res.Page,
+	}, nil
+}
+
+// facetFilterGroups converts facet filter groups into Algolia facet filter
+// options, dropping empty filters and groups.
+func facetFilterGroups(groups [][]string) []interface{} {
+	var res []interface{}
+	for _, g := range groups {
+		var or []interface{}
+		for _, f := range g {
+			if f != "" {
+				or = append(or, f)
+			}
+		}
+		if len(or) > 0 {
+			res = append(res, opt.FacetFilterOr(or...))
+		}
+	}
+	return res
+}
@@ -0,0 +1,324 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	postgresDocsIndexName     = "docs"
+	postgresDraftsIndexName   = "drafts"
+	postgresInternalIndexName = "internal"
+	postgresLinksIndexName    = "links"
+	postgresSectionsIndexName = "sections"
+
+	// defaultHitsPerPage is the number of results per page if not specified in
+	// a query.
+	defaultHitsPerPage = 20
+
+	// defaultMaxValuesPerFacet is the maximum number of values returned per
+	// facet if not specified in a query.
+	defaultMaxValuesPerFacet = 100
+
+	// postgresFacetValues is a set-returning SQL expression of the values of
+	// the attribute of a search object's data as text. Array attributes
+	// return their elements, and scalar attributes (including booleans and
+	// numbers) return themselves. The attribute name is bound three times.
+	postgresFacetValues = "jsonb_array_elements_text(" +
+		"CASE jsonb_typeof(data->(?::text)) " +
+		"WHEN 'array' THEN data->(?::text) " +
+		"ELSE jsonb_build_array(data->(?::text)) END)"
+)
+
+// PostgresProvider is a search provider that stores objects in PostgreSQL and
+// searches them using full-text search.
+type PostgresProvider struct {
+	db *gorm.DB
+}
+
+// NewPostgresProvider returns a new PostgreSQL search provider using database
+// db, which must have been migrated with the models.SearchObject model.
+func NewPostgresProvider(db *gorm.DB) *PostgresProvider {
+	return &PostgresProvider{db: db}
+}
+
+func (p *PostgresProvider) Name() string { return ProviderPostgres }
+func (p *PostgresProvider) Docs() Index {
+	return &postgresIndex{db: p.db, name: postgresDocsIndexName}
+}
+func (p *PostgresProvider) Drafts() Index {
+	return &postgresIndex{db: p.db, name: postgresDraftsIndexName}
+}
+func (p *PostgresProvider) Internal() Index {
+	return &postgresIndex{db: p.db, name: postgresInternalIndexName}
+}
+func (p *PostgresProvider) Links() Index {
+	return &postgresIndex{db: p.db, name: postgresLinksIndexName}
+}
+func (p *PostgresProvider) Sections() Index {
+	return &postgresIndex{db: p.db, name: postgresSectionsIndexName}
+}
+
+// postgresIndex implements Index for a logical index stored in the
+// search_objects table.
+type postgresIndex struct {
+	db   *gorm.DB
+	name string
+}
+
+func (i *postgresIndex) GetObject(objectID string, obj interface{}) error {
+	o := models.SearchObject{
+		IndexName: i.name,
+		ObjectID:  objectID,
+	}
+	if err := o.Get(i.db); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: index=%q, objectID=%q",
+				ErrNotFound, i.name, objectID)
+		}
+		return fmt.Errorf("error getting search object: %w", err)
+	}
+
+	if err := json.Unmarshal(o.Data, obj); err != nil {
+		return fmt.Errorf("error decoding search object: %w", err)
+	}
+	return nil
+}
+
+func (i *postgresIndex) SaveObject(obj interface{}) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("error encoding search object: %w", err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("error decoding search object: %w", err)
+	}
+	objectID, _ := m["objectID"].(string)
+	if objectID == "" {
+		return fmt.Errorf("search object is missing objectID")
+	}
+
+	o := models.SearchObject{
+		IndexName: i.name,
+		ObjectID:  objectID,
+		Data:      b,
+	}
+	if err := o.Upsert(i.db,
+		joinFields(m, "title", "docNumber"),
+		joinFields(m, "summary", "heading", "owners", "contributors", "product",
+			"docType"),
+		joinFields(m, "content"),
+	); err != nil {
+		return fmt.Errorf("error upserting search object: %w", err)
+	}
+	return nil
+}
+
+func (i *postgresIndex) DeleteObject(objectID string) error {
+	o := models.SearchObject{
+		IndexName: i.name,
+		ObjectID:  objectID,
+	}
+	if err := o.Delete(i.db); err != nil {
+		return fmt.Errorf("error deleting search object: %w", err)
+	}
+	return nil
+}
+
+func (i *postgresIndex) DeleteBy(facetFilters [][]string) error {
+	// Don't delete all objects in the index.
+	if len(facetFilterGroups(facetFilters)) == 0 {
+		return fmt.Errorf("facet filters are required")
+	}
+
+	if err := i.query(Query{FacetFilters: facetFilters}).
+		Delete(&models.SearchObject{}).
+		Error; err != nil {
+		return fmt.Errorf("error deleting search objects: %w", err)
+	}
+	return nil
+}
+
+func (i *postgresIndex) Browse(fn func(obj map[string]interface{}) error) error {
+	var rows [][]byte
+	if err := i.query(Query{}).
+		Order("object_id").
+		Pluck("data", &rows).
+		Error; err != nil {
+		return fmt.Errorf("error getting search objects: %w", err)
+	}
+
+	for _, r := range rows {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(r, &obj); err != nil {
+			return fmt.Errorf("error decoding search object: %w", err)
+		}
+		if err := fn(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *postgresIndex) Search(q Query) (*Result, error) {
+	hitsPerPage := q.HitsPerPage
+	if hitsPerPage <= 0 {
+		hitsPerPage = defaultHitsPerPage
+	}
+	maxValuesPerFacet := q.MaxValuesPerFacet
+	if maxValuesPerFacet <= 0 {
+		maxValuesPerFacet = defaultMaxValuesPerFacet
+	}
+
+	// Count matching objects.
+	var nbHits int64
+	if err := i.query(q).Count(&nbHits).Error; err != nil {
+		return nil, fmt.Errorf("error counting search results: %w", err)
+	}
+
+	// Get the requested page of matching objects.
+	tx := i.query(q)
+	switch {
+	case q.SortBy != "":
+		tx = tx.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "data->(?::text) " + sortOrder(q.SortDesc),
+			Vars: []interface{}{q.SortBy},
+		}})
+	case q.Text != "":
+		tx = tx.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL: "ts_rank(search_vector, " +
+				"websearch_to_tsquery('english', ?)) DESC",
+			Vars: []interface{}{q.Text},
+		}})
+	default:
+		tx = tx.Order("updated_at DESC")
+	}
+	var rows [][]byte
+	if err := tx.
+		Limit(hitsPerPage).
+		Offset(q.Page*hitsPerPage).
+		Pluck("data", &rows).
+		Error; err != nil {
+		return nil, fmt.Errorf("error getting search results: %w", err)
+	}
+
+	res := &Result{
+		Facets:      make(map[string]map[string]int),
+		Hits:        make([]map[string]interface{}, 0, len(rows)),
+		HitsPerPage: hitsPerPage,
+		NbHits:      int(nbHits),
+		NbPages:     (int(nbHits) + hitsPerPage - 1) / hitsPerPage,
+		Page:        q.Page,
+	}
+	for _, r := range rows {
+		var hit map[string]interface{}
+		if err := json.Unmarshal(r, &hit); err != nil {
+			return nil, fmt.Errorf("error decoding search result: %w", err)
+		}
+		res.Hits = append(res.Hits, hit)
+	}
+
+	// Count facet values.
+	for _, f := range q.Facets {
+		if f == "" {
+			continue
+		}
+		var counts []struct {
+			Value string
+			Count int
+		}
+		if err := i.query(q).
+			Select("value, count(*) AS count").
+			Joins("CROSS JOIN LATERAL "+postgresFacetValues+" AS value",
+				f, f, f).
+			Where("value IS NOT NULL").
+			Group("value").
+			Order("count DESC").
+			Limit(maxValuesPerFacet).
+			Scan(&counts).
+			Error; err != nil {
+			return nil, fmt.Errorf("error counting facet values: %w", err)
+		}
+		res.Facets[f] = make(map[string]int, len(counts))
+		for _, c := range counts {
+			res.Facets[f][c.Value] = c.Count
+		}
+	}
+
+	return res, nil
+}
+
+// query returns a query for objects in the index matching the text and facet
+// filters of q.
+func (i *postgresIndex) query(q Query) *gorm.DB {
+	tx := i.db.
+		Model(&models.SearchObject{}).
+		Where("index_name = ?", i.name)
+
+	if q.Text != "" {
+		tx = tx.Where(
+			"search_vector @@ websearch_to_tsquery('english', ?)", q.Text)
+	}
+
+	for _, g := range q.FacetFilters {
+		var (
+			conds []string
+			vars  []interface{}
+		)
+		for _, f := range g {
+			attr, val, ok := strings.Cut(f, ":")
+			if !ok || attr == "" {
+				continue
+			}
+			// Like Algolia, match values ignoring case, and match boolean and
+			// numeric values by their text.
+			cond := "EXISTS (SELECT 1 FROM " + postgresFacetValues +
+				" AS value WHERE lower(value) = lower(?))"
+			if strings.HasPrefix(val, "-") {
+				val = strings.TrimPrefix(val, "-")
+				cond = "NOT " + cond
+			}
+			conds = append(conds, cond)
+			vars = append(vars, attr, attr, attr, val)
+		}
+		if len(conds) > 0 {
+			tx = tx.Where("("+strings.Join(conds, " OR ")+")", vars...)
+		}
+	}
+
+	return tx
+}
+
+// joinFields joins the string and string slice values of the provided keys
+// in m with spaces.
+func joinFields(m map[string]interface{}, keys ...string) string {
+	var s []string
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case string:
+			s = append(s, v)
+		case []interface{}:
+			for _, e := range v {
+				if es, ok := e.(string); ok {
+					s = append(s, es)
+				}
+			}
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// sortOrder returns the SQL sort order keyword.
+func sortOrder(desc bool) string {
+	if desc {
+		return "DESC"
+	}
+	return "ASC"
+}
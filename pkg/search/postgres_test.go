@@ -0,0 +1,192 @@
+package search
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hashicorp-forge/hermes/internal/test"
+	"github.com/hashicorp-forge/hermes/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDoc struct {
+	ObjectID    string   `json:"objectID"`
+	Title       string   `json:"title"`
+	Summary     string   `json:"summary,omitempty"`
+	Owners      []string `json:"owners,omitempty"`
+	Product     string   `json:"product,omitempty"`
+	CreatedTime int64    `json:"createdTime"`
+}
+
+func TestPostgresProvider(t *testing.T) {
+	dsn := os.Getenv("HERMES_TEST_POSTGRESQL_DSN")
+	if dsn == "" {
+		t.Skip("HERMES_TEST_POSTGRESQL_DSN environment variable isn't set")
+	}
+
+	assert, require := assert.New(t), require.New(t)
+	db, _, err := test.CreateTestDatabase(t, dsn)
+	require.NoError(err)
+	require.NoError(db.AutoMigrate(&models.SearchObject{}))
+
+	sp := NewPostgresProvider(db)
+	idx := sp.Docs()
+
+	// Get object, which won't exist yet (should error).
+	var d testDoc
+	err = idx.GetObject("doc1", &d)
+	require.ErrorIs(err, ErrNotFound)
+
+	// Save objects.
+	require.NoError(idx.SaveObject(testDoc{
+		ObjectID:    "doc1",
+		Title:       "Kubernetes networking",
+		Owners:      []string{"a@example.com"},
+		Product:     "Platform",
+		CreatedTime: 1,
+	}))
+	require.NoError(idx.SaveObject(testDoc{
+		ObjectID:    "doc2",
+		Title:       "Payments retries",
+		Summary:     "Retry failed payments with backoff",
+		Owners:      []string{"b@example.com"},
+		Product:     "Payments",
+		CreatedTime: 2,
+	}))
+	require.NoError(sp.Drafts().SaveObject(testDoc{
+		ObjectID: "draft1",
+		Title:    "Payments draft",
+	}))
+
+	// Saving an object without an objectID should error.
+	require.Error(idx.SaveObject(testDoc{Title: "No ID"}))
+
+	// Get object.
+	require.NoError(idx.GetObject("doc2", &d))
+	assert.Equal("Payments retries", d.Title)
+
+	t.Run("full-text search", func(t *testing.T) {
+		res, err := idx.Search(Query{Text: "payments"})
+		require.NoError(err)
+		require.Len(res.Hits, 1)
+		assert.Equal("doc2", res.Hits[0]["objectID"])
+		assert.Equal(1, res.NbHits)
+	})
+
+	t.Run("facet filters and facets", func(t *testing.T) {
+		res, err := idx.Search(Query{
+			FacetFilters: [][]string{
+				{"owners:a@example.com", "owners:b@example.com"},
+				{"product:-Payments"},
+			},
+			Facets: []string{"owners", "product"},
+		})
+		require.NoError(err)
+		require.Len(res.Hits, 1)
+		assert.Equal("doc1", res.Hits[0]["objectID"])
+		assert.Equal(map[string]int{"a@example.com": 1}, res.Facets["owners"])
+		assert.Equal(map[string]int{"Platform": 1}, res.Facets["product"])
+	})
+
+	t.Run("facet filters ignore case and match non-string values",
+		func(t *testing.T) {
+			links := sp.Links()
+			for _, o := range []map[string]interface{}{
+				{"objectID": "a", "status": "Reviewed", "archived": true, "version": 2},
+				{"objectID": "b", "status": "In-Review", "archived": false, "version": 1},
+			} {
+				require.NoError(links.SaveObject(o))
+			}
+
+			for _, filter := range []string{
+				"status:reviewed",
+				"archived:true",
+				"version:2",
+				"status:-in-review",
+			} {
+				res, err := links.Search(Query{
+					FacetFilters: [][]string{{filter}},
+				})
+				require.NoError(err)
+				require.Len(res.Hits, 1, filter)
+				assert.Equal("a", res.Hits[0]["objectID"], filter)
+			}
+		})
+
+	t.Run("sort and paginate", func(t *testing.T) {
+		res, err := idx.Search(Query{
+			HitsPerPage: 1,
+			Page:        1,
+			SortBy:      "createdTime",
+			SortDesc:    true,
+		})
+		require.NoError(err)
+		require.Len(res.Hits, 1)
+		assert.Equal("doc1", res.Hits[0]["objectID"])
+		assert.Equal(2, res.NbHits)
+		assert.Equal(2, res.NbPages)
+	})
+
+	t.Run("browse", func(t *testing.T) {
+		var ids []interface{}
+		require.NoError(idx.Browse(func(obj map[string]interface{}) error {
+			ids = append(ids, obj["objectID"])
+			return nil
+		}))
+		assert.Equal([]interface{}{"doc1", "doc2"}, ids)
+	})
+
+	// Delete object.
+	require.NoError(idx.DeleteObject("doc1"))
+	require.ErrorIs(idx.GetObject("doc1", &d), ErrNotFound)
+
+	t.Run("delete by facet filters", func(t *testing.T) {
+		sections := sp.Sections()
+		for _, s := range []map[string]string{
+			{"objectID": "doc1_0", "docID": "doc1", "content": "Intro"},
+			{"objectID": "doc1_1", "docID": "doc1", "content": "Design"},
+			{"objectID": "doc2_0", "docID": "doc2", "content": "Intro"},
+		} {
+			require.NoError(sections.SaveObject(s))
+		}
+
+		// Deleting without facet filters should error.
+		require.Error(sections.DeleteBy(nil))
+
+		require.NoError(sections.DeleteBy([][]string{{"docID:doc1"}}))
+		res, err := sections.Search(Query{})
+		require.NoError(err)
+		require.Len(res.Hits, 1)
+		assert.Equal("doc2_0", res.Hits[0]["objectID"])
+	})
+}
+
+func TestJoinFields(t *testing.T) {
+	cases := map[string]struct {
+		m    map[string]interface{}
+		keys []string
+		want string
+	}{
+		"strings and slices": {
+			m: map[string]interface{}{
+				"title":  "Title",
+				"owners": []interface{}{"a@example.com", "b@example.com"},
+				"number": 1,
+			},
+			keys: []string{"title", "owners", "number"},
+			want: "Title a@example.com b@example.com",
+		},
+		"missing keys": {
+			m:    map[string]interface{}{},
+			keys: []string{"title"},
+			want: "",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, joinFields(c.m, c.keys...))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp-forge/hermes/pkg/algolia"
+	"gorm.io/gorm"
+)
+
+const (
+	// ProviderAlgolia is the name of the Algolia search provider.
+	ProviderAlgolia = "algolia"
+
+	// ProviderPostgres is the name of the PostgreSQL search provider.
+	ProviderPostgres = "postgres"
+)
+
+// ErrNotFound is returned when an object does not exist in an index.
+var ErrNotFound = errors.New("object not found")
+
+// Provider provides access to Hermes search indexes.
+type Provider interface {
+	// Name returns the name of the provider (e.g., "algolia").
+	Name() string
+
+	// Docs returns the index for published documents.
+	Docs() Index
+
+	// Drafts returns the index for document drafts.
+	Drafts() Index
+
+	// Internal returns the index for internal Hermes metadata.
+	Internal() Index
+
+	// Links returns the index for short link redirects.
+	Links() Index
+
+	// Sections returns the index for sections of published document content.
+	// Section objects are linked to their document by the "docID" attribute.
+	Sections() Index
+}
+
+// NewProvider returns the search provider with the provided name. The Algolia
+// search and write clients ar and aw are used by the Algolia provider, and
+// database db is used by the PostgreSQL provider.
+func NewProvider(
+	name string, ar, aw *algolia.Client, db *gorm.DB) (Provider, error) {
+	switch name {
+	case ProviderAlgolia:
+		if ar == nil || aw == nil {
+			return nil, fmt.Errorf("Algolia clients are required")
+		}
+		return NewAlgoliaProvider(ar, aw), nil
+	case ProviderPostgres:
+		if db == nil {
+			return nil, fmt.Errorf("database is required")
+		}
+		return NewPostgresProvider(db), nil
+	default:
+		return nil, fmt.Errorf("invalid search provider: %q", name)
+	}
+}
+
+// Index is a search index that stores JSON objects keyed by their "objectID"
+// field.
+type Index interface {
+	// GetObject retrieves the object with ID objectID and decodes it into obj.
+	// It returns an error wrapping ErrNotFound if the object does not exist.
+	GetObject(objectID string, obj interface{}) error
+
+	// SaveObject creates or replaces obj in the index. The object must encode
+	// to JSON with a non-empty "objectID" field.
+	SaveObject(obj interface{}) error
+
+	// DeleteObject deletes the object with ID objectID from the index.
+	DeleteObject(objectID string) error
+
+	// DeleteBy deletes all objects matching facetFilters, which are combined
+	// as in Query.FacetFilters.
+	DeleteBy(facetFilters [][]string) error
+
+	// Search searches the index.
+	Search(q Query) (*Result, error)
+
+	// Browse calls fn for every object in the index, stopping at the first
+	// error returned by fn.
+	Browse(fn func(obj map[string]interface{}) error) error
+}
+
+// Query is a search query.
+type Query struct {
+	// Text is the full-text query. An empty string matches all objects.
+	Text string
+
+	// FacetFilters filter results by attribute values in the form
+	// "attribute:value". Filters within a group are combined with OR and groups
+	// are combined with AND. A value prefixed with "-" excludes matches.
+	FacetFilters [][]string
+
+	// Facets are the attributes to return value counts for.
+	Facets []string
+
+	// MaxValuesPerFacet is the maximum number of values returned per facet.
+	MaxValuesPerFacet int
+
+	// HitsPerPage is the number of results per page.
+	HitsPerPage int
+
+	// Page is the zero-based page of results to return.
+	Page int
+
+	// SortBy is the attribute to sort results by. Results are sorted by
+	// relevance if empty.
+	SortBy string
+
+	// SortDesc sorts results in descending order of SortBy.
+	SortDesc bool
+}
+
+// Result is the result of a search query.
+type Result struct {
+	// Facets are value counts per facet attribute.
+	Facets map[string]map[string]int `json:"facets"`
+
+	// Hits are the matching objects. The JSON key is capitalized to stay
+	// compatible with Algolia query responses consumed by the web app.
+	Hits []map[string]interface{} `json:"Hits"`
+
+	// HitsPerPage is the number of results per page.
+	HitsPerPage int `json:"hitsPerPage"`
+
+	// NbHits is the total number of matching objects.
+	NbHits int `json:"nbHits"`
+
+	// NbPages is the total number of pages.
+	NbPages int `json:"nbPages"`
+
+	// Page is the zero-based page of results.
+	Page int `json:"page"`
+}
@@ -0,0 +1,785 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/drive/v3"
+)
+
+const (
+	localContentFile    = "content.md"
+	localMetadataFile   = "metadata.json"
+	localRevisionsDir   = "revisions"
+	localHeaderStart    = "<!-- hermes:header -->"
+	localHeaderEnd      = "<!-- /hermes:header -->"
+	localPermissionUser = "user"
+)
+
+// LocalProvider is a storage provider that keeps files in a directory tree on
+// the local filesystem. Each file is stored in a directory named after its ID,
+// containing its JSON metadata, its Markdown content (for documents), and a
+// snapshot of its content for every revision:
+//
+//	<root>/<file ID>/metadata.json
+//	<root>/<file ID>/content.md
+//	<root>/<file ID>/revisions/<revision ID>.md
+//
+// Folders are files with the folder MIME type. Folder IDs that don't exist in
+// the store (e.g., the configured drafts and documents folders) are valid
+// parents.
+type LocalProvider struct {
+	mu   sync.Mutex
+	root string
+}
+
+// localFile is the metadata of a file in a LocalProvider.
+type localFile struct {
+	ID               string             `json:"id"`
+	Name             string             `json:"name"`
+	MimeType         string             `json:"mimeType"`
+	Parents          []string           `json:"parents"`
+	ShortcutTargetID string             `json:"shortcutTargetId,omitempty"`
+	CreatedTime      time.Time          `json:"createdTime"`
+	ModifiedTime     time.Time          `json:"modifiedTime"`
+	Permissions      []*localPermission `json:"permissions,omitempty"`
+	Revisions        []*localRevision   `json:"revisions,omitempty"`
+}
+
+// localPermission is a permission of a file in a LocalProvider.
+type localPermission struct {
+	ID           string `json:"id"`
+	EmailAddress string `json:"emailAddress"`
+	Role         string `json:"role"`
+}
+
+// localRevision is a revision of a file in a LocalProvider.
+type localRevision struct {
+	ID           string    `json:"id"`
+	ModifiedTime time.Time `json:"modifiedTime"`
+	KeepForever  bool      `json:"keepForever,omitempty"`
+}
+
+var _ Provider = (*LocalProvider)(nil)
+var _ HeaderReplacer = (*LocalProvider)(nil)
+
+// NewLocalProvider returns a local storage provider rooted at directory root,
+// creating the directory if it doesn't exist.
+func NewLocalProvider(root string) (*LocalProvider, error) {
+	if root == "" {
+		return nil, fmt.Errorf("root directory is required")
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating root directory: %w", err)
+	}
+	return &LocalProvider{root: root}, nil
+}
+
+// CreateFile creates a document with the provided name and Markdown content in
+// folder destFolder. It can be used to add templates to the store.
+func (p *LocalProvider) CreateFile(
+	name, destFolder string, content []byte) (*drive.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if name == "" {
+		return nil, fmt.Errorf("file name is required")
+	}
+	if destFolder == "" {
+		return nil, fmt.Errorf("destination folder is required")
+	}
+
+	f, err := p.create(name, DocumentMimeType, destFolder)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.writeContent(f, content); err != nil {
+		return nil, err
+	}
+	return f.driveFile(), nil
+}
+
+// GetContent returns the Markdown content of a document.
+func (p *LocalProvider) GetContent(fileID string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.read(fileID); err != nil {
+		return nil, err
+	}
+	return p.readContent(fileID)
+}
+
+// UpdateContent replaces the Markdown content of a document, creating a new
+// revision.
+func (p *LocalProvider) UpdateContent(fileID string, content []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return err
+	}
+	return p.writeContent(f, content)
+}
+
+// CopyFile copies a file.
+func (p *LocalProvider) CopyFile(
+	fileID, name, destFolder string) (*drive.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	src, err := p.read(fileID)
+	if err != nil {
+		return nil, err
+	}
+	content, err := p.readContent(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := p.create(name, src.MimeType, destFolder)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.writeContent(f, content); err != nil {
+		return nil, err
+	}
+	return f.driveFile(), nil
+}
+
+// CreateFolder creates a folder.
+func (p *LocalProvider) CreateFolder(
+	folderName, destFolder string) (*drive.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Validate inputs.
+	if folderName == "" {
+		return nil, fmt.Errorf("folder name is required")
+	}
+	if destFolder == "" {
+		return nil, fmt.Errorf("destination folder is required")
+	}
+
+	f, err := p.create(folderName, FolderMimeType, destFolder)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.write(f); err != nil {
+		return nil, err
+	}
+	return f.driveFile(), nil
+}
+
+// CreateShortcut creates a shortcut.
+func (p *LocalProvider) CreateShortcut(
+	targetFileID, destFolder string) (*drive.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Validate inputs.
+	if targetFileID == "" {
+		return nil, fmt.Errorf("target file ID is required")
+	}
+	if destFolder == "" {
+		return nil, fmt.Errorf("destination folder is required")
+	}
+
+	target, err := p.read(targetFileID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting target file: %w", err)
+	}
+
+	f, err := p.create(target.Name, ShortcutMimeType, destFolder)
+	if err != nil {
+		return nil, err
+	}
+	f.ShortcutTargetID = targetFileID
+	if err := p.write(f); err != nil {
+		return nil, err
+	}
+	return f.driveFile(), nil
+}
+
+// DeleteFile deletes a file.
+func (p *LocalProvider) DeleteFile(fileID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.read(fileID); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(p.dir(fileID)); err != nil {
+		return fmt.Errorf("error deleting file: %w", err)
+	}
+	return nil
+}
+
+// DeletePermission deletes a permission from a file.
+func (p *LocalProvider) DeletePermission(fileID, permissionID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return err
+	}
+	for i, perm := range f.Permissions {
+		if perm.ID == permissionID {
+			f.Permissions = append(f.Permissions[:i], f.Permissions[i+1:]...)
+			return p.write(f)
+		}
+	}
+	return fmt.Errorf("permission not found: %s", permissionID)
+}
+
+// GetDoc returns a document with its Markdown content as one paragraph per
+// line.
+func (p *LocalProvider) GetDoc(id string) (*docs.Document, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(id)
+	if err != nil {
+		return nil, err
+	}
+	content, err := p.readContent(id)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &docs.Document{
+		DocumentId: f.ID,
+		Title:      f.Name,
+		Body:       &docs.Body{},
+	}
+	if n := len(f.Revisions); n > 0 {
+		doc.RevisionId = f.Revisions[n-1].ID
+	}
+
+	// Document body indexes start at 1 and are in UTF-16 code units.
+	idx := int64(1)
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if line == "" {
+			continue
+		}
+		n := int64(len(utf16.Encode([]rune(line))))
+		doc.Body.Content = append(doc.Body.Content, &docs.StructuralElement{
+			StartIndex: idx,
+			EndIndex:   idx + n,
+			Paragraph: &docs.Paragraph{
+				Elements: []*docs.ParagraphElement{
+					{
+						StartIndex: idx,
+						EndIndex:   idx + n,
+						TextRun:    &docs.TextRun{Content: line},
+					},
+				},
+			},
+		})
+		idx += n
+	}
+
+	return doc, nil
+}
+
+// GetDocs returns all documents in a folder.
+func (p *LocalProvider) GetDocs(folderID string) ([]*drive.File, error) {
+	return p.GetFiles(folderID, DocumentMimeType)
+}
+
+// GetFile returns a file.
+func (p *LocalProvider) GetFile(fileID string) (*drive.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return nil, err
+	}
+	return f.driveFile(), nil
+}
+
+// GetFiles returns all files with the provided MIME type in a folder.
+func (p *LocalProvider) GetFiles(
+	folderID, mimeType string) ([]*drive.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.list(func(f *localFile) bool {
+		return f.MimeType == mimeType && f.hasParent(folderID)
+	})
+}
+
+// GetLatestRevision returns the latest revision of a file.
+func (p *LocalProvider) GetLatestRevision(
+	fileID string) (*drive.Revision, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Revisions) == 0 {
+		return nil, fmt.Errorf("no revisions found")
+	}
+	return f.Revisions[len(f.Revisions)-1].driveRevision(), nil
+}
+
+// GetSubfolder returns the subfolder named subfolderName in folder folderID,
+// or nil if it doesn't exist.
+func (p *LocalProvider) GetSubfolder(
+	folderID, subfolderName string) (*drive.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	folders, err := p.list(func(f *localFile) bool {
+		return f.MimeType == FolderMimeType &&
+			f.Name == subfolderName &&
+			f.hasParent(folderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(folders) == 0 {
+		return nil, nil
+	}
+	return folders[0], nil
+}
+
+// GetUpdatedDocsBetween returns all documents in a folder that were modified
+// after afterTime and no later than beforeTime.
+func (p *LocalProvider) GetUpdatedDocsBetween(
+	folderID, afterTime, beforeTime string) ([]*drive.File, error) {
+	after, err := time.Parse(time.RFC3339Nano, afterTime)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing after time: %w", err)
+	}
+	before, err := time.Parse(time.RFC3339Nano, beforeTime)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing before time: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.list(func(f *localFile) bool {
+		return f.MimeType == DocumentMimeType &&
+			f.hasParent(folderID) &&
+			f.ModifiedTime.After(after) &&
+			!f.ModifiedTime.After(before)
+	})
+}
+
+// KeepRevisionForever keeps a file revision forever.
+func (p *LocalProvider) KeepRevisionForever(
+	fileID, revisionID string) (*drive.Revision, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rev := range f.Revisions {
+		if rev.ID == revisionID {
+			rev.KeepForever = true
+			if err := p.write(f); err != nil {
+				return nil, err
+			}
+			return rev.driveRevision(), nil
+		}
+	}
+	return nil, fmt.Errorf("revision not found: %s", revisionID)
+}
+
+// ListPermissions lists the permissions of a file.
+func (p *LocalProvider) ListPermissions(
+	fileID string) ([]*drive.Permission, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return nil, err
+	}
+	var perms []*drive.Permission
+	for _, perm := range f.Permissions {
+		perms = append(perms, &drive.Permission{
+			Id:           perm.ID,
+			EmailAddress: perm.EmailAddress,
+			Role:         perm.Role,
+			Type:         localPermissionUser,
+		})
+	}
+	return perms, nil
+}
+
+// ListRevisions lists the revisions of a file, oldest first.
+func (p *LocalProvider) ListRevisions(fileID string) ([]*drive.Revision, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return nil, err
+	}
+	var revs []*drive.Revision
+	for _, rev := range f.Revisions {
+		revs = append(revs, rev.driveRevision())
+	}
+	return revs, nil
+}
+
+// MoveFile moves a file to folder destFolder.
+func (p *LocalProvider) MoveFile(
+	fileID, destFolder string) (*drive.File, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if destFolder == "" {
+		return nil, fmt.Errorf("destination folder cannot be empty")
+	}
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return nil, err
+	}
+	f.Parents = []string{destFolder}
+	if err := p.write(f); err != nil {
+		return nil, err
+	}
+	return f.driveFile(), nil
+}
+
+// RenameFile renames a file.
+func (p *LocalProvider) RenameFile(fileID, newName string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return err
+	}
+	f.Name = newName
+	f.ModifiedTime = time.Now().UTC()
+	return p.write(f)
+}
+
+// ReplaceHeader replaces the header of a document with a Markdown table of
+// fields. The header is the first block delimited by header comments, and is
+// added to the top of the document if it doesn't exist.
+func (p *LocalProvider) ReplaceHeader(
+	fileID string, fields []HeaderField) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return err
+	}
+	content, err := p.readContent(fileID)
+	if err != nil {
+		return err
+	}
+
+	return p.writeContent(f, []byte(replaceMarkdownHeader(
+		string(content), renderMarkdownHeader(fields))))
+}
+
+// ReplaceText replaces all "{{key}}" placeholders in a document with their
+// values in r.
+func (p *LocalProvider) ReplaceText(id string, r map[string]string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(id)
+	if err != nil {
+		return err
+	}
+	content, err := p.readContent(id)
+	if err != nil {
+		return err
+	}
+
+	s := string(content)
+	for k, v := range r {
+		s = strings.ReplaceAll(s, fmt.Sprintf("{{%s}}", k), v)
+	}
+	return p.writeContent(f, []byte(s))
+}
+
+// ShareFile shares a file with a user. Sharing with a user that already has a
+// permission updates the role.
+func (p *LocalProvider) ShareFile(fileID, email, role string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := p.read(fileID)
+	if err != nil {
+		return err
+	}
+	for _, perm := range f.Permissions {
+		if strings.EqualFold(perm.EmailAddress, email) {
+			perm.Role = role
+			return p.write(f)
+		}
+	}
+	id, err := newLocalID()
+	if err != nil {
+		return err
+	}
+	f.Permissions = append(f.Permissions, &localPermission{
+		ID:           id,
+		EmailAddress: email,
+		Role:         role,
+	})
+	return p.write(f)
+}
+
+// create returns the metadata for a new file. It is not written until write or
+// writeContent is called.
+func (p *LocalProvider) create(
+	name, mimeType, destFolder string) (*localFile, error) {
+	id, err := newLocalID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &localFile{
+		ID:           id,
+		Name:         name,
+		MimeType:     mimeType,
+		Parents:      []string{destFolder},
+		CreatedTime:  now,
+		ModifiedTime: now,
+	}, nil
+}
+
+// dir returns the directory of a file.
+func (p *LocalProvider) dir(fileID string) string {
+	return filepath.Join(p.root, fileID)
+}
+
+// list returns all files matching filter, ordered by name.
+func (p *LocalProvider) list(
+	filter func(f *localFile) bool) ([]*drive.File, error) {
+	entries, err := os.ReadDir(p.root)
+	if err != nil {
+		return nil, fmt.Errorf("error reading root directory: %w", err)
+	}
+
+	var files []*drive.File
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		f, err := p.read(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		if filter(f) {
+			files = append(files, f.driveFile())
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// read reads the metadata of a file.
+func (p *LocalProvider) read(fileID string) (*localFile, error) {
+	if !validLocalID(fileID) {
+		return nil, fmt.Errorf("invalid file ID: %q", fileID)
+	}
+	b, err := os.ReadFile(filepath.Join(p.dir(fileID), localMetadataFile))
+	if err != nil {
+		return nil, fmt.Errorf("error reading file metadata: %w", err)
+	}
+	var f localFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("error unmarshaling file metadata: %w", err)
+	}
+	return &f, nil
+}
+
+// readContent reads the content of a file. Files without content (e.g.,
+// folders) have empty content.
+func (p *LocalProvider) readContent(fileID string) ([]byte, error) {
+	b, err := os.ReadFile(filepath.Join(p.dir(fileID), localContentFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading file content: %w", err)
+	}
+	return b, nil
+}
+
+// write writes the metadata of a file.
+func (p *LocalProvider) write(f *localFile) error {
+	if err := os.MkdirAll(p.dir(f.ID), 0o755); err != nil {
+		return fmt.Errorf("error creating file directory: %w", err)
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling file metadata: %w", err)
+	}
+	if err := writeFileAtomic(
+		filepath.Join(p.dir(f.ID), localMetadataFile), b); err != nil {
+		return fmt.Errorf("error writing file metadata: %w", err)
+	}
+	return nil
+}
+
+// writeContent writes the content of a file, saves a snapshot of it as a new
+// revision, and writes the updated metadata.
+func (p *LocalProvider) writeContent(f *localFile, content []byte) error {
+	revDir := filepath.Join(p.dir(f.ID), localRevisionsDir)
+	if err := os.MkdirAll(revDir, 0o755); err != nil {
+		return fmt.Errorf("error creating revisions directory: %w", err)
+	}
+
+	now := time.Now().UTC()
+	rev := &localRevision{
+		ID:           strconv.Itoa(len(f.Revisions) + 1),
+		ModifiedTime: now,
+	}
+	if err := writeFileAtomic(
+		filepath.Join(revDir, rev.ID+".md"), content); err != nil {
+		return fmt.Errorf("error writing revision: %w", err)
+	}
+	if err := writeFileAtomic(
+		filepath.Join(p.dir(f.ID), localContentFile), content); err != nil {
+		return fmt.Errorf("error writing file content: %w", err)
+	}
+
+	f.Revisions = append(f.Revisions, rev)
+	f.ModifiedTime = now
+	return p.write(f)
+}
+
+// driveFile returns the file as a Google Drive file.
+func (f *localFile) driveFile() *drive.File {
+	df := &drive.File{
+		Id:           f.ID,
+		Name:         f.Name,
+		MimeType:     f.MimeType,
+		Parents:      f.Parents,
+		CreatedTime:  f.CreatedTime.Format(time.RFC3339Nano),
+		ModifiedTime: f.ModifiedTime.Format(time.RFC3339Nano),
+	}
+	if n := len(f.Revisions); n > 0 {
+		df.HeadRevisionId = f.Revisions[n-1].ID
+	}
+	if f.ShortcutTargetID != "" {
+		df.ShortcutDetails = &drive.FileShortcutDetails{
+			TargetId: f.ShortcutTargetID,
+		}
+	}
+	return df
+}
+
+// hasParent returns true if folderID is a parent of the file.
+func (f *localFile) hasParent(folderID string) bool {
+	for _, p := range f.Parents {
+		if p == folderID {
+			return true
+		}
+	}
+	return false
+}
+
+// driveRevision returns the revision as a Google Drive revision.
+func (r *localRevision) driveRevision() *drive.Revision {
+	return &drive.Revision{
+		Id:           r.ID,
+		KeepForever:  r.KeepForever,
+		MimeType:     "text/markdown",
+		ModifiedTime: r.ModifiedTime.Format(time.RFC3339Nano),
+	}
+}
+
+// renderMarkdownHeader renders header fields as a Markdown table wrapped in
+// header comments.
+func renderMarkdownHeader(fields []HeaderField) string {
+	var b strings.Builder
+	b.WriteString(localHeaderStart + "\n")
+	b.WriteString("| | |\n| --- | --- |\n")
+	for _, f := range fields {
+		fmt.Fprintf(&b, "| **%s** | %s |\n",
+			escapeMarkdownCell(f.Name), escapeMarkdownCell(f.Value))
+	}
+	b.WriteString(localHeaderEnd + "\n")
+	return b.String()
+}
+
+// replaceMarkdownHeader replaces the header in Markdown content s, or adds it
+// to the top if s doesn't have one.
+func replaceMarkdownHeader(s, header string) string {
+	start := strings.Index(s, localHeaderStart)
+	if start >= 0 {
+		if end := strings.Index(s[start:], localHeaderEnd); end >= 0 {
+			end += start + len(localHeaderEnd)
+			if end < len(s) && s[end] == '\n' {
+				end++
+			}
+			return s[:start] + header + s[end:]
+		}
+	}
+	if s == "" {
+		return header
+	}
+	return header + "\n" + s
+}
+
+// escapeMarkdownCell escapes s for use in a Markdown table cell.
+func escapeMarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// newLocalID returns a new random file ID.
+func newLocalID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validLocalID returns true if id can be safely used as a directory name.
+func validLocalID(id string) bool {
+	return id != "" && id != "." && id != ".." &&
+		!strings.ContainsAny(id, `/\`)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to name so
+// readers never see a partially written file.
+func writeFileAtomic(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
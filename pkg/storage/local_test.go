@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalProvider(t *testing.T) {
+	p, err := NewLocalProvider(t.TempDir())
+	require.NoError(t, err)
+
+	// Create template.
+	tmpl, err := p.CreateFile("Template", "templates",
+		[]byte("# {{title}}\n\nOwner: {{owner}}\n"))
+	require.NoError(t, err)
+
+	t.Run("copy template to drafts", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+
+		f, err := p.CopyFile(tmpl.Id, "My Draft", "drafts")
+		require.NoError(err)
+		assert.NotEqual(tmpl.Id, f.Id)
+		assert.Equal("My Draft", f.Name)
+		assert.Equal([]string{"drafts"}, f.Parents)
+		assert.Equal(DocumentMimeType, f.MimeType)
+
+		require.NoError(p.ReplaceText(f.Id, map[string]string{
+			"title": "My Draft",
+			"owner": "a@example.com",
+		}))
+		content, err := p.GetContent(f.Id)
+		require.NoError(err)
+		assert.Equal("# My Draft\n\nOwner: a@example.com\n", string(content))
+
+		// The template is unchanged.
+		content, err = p.GetContent(tmpl.Id)
+		require.NoError(err)
+		assert.Equal("# {{title}}\n\nOwner: {{owner}}\n", string(content))
+
+		docs, err := p.GetDocs("drafts")
+		require.NoError(err)
+		require.Len(docs, 1)
+		assert.Equal(f.Id, docs[0].Id)
+	})
+
+	t.Run("move draft to published and create shortcut", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+
+		f, err := p.CopyFile(tmpl.Id, "To Publish", "drafts")
+		require.NoError(err)
+
+		// Create shortcut folder structure.
+		folder, err := p.GetSubfolder("shortcuts", "Product")
+		require.NoError(err)
+		assert.Nil(folder)
+		folder, err = p.CreateFolder("Product", "shortcuts")
+		require.NoError(err)
+		got, err := p.GetSubfolder("shortcuts", "Product")
+		require.NoError(err)
+		require.NotNil(got)
+		assert.Equal(folder.Id, got.Id)
+
+		shortcut, err := p.CreateShortcut(f.Id, folder.Id)
+		require.NoError(err)
+		assert.Equal("To Publish", shortcut.Name)
+		require.NotNil(shortcut.ShortcutDetails)
+		assert.Equal(f.Id, shortcut.ShortcutDetails.TargetId)
+
+		moved, err := p.MoveFile(f.Id, "docs")
+		require.NoError(err)
+		assert.Equal([]string{"docs"}, moved.Parents)
+
+		docs, err := p.GetDocs("docs")
+		require.NoError(err)
+		require.Len(docs, 1)
+		assert.Equal(f.Id, docs[0].Id)
+		docs, err = p.GetDocs("drafts")
+		require.NoError(err)
+		for _, d := range docs {
+			assert.NotEqual(f.Id, d.Id)
+		}
+	})
+
+	t.Run("replace header", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+
+		f, err := p.CreateFile("Header", "drafts", []byte("Body\n"))
+		require.NoError(err)
+
+		require.NoError(p.ReplaceHeader(f.Id, []HeaderField{
+			{Name: "Title", Value: "Header"},
+			{Name: "Status", Value: "WIP"},
+		}))
+		require.NoError(p.ReplaceHeader(f.Id, []HeaderField{
+			{Name: "Title", Value: "Header"},
+			{Name: "Status", Value: "In-Review"},
+		}))
+
+		content, err := p.GetContent(f.Id)
+		require.NoError(err)
+		assert.Equal(localHeaderStart+"\n"+
+			"| | |\n| --- | --- |\n"+
+			"| **Title** | Header |\n"+
+			"| **Status** | In-Review |\n"+
+			localHeaderEnd+"\n"+
+			"\nBody\n",
+			string(content))
+
+		doc, err := p.GetDoc(f.Id)
+		require.NoError(err)
+		assert.Equal("Header", doc.Title)
+		assert.Len(doc.Body.Content, 8)
+	})
+
+	t.Run("revisions", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+
+		f, err := p.CreateFile("Revisions", "docs", []byte("v1"))
+		require.NoError(err)
+		require.NoError(p.UpdateContent(f.Id, []byte("v2")))
+
+		revs, err := p.ListRevisions(f.Id)
+		require.NoError(err)
+		require.Len(revs, 2)
+		assert.Equal("1", revs[0].Id)
+		assert.Equal("2", revs[1].Id)
+
+		latest, err := p.GetLatestRevision(f.Id)
+		require.NoError(err)
+		assert.Equal("2", latest.Id)
+		assert.False(latest.KeepForever)
+
+		kept, err := p.KeepRevisionForever(f.Id, latest.Id)
+		require.NoError(err)
+		assert.True(kept.KeepForever)
+
+		_, err = p.KeepRevisionForever(f.Id, "3")
+		assert.Error(err)
+	})
+
+	t.Run("permissions", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+
+		f, err := p.CreateFile("Permissions", "drafts", nil)
+		require.NoError(err)
+		require.NoError(p.ShareFile(f.Id, "a@example.com", "writer"))
+		require.NoError(p.ShareFile(f.Id, "b@example.com", "reader"))
+		require.NoError(p.ShareFile(f.Id, "b@example.com", "writer"))
+
+		perms, err := p.ListPermissions(f.Id)
+		require.NoError(err)
+		require.Len(perms, 2)
+		assert.Equal("writer", perms[1].Role)
+
+		require.NoError(p.DeletePermission(f.Id, perms[0].Id))
+		perms, err = p.ListPermissions(f.Id)
+		require.NoError(err)
+		require.Len(perms, 1)
+		assert.Equal("b@example.com", perms[0].EmailAddress)
+	})
+
+	t.Run("updated docs between", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+
+		before := time.Now().UTC()
+		f, err := p.CreateFile("Updated", "updated", []byte("content"))
+		require.NoError(err)
+		after := time.Now().UTC()
+
+		docs, err := p.GetUpdatedDocsBetween("updated",
+			before.Add(-time.Second).Format(time.RFC3339Nano),
+			after.Format(time.RFC3339Nano))
+		require.NoError(err)
+		require.Len(docs, 1)
+		assert.Equal(f.Id, docs[0].Id)
+
+		docs, err = p.GetUpdatedDocsBetween("updated",
+			after.Format(time.RFC3339Nano),
+			after.Add(time.Hour).Format(time.RFC3339Nano))
+		require.NoError(err)
+		assert.Empty(docs)
+	})
+
+	t.Run("rename and delete", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+
+		f, err := p.CreateFile("Old", "drafts", nil)
+		require.NoError(err)
+		require.NoError(p.RenameFile(f.Id, "New"))
+		got, err := p.GetFile(f.Id)
+		require.NoError(err)
+		assert.Equal("New", got.Name)
+
+		require.NoError(p.DeleteFile(f.Id))
+		_, err = p.GetFile(f.Id)
+		assert.Error(err)
+	})
+
+	t.Run("invalid file IDs", func(t *testing.T) {
+		assert := assert.New(t)
+
+		for _, id := range []string{"", ".", "..", "../x", `a\b`} {
+			_, err := p.GetFile(id)
+			assert.Error(err, id)
+		}
+	})
+}
+
+func TestReplaceMarkdownHeader(t *testing.T) {
+	header := localHeaderStart + "\nnew\n" + localHeaderEnd + "\n"
+
+	cases := map[string]struct {
+		s    string
+		want string
+	}{
+		"empty content": {
+			s:    "",
+			want: header,
+		},
+		"no header": {
+			s:    "Body\n",
+			want: header + "\nBody\n",
+		},
+		"existing header": {
+			s:    "Intro\n" + localHeaderStart + "\nold\n" + localHeaderEnd + "\nBody\n",
+			want: "Intro\n" + header + "Body\n",
+		},
+		"unterminated header": {
+			s:    localHeaderStart + "\nBody\n",
+			want: header + "\n" + localHeaderStart + "\nBody\n",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, c.want, replaceMarkdownHeader(c.s, header))
+		})
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider(ProviderGoogle, nil, "")
+	assert.Error(t, err)
+
+	p, err := NewProvider(ProviderLocal, nil, t.TempDir())
+	require.NoError(t, err)
+	assert.IsType(t, &LocalProvider{}, p)
+
+	_, err = NewProvider("invalid", nil, "")
+	assert.Error(t, err)
+}
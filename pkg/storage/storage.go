@@ -0,0 +1,171 @@
+// Package storage contains document storage providers used by Hermes.
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	gw "github.com/hashicorp-forge/hermes/pkg/googleworkspace"
+	"google.golang.org/api/docs/v1"
+	"google.golang.org/api/drive/v3"
+)
+
+const (
+	// ProviderGoogle is the name of the Google Workspace storage provider.
+	ProviderGoogle = "google"
+
+	// ProviderLocal is the name of the local filesystem storage provider.
+	ProviderLocal = "local"
+)
+
+const (
+	// DocumentMimeType is the MIME type of documents.
+	DocumentMimeType = "application/vnd.google-apps.document"
+
+	// FolderMimeType is the MIME type of folders.
+	FolderMimeType = "application/vnd.google-apps.folder"
+
+	// ShortcutMimeType is the MIME type of shortcuts.
+	ShortcutMimeType = "application/vnd.google-apps.shortcut"
+)
+
+// Provider stores Hermes documents. Files and revisions are represented with
+// Google Drive API types so that providers are interchangeable with the Google
+// Workspace service.
+type Provider interface {
+	// CopyFile copies file fileID to a new file with the provided name in folder
+	// destFolder.
+	CopyFile(fileID, name, destFolder string) (*drive.File, error)
+
+	// CreateFolder creates a folder named folderName in folder destFolder.
+	CreateFolder(folderName, destFolder string) (*drive.File, error)
+
+	// CreateShortcut creates a shortcut to file targetFileID in folder
+	// destFolder.
+	CreateShortcut(targetFileID, destFolder string) (*drive.File, error)
+
+	// DeleteFile deletes a file.
+	DeleteFile(fileID string) error
+
+	// DeletePermission deletes a permission from a file.
+	DeletePermission(fileID, permissionID string) error
+
+	// GetDoc returns the contents of a document.
+	GetDoc(id string) (*docs.Document, error)
+
+	// GetDocs returns all documents in a folder.
+	GetDocs(folderID string) ([]*drive.File, error)
+
+	// GetFile returns a file.
+	GetFile(fileID string) (*drive.File, error)
+
+	// GetFiles returns all files with the provided MIME type in a folder. It is
+	// the portable form of listing files; query strings are specific to the
+	// Google Drive API.
+	GetFiles(folderID, mimeType string) ([]*drive.File, error)
+
+	// GetLatestRevision returns the latest revision of a file.
+	GetLatestRevision(fileID string) (*drive.Revision, error)
+
+	// GetSubfolder returns the subfolder named subfolderName in folder folderID,
+	// or nil if it doesn't exist.
+	GetSubfolder(folderID, subfolderName string) (*drive.File, error)
+
+	// GetUpdatedDocsBetween returns all documents in a folder that were modified
+	// after afterTime and no later than beforeTime (RFC 3339 timestamps).
+	GetUpdatedDocsBetween(
+		folderID, afterTime, beforeTime string) ([]*drive.File, error)
+
+	// KeepRevisionForever keeps a file revision forever.
+	KeepRevisionForever(fileID, revisionID string) (*drive.Revision, error)
+
+	// ListPermissions lists the permissions of a file.
+	ListPermissions(fileID string) ([]*drive.Permission, error)
+
+	// ListRevisions lists the revisions of a file.
+	ListRevisions(fileID string) ([]*drive.Revision, error)
+
+	// MoveFile moves a file to folder destFolder.
+	MoveFile(fileID, destFolder string) (*drive.File, error)
+
+	// RenameFile renames a file.
+	RenameFile(fileID, newName string) error
+
+	// ReplaceText replaces all "{{key}}" placeholders in a document with their
+	// values in r.
+	ReplaceText(id string, r map[string]string) error
+
+	// ShareFile shares a file with a user.
+	ShareFile(fileID, email, role string) error
+}
+
+// HeaderField is a field of a document header.
+type HeaderField struct {
+	// Name is the name of the field (e.g., "Status").
+	Name string
+
+	// Value is the value of the field.
+	Value string
+}
+
+// HeaderReplacer is implemented by providers that write document headers
+// themselves instead of through Google Docs API requests.
+type HeaderReplacer interface {
+	// ReplaceHeader replaces the header of document fileID with fields.
+	ReplaceHeader(fileID string, fields []HeaderField) error
+}
+
+// ChangeWatcher is implemented by providers that can list changes to files
+// since a page token and push notifications of changes, like the Google Drive
+// Changes API.
+type ChangeWatcher interface {
+	// GetChangesStartPageToken returns the page token for listing future
+	// changes.
+	GetChangesStartPageToken() (string, error)
+
+	// ListChanges lists all changes since page token pageToken, and returns them
+	// with the page token for listing later changes.
+	ListChanges(pageToken string) ([]*drive.Change, string, error)
+
+	// WatchChanges subscribes to push notifications for changes since page
+	// token pageToken, which are sent to URL address with channel ID channelID
+	// and token token until time expiration.
+	WatchChanges(
+		pageToken, channelID, address, token string,
+		expiration time.Time,
+	) (*drive.Channel, error)
+
+	// StopChannel stops push notifications for a channel.
+	StopChannel(ch *drive.Channel) error
+}
+
+// The Google Workspace service is a storage provider that can watch changes.
+var (
+	_ Provider      = (*gw.Service)(nil)
+	_ ChangeWatcher = (*gw.Service)(nil)
+)
+
+// NewProvider returns the storage provider with the provided name. The Google
+// Workspace service s is used by the Google provider, and directory localRoot
+// is used by the local provider.
+func NewProvider(name string, s *gw.Service, localRoot string) (Provider, error) {
+	switch name {
+	case ProviderGoogle:
+		if s == nil {
+			return nil, fmt.Errorf("Google Workspace service is required")
+		}
+		return s, nil
+	case ProviderLocal:
+		return NewLocalProvider(localRoot)
+	default:
+		return nil, fmt.Errorf("invalid storage provider: %q", name)
+	}
+}
+
+// CommentLister is implemented by providers that store comments on documents,
+// like Google Drive.
+type CommentLister interface {
+	// ListComments lists the comments on file fileID created after afterTime (an
+	// RFC 3339 timestamp).
+	ListComments(fileID, afterTime string) ([]*drive.Comment, error)
+}
@@ -10,7 +10,7 @@ import (
 
 	"github.com/hashicorp-forge/hermes/internal/config"
 	"github.com/hashicorp-forge/hermes/internal/pkg/featureflags"
-	"github.com/hashicorp-forge/hermes/pkg/algolia"
+	"github.com/hashicorp-forge/hermes/pkg/search"
 	"github.com/hashicorp/go-hclog"
 )
 
@@ -69,7 +69,7 @@ type ConfigResponse struct {
 // ConfigHandler returns runtime configuration for the Hermes frontend.
 func ConfigHandler(
 	cfg *config.Config,
-	a *algolia.Client,
+	sp search.Provider,
 	log hclog.Logger,
 ) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -83,7 +83,7 @@ func ConfigHandler(
 		// in the configuration
 		featureFlags := featureflags.SetAndToggle(
 			cfg.FeatureFlags,
-			a,
+			sp,
 			// Use the "x-amzn-oidc-identity" header if set
 			// as id to be hashed and toggle flags.
 			r.Header.Get("x-amzn-oidc-identity"),
@@ -101,23 +101,29 @@ func ConfigHandler(
 			shortLinkBaseURL = strings.TrimSuffix(cfg.BaseURL, "/") + "/l"
 		}
 
-		// Skip Google auth if Okta is not disabled in the config.
+		// Skip Google auth if Okta or OIDC is not disabled in the config.
 		skipGoogleAuth := false
 		if cfg.Okta == nil || (cfg.Okta != nil && !cfg.Okta.Disabled) {
 			skipGoogleAuth = true
 		}
+		if cfg.OIDC != nil && !cfg.OIDC.Disabled {
+			skipGoogleAuth = true
+		}
 
 		response := &ConfigResponse{
-			AlgoliaDocsIndexName:     cfg.Algolia.DocsIndexName,
-			AlgoliaDraftsIndexName:   cfg.Algolia.DraftsIndexName,
-			AlgoliaTemplateIndexName: cfg.Algolia.TemplateIndexName,
-			AlgoliaInternalIndexName: cfg.Algolia.InternalIndexName,
-			FeatureFlags:             featureFlags,
-			GoogleAnalyticsTagID:     cfg.GoogleAnalyticsTagID,
-			GoogleOAuth2ClientID:     cfg.GoogleWorkspace.OAuth2.ClientID,
-			GoogleOAuth2HD:           cfg.GoogleWorkspace.OAuth2.HD,
-			ShortLinkBaseURL:         shortLinkBaseURL,
-			SkipGoogleAuth:           skipGoogleAuth,
+			FeatureFlags:         featureFlags,
+			GoogleAnalyticsTagID: cfg.GoogleAnalyticsTagID,
+			GoogleOAuth2ClientID: cfg.GoogleWorkspace.OAuth2.ClientID,
+			GoogleOAuth2HD:       cfg.GoogleWorkspace.OAuth2.HD,
+			ShortLinkBaseURL:     shortLinkBaseURL,
+			SkipGoogleAuth:       skipGoogleAuth,
+		}
+		// Algolia isn't configured when using the PostgreSQL search provider.
+		if cfg.Algolia != nil {
+			response.AlgoliaDocsIndexName = cfg.Algolia.DocsIndexName
+			response.AlgoliaDraftsIndexName = cfg.Algolia.DraftsIndexName
+			response.AlgoliaTemplateIndexName = cfg.Algolia.TemplateIndexName
+			response.AlgoliaInternalIndexName = cfg.Algolia.InternalIndexName
 		}
 
 		w.Header().Set("Content-Type", "application/json")